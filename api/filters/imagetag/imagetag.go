@@ -24,6 +24,10 @@ type Filter struct {
 	// e.g. Path: "spec/myContainers[]/image"
 	FsSlice types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
 
+	// ResolveDigest, if set, resolves digests for ImageTag.ResolveDigests
+	// in place of the registries.
+	ResolveDigest DigestResolver `json:"-" yaml:"-"`
+
 	trackableSetter filtersutil.TrackableSetter
 }
 
@@ -52,6 +56,7 @@ func (f Filter) filter(node *yaml.RNode) (*yaml.RNode, error) {
 		SetValue: imageTagUpdater{
 			ImageTag:        f.ImageTag,
 			trackableSetter: f.trackableSetter,
+			resolveDigest:   f.ResolveDigest,
 		}.SetImageValue,
 	}); err != nil {
 		return nil, err
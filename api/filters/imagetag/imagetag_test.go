@@ -896,3 +896,62 @@ spec:
 		})
 	}
 }
+
+func TestImageTagUpdater_ResolveDigests(t *testing.T) {
+	var resolved []string
+	resolve := func(name, tag string) (string, error) {
+		resolved = append(resolved, name+":"+tag)
+		return "sha256:" + strings.Repeat(tag[len(tag)-1:], 64), nil
+	}
+	input := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy
+spec:
+  template:
+    spec:
+      initContainers:
+      - image: nginx:1.7.9
+      containers:
+      - image: nginx
+      - image: busybox:1
+`
+	filter := Filter{
+		ImageTag: types.Image{
+			Name:           "nginx",
+			NewName:        "registry.example.com/nginx",
+			NewTag:         "1.25.1",
+			ResolveDigests: true,
+		},
+		FsSlice: []types.FieldSpec{
+			{Path: "spec/template/spec/containers[]/image"},
+			{Path: "spec/template/spec/initContainers[]/image"},
+		},
+		ResolveDigest: resolve,
+	}
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy
+spec:
+  template:
+    spec:
+      initContainers:
+      - image: registry.example.com/nginx@sha256:1111111111111111111111111111111111111111111111111111111111111111
+      containers:
+      - image: registry.example.com/nginx@sha256:1111111111111111111111111111111111111111111111111111111111111111
+      - image: busybox:1
+`), strings.TrimSpace(filtertest.RunFilter(t, input, filter)))
+	assert.Equal(t, []string{
+		"registry.example.com/nginx:1.25.1", "registry.example.com/nginx:1.25.1",
+	}, resolved)
+
+	// An explicit digest is kept.
+	resolved = nil
+	filter.ImageTag.Digest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	assert.Contains(t, filtertest.RunFilter(t, input, filter),
+		"image: registry.example.com/nginx:1.25.1@sha256:2222")
+	assert.Empty(t, resolved)
+}
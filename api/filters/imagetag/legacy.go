@@ -19,6 +19,10 @@ import (
 // of the image is a match with the provided ImageTag.
 type LegacyFilter struct {
 	ImageTag types.Image `json:"imageTag,omitempty" yaml:"imageTag,omitempty"`
+
+	// ResolveDigest, if set, resolves digests for ImageTag.ResolveDigests
+	// in place of the registries.
+	ResolveDigest DigestResolver `json:"-" yaml:"-"`
 }
 
 var _ kio.Filter = LegacyFilter{}
@@ -41,7 +45,7 @@ func (lf LegacyFilter) filter(node *yaml.RNode) (*yaml.RNode, error) {
 
 	fff := findFieldsFilter{
 		fields:        []string{"containers", "initContainers"},
-		fieldCallback: checkImageTagsFn(lf.ImageTag),
+		fieldCallback: checkImageTagsFn(lf.ImageTag, lf.ResolveDigest),
 	}
 	if err := node.PipeE(fff); err != nil {
 		return nil, err
@@ -87,7 +91,7 @@ func (f findFieldsFilter) walk(node *yaml.RNode) error {
 	return nil
 }
 
-func checkImageTagsFn(imageTag types.Image) fieldCallback {
+func checkImageTagsFn(imageTag types.Image, resolveDigest DigestResolver) fieldCallback {
 	return func(node *yaml.RNode) error {
 		if node.YNode().Kind != yaml.SequenceNode {
 			return nil
@@ -97,7 +101,8 @@ func checkImageTagsFn(imageTag types.Image) fieldCallback {
 			// Look up any fields on the provided node that is named
 			// image.
 			return n.PipeE(yaml.Get("image"), imageTagUpdater{
-				ImageTag: imageTag,
+				ImageTag:      imageTag,
+				resolveDigest: resolveDigest,
 			})
 		})
 	}
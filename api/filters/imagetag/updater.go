@@ -4,9 +4,12 @@
 package imagetag
 
 import (
+	"net/http"
+
 	"sigs.k8s.io/kustomize/api/filters/filtersutil"
 
 	"sigs.k8s.io/kustomize/api/internal/image"
+	"sigs.k8s.io/kustomize/api/internal/oci"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -18,8 +21,16 @@ type imageTagUpdater struct {
 	Kind            string      `yaml:"kind,omitempty"`
 	ImageTag        types.Image `yaml:"imageTag,omitempty"`
 	trackableSetter filtersutil.TrackableSetter
+	resolveDigest   DigestResolver
 }
 
+// DigestResolver returns the digest of an image name at a tag.
+type DigestResolver func(name, tag string) (string, error)
+
+// registryResolver looks up digests in the registries, and is
+// shared by the filters so that each tag is looked up once.
+var registryResolver = oci.NewDigestResolver(&http.Client{}, oci.DockerConfigKeychain)
+
 func (u imageTagUpdater) SetImageValue(rn *yaml.RNode) error {
 	if err := yaml.ErrorIfInvalid(rn, yaml.ScalarNode); err != nil {
 		return err
@@ -52,6 +63,18 @@ func (u imageTagUpdater) SetImageValue(rn *yaml.RNode) error {
 		digest = ""
 	}
 
+	if u.ImageTag.ResolveDigests && digest == "" {
+		resolve := u.resolveDigest
+		if resolve == nil {
+			resolve = registryResolver.Resolve
+		}
+		var err error
+		if digest, err = resolve(name, tag); err != nil {
+			return err
+		}
+		tag = ""
+	}
+
 	// build final image name
 	if tag != "" {
 		name += ":" + tag
@@ -0,0 +1,6 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package networkpolicygenerator contains a kio.Filter that generates
+// NetworkPolicy resources from a compact list of allowed peers.
+package networkpolicygenerator
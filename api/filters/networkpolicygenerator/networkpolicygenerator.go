@@ -0,0 +1,175 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package networkpolicygenerator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	defaultAppLabel = "app"
+	// namespaceNameLabel is set on every namespace by the API server.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+type Filter struct {
+	NetworkPolicy types.NetworkPolicyArgs `json:",inline,omitempty" yaml:",inline,omitempty"`
+
+	// SelectorLabels are added to the pod selectors, e.g. the
+	// commonLabels of the kustomization.
+	SelectorLabels map[string]string `json:"selectorLabels,omitempty" yaml:"selectorLabels,omitempty"`
+}
+
+// Filter adds a NetworkPolicy to nodes
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	np, err := f.generateNetworkPolicy()
+	if err != nil {
+		return nil, err
+	}
+	return append(nodes, np), nil
+}
+
+func (f Filter) generateNetworkPolicy() (*yaml.RNode, error) {
+	args := f.NetworkPolicy
+	name := args.Name
+	if name == "" {
+		name = args.App
+	}
+	if name == "" {
+		return nil, fmt.Errorf("networkPolicyGenerator must specify a name or an app")
+	}
+	metadata := map[string]interface{}{"name": name}
+	if args.Namespace != "" {
+		metadata["namespace"] = args.Namespace
+	}
+	policyTypes := []interface{}{"Ingress"}
+	spec := map[string]interface{}{
+		"podSelector": f.podSelector(args.App),
+		"policyTypes": policyTypes,
+	}
+	ingress, err := f.rules(args.Ingress, "from")
+	if err != nil {
+		return nil, fmt.Errorf("networkPolicyGenerator %s: ingress: %w", name, err)
+	}
+	spec["ingress"] = ingress
+	if len(args.Egress) > 0 {
+		egress, err := f.rules(args.Egress, "to")
+		if err != nil {
+			return nil, fmt.Errorf("networkPolicyGenerator %s: egress: %w", name, err)
+		}
+		spec["egress"] = egress
+		spec["policyTypes"] = append(policyTypes, "Egress")
+	}
+	return yaml.FromMap(map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "NetworkPolicy",
+		"metadata":   metadata,
+		"spec":       spec,
+	})
+}
+
+// podSelector selects the pods of the app, or all pods
+// if app is empty; both carry the selector labels.
+func (f Filter) podSelector(app string) map[string]interface{} {
+	matchLabels := map[string]interface{}{}
+	for k, v := range f.SelectorLabels {
+		matchLabels[k] = v
+	}
+	if app != "" {
+		appLabel := f.NetworkPolicy.AppLabel
+		if appLabel == "" {
+			appLabel = defaultAppLabel
+		}
+		matchLabels[appLabel] = app
+	}
+	if len(matchLabels) == 0 {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"matchLabels": matchLabels}
+}
+
+// rules expands the rules, listing their peers under the peersField.
+func (f Filter) rules(rules []types.NetworkPolicyRule, peersField string) ([]interface{}, error) {
+	result := []interface{}{}
+	for i, r := range rules {
+		rule := map[string]interface{}{}
+		peers := f.peers(r)
+		if len(peers) > 0 {
+			rule[peersField] = peers
+		}
+		if len(r.Ports) > 0 {
+			ports := make([]interface{}, len(r.Ports))
+			for j, p := range r.Ports {
+				port, err := parsePort(p)
+				if err != nil {
+					return nil, fmt.Errorf("rule %d: %w", i, err)
+				}
+				ports[j] = port
+			}
+			rule["ports"] = ports
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+func (f Filter) peers(r types.NetworkPolicyRule) []interface{} {
+	var peers []interface{}
+	if len(r.Namespaces) == 0 {
+		for _, app := range r.Apps {
+			peers = append(peers, map[string]interface{}{"podSelector": f.podSelector(app)})
+		}
+	}
+	for _, ns := range r.Namespaces {
+		namespaceSelector := map[string]interface{}{
+			"matchLabels": map[string]interface{}{namespaceNameLabel: ns},
+		}
+		if len(r.Apps) == 0 {
+			peers = append(peers, map[string]interface{}{"namespaceSelector": namespaceSelector})
+		}
+		for _, app := range r.Apps {
+			peers = append(peers, map[string]interface{}{
+				"namespaceSelector": namespaceSelector,
+				"podSelector":       f.podSelector(app),
+			})
+		}
+	}
+	for _, cidr := range r.CIDRs {
+		peers = append(peers, map[string]interface{}{
+			"ipBlock": map[string]interface{}{"cidr": cidr},
+		})
+	}
+	return peers
+}
+
+// parsePort parses a port of the form port[/protocol], e.g. 53/UDP.
+func parsePort(s string) (map[string]interface{}, error) {
+	port, protocol, found := strings.Cut(s, "/")
+	if !found {
+		protocol = "TCP"
+	}
+	protocol = strings.ToUpper(protocol)
+	switch protocol {
+	case "TCP", "UDP", "SCTP":
+	default:
+		return nil, fmt.Errorf("invalid protocol of port %q", s)
+	}
+	result := map[string]interface{}{"protocol": protocol}
+	if n, err := strconv.Atoi(port); err == nil {
+		if n < 1 || n > 65535 {
+			return nil, fmt.Errorf("port %q is out of range", s)
+		}
+		result["port"] = n
+	} else if port != "" {
+		result["port"] = port
+	} else {
+		return nil, fmt.Errorf("invalid port %q", s)
+	}
+	return result, nil
+}
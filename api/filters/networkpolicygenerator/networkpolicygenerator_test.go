@@ -0,0 +1,176 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package networkpolicygenerator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	filtertest "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		args           types.NetworkPolicyArgs
+		selectorLabels map[string]string
+		expected       string
+	}{
+		"deny all ingress": {
+			args: types.NetworkPolicyArgs{Name: "deny-all", Namespace: "shop"},
+			expected: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: deny-all
+  namespace: shop
+spec:
+  ingress: []
+  podSelector: {}
+  policyTypes:
+  - Ingress
+`,
+		},
+		"apps and namespaces": {
+			args: types.NetworkPolicyArgs{
+				App: "api",
+				Ingress: []types.NetworkPolicyRule{
+					{Apps: []string{"web"}, Ports: []string{"8080"}},
+					{Namespaces: []string{"monitoring"}, Apps: []string{"prometheus"}, Ports: []string{"metrics"}},
+				},
+				Egress: []types.NetworkPolicyRule{
+					{Apps: []string{"db"}, Ports: []string{"5432/tcp"}},
+					{Namespaces: []string{"kube-system"}, Ports: []string{"53/UDP"}},
+					{CIDRs: []string{"10.0.0.0/8"}},
+				},
+			},
+			selectorLabels: map[string]string{"team": "shop"},
+			expected: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: api
+spec:
+  egress:
+  - ports:
+    - port: 5432
+      protocol: TCP
+    to:
+    - podSelector:
+        matchLabels:
+          app: db
+          team: shop
+  - ports:
+    - port: 53
+      protocol: UDP
+    to:
+    - namespaceSelector:
+        matchLabels:
+          kubernetes.io/metadata.name: kube-system
+  - to:
+    - ipBlock:
+        cidr: 10.0.0.0/8
+  ingress:
+  - from:
+    - podSelector:
+        matchLabels:
+          app: web
+          team: shop
+    ports:
+    - port: 8080
+      protocol: TCP
+  - from:
+    - namespaceSelector:
+        matchLabels:
+          kubernetes.io/metadata.name: monitoring
+      podSelector:
+        matchLabels:
+          app: prometheus
+          team: shop
+    ports:
+    - port: metrics
+      protocol: TCP
+  podSelector:
+    matchLabels:
+      app: api
+      team: shop
+  policyTypes:
+  - Ingress
+  - Egress
+`,
+		},
+		"app label": {
+			args: types.NetworkPolicyArgs{
+				Name:     "api-ingress",
+				App:      "api",
+				AppLabel: "app.kubernetes.io/name",
+				Ingress:  []types.NetworkPolicyRule{{Ports: []string{"443"}}},
+			},
+			expected: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: api-ingress
+spec:
+  ingress:
+  - ports:
+    - port: 443
+      protocol: TCP
+  podSelector:
+    matchLabels:
+      app.kubernetes.io/name: api
+  policyTypes:
+  - Ingress
+`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			f := Filter{
+				NetworkPolicy:  tc.args,
+				SelectorLabels: tc.selectorLabels,
+			}
+			actual := filtertest.RunFilter(t, "", f)
+			if !assert.Equal(t, strings.TrimSpace(tc.expected), strings.TrimSpace(actual)) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	testCases := map[string]struct {
+		args   types.NetworkPolicyArgs
+		errMsg string
+	}{
+		"no name": {
+			args:   types.NetworkPolicyArgs{},
+			errMsg: "networkPolicyGenerator must specify a name or an app",
+		},
+		"invalid protocol": {
+			args: types.NetworkPolicyArgs{
+				App:     "api",
+				Ingress: []types.NetworkPolicyRule{{Ports: []string{"80/HTTP"}}},
+			},
+			errMsg: `networkPolicyGenerator api: ingress: rule 0: invalid protocol of port "80/HTTP"`,
+		},
+		"port out of range": {
+			args: types.NetworkPolicyArgs{
+				App:    "api",
+				Egress: []types.NetworkPolicyRule{{}, {Ports: []string{"70000"}}},
+			},
+			errMsg: `networkPolicyGenerator api: egress: rule 1: port "70000" is out of range`,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			_, err := filtertest.RunFilterE(t, "", Filter{NetworkPolicy: tc.args})
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
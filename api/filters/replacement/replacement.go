@@ -5,8 +5,10 @@ package replacement
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
+	"sigs.k8s.io/kustomize/api/internal/cel"
 	"sigs.k8s.io/kustomize/api/internal/utils"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
@@ -30,7 +32,7 @@ func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 		if err != nil {
 			return nil, err
 		}
-		nodes, err = applyReplacement(nodes, value, r.Targets)
+		nodes, err = applyReplacement(nodes, value, r.Targets, false)
 		if err != nil {
 			return nil, err
 		}
@@ -87,6 +89,9 @@ func selectSourceNode(nodes []*yaml.RNode, selector *types.SourceSelector) (*yam
 }
 
 func getRefinedValue(options *types.FieldOptions, rn *yaml.RNode) (*yaml.RNode, error) {
+	if options != nil && options.Regex != "" {
+		return getRegexValue(options, rn)
+	}
 	if options == nil || options.Delimiter == "" {
 		return rn, nil
 	}
@@ -102,7 +107,47 @@ func getRefinedValue(options *types.FieldOptions, rn *yaml.RNode) (*yaml.RNode,
 	return n, nil
 }
 
-func applyReplacement(nodes []*yaml.RNode, value *yaml.RNode, targetSelectors []*types.TargetSelector) ([]*yaml.RNode, error) {
+// getRegexValue rewrites the value matched by options.Regex
+// to options.Replacement.
+func getRegexValue(options *types.FieldOptions, rn *yaml.RNode) (*yaml.RNode, error) {
+	re, err := compileRegex(options)
+	if err != nil {
+		return nil, err
+	}
+	if rn.YNode().Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("regex option can only be used with scalar nodes")
+	}
+	v := yaml.GetValue(rn)
+	match := re.FindStringSubmatchIndex(v)
+	if match == nil {
+		return nil, fmt.Errorf("options.regex %q does not match value %s", options.Regex, v)
+	}
+	template := options.Replacement
+	if template == "" {
+		template = "$0"
+		if re.NumSubexp() > 0 {
+			template = "${1}"
+		}
+	}
+	n := rn.Copy()
+	n.YNode().Value = string(re.ExpandString(nil, template, v, match))
+	return n, nil
+}
+
+func compileRegex(options *types.FieldOptions) (*regexp.Regexp, error) {
+	if options.Delimiter != "" {
+		return nil, fmt.Errorf("options.regex and options.delimiter cannot be used together")
+	}
+	re, err := regexp.Compile(options.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options.regex %q: %w", options.Regex, err)
+	}
+	return re, nil
+}
+
+// applyReplacement copies the value to the fields of the targets. Scalar
+// target fields keep their tag unless retag is set.
+func applyReplacement(nodes []*yaml.RNode, value *yaml.RNode, targetSelectors []*types.TargetSelector, retag bool) ([]*yaml.RNode, error) {
 	for _, selector := range targetSelectors {
 		if selector.Select == nil {
 			return nil, errors.Errorf("target must specify resources to select")
@@ -110,6 +155,10 @@ func applyReplacement(nodes []*yaml.RNode, value *yaml.RNode, targetSelectors []
 		if len(selector.FieldPaths) == 0 {
 			selector.FieldPaths = []string{types.DefaultReplacementFieldPath}
 		}
+		celExpressions, err := parseCelExpressions(selector)
+		if err != nil {
+			return nil, err
+		}
 		for _, possibleTarget := range nodes {
 			ids, err := utils.MakeResIds(possibleTarget)
 			if err != nil {
@@ -117,7 +166,7 @@ func applyReplacement(nodes []*yaml.RNode, value *yaml.RNode, targetSelectors []
 			}
 
 			// filter targets by label and annotation selectors
-			selectByAnnoAndLabel, err := selectByAnnoAndLabel(possibleTarget, selector)
+			selectByAnnoAndLabel, err := selectByAnnoAndLabel(possibleTarget, selector, celExpressions)
 			if err != nil {
 				return nil, err
 			}
@@ -128,7 +177,7 @@ func applyReplacement(nodes []*yaml.RNode, value *yaml.RNode, targetSelectors []
 			// filter targets by matching resource IDs
 			for _, id := range ids {
 				if id.IsSelectedBy(selector.Select.ResId) && !containsRejectId(selector.Reject, ids) {
-					err := copyValueToTarget(possibleTarget, value, selector)
+					err := copyValueToTarget(possibleTarget, value, selector, retag)
 					if err != nil {
 						return nil, err
 					}
@@ -140,22 +189,47 @@ func applyReplacement(nodes []*yaml.RNode, value *yaml.RNode, targetSelectors []
 	return nodes, nil
 }
 
-func selectByAnnoAndLabel(n *yaml.RNode, t *types.TargetSelector) (bool, error) {
-	if matchesSelect, err := matchesAnnoAndLabelSelector(n, t.Select); !matchesSelect || err != nil {
-		return false, err
+// celExpressions are the parsed CEL expressions of the select and
+// reject entries of a target selector, nil where empty.
+type celExpressions struct {
+	selectExpression  *cel.Expression
+	rejectExpressions []*cel.Expression
+}
+
+// parseCelExpressions parses the CEL expressions of t once, for all
+// the nodes they are matched against.
+func parseCelExpressions(t *types.TargetSelector) (*celExpressions, error) {
+	e, err := cel.Parse(t.Select.CelExpression)
+	if err != nil {
+		return nil, err
 	}
+	exprs := &celExpressions{selectExpression: e}
 	for _, reject := range t.Reject {
-		if reject.AnnotationSelector == "" && reject.LabelSelector == "" {
+		e, err := cel.Parse(reject.CelExpression)
+		if err != nil {
+			return nil, err
+		}
+		exprs.rejectExpressions = append(exprs.rejectExpressions, e)
+	}
+	return exprs, nil
+}
+
+func selectByAnnoAndLabel(n *yaml.RNode, t *types.TargetSelector, exprs *celExpressions) (bool, error) {
+	if matchesSelect, err := matchesAnnoAndLabelSelector(n, t.Select, exprs.selectExpression); !matchesSelect || err != nil {
+		return false, err
+	}
+	for i, reject := range t.Reject {
+		if reject.AnnotationSelector == "" && reject.LabelSelector == "" && reject.CelExpression == "" {
 			continue
 		}
-		if m, err := matchesAnnoAndLabelSelector(n, reject); m || err != nil {
+		if m, err := matchesAnnoAndLabelSelector(n, reject, exprs.rejectExpressions[i]); m || err != nil {
 			return false, err
 		}
 	}
 	return true, nil
 }
 
-func matchesAnnoAndLabelSelector(n *yaml.RNode, selector *types.Selector) (bool, error) {
+func matchesAnnoAndLabelSelector(n *yaml.RNode, selector *types.Selector, celExpression *cel.Expression) (bool, error) {
 	r := resource.Resource{RNode: *n}
 	annoMatch, err := r.MatchesAnnotationSelector(selector.AnnotationSelector)
 	if err != nil {
@@ -165,7 +239,11 @@ func matchesAnnoAndLabelSelector(n *yaml.RNode, selector *types.Selector) (bool,
 	if err != nil {
 		return false, err
 	}
-	return annoMatch && labelMatch, nil
+	celMatch, err := celExpression.MatchesNode(n)
+	if err != nil {
+		return false, err
+	}
+	return annoMatch && labelMatch && celMatch, nil
 }
 
 func containsRejectId(rejects []*types.Selector, ids []resid.ResId) bool {
@@ -182,7 +260,7 @@ func containsRejectId(rejects []*types.Selector, ids []resid.ResId) bool {
 	return false
 }
 
-func copyValueToTarget(target *yaml.RNode, value *yaml.RNode, selector *types.TargetSelector) error {
+func copyValueToTarget(target *yaml.RNode, value *yaml.RNode, selector *types.TargetSelector, retag bool) error {
 	for _, fp := range selector.FieldPaths {
 		createKind := yaml.Kind(0) // do not create
 		if selector.Options != nil && selector.Options.Create {
@@ -203,7 +281,7 @@ func copyValueToTarget(target *yaml.RNode, value *yaml.RNode, selector *types.Ta
 		}
 
 		for _, t := range targetFields {
-			if err := setFieldValue(selector.Options, t, value); err != nil {
+			if err := setFieldValue(selector.Options, t, value, retag); err != nil {
 				return err
 			}
 		}
@@ -218,7 +296,7 @@ func fieldRetrievalError(fieldPath string, isCreate bool) string {
 	return fmt.Sprintf("unable to find field %q in replacement target", fieldPath)
 }
 
-func setFieldValue(options *types.FieldOptions, targetField *yaml.RNode, value *yaml.RNode) error {
+func setFieldValue(options *types.FieldOptions, targetField *yaml.RNode, value *yaml.RNode, retag bool) error {
 	value = value.Copy()
 	if options != nil && options.Delimiter != "" {
 		if targetField.YNode().Kind != yaml.ScalarNode {
@@ -237,10 +315,34 @@ func setFieldValue(options *types.FieldOptions, targetField *yaml.RNode, value *
 		}
 		value.YNode().Value = strings.Join(tv, options.Delimiter)
 	}
+	if options != nil && options.Regex != "" {
+		re, err := compileRegex(options)
+		if err != nil {
+			return err
+		}
+		if targetField.YNode().Kind != yaml.ScalarNode {
+			return fmt.Errorf("regex option can only be used with scalar nodes")
+		}
+		tv := targetField.YNode().Value
+		match := re.FindStringSubmatchIndex(tv)
+		if match == nil {
+			return fmt.Errorf("options.regex %q does not match target value %s", options.Regex, tv)
+		}
+		// Replace the first capture group, or the whole match.
+		start, end := match[0], match[1]
+		if re.NumSubexp() > 0 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+		value.YNode().Value = tv[:start] + yaml.GetValue(value) + tv[end:]
+	}
 
 	if targetField.YNode().Kind == yaml.ScalarNode {
 		// For scalar, only copy the value (leave any type intact to auto-convert int->string or string->int)
 		targetField.YNode().Value = value.YNode().Value
+		if retag && (options == nil || (options.Delimiter == "" && options.Regex == "")) {
+			targetField.YNode().Tag = value.YNode().Tag
+			targetField.YNode().Style = value.YNode().Style
+		}
 	} else {
 		targetField.SetYNode(value.YNode())
 	}
@@ -2830,6 +2830,140 @@ spec:
 `,
 			expectedErr: "unable to find or create field \"spec.tls.5.hosts.5\" in replacement target: index 5 specified but only 0 elements found",
 		},
+		"regex capture group source": {
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.example.com:5000/team/app:v1.2.3@sha256:abc
+        name: app
+`,
+			replacements: `replacements:
+- source:
+    kind: Deployment
+    name: deploy
+    fieldPath: spec.template.spec.containers.0.image
+    options:
+      regex: '^[^/]+/(?P<repo>[^:@]+):(?P<tag>[^@]+)'
+      replacement: '${repo}-${tag}'
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - metadata.annotations.version
+    options:
+      create: true
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy
+  annotations:
+    version: team/app-v1.2.3
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.example.com:5000/team/app:v1.2.3@sha256:abc
+        name: app
+`,
+		},
+		"regex default replacement and target group": {
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: source
+data:
+  url: https://api.prod.example.com:8443/v1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: target
+data:
+  endpoint: https://localhost:8443/healthz
+`,
+			replacements: `replacements:
+- source:
+    kind: ConfigMap
+    name: source
+    fieldPath: data.url
+    options:
+      regex: '^https://([^:/]+)'
+  targets:
+  - select:
+      name: target
+    fieldPaths:
+    - data.endpoint
+    options:
+      regex: '^https://([^:/]+)'
+`,
+			expected: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: source
+data:
+  url: https://api.prod.example.com:8443/v1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: target
+data:
+  endpoint: https://api.prod.example.com:8443/healthz
+`,
+		},
+		"regex no match": {
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: source
+data:
+  url: http://example.com
+`,
+			replacements: `replacements:
+- source:
+    kind: ConfigMap
+    name: source
+    fieldPath: data.url
+    options:
+      regex: '^https://(.+)'
+  targets:
+  - select:
+      name: source
+    fieldPaths:
+    - data.host
+`,
+			expectedErr: "options.regex \"^https://(.+)\" does not match value http://example.com",
+		},
+		"regex with delimiter": {
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: source
+data:
+  url: http://example.com
+`,
+			replacements: `replacements:
+- source:
+    kind: ConfigMap
+    name: source
+    fieldPath: data.url
+    options:
+      regex: '(.+)'
+      delimiter: ':'
+  targets:
+  - select:
+      name: source
+    fieldPaths:
+    - data.host
+`,
+			expectedErr: "options.regex and options.delimiter cannot be used together",
+		},
 	}
 
 	for tn, tc := range testCases {
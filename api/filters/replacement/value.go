@@ -0,0 +1,27 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package replacement
+
+import (
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ValueFilter writes a given value to the fields of the targets, the way
+// Filter writes the value read from the source of a replacement, except
+// that scalar fields take the tag of the value, e.g. !!int.
+type ValueFilter struct {
+	Value   *yaml.RNode
+	Targets []*types.TargetSelector
+}
+
+// Filter writes the value to the targets.
+func (f ValueFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return applyReplacement(nodes, f.Value, f.Targets, true)
+}
+
+// SourceValue returns the value selected by the source of a replacement.
+func SourceValue(nodes []*yaml.RNode, source *types.SourceSelector) (*yaml.RNode, error) {
+	return getReplacement(nodes, &types.Replacement{Source: source})
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resourceoverride
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+var quantityRegexp = regexp.MustCompile(`^([0-9]+(?:\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+|[a-zA-Z]*)$`)
+
+// maxExponent bounds the decimal exponent of quantities, e.g. 1e3,
+// well beyond those of the largest and smallest quantities Kubernetes
+// holds, so that scaling a quantity takes little time and memory.
+const maxExponent = 64
+
+type suffix struct {
+	name       string
+	multiplier *big.Rat
+}
+
+func pow(base, exp int64) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(base), big.NewInt(exp), nil))
+}
+
+// The suffixes of quantities, in decreasing order of their multipliers.
+var (
+	decimalSuffixes = []suffix{
+		{"E", pow(10, 18)}, {"P", pow(10, 15)}, {"T", pow(10, 12)},
+		{"G", pow(10, 9)}, {"M", pow(10, 6)}, {"k", pow(10, 3)},
+		{"", big.NewRat(1, 1)}, {"m", big.NewRat(1, 1000)},
+	}
+	binarySuffixes = []suffix{
+		{"Ei", pow(2, 60)}, {"Pi", pow(2, 50)}, {"Ti", pow(2, 40)},
+		{"Gi", pow(2, 30)}, {"Mi", pow(2, 20)}, {"Ki", pow(2, 10)},
+		{"", big.NewRat(1, 1)},
+	}
+)
+
+// parseQuantity parses a Kubernetes quantity, e.g. 500m or 1Gi, into
+// its value and the suffixes it can be written with, the quantity's
+// suffix being first.
+func parseQuantity(q string) (*big.Rat, []suffix, error) {
+	m := quantityRegexp.FindStringSubmatch(q)
+	if m == nil {
+		return nil, nil, fmt.Errorf("invalid quantity %q", q)
+	}
+	value, ok := new(big.Rat).SetString(m[1])
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid quantity %q", q)
+	}
+	if len(m[2]) > 1 && (m[2][0] == 'e' || m[2][0] == 'E') {
+		exp, err := strconv.ParseInt(m[2][1:], 10, 32)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid quantity %q", q)
+		}
+		if exp > maxExponent || exp < -maxExponent {
+			return nil, nil, fmt.Errorf("invalid quantity %q: exponent out of range", q)
+		}
+		if exp >= 0 {
+			value.Mul(value, pow(10, exp))
+		} else {
+			value.Quo(value, pow(10, -exp))
+		}
+		return value, decimalSuffixes[6:], nil
+	}
+	for _, suffixes := range [][]suffix{binarySuffixes, decimalSuffixes} {
+		for i, s := range suffixes {
+			if s.name == m[2] && (s.name != "" || len(suffixes) == len(decimalSuffixes)) {
+				return value.Mul(value, s.multiplier), suffixes[i:], nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("invalid quantity %q: unknown suffix %q", q, m[2])
+}
+
+// scaleQuantity multiplies the quantity by the factor, writing the
+// result with the quantity's suffix if it's a whole number of it,
+// else with the largest smaller suffix it's a whole number of,
+// rounding up to the smallest suffix, e.g. 1 * 0.25 is 250m.
+func scaleQuantity(q string, factor float64) (string, error) {
+	value, suffixes, err := parseQuantity(q)
+	if err != nil {
+		return "", err
+	}
+	f, ok := new(big.Rat).SetString(strconv.FormatFloat(factor, 'f', -1, 64))
+	if !ok {
+		return "", fmt.Errorf("invalid factor %v", factor)
+	}
+	value.Mul(value, f)
+	for i, s := range suffixes {
+		n := new(big.Rat).Quo(value, s.multiplier)
+		if n.IsInt() {
+			return n.Num().String() + s.name, nil
+		}
+		if i == len(suffixes)-1 {
+			ceil := new(big.Int).Quo(n.Num(), n.Denom())
+			return ceil.Add(ceil, big.NewInt(1)).String() + s.name, nil
+		}
+	}
+	return "", fmt.Errorf("invalid quantity %q", q)
+}
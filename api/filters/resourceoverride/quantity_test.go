@@ -0,0 +1,55 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resourceoverride
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaleQuantity(t *testing.T) {
+	testCases := []struct {
+		quantity string
+		factor   float64
+		expected string
+	}{
+		{"500m", 2, "1000m"},
+		{"500m", 1.5, "750m"},
+		{"1", 0.25, "250m"},
+		{"2", 1.5, "3"},
+		{"0.5", 3, "1500m"},
+		{"1", 0.0001, "1m"},
+		{"128Mi", 2, "256Mi"},
+		{"1Gi", 0.5, "512Mi"},
+		{"1Gi", 0.3, "322122548"},
+		{"1G", 0.5, "500M"},
+		{"1e3", 2, "2000"},
+		{"100Ki", 0.1, "10Ki"},
+	}
+	for _, tc := range testCases {
+		actual, err := scaleQuantity(tc.quantity, tc.factor)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, actual, "%s * %v", tc.quantity, tc.factor)
+	}
+}
+
+func TestParseQuantityErrors(t *testing.T) {
+	for q, errMsg := range map[string]string{
+		"":             `invalid quantity ""`,
+		"-1":           `invalid quantity "-1"`,
+		"1 Gi":         `invalid quantity "1 Gi"`,
+		"1GB":          `invalid quantity "1GB": unknown suffix "GB"`,
+		"ten":          `invalid quantity "ten"`,
+		"1.5Xi":        `unknown suffix "Xi"`,
+		"1e999999999":  `invalid quantity "1e999999999": exponent out of range`,
+		"1e-999999999": `invalid quantity "1e-999999999": exponent out of range`,
+		"1e65":         `invalid quantity "1e65": exponent out of range`,
+	} {
+		_, _, err := parseQuantity(q)
+		require.Error(t, err, q)
+		assert.Contains(t, err.Error(), errMsg)
+	}
+}
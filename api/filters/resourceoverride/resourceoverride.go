@@ -0,0 +1,167 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resourceoverride
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
+	"sigs.k8s.io/kustomize/api/filters/fsslice"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Filter sets and scales the resources of the containers in the
+// lists of containers located by the fieldSpecs.
+type Filter struct {
+	Override types.ResourceOverride `json:"resourceOverride,omitempty" yaml:"resourceOverride,omitempty"`
+	FsSlice  types.FsSlice          `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	trackableSetter filtersutil.TrackableSetter
+}
+
+var _ kio.Filter = Filter{}
+var _ kio.TrackableFilter = &Filter{}
+
+// WithMutationTracker registers a callback which will be invoked each time a field is mutated
+func (f *Filter) WithMutationTracker(callback func(key, value, tag string, node *yaml.RNode)) {
+	f.trackableSetter.WithMutationTracker(callback)
+}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	err := node.PipeE(fsslice.Filter{
+		FsSlice:  f.FsSlice,
+		SetValue: f.setContainers,
+	})
+	return node, err
+}
+
+func (f Filter) setContainers(node *yaml.RNode) error {
+	if err := yaml.ErrorIfInvalid(node, yaml.SequenceNode); err != nil {
+		return err
+	}
+	return node.VisitElements(func(container *yaml.RNode) error {
+		if !f.selects(container) {
+			return nil
+		}
+		for _, rl := range []struct {
+			name  string
+			set   map[string]string
+			scale map[string]float64
+		}{
+			{"requests", f.Override.Requests, f.scale().Requests},
+			{"limits", f.Override.Limits, f.scale().Limits},
+		} {
+			if err := f.setResources(container, rl.name, rl.set, rl.scale); err != nil {
+				name := container.Field("name")
+				if name != nil {
+					return errors.WrapPrefixf(err, "container %s", yaml.GetValue(name.Value))
+				}
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (f Filter) scale() types.ResourceScale {
+	if f.Override.Scale == nil {
+		return types.ResourceScale{}
+	}
+	return *f.Override.Scale
+}
+
+func (f Filter) selects(container *yaml.RNode) bool {
+	if len(f.Override.Containers) == 0 {
+		return true
+	}
+	name := container.Field("name")
+	if name == nil {
+		return false
+	}
+	for _, c := range f.Override.Containers {
+		if c == yaml.GetValue(name.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// setResources sets, then scales, the quantities of the
+// container's resources.requests or resources.limits.
+func (f Filter) setResources(
+	container *yaml.RNode, field string, set map[string]string, scale map[string]float64) error {
+	if len(set) > 0 {
+		list, err := container.Pipe(yaml.LookupCreate(yaml.MappingNode, "resources", field))
+		if err != nil {
+			return err
+		}
+		for _, name := range sortedKeys(set) {
+			if _, _, err = parseQuantity(set[name]); err != nil {
+				return fmt.Errorf("%s.%s: %w", field, name, err)
+			}
+			if err = f.trackableSetter.SetEntry(name, set[name], quantityTag(set[name]))(list); err != nil {
+				return err
+			}
+		}
+	}
+	if len(scale) == 0 {
+		return nil
+	}
+	list, err := container.Pipe(yaml.Lookup("resources", field))
+	if err != nil || list == nil {
+		return err
+	}
+	names := make([]string, 0, len(scale))
+	for name := range scale {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		q := list.Field(name)
+		if q == nil {
+			continue
+		}
+		if scale[name] <= 0 {
+			return fmt.Errorf("scale.%s.%s must be positive", field, name)
+		}
+		scaled, err := scaleQuantity(yaml.GetValue(q.Value), scale[name])
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", field, name, err)
+		}
+		if err = f.trackableSetter.SetEntry(name, scaled, quantityTag(scaled))(list); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quantityTag returns the tag that writes the quantity as
+// is, e.g. 1 rather than "1".
+func quantityTag(q string) string {
+	if _, err := strconv.ParseInt(q, 10, 64); err == nil {
+		return yaml.NodeTagInt
+	}
+	if _, err := strconv.ParseFloat(q, 64); err == nil {
+		return yaml.NodeTagFloat
+	}
+	return yaml.NodeTagString
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,191 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resourceoverride
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	filtertest "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: init
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: 500m
+            memory: 256Mi
+          limits:
+            memory: 1Gi
+      - name: proxy
+        image: proxy
+`
+
+var fsSlice = types.FsSlice{
+	{Path: "spec/template/spec/containers"},
+	{Path: "spec/template/spec/initContainers"},
+}
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		override types.ResourceOverride
+		expected string
+	}{
+		"set in all containers": {
+			override: types.ResourceOverride{
+				Requests: map[string]string{"cpu": "100m"},
+				Limits:   map[string]string{"cpu": "1"},
+			},
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: init
+        resources:
+          requests:
+            cpu: 100m
+          limits:
+            cpu: 1
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: 100m
+            memory: 256Mi
+          limits:
+            memory: 1Gi
+            cpu: 1
+      - name: proxy
+        image: proxy
+        resources:
+          requests:
+            cpu: 100m
+          limits:
+            cpu: 1
+`,
+		},
+		"scale selected containers": {
+			override: types.ResourceOverride{
+				Containers: []string{"app", "proxy"},
+				Scale: &types.ResourceScale{
+					Requests: map[string]float64{"cpu": 1.5, "memory": 2},
+					Limits:   map[string]float64{"memory": 0.5},
+				},
+			},
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: init
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: 750m
+            memory: 512Mi
+          limits:
+            memory: 512Mi
+      - name: proxy
+        image: proxy
+`,
+		},
+		"set then scale": {
+			override: types.ResourceOverride{
+				Containers: []string{"proxy"},
+				Limits:     map[string]string{"memory": "64Mi"},
+				Scale: &types.ResourceScale{
+					Limits: map[string]float64{"memory": 4},
+				},
+			},
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: init
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: 500m
+            memory: 256Mi
+          limits:
+            memory: 1Gi
+      - name: proxy
+        image: proxy
+        resources:
+          limits:
+            memory: 256Mi
+`,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			filter := Filter{Override: tc.override, FsSlice: fsSlice}
+			assert.Equal(t,
+				strings.TrimSpace(tc.expected),
+				strings.TrimSpace(filtertest.RunFilter(t, deployment, filter)))
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	testCases := map[string]struct {
+		override types.ResourceOverride
+		errMsg   string
+	}{
+		"invalid quantity": {
+			override: types.ResourceOverride{Requests: map[string]string{"cpu": "lots"}},
+			errMsg:   `container app: requests.cpu: invalid quantity "lots"`,
+		},
+		"negative factor": {
+			override: types.ResourceOverride{
+				Scale: &types.ResourceScale{Requests: map[string]float64{"cpu": -1}},
+			},
+			errMsg: "container app: scale.requests.cpu must be positive",
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			_, err := filtertest.RunFilterE(t, deployment, Filter{Override: tc.override, FsSlice: fsSlice})
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
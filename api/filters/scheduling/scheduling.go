@@ -0,0 +1,180 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduling
+
+import (
+	"reflect"
+	"sort"
+
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
+	"sigs.k8s.io/kustomize/api/filters/fsslice"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Filter merges scheduling constraints into the pod specs
+// located by the fieldSpecs.
+type Filter struct {
+	Scheduling types.Scheduling `json:"scheduling,omitempty" yaml:"scheduling,omitempty"`
+	FsSlice    types.FsSlice    `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	trackableSetter filtersutil.TrackableSetter
+}
+
+var _ kio.Filter = Filter{}
+var _ kio.TrackableFilter = &Filter{}
+
+// WithMutationTracker registers a callback which will be invoked each time a field is mutated
+func (f *Filter) WithMutationTracker(callback func(key, value, tag string, node *yaml.RNode)) {
+	f.trackableSetter.WithMutationTracker(callback)
+}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	err := node.PipeE(fsslice.Filter{
+		FsSlice:  f.FsSlice,
+		SetValue: f.setPodSpec,
+	})
+	return node, err
+}
+
+func (f Filter) setPodSpec(podSpec *yaml.RNode) error {
+	if err := yaml.ErrorIfInvalid(podSpec, yaml.MappingNode); err != nil {
+		return err
+	}
+	if err := f.setNodeSelector(podSpec); err != nil {
+		return err
+	}
+	for _, t := range f.Scheduling.Tolerations {
+		if err := addElement(podSpec, "tolerations", t, equal); err != nil {
+			return err
+		}
+	}
+	for _, c := range f.Scheduling.TopologySpreadConstraints {
+		if err := addElement(podSpec, "topologySpreadConstraints", c, func(a, b *yaml.RNode) bool {
+			return sameFields(a, b, "topologyKey", "whenUnsatisfiable")
+		}); err != nil {
+			return err
+		}
+	}
+	if len(f.Scheduling.Affinity) > 0 {
+		affinity, err := yaml.FromMap(f.Scheduling.Affinity)
+		if err != nil {
+			return errors.WrapPrefixf(err, "invalid affinity")
+		}
+		existing, err := podSpec.Pipe(yaml.LookupCreate(yaml.MappingNode, "affinity"))
+		if err != nil {
+			return err
+		}
+		merge(existing, affinity)
+	}
+	return nil
+}
+
+func (f Filter) setNodeSelector(podSpec *yaml.RNode) error {
+	if len(f.Scheduling.NodeSelector) == 0 {
+		return nil
+	}
+	nodeSelector, err := podSpec.Pipe(yaml.LookupCreate(yaml.MappingNode, "nodeSelector"))
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(f.Scheduling.NodeSelector))
+	for k := range f.Scheduling.NodeSelector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		err = f.trackableSetter.SetEntry(k, f.Scheduling.NodeSelector[k], yaml.NodeTagString)(nodeSelector)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addElement adds the element to the list field of the pod spec,
+// in place of the first element that is the same, if any. Equal
+// elements are left as they are.
+func addElement(podSpec *yaml.RNode, field string, element map[string]interface{},
+	same func(a, b *yaml.RNode) bool) error {
+	e, err := yaml.FromMap(element)
+	if err != nil {
+		return errors.WrapPrefixf(err, "invalid %s", field)
+	}
+	list, err := podSpec.Pipe(yaml.LookupCreate(yaml.SequenceNode, field))
+	if err != nil {
+		return err
+	}
+	for i, existing := range list.Content() {
+		if same(yaml.NewRNode(existing), e) {
+			if !equal(yaml.NewRNode(existing), e) {
+				list.YNode().Content[i] = e.YNode()
+			}
+			return nil
+		}
+	}
+	list.YNode().Content = append(list.YNode().Content, e.YNode())
+	return nil
+}
+
+// merge merges src into dst: fields of maps are merged recursively,
+// elements of lists missing from dst are appended, and other
+// values of src replace those of dst.
+func merge(dst, src *yaml.RNode) {
+	for i := 0; i+1 < len(src.Content()); i += 2 {
+		key, value := src.Content()[i], src.Content()[i+1]
+		existing := dst.Field(key.Value)
+		switch {
+		case existing == nil:
+			dst.YNode().Content = append(dst.YNode().Content, key, value)
+		case existing.Value.YNode().Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			merge(existing.Value, yaml.NewRNode(value))
+		case existing.Value.YNode().Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			for _, e := range value.Content {
+				if !contains(existing.Value, yaml.NewRNode(e)) {
+					existing.Value.YNode().Content = append(existing.Value.YNode().Content, e)
+				}
+			}
+		default:
+			existing.Value.SetYNode(value)
+		}
+	}
+}
+
+func contains(list, element *yaml.RNode) bool {
+	for _, e := range list.Content() {
+		if equal(yaml.NewRNode(e), element) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b *yaml.RNode) bool {
+	var av, bv interface{}
+	if a.YNode().Decode(&av) != nil || b.YNode().Decode(&bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// sameFields returns true if a and b have the same values of the fields.
+func sameFields(a, b *yaml.RNode, fields ...string) bool {
+	for _, field := range fields {
+		af, bf := a.Field(field), b.Field(field)
+		if (af == nil) != (bf == nil) {
+			return false
+		}
+		if af != nil && !equal(af.Value, bf.Value) {
+			return false
+		}
+	}
+	return true
+}
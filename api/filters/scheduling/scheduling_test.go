@@ -0,0 +1,284 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduling
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	filtertest "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      nodeSelector:
+        disk: ssd
+      tolerations:
+      - key: dedicated
+        operator: Equal
+        value: app
+        effect: NoSchedule
+      topologySpreadConstraints:
+      - maxSkew: 2
+        topologyKey: topology.kubernetes.io/zone
+        whenUnsatisfiable: ScheduleAnyway
+      affinity:
+        nodeAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 1
+            preference:
+              matchExpressions:
+              - key: disk
+                operator: In
+                values:
+                - ssd
+      containers:
+      - name: app
+        image: app
+`
+
+var fsSlice = types.FsSlice{{Path: "spec/template/spec"}}
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		scheduling types.Scheduling
+		expected   string
+	}{
+		"node selector": {
+			scheduling: types.Scheduling{
+				NodeSelector: map[string]string{"disk": "nvme", "pool": "apps"},
+			},
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      nodeSelector:
+        disk: nvme
+        pool: apps
+      tolerations:
+      - key: dedicated
+        operator: Equal
+        value: app
+        effect: NoSchedule
+      topologySpreadConstraints:
+      - maxSkew: 2
+        topologyKey: topology.kubernetes.io/zone
+        whenUnsatisfiable: ScheduleAnyway
+      affinity:
+        nodeAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 1
+            preference:
+              matchExpressions:
+              - key: disk
+                operator: In
+                values:
+                - ssd
+      containers:
+      - name: app
+        image: app
+`,
+		},
+		"tolerations and topology spread constraints": {
+			scheduling: types.Scheduling{
+				Tolerations: []map[string]interface{}{
+					{"key": "dedicated", "operator": "Equal", "value": "app", "effect": "NoSchedule"},
+					{"key": "spot", "operator": "Exists", "effect": "NoExecute", "tolerationSeconds": 60},
+				},
+				TopologySpreadConstraints: []map[string]interface{}{
+					{
+						"maxSkew":           1,
+						"topologyKey":       "topology.kubernetes.io/zone",
+						"whenUnsatisfiable": "ScheduleAnyway",
+					},
+					{
+						"maxSkew":           1,
+						"topologyKey":       "kubernetes.io/hostname",
+						"whenUnsatisfiable": "DoNotSchedule",
+					},
+				},
+			},
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      nodeSelector:
+        disk: ssd
+      tolerations:
+      - key: dedicated
+        operator: Equal
+        value: app
+        effect: NoSchedule
+      - effect: NoExecute
+        key: spot
+        operator: Exists
+        tolerationSeconds: 60
+      topologySpreadConstraints:
+      - maxSkew: 1
+        topologyKey: topology.kubernetes.io/zone
+        whenUnsatisfiable: ScheduleAnyway
+      - maxSkew: 1
+        topologyKey: kubernetes.io/hostname
+        whenUnsatisfiable: DoNotSchedule
+      affinity:
+        nodeAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 1
+            preference:
+              matchExpressions:
+              - key: disk
+                operator: In
+                values:
+                - ssd
+      containers:
+      - name: app
+        image: app
+`,
+		},
+		"affinity": {
+			scheduling: types.Scheduling{
+				Affinity: map[string]interface{}{
+					"nodeAffinity": map[string]interface{}{
+						"preferredDuringSchedulingIgnoredDuringExecution": []interface{}{
+							map[string]interface{}{
+								"weight": 1,
+								"preference": map[string]interface{}{
+									"matchExpressions": []interface{}{
+										map[string]interface{}{"key": "disk", "operator": "In", "values": []interface{}{"ssd"}},
+									},
+								},
+							},
+							map[string]interface{}{
+								"weight": 2,
+								"preference": map[string]interface{}{
+									"matchExpressions": []interface{}{
+										map[string]interface{}{"key": "pool", "operator": "In", "values": []interface{}{"apps"}},
+									},
+								},
+							},
+						},
+					},
+					"podAntiAffinity": map[string]interface{}{
+						"preferredDuringSchedulingIgnoredDuringExecution": []interface{}{
+							map[string]interface{}{
+								"weight": 100,
+								"podAffinityTerm": map[string]interface{}{
+									"topologyKey":   "kubernetes.io/hostname",
+									"labelSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "app"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      nodeSelector:
+        disk: ssd
+      tolerations:
+      - key: dedicated
+        operator: Equal
+        value: app
+        effect: NoSchedule
+      topologySpreadConstraints:
+      - maxSkew: 2
+        topologyKey: topology.kubernetes.io/zone
+        whenUnsatisfiable: ScheduleAnyway
+      affinity:
+        nodeAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 1
+            preference:
+              matchExpressions:
+              - key: disk
+                operator: In
+                values:
+                - ssd
+          - preference:
+              matchExpressions:
+              - key: pool
+                operator: In
+                values:
+                - apps
+            weight: 2
+        podAntiAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - podAffinityTerm:
+              labelSelector:
+                matchLabels:
+                  app: app
+              topologyKey: kubernetes.io/hostname
+            weight: 100
+      containers:
+      - name: app
+        image: app
+`,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			filter := Filter{Scheduling: tc.scheduling, FsSlice: fsSlice}
+			assert.Equal(t,
+				strings.TrimSpace(tc.expected),
+				strings.TrimSpace(filtertest.RunFilter(t, deployment, filter)))
+		})
+	}
+}
+
+func TestFilterCreatesFields(t *testing.T) {
+	input := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+  - name: app
+    image: app
+`
+	filter := Filter{
+		Scheduling: types.Scheduling{
+			NodeSelector: map[string]string{"pool": "apps"},
+			Tolerations:  []map[string]interface{}{{"key": "spot", "operator": "Exists"}},
+		},
+		FsSlice: types.FsSlice{{Path: "spec"}},
+	}
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+  - name: app
+    image: app
+  nodeSelector:
+    pool: apps
+  tolerations:
+  - key: spot
+    operator: Exists
+`), strings.TrimSpace(filtertest.RunFilter(t, input, filter)))
+}
@@ -26,6 +26,13 @@ type KvLoader interface {
 	Load(args types.KvPairSources) (all []types.Pair, err error)
 }
 
+// SecretValueLoader is a KvLoader that also pulls the
+// values of secrets from external secret providers.
+type SecretValueLoader interface {
+	KvLoader
+	LoadSecretValues(values []types.SecretValue) ([]types.Pair, error)
+}
+
 // Loader interface exposes methods to read bytes.
 type Loader interface {
 
@@ -46,6 +53,19 @@ type Loader interface {
 	Cleanup() error
 }
 
+// FileLister is a Loader that can also list files, to
+// expand the directories and globs of file sources.
+type FileLister interface {
+	Loader
+
+	// IsDir returns true if the location is a directory.
+	IsDir(location string) bool
+
+	// ListFiles returns the locations of the files in the
+	// directory and its subdirectories, in lexical order.
+	ListFiles(dir string) ([]string, error)
+}
+
 // KustHasher returns a hash of the argument
 // or an error.
 type KustHasher interface {
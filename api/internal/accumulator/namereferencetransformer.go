@@ -6,12 +6,14 @@ package accumulator
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"sigs.k8s.io/kustomize/api/filters/nameref"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/kyaml/resid"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 type nameReferenceTransformer struct {
@@ -51,6 +53,7 @@ func newNameReferenceTransformer(
 func (t *nameReferenceTransformer) Transform(m resmap.ResMap) error {
 	fMap := t.determineFilters(m.Resources())
 	debug(fMap)
+	hasTarget := referralTargets(m.Resources())
 	for r, fList := range fMap {
 		c, err := m.SubsetThatCouldBeReferencedByResource(r)
 		if err != nil {
@@ -59,6 +62,15 @@ func (t *nameReferenceTransformer) Transform(m resmap.ResMap) error {
 		for _, f := range fList {
 			f.Referrer = r
 			f.ReferralCandidates = c
+			if changesNothing(f, hasTarget) {
+				// The filter only checks the fields of the referrer,
+				// so run it on the node the referrer may share with
+				// its copies rather than copying the node.
+				if _, err := f.Filter([]*kyaml.RNode{&r.RNode}); err != nil {
+					return err
+				}
+				continue
+			}
 			if err := f.Referrer.ApplyFilter(f); err != nil {
 				return err
 			}
@@ -67,6 +79,38 @@ func (t *nameReferenceTransformer) Transform(m resmap.ResMap) error {
 	return nil
 }
 
+// changesNothing reports whether filter f can't change its referrer: it
+// writes a name only once it finds a referral, of which there's none
+// of its kind, and it neither creates fields nor, as it does for the
+// "[]" elements of a path, turns null fields into sequences.
+func changesNothing(f nameref.Filter, hasTarget func(resid.Gvk) bool) bool {
+	return !hasTarget(f.ReferralTarget) &&
+		!f.NameFieldToUpdate.CreateIfNotPresent &&
+		!strings.Contains(f.NameFieldToUpdate.Path, "[]")
+}
+
+// referralTargets returns a func reporting whether any of the resources
+// was of the given kind, the filters' test of a referral candidate.
+func referralTargets(resources []*resource.Resource) func(resid.Gvk) bool {
+	var prevIds []resid.ResId
+	for _, r := range resources {
+		prevIds = append(prevIds, r.PrevIds()...)
+	}
+	found := map[resid.Gvk]bool{}
+	return func(gvk resid.Gvk) bool {
+		has, ok := found[gvk]
+		if !ok {
+			for _, id := range prevIds {
+				if has = id.IsSelected(&gvk); has {
+					break
+				}
+			}
+			found[gvk] = has
+		}
+		return has
+	}
+}
+
 func debug(fMap filterMap) {
 	if !doDebug {
 		return
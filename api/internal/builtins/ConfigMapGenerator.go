@@ -4,6 +4,8 @@
 package builtins
 
 import (
+	"path/filepath"
+
 	"sigs.k8s.io/kustomize/api/kv"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
@@ -14,10 +16,15 @@ type ConfigMapGeneratorPlugin struct {
 	h                *resmap.PluginHelpers
 	types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
 	types.ConfigMapArgs
+
+	// TemplateData, set by the kustomization, is the
+	// data templated literals are evaluated with.
+	TemplateData *types.LiteralTemplateData `json:"templateData,omitempty" yaml:"templateData,omitempty"`
 }
 
 func (p *ConfigMapGeneratorPlugin) Config(h *resmap.PluginHelpers, config []byte) (err error) {
 	p.ConfigMapArgs = types.ConfigMapArgs{}
+	p.TemplateData = nil
 	err = yaml.Unmarshal(config, p)
 	if p.ConfigMapArgs.Name == "" {
 		p.ConfigMapArgs.Name = p.Name
@@ -30,8 +37,19 @@ func (p *ConfigMapGeneratorPlugin) Config(h *resmap.PluginHelpers, config []byte
 }
 
 func (p *ConfigMapGeneratorPlugin) Generate() (resmap.ResMap, error) {
-	return p.h.ResmapFactory().FromConfigMapArgs(
-		kv.NewLoader(p.h.Loader(), p.h.Validator()), p.ConfigMapArgs)
+	kvLdr := kv.NewLoader(p.h.Loader(), p.h.Validator())
+	if p.TemplateLiterals {
+		data := types.LiteralTemplateData{
+			Overlay:   filepath.Base(p.h.Loader().Root()),
+			Namespace: p.ConfigMapArgs.Namespace,
+			Name:      p.ConfigMapArgs.Name,
+		}
+		if p.TemplateData != nil {
+			data = *p.TemplateData
+		}
+		kvLdr = kv.NewTemplateLoader(p.h.Loader(), p.h.Validator(), data)
+	}
+	return p.h.ResmapFactory().FromConfigMapArgs(kvLdr, p.ConfigMapArgs)
 }
 
 func NewConfigMapGeneratorPlugin() resmap.GeneratorPlugin {
@@ -0,0 +1,96 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cel evaluates Common Expression Language
+// (https://github.com/google/cel-spec) expressions selecting
+// resources, e.g.
+//
+//	resource.spec.replicas > 3 && resource.metadata.labels['tier'] == 'web'
+//
+// The resource is bound to the variable resource. Besides the
+// standard definitions, the extended string functions are available.
+package cel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ErrNoSuchKey is returned when an expression selects a field
+// the resource doesn't have.
+var ErrNoSuchKey = errors.Errorf("no such key")
+
+// Expression is a compiled expression.
+type Expression struct {
+	source  string
+	program cel.Program
+}
+
+// Parse compiles the expression, once for all the resources it is
+// evaluated against. It returns nil for an empty expression.
+func Parse(source string) (*Expression, error) {
+	if source == "" {
+		return nil, nil
+	}
+	env, err := cel.NewEnv(
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+		cel.CrossTypeNumericComparisons(true),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	ast, issues := env.Compile(source)
+	if issues.Err() != nil {
+		return nil, errors.WrapPrefixf(issues.Err(), "invalid CEL expression %q", source)
+	}
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return nil, errors.Errorf("CEL expression %q evaluates to %s, not bool", source, t)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid CEL expression %q", source)
+	}
+	return &Expression{source: source, program: program}, nil
+}
+
+// Matches evaluates the expression with the variable resource
+// bound to the object, which must evaluate to a bool.
+func (e *Expression) Matches(object map[string]interface{}) (bool, error) {
+	v, _, err := e.program.Eval(map[string]interface{}{"resource": object})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "no such key") {
+			err = fmt.Errorf("%w: %s", ErrNoSuchKey, strings.TrimPrefix(err.Error(), "no such key: "))
+		}
+		return false, fmt.Errorf("unable to evaluate %q: %w", e.source, err)
+	}
+	b, ok := v.Value().(bool)
+	if !ok {
+		return false, errors.Errorf("CEL expression %q evaluates to %s, not bool", e.source, v.Type().TypeName())
+	}
+	return b, nil
+}
+
+// MatchesNode returns true if the expression, with the variable
+// resource bound to the node, evaluates to true. A nil expression
+// matches every node, and an expression selecting a field the node
+// doesn't have matches none.
+func (e *Expression) MatchesNode(rn *yaml.RNode) (bool, error) {
+	if e == nil {
+		return true, nil
+	}
+	m, err := rn.Map()
+	if err != nil {
+		return false, err
+	}
+	matched, err := e.Matches(m)
+	if errors.Is(err, ErrNoSuchKey) {
+		return false, nil
+	}
+	return matched, err
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package cel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/cel"
+)
+
+var deployment = map[string]interface{}{
+	"apiVersion": "apps/v1",
+	"kind":       "Deployment",
+	"metadata": map[string]interface{}{
+		"name":   "web-frontend",
+		"labels": map[string]interface{}{"tier": "web"},
+	},
+	"spec": map[string]interface{}{
+		"replicas": 5,
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:v1.2"},
+				},
+			},
+		},
+	},
+}
+
+func TestMatches(t *testing.T) {
+	testCases := map[string]bool{
+		`resource.spec.replicas > 3 && resource.metadata.labels['tier'] == 'web'`:        true,
+		`resource.spec.replicas > 3 && resource.metadata.labels['tier'] == 'db'`:         false,
+		`resource.kind in ['Deployment', 'StatefulSet']`:                                 true,
+		`'tier' in resource.metadata.labels`:                                             true,
+		`has(resource.spec.paused)`:                                                      false,
+		`!has(resource.spec.paused) || resource.spec.paused`:                             true,
+		`resource.spec.replicas * 2 == 10 && resource.spec.replicas % 2 == 1`:            true,
+		`resource.spec.replicas >= 4.5`:                                                  true,
+		`size(resource.spec.template.spec.containers) == 1`:                              true,
+		`resource.spec.template.spec.containers[0].image.endsWith(':v1.2')`:              true,
+		`resource.metadata.name.startsWith("web-")`:                                      true,
+		`resource.metadata.name.matches('^web-(front|back)end$')`:                        true,
+		`resource.metadata.name.contains('end') ? resource.kind == 'Deployment' : false`: true,
+		`-resource.spec.replicas < 0 && (1 + 2) * 3 == 9`:                                true,
+		// As in CEL, the side that decides the result hides errors on the other.
+		`resource.spec.paused || resource.spec.replicas == 5`: true,
+		`resource.spec.replicas == 4 && resource.spec.paused`: false,
+		// macros, conversions and the extended string functions
+		`resource.spec.template.spec.containers.all(c, c.image.startsWith('app:'))`:   true,
+		`resource.spec.template.spec.containers.exists(c, c.name == 'sidecar')`:       false,
+		`string(resource.spec.replicas) == '5' && int('5') == resource.spec.replicas`: true,
+		`resource.metadata.name.split('-')[0].upperAscii() == 'WEB'`:                  true,
+	}
+	for expression, expected := range testCases {
+		t.Run(expression, func(t *testing.T) {
+			e, err := cel.Parse(expression)
+			require.NoError(t, err)
+			matched, err := e.Matches(deployment)
+			require.NoError(t, err)
+			assert.Equal(t, expected, matched)
+		})
+	}
+}
+
+func TestMatchesNoSuchKey(t *testing.T) {
+	e, err := cel.Parse(`resource.spec.paused`)
+	require.NoError(t, err)
+	_, err = e.Matches(deployment)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, cel.ErrNoSuchKey))
+}
+
+func TestErrors(t *testing.T) {
+	testCases := map[string]string{
+		`resource.spec.replicas >`:        `invalid CEL expression "resource.spec.replicas >": ERROR: <input>:1:25: Syntax error`,
+		`resource.kind == 'Deployment`:    "token recognition error",
+		`resource.kind #`:                 "token recognition error at: '#'",
+		`has(resource)`:                   "invalid argument to has() macro",
+		`resource.spec`:                   "evaluates to map, not bool",
+		`resource.kind > 3`:               "no such overload",
+		`resource.kind.lower() == 'x'`:    "undeclared reference to 'lower'",
+		`object.kind == 'Deployment'`:     "undeclared reference to 'object'",
+		`resource.spec.replicas / 0 == 1`: "division by zero",
+		`resource.kind + 'x'`:             "evaluates to string, not bool",
+	}
+	for expression, errMsg := range testCases {
+		t.Run(expression, func(t *testing.T) {
+			e, err := cel.Parse(expression)
+			if err == nil {
+				_, err = e.Matches(deployment)
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), errMsg)
+		})
+	}
+}
@@ -6,6 +6,7 @@ package generators
 import (
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -46,7 +47,7 @@ func MakeSecret(
 			Value: yaml.NewStringRNode(t)}); err != nil {
 		return nil, err
 	}
-	m, err := makeValidatedDataMap(ldr, args.Name, args.KvPairSources)
+	m, err := makeValidatedSecretDataMap(ldr, args)
 	if err != nil {
 		return nil, err
 	}
@@ -57,3 +58,26 @@ func MakeSecret(
 	setImmutable(rn, args.Options)
 	return rn, nil
 }
+
+// makeValidatedSecretDataMap loads the sources of the secret
+// along with the values pulled from secret providers.
+func makeValidatedSecretDataMap(
+	ldr ifc.KvLoader, args *types.SecretArgs) (map[string]string, error) {
+	if len(args.Values) == 0 {
+		return makeValidatedDataMap(ldr, args.Name, args.KvPairSources)
+	}
+	vl, ok := ldr.(ifc.SecretValueLoader)
+	if !ok {
+		return nil, errors.Errorf(
+			"secret %s: the kv loader cannot pull values from secret providers", args.Name)
+	}
+	pairs, err := ldr.Load(args.KvPairSources)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "loading KV pairs")
+	}
+	values, err := vl.LoadSecretValues(args.Values)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "secret %s", args.Name)
+	}
+	return makeValidatedPairMap(ldr.Validator(), args.Name, append(pairs, values...))
+}
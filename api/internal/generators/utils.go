@@ -42,10 +42,15 @@ func makeValidatedDataMap(
 	if err != nil {
 		return nil, errors.WrapPrefix(err, "loading KV pairs", 0)
 	}
+	return makeValidatedPairMap(ldr.Validator(), name, pairs)
+}
+
+func makeValidatedPairMap(
+	v ifc.Validator, name string, pairs []types.Pair) (map[string]string, error) {
 	knownKeys := make(map[string]string)
 	for _, p := range pairs {
 		// legal key: alphanumeric characters, '-', '_' or '.'
-		if err := ldr.Validator().ErrIfInvalidKey(p.Key); err != nil {
+		if err := v.ErrIfInvalidKey(p.Key); err != nil {
 			return nil, err
 		}
 		if _, ok := knownKeys[p.Key]; ok {
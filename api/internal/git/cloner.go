@@ -4,6 +4,8 @@
 package git
 
 import (
+	"sigs.k8s.io/kustomize/api/remotecache"
+	"sigs.k8s.io/kustomize/kyaml/copyutil"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
@@ -44,6 +46,33 @@ func ClonerUsingGitExec(repoSpec *RepoSpec) error {
 	return nil
 }
 
+// CachingCloner returns a cloner that copies clones out
+// of the cache, only running the given cloner for repos
+// that aren't cached, or whose clones have expired.
+func CachingCloner(cloner Cloner, cache *remotecache.Cache) Cloner {
+	return func(repoSpec *RepoSpec) error {
+		url, ref := repoSpec.CloneSpec(), repoSpec.Ref
+		if repoSpec.Submodules {
+			ref += "?submodules=true"
+		}
+		e, ok, err := cache.Lookup(url, ref)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if repoSpec.Dir, err = filesys.NewTmpConfirmedDir(); err != nil {
+				return err
+			}
+			return copyutil.CopyDir(filesys.MakeFsOnDisk(), e.Path, repoSpec.Dir.String())
+		}
+		if err = cloner(repoSpec); err != nil {
+			return err
+		}
+		_, err = cache.Store(url, ref, repoSpec.Dir.String())
+		return err
+	}
+}
+
 // DoNothingCloner returns a cloner that only sets
 // cloneDir field in the repoSpec.  It's assumed that
 // the cloneDir is associated with some fake filesystem
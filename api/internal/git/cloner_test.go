@@ -0,0 +1,48 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/remotecache"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestCachingCloner(t *testing.T) {
+	clones := 0
+	cloner := func(repoSpec *RepoSpec) error {
+		clones++
+		dir, err := filesys.NewTmpConfirmedDir()
+		if err != nil {
+			return err
+		}
+		repoSpec.Dir = dir
+		return os.WriteFile(dir.Join("kustomization.yaml"), []byte("resources: []\n"), 0o600)
+	}
+	cache := &remotecache.Cache{Dir: t.TempDir(), TTL: time.Hour}
+	caching := CachingCloner(cloner, cache)
+
+	for i := 0; i < 2; i++ {
+		repoSpec, err := NewRepoSpecFromURL("https://github.com/org/repo//base?ref=v1")
+		require.NoError(t, err)
+		require.NoError(t, caching(repoSpec))
+		t.Cleanup(func() { _ = repoSpec.Cleaner(filesys.MakeFsOnDisk())() })
+		content, err := os.ReadFile(filepath.Join(repoSpec.Dir.String(), "kustomization.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, "resources: []\n", string(content))
+	}
+	require.Equal(t, 1, clones)
+
+	// Another ref is another entry.
+	repoSpec, err := NewRepoSpecFromURL("https://github.com/org/repo//base?ref=v2")
+	require.NoError(t, err)
+	require.NoError(t, caching(repoSpec))
+	t.Cleanup(func() { _ = repoSpec.Cleaner(filesys.MakeFsOnDisk())() })
+	require.Equal(t, 2, clones)
+}
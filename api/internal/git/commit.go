@@ -0,0 +1,81 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// HeadCommit returns the commit checked out in the git work tree
+// containing dir.
+func HeadCommit(dir string) (string, error) {
+	gitProgram, err := exec.LookPath("git")
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "no 'git' program on path")
+	}
+	cmd := exec.Command(gitProgram, "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "failed to run '%s' in %s", cmd.String(), dir)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+var commitRegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ResolveCommit returns the commit that the ref of repoSpec, HEAD if
+// empty, points to in the remote repo, without cloning it.  A ref
+// that is a full commit hash resolves to itself.
+func ResolveCommit(repoSpec *RepoSpec) (string, error) {
+	ref := repoSpec.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if commitRegexp.MatchString(ref) {
+		return ref, nil
+	}
+	gitProgram, err := exec.LookPath("git")
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "no 'git' program on path")
+	}
+	// annotated tags point to tag objects; their peeled ref, suffixed
+	// with ^{}, points to the commit
+	//nolint: gosec
+	cmd := exec.Command(gitProgram, "ls-remote", repoSpec.CloneSpec(), ref, ref+"^{}")
+	var out []byte
+	err = utils.TimedCall(cmd.String(), repoSpec.Timeout, func() error {
+		var err error
+		out, err = cmd.Output()
+		if err != nil {
+			return errors.WrapPrefixf(err, "failed to run '%s'", cmd.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	var commit string
+	for _, line := range strings.Split(string(out), "\n") {
+		hash, name, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+		if strings.HasSuffix(name, "^{}") {
+			return hash, nil
+		}
+		if commit == "" {
+			commit = hash
+		}
+	}
+	if commit == "" {
+		return "", errors.Errorf("ref %q not found in %s", ref, repoSpec.CloneSpec())
+	}
+	return commit, nil
+}
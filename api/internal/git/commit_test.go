@@ -0,0 +1,75 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// gitIn runs git in dir and returns its trimmed output.
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func TestResolveCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "--quiet", "--initial-branch=main")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "kustomization.yaml"), []byte("resources: []\n"), 0o600))
+	gitIn(t, repo, "add", ".")
+	gitIn(t, repo, "commit", "--quiet", "-m", "first")
+	first := gitIn(t, repo, "rev-parse", "HEAD")
+	gitIn(t, repo, "tag", "-a", "v1", "-m", "v1")
+	gitIn(t, repo, "commit", "--quiet", "--allow-empty", "-m", "second")
+	second := gitIn(t, repo, "rev-parse", "HEAD")
+
+	for ref, expected := range map[string]string{
+		"main": second,
+		"v1":   first,
+		first:  first,
+		"":     second,
+	} {
+		repoSpec, err := NewRepoSpecFromURL("file://" + repo + "?ref=" + ref)
+		require.NoError(t, err)
+		commit, err := ResolveCommit(repoSpec)
+		require.NoError(t, err)
+		require.Equal(t, expected, commit, "ref %q", ref)
+	}
+
+	repoSpec, err := NewRepoSpecFromURL("file://" + repo + "?ref=missing")
+	require.NoError(t, err)
+	_, err = ResolveCommit(repoSpec)
+	require.ErrorContains(t, err, `ref "missing" not found in file://`)
+}
+
+func TestHeadCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "--quiet")
+	require.NoError(t, os.Mkdir(filepath.Join(repo, "base"), 0o700))
+	gitIn(t, repo, "commit", "--quiet", "--allow-empty", "-m", "first")
+
+	commit, err := HeadCommit(filepath.Join(repo, "base"))
+	require.NoError(t, err)
+	require.Equal(t, gitIn(t, repo, "rev-parse", "HEAD"), commit)
+
+	_, err = HeadCommit(t.TempDir())
+	require.ErrorContains(t, err, "failed to run")
+}
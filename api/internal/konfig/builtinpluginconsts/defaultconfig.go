@@ -20,6 +20,8 @@ func GetDefaultFieldSpecs() []byte {
 		[]byte(nameReferenceFieldSpecs),
 		[]byte(imagesFieldSpecs),
 		[]byte(replicasFieldSpecs),
+		[]byte(resourceOverridesFieldSpecs),
+		[]byte(schedulingFieldSpecs),
 	}
 	return bytes.Join(configData, []byte("\n"))
 }
@@ -38,5 +40,7 @@ func GetDefaultFieldSpecsAsMap() map[string]string {
 	result["namereference"] = nameReferenceFieldSpecs
 	result["images"] = imagesFieldSpecs
 	result["replicas"] = replicasFieldSpecs
+	result["resourceoverrides"] = resourceOverridesFieldSpecs
+	result["scheduling"] = schedulingFieldSpecs
 	return result
 }
@@ -0,0 +1,16 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package builtinpluginconsts
+
+const resourceOverridesFieldSpecs = `
+resourceOverrides:
+- path: spec/containers
+- path: spec/initContainers
+- path: spec/template/spec/containers
+- path: spec/template/spec/initContainers
+- path: spec/jobTemplate/spec/template/spec/containers
+  kind: CronJob
+- path: spec/jobTemplate/spec/template/spec/initContainers
+  kind: CronJob
+`
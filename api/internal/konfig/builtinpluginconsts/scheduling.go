@@ -0,0 +1,24 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package builtinpluginconsts
+
+const schedulingFieldSpecs = `
+scheduling:
+- path: spec
+  kind: Pod
+- path: spec/template/spec
+  kind: Deployment
+- path: spec/template/spec
+  kind: StatefulSet
+- path: spec/template/spec
+  kind: DaemonSet
+- path: spec/template/spec
+  kind: ReplicaSet
+- path: spec/template/spec
+  kind: ReplicationController
+- path: spec/template/spec
+  kind: Job
+- path: spec/jobTemplate/spec/template/spec
+  kind: CronJob
+`
@@ -9,11 +9,15 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/oci"
+	"sigs.k8s.io/kustomize/api/remotecache"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
@@ -93,6 +97,10 @@ type FileLoader struct {
 	// obtained from the given repository.
 	repoSpec *git.RepoSpec
 
+	// If this is non-nil, the files were
+	// obtained from the given OCI artifact.
+	artifactSpec *oci.ArtifactSpec
+
 	// File system utilities.
 	fSys filesys.FileSystem
 
@@ -102,6 +110,13 @@ type FileLoader struct {
 	// Used to clone repositories.
 	cloner git.Cloner
 
+	// Used to pull OCI artifacts.
+	puller oci.Puller
+
+	// If this is non-nil, remote files are kept in the
+	// given cache. Only set on the root loader.
+	cache *remotecache.Cache
+
 	// Used to clean up, as needed.
 	cleaner func() error
 }
@@ -143,17 +158,31 @@ func newLoaderAtConfirmedDir(
 		referrer:       referrer,
 		fSys:           fSys,
 		cloner:         cloner,
+		puller:         oci.PullerUsingHTTP,
 		cleaner:        func() error { return nil },
 	}
 }
 
 // New returns a new Loader, rooted relative to current loader,
-// or rooted in a temp directory holding a git repo clone.
+// or rooted in a temp directory holding a git repo clone,
+// or rooted in the cache directory holding an OCI artifact.
 func (fl *FileLoader) New(path string) (ifc.Loader, error) {
 	if path == "" {
 		return nil, errors.Errorf("new root cannot be empty")
 	}
 
+	if oci.IsOCIArtifact(path) {
+		artifactSpec, err := oci.NewArtifactSpecFromURL(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = fl.errIfArtifactCycle(artifactSpec); err != nil {
+			return nil, err
+		}
+		return newLoaderAtOCIArtifact(
+			artifactSpec, fl.fSys, fl, fl.cloner, fl.puller)
+	}
+
 	repoSpec, err := git.NewRepoSpecFromURL(path)
 	if err == nil {
 		// Treat this as git repo clone request.
@@ -174,11 +203,16 @@ func (fl *FileLoader) New(path string) (ifc.Loader, error) {
 	if err = fl.errIfGitContainmentViolation(root); err != nil {
 		return nil, err
 	}
+	if err = fl.errIfArtifactContainmentViolation(root); err != nil {
+		return nil, err
+	}
 	if err = fl.errIfArgEqualOrHigher(root); err != nil {
 		return nil, err
 	}
-	return newLoaderAtConfirmedDir(
-		fl.loadRestrictor, root, fl.fSys, fl, fl.cloner), nil
+	ldr := newLoaderAtConfirmedDir(
+		fl.loadRestrictor, root, fl.fSys, fl, fl.cloner)
+	ldr.puller = fl.puller
+	return ldr, nil
 }
 
 // newLoaderAtGitClone returns a new Loader pinned to a temporary
@@ -222,10 +256,46 @@ func newLoaderAtGitClone(
 		repoSpec:       repoSpec,
 		fSys:           fSys,
 		cloner:         cloner,
+		puller:         oci.PullerUsingHTTP,
 		cleaner:        cleaner,
 	}, nil
 }
 
+// newLoaderAtOCIArtifact returns a new Loader pinned to the
+// cache directory holding an extracted OCI artifact.
+func newLoaderAtOCIArtifact(
+	artifactSpec *oci.ArtifactSpec, fSys filesys.FileSystem,
+	referrer *FileLoader, cloner git.Cloner, puller oci.Puller) (ifc.Loader, error) {
+	if err := puller(artifactSpec); err != nil {
+		return nil, err
+	}
+	root, f, err := fSys.CleanedAbs(artifactSpec.AbsPath())
+	if err != nil {
+		return nil, err
+	}
+	if f != "" {
+		return nil, fmt.Errorf(
+			"'%s' refers to file '%s'; expecting directory",
+			artifactSpec.AbsPath(), f)
+	}
+	if !root.HasPrefix(artifactSpec.ArtifactDir()) {
+		return nil, fmt.Errorf("%q refers to directory outside of OCI artifact %q",
+			artifactSpec.AbsPath(), artifactSpec.ArtifactDir())
+	}
+	return &FileLoader{
+		// Artifacts never allowed to escape root.
+		loadRestrictor: RestrictionRootOnly,
+		root:           root,
+		referrer:       referrer,
+		artifactSpec:   artifactSpec,
+		fSys:           fSys,
+		cloner:         cloner,
+		puller:         puller,
+		// The cache holding the artifact outlives the build.
+		cleaner: func() error { return nil },
+	}, nil
+}
+
 func (fl *FileLoader) errIfGitContainmentViolation(
 	base filesys.ConfirmedDir) error {
 	containingRepo := fl.containingRepo()
@@ -242,6 +312,34 @@ func (fl *FileLoader) errIfGitContainmentViolation(
 	return nil
 }
 
+func (fl *FileLoader) errIfArtifactContainmentViolation(
+	base filesys.ConfirmedDir) error {
+	containingArtifact := fl.containingArtifact()
+	if containingArtifact == nil {
+		return nil
+	}
+	if !base.HasPrefix(containingArtifact.ArtifactDir()) {
+		return fmt.Errorf(
+			"security; bases in kustomizations found in "+
+				"OCI artifacts must be within the artifact, "+
+				"but base '%s' is outside '%s'",
+			base, containingArtifact.ArtifactDir())
+	}
+	return nil
+}
+
+// Looks back through referrers for an OCI artifact,
+// returning nil if none found.
+func (fl *FileLoader) containingArtifact() *oci.ArtifactSpec {
+	if fl.artifactSpec != nil {
+		return fl.artifactSpec
+	}
+	if fl.referrer == nil {
+		return nil
+	}
+	return fl.referrer.containingArtifact()
+}
+
 // Looks back through referrers for a git repo, returning nil
 // if none found.
 func (fl *FileLoader) containingRepo() *git.RepoSpec {
@@ -287,12 +385,25 @@ func (fl *FileLoader) errIfRepoCycle(newRepoSpec *git.RepoSpec) error {
 	return fl.referrer.errIfRepoCycle(newRepoSpec)
 }
 
+func (fl *FileLoader) errIfArtifactCycle(newArtifactSpec *oci.ArtifactSpec) error {
+	if fl.artifactSpec != nil &&
+		strings.HasPrefix(fl.artifactSpec.Raw(), newArtifactSpec.Raw()) {
+		return fmt.Errorf(
+			"cycle detected: URI '%s' referenced by previous URI '%s'",
+			newArtifactSpec.Raw(), fl.artifactSpec.Raw())
+	}
+	if fl.referrer == nil {
+		return nil
+	}
+	return fl.referrer.errIfArtifactCycle(newArtifactSpec)
+}
+
 // Load returns the content of file at the given path,
 // else an error. Relative paths are taken relative
 // to the root.
 func (fl *FileLoader) Load(path string) ([]byte, error) {
 	if IsRemoteFile(path) {
-		return fl.httpClientGetContent(path)
+		return fl.cachedHTTPGetContent(path)
 	}
 	if !filepath.IsAbs(path) {
 		path = fl.root.Join(path)
@@ -304,6 +415,88 @@ func (fl *FileLoader) Load(path string) ([]byte, error) {
 	return fl.fSys.ReadFile(path)
 }
 
+// IsDir returns true if the path, taken relative to
+// the root unless absolute, is a local directory.
+func (fl *FileLoader) IsDir(path string) bool {
+	if IsRemoteFile(path) {
+		return false
+	}
+	if !filepath.IsAbs(path) {
+		path = fl.root.Join(path)
+	}
+	return fl.fSys.IsDir(path)
+}
+
+// ListFiles returns the paths of the files in the
+// directory dir and its subdirectories, in lexical
+// order, each joined to dir. All of them must pass
+// the load restrictions.
+func (fl *FileLoader) ListFiles(dir string) ([]string, error) {
+	if IsRemoteFile(dir) {
+		return nil, fmt.Errorf("cannot list the files of remote directory %s", dir)
+	}
+	abs := dir
+	if !filepath.IsAbs(abs) {
+		abs = fl.root.Join(dir)
+	}
+	var files []string
+	err := fl.fSys.Walk(abs, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if _, err = fl.loadRestrictor(fl.fSys, fl.root, path); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(abs, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.Join(dir, rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Looks back through referrers for a remote cache,
+// returning nil if none found.
+func (fl *FileLoader) remoteCache() *remotecache.Cache {
+	if fl.cache != nil {
+		return fl.cache
+	}
+	if fl.referrer == nil {
+		return nil
+	}
+	return fl.referrer.remoteCache()
+}
+
+// cachedHTTPGetContent serves remote files from the
+// remote cache, if any, refreshing expired entries.
+func (fl *FileLoader) cachedHTTPGetContent(path string) ([]byte, error) {
+	cache := fl.remoteCache()
+	if cache == nil {
+		return fl.httpClientGetContent(path)
+	}
+	e, ok, err := cache.Lookup(path, "")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return os.ReadFile(e.Path)
+	}
+	content, err := fl.httpClientGetContent(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = cache.StoreFile(path, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
 func (fl *FileLoader) httpClientGetContent(path string) ([]byte, error) {
 	var hc *http.Client
 	if fl.http != nil {
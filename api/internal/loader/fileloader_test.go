@@ -14,11 +14,14 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/oci"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/remotecache"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
@@ -507,6 +510,42 @@ func TestLoaderDisallowsRemoteBaseExitRepo(t *testing.T) {
 	require.Contains(t, err.Error(), fmt.Sprintf("%q refers to directory outside of repo %q", base, repo))
 }
 
+func TestLoaderAtOCIArtifact(t *testing.T) {
+	require := require.New(t)
+
+	topDir := "/whatever"
+	artifactRoot := topDir + "/someArtifact"
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(fSys.MkdirAll(topDir + "/highBase"))
+	require.NoError(fSys.MkdirAll(artifactRoot + "/base"))
+	require.NoError(fSys.MkdirAll(artifactRoot + "/overlay"))
+
+	l0 := newLoaderAtConfirmedDir(
+		RestrictionRootOnly, filesys.ConfirmedDir(topDir), fSys, nil,
+		git.DoNothingCloner(filesys.ConfirmedDir(topDir)))
+	l0.puller = oci.DoNothingPuller(filesys.ConfirmedDir(artifactRoot))
+
+	l1, err := l0.New("oci://ghcr.io/org/base-manifests:v1.2.3//overlay")
+	require.NoError(err)
+	require.Equal(artifactRoot+"/overlay", l1.Root())
+
+	// This is okay.
+	l2, err := l1.New("../base")
+	require.NoError(err)
+	require.Equal(artifactRoot+"/base", l2.Root())
+
+	// This is not okay.
+	_, err = l2.New("../../highBase")
+	require.Error(err)
+	require.Contains(err.Error(),
+		"base '/whatever/highBase' is outside '/whatever/someArtifact'")
+
+	// Neither is referring back to the artifact.
+	_, err = l2.New("oci://ghcr.io/org/base-manifests:v1.2.3")
+	require.Error(err)
+	require.Contains(err.Error(), "cycle detected")
+}
+
 func TestLocalLoaderReferencingGitBase(t *testing.T) {
 	require := require.New(t)
 
@@ -661,6 +700,40 @@ func TestLoaderHTTP(t *testing.T) {
 	}
 }
 
+func TestLoaderHTTPWithRemoteCache(t *testing.T) {
+	require := require.New(t)
+
+	requests := 0
+	l1 := NewLoaderOrDie(
+		RestrictionRootOnly, filesys.MakeFsInMemory(), filesys.Separator)
+	l1.http = makeFakeHTTPClient(func(req *http.Request) *http.Response {
+		requests++
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString("https content")),
+			Header:     make(http.Header),
+		}
+	})
+	cache := &remotecache.Cache{Dir: t.TempDir(), TTL: time.Hour}
+	l1.cache = cache
+
+	for i := 0; i < 2; i++ {
+		b, err := l1.Load("https://example.com/resource.yaml")
+		require.NoError(err)
+		require.Equal("https content", string(b))
+	}
+	require.Equal(1, requests)
+
+	cache.TTL = 0
+	cache.Offline = true
+	b, err := l1.Load("https://example.com/resource.yaml")
+	require.NoError(err)
+	require.Equal("https content", string(b))
+	_, err = l1.Load("https://example.com/other.yaml")
+	require.ErrorIs(err, remotecache.ErrNotCached)
+	require.Equal(1, requests)
+}
+
 // setupOnDisk sets up a file system on disk and directory that is cleaned after
 // test completion.
 // TODO(annasong): Move all loader tests that require real file system into
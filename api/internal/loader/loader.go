@@ -7,6 +7,8 @@ package loader
 import (
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/oci"
+	"sigs.k8s.io/kustomize/api/remotecache"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
@@ -17,19 +19,52 @@ import (
 // loader will have the restrictions passed in.  Regardless,
 // if a local target attempts to transitively load remote bases,
 // the remote bases will all be root-only restricted.
+// Remote targets are git repositories, or OCI artifacts
+// named by an oci:// reference.
 func NewLoader(
 	lr LoadRestrictorFunc,
 	target string, fSys filesys.FileSystem) (ifc.Loader, error) {
+	return NewLoaderWithCache(lr, target, fSys, nil)
+}
+
+// NewLoaderWithCache is NewLoader, keeping the remote git
+// repositories and files it loads in the given cache, if any.
+func NewLoaderWithCache(
+	lr LoadRestrictorFunc,
+	target string, fSys filesys.FileSystem,
+	cache *remotecache.Cache) (ifc.Loader, error) {
+	cloner := git.ClonerUsingGitExec
+	if cache != nil {
+		cloner = git.CachingCloner(cloner, cache)
+	}
+	if oci.IsOCIArtifact(target) {
+		artifactSpec, err := oci.NewArtifactSpecFromURL(target)
+		if err != nil {
+			return nil, err
+		}
+		ldr, err := newLoaderAtOCIArtifact(
+			artifactSpec, fSys, nil, cloner, oci.PullerUsingHTTP)
+		if err != nil {
+			return nil, err
+		}
+		ldr.(*FileLoader).cache = cache
+		return ldr, nil
+	}
 	repoSpec, err := git.NewRepoSpecFromURL(target)
 	if err == nil {
 		// The target qualifies as a remote git target.
-		return newLoaderAtGitClone(
-			repoSpec, fSys, nil, git.ClonerUsingGitExec)
+		ldr, err := newLoaderAtGitClone(repoSpec, fSys, nil, cloner)
+		if err != nil {
+			return nil, err
+		}
+		ldr.(*FileLoader).cache = cache
+		return ldr, nil
 	}
 	root, err := filesys.ConfirmDir(fSys, target)
 	if err != nil {
 		return nil, errors.WrapPrefixf(err, ErrRtNotDir.Error())
 	}
-	return newLoaderAtConfirmedDir(
-		lr, root, fSys, nil, git.ClonerUsingGitExec), nil
+	ldr := newLoaderAtConfirmedDir(lr, root, fSys, nil, cloner)
+	ldr.cache = cache
+	return ldr, nil
 }
@@ -18,6 +18,7 @@ import (
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/yaml"
 )
 
@@ -35,17 +36,63 @@ type localizer struct {
 
 	// destination directory in newDir that mirrors root
 	dst string
+
+	// newDir is the localize destination
+	newDir string
+
+	opts *Options
+
+	// prov records the remote content vendored into newDir
+	prov *provenance
+}
+
+// Options configure the optional parts of localization.
+type Options struct {
+	// HelmCommand, if set, is the helm executable that vendors the
+	// charts of helmCharts entries with a repo, pulling them into the
+	// localized chart home, where build finds them.
+	HelmCommand string
+
+	// FunctionImagesDir, if set, is the directory, relative to newDir,
+	// to which the images of container functions are exported as OCI
+	// layouts, and to which their references are rewritten.
+	FunctionImagesDir string
+
+	// SkopeoCommand is the skopeo executable that exports the images of
+	// container functions, container.DefaultSkopeoCommand if empty.
+	SkopeoCommand string
+
+	// Update, if set, updates the existing newDir of a previous
+	// localization rather than creating it, re-fetching only the
+	// remote roots whose refs point to other commits than recorded in
+	// its ProvenanceFile, and reusing its function images.
+	Update bool
 }
 
 // Run attempts to localize the kustomization root at target with the given localize arguments
 // and returns the path to the created newDir.
 func Run(target, scope, newDir string, fSys filesys.FileSystem) (string, error) {
-	ldr, args, err := NewLoader(target, scope, newDir, fSys)
+	return RunWithOptions(target, scope, newDir, fSys, Options{})
+}
+
+// RunWithOptions is Run, localizing the optional parts set in opts.
+func RunWithOptions(target, scope, newDir string, fSys filesys.FileSystem, opts Options) (string, error) {
+	if opts.FunctionImagesDir != "" && !filepath.IsLocal(opts.FunctionImagesDir) {
+		return "", errors.Errorf(
+			"function images directory %q must be a relative path inside of the localize destination",
+			opts.FunctionImagesDir)
+	}
+	ldr, args, err := newLoader(target, scope, newDir, fSys, opts.Update)
 	if err != nil {
 		return "", errors.Wrap(err)
 	}
 	defer func() { _ = ldr.Cleanup() }()
 
+	prov, err := newProvenance(fSys, args.NewDir.String(), opts.Update)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+
 	toDst, err := filepath.Rel(args.Scope.String(), args.Target.String())
 	if err != nil {
 		log.Panicf("cannot find path from %q to child directory %q: %s", args.Scope, args.Target, err)
@@ -55,16 +102,27 @@ func Run(target, scope, newDir string, fSys filesys.FileSystem) (string, error)
 		return "", errors.WrapPrefixf(err, "unable to create directory in localize destination")
 	}
 
-	err = (&localizer{
+	lc := &localizer{
 		fSys:     fSys,
 		ldr:      ldr,
 		root:     args.Target,
 		rFactory: resmap.NewFactory(provider.NewDepProvider().GetResourceFactory()),
 		dst:      dst,
-	}).localize()
+		newDir:   args.NewDir.String(),
+		opts:     &opts,
+		prov:     prov,
+	}
+	err = lc.localize()
+	if err == nil && ldr.Repo() != "" {
+		err = lc.recordRoot(VendoredRemoteTarget, target, args.NewDir.String())
+	}
+	if err == nil {
+		err = prov.write(fSys)
+	}
 	if err != nil {
-		errCleanup := fSys.RemoveAll(args.NewDir.String())
-		if errCleanup != nil {
+		if opts.Update {
+			log.Printf("localize destination %s is partially updated", args.NewDir)
+		} else if errCleanup := fSys.RemoveAll(args.NewDir.String()); errCleanup != nil {
 			log.Printf("unable to clean localize destination: %s", errCleanup)
 		}
 		return "", errors.WrapPrefixf(err, "unable to localize target %q", target)
@@ -272,7 +330,8 @@ func (lc *localizer) localizeHelmInflationGenerator(kust *types.Kustomization) e
 }
 
 // localizeHelmCharts localizes helmCharts and helmGlobals on kust.
-// localizeHelmCharts localizes values files and copies a local chart home.
+// localizeHelmCharts localizes values files and copies a local chart home,
+// into which it vendors remote charts if lc has a helm command.
 func (lc *localizer) localizeHelmCharts(kust *types.Kustomization) error {
 	for i, chart := range kust.HelmCharts {
 		locFile, err := lc.localizeFile(chart.ValuesFile)
@@ -289,18 +348,28 @@ func (lc *localizer) localizeHelmCharts(kust *types.Kustomization) error {
 			kust.HelmCharts[i].AdditionalValuesFiles[j] = locFile
 		}
 	}
+	home := ""
 	if kust.HelmGlobals != nil {
 		locDir, err := lc.copyChartHomeEntry(kust.HelmGlobals.ChartHome)
 		if err != nil {
 			return errors.WrapPrefixf(err, "unable to copy helmGlobals")
 		}
 		kust.HelmGlobals.ChartHome = locDir
+		home = locDir
 	} else if len(kust.HelmCharts) > 0 {
 		_, err := lc.copyChartHomeEntry("")
 		if err != nil {
 			return errors.WrapPrefixf(err, "unable to copy default chart home")
 		}
 	}
+	if home == "" {
+		home = types.HelmDefaultHome
+	}
+	for i, chart := range kust.HelmCharts {
+		if err := lc.vendorHelmChart(&chart, home); err != nil {
+			return errors.WrapPrefixf(err, "unable to vendor helmCharts entry %d", i)
+		}
+	}
 	return nil
 }
 
@@ -390,6 +459,9 @@ func (lc *localizer) localizeFileWithContent(path string, content []byte) (strin
 	if err := lc.fSys.WriteFile(absPath, content); err != nil {
 		return "", errors.WrapPrefixf(err, "unable to localize file %q", path)
 	}
+	if loader.IsRemoteFile(path) {
+		lc.prov.record(absPath, Vendored{Type: VendoredRemoteFile, Source: path})
+	}
 	return locPath, nil
 }
 
@@ -398,6 +470,9 @@ func (lc *localizer) localizeRoot(path string) (string, error) {
 	if path == "" {
 		return "", nil
 	}
+	if locPath, reused, err := lc.reuseRoot(path); err != nil || reused {
+		return locPath, err
+	}
 	ldr, err := lc.ldr.New(path)
 	if err != nil {
 		return "", errors.Wrap(err)
@@ -433,10 +508,18 @@ func (lc *localizer) localizeRoot(path string) (string, error) {
 		root:     root,
 		rFactory: lc.rFactory,
 		dst:      newDst,
+		newDir:   lc.newDir,
+		opts:     lc.opts,
+		prov:     lc.prov,
 	}).localize()
 	if err != nil {
 		return "", errors.WrapPrefixf(err, "unable to localize root %q", path)
 	}
+	if ldr.Repo() != "" {
+		if err = lc.recordRoot(VendoredRemoteRoot, path, newDst); err != nil {
+			return "", err
+		}
+	}
 	return locPath, nil
 }
 
@@ -509,12 +592,17 @@ func (lc *localizer) copyChartHome(path string, clean bool) (string, error) {
 
 // copyDir copies src to dst. copyDir does not follow symlinks.
 func (lc *localizer) copyDir(src filesys.ConfirmedDir, dst string) error {
-	err := lc.fSys.Walk(src.String(),
+	return lc.copyDirFrom(lc.fSys, src.String(), dst)
+}
+
+// copyDirFrom copies src on fSys to dst. copyDirFrom does not follow symlinks.
+func (lc *localizer) copyDirFrom(fSys filesys.FileSystem, src, dst string) error {
+	err := fSys.Walk(src,
 		func(path string, info fs.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
-			pathToCreate, err := filepath.Rel(src.String(), path)
+			pathToCreate, err := filepath.Rel(src, path)
 			if err != nil {
 				log.Panicf("no path from %q to child file %q: %s", src, path, err)
 			}
@@ -526,7 +614,7 @@ func (lc *localizer) copyDir(src filesys.ConfirmedDir, dst string) error {
 				err = lc.fSys.MkdirAll(pathInDst)
 			} else {
 				var content []byte
-				content, err = lc.fSys.ReadFile(path)
+				content, err = fSys.ReadFile(path)
 				if err != nil {
 					return errors.Wrap(err)
 				}
@@ -542,6 +630,8 @@ func (lc *localizer) copyDir(src filesys.ConfirmedDir, dst string) error {
 
 // localizeBuiltinPlugins localizes built-in plugins on kust that can contain file paths. The built-in plugins
 // can be inline or in a file. This excludes the HelmChartInflationGenerator.
+// If lc has a function images directory, localizeBuiltinPlugins also exports the
+// images of the container functions on kust.
 //
 // Note that the localization in this function has not been implemented yet.
 func (lc *localizer) localizeBuiltinPlugins(kust *types.Kustomization) error {
@@ -559,6 +649,12 @@ func (lc *localizer) localizeBuiltinPlugins(kust *types.Kustomization) error {
 			if err != nil {
 				return errors.Wrap(err)
 			}
+			if lc.opts.FunctionImagesDir != "" {
+				err = rm.ApplyFilter(kio.FilterFunc(lc.localizeFunctionImages))
+				if err != nil {
+					return errors.WrapPrefixf(err, "unable to localize %s entry function images", fieldName)
+				}
+			}
 			localizedPlugin, err := rm.AsYaml()
 			if err != nil {
 				return errors.WrapPrefixf(err, "unable to serialize localized %s entry %q", fieldName, entry)
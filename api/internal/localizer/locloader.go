@@ -44,6 +44,12 @@ var _ ifc.Loader = &Loader{}
 // NewLoader is the factory method for Loader, under localize constraints, at rawTarget. For invalid localize arguments,
 // NewLoader returns an error.
 func NewLoader(rawTarget string, rawScope string, rawNewDir string, fSys filesys.FileSystem) (*Loader, Args, error) {
+	return newLoader(rawTarget, rawScope, rawNewDir, fSys, false)
+}
+
+// newLoader is NewLoader, at an existing localize destination to update if update is set.
+func newLoader(rawTarget string, rawScope string, rawNewDir string, fSys filesys.FileSystem,
+	update bool) (*Loader, Args, error) {
 	// check earlier to avoid cleanup
 	repoSpec, err := git.NewRepoSpecFromURL(rawTarget)
 	if err == nil && repoSpec.Ref == "" {
@@ -62,7 +68,12 @@ func NewLoader(rawTarget string, rawScope string, rawNewDir string, fSys filesys
 		return nil, Args{}, errors.WrapPrefixf(err, "invalid localize scope %q", rawScope)
 	}
 
-	newDir, err := createNewDir(rawNewDir, ldr, repoSpec, fSys)
+	var newDir filesys.ConfirmedDir
+	if update {
+		newDir, err = existingNewDir(rawNewDir, ldr, repoSpec, fSys)
+	} else {
+		newDir, err = createNewDir(rawNewDir, ldr, repoSpec, fSys)
+	}
 	if err != nil {
 		_ = ldr.Cleanup()
 		return nil, Args{}, errors.WrapPrefixf(err, "invalid localize destination %q", rawNewDir)
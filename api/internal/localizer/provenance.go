@@ -0,0 +1,387 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package localizer
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/oci"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// ProvenanceFile is the file in the localize destination that records
+// the remote content vendored into it, if any.
+const ProvenanceFile = "localize-provenance.yaml"
+
+// Types of vendored content.
+const (
+	VendoredRemoteTarget  = "remoteTarget"
+	VendoredRemoteRoot    = "remoteRoot"
+	VendoredRemoteFile    = "remoteFile"
+	VendoredHelmChart     = "helmChart"
+	VendoredFunctionImage = "functionImage"
+)
+
+// Provenance is the content of the ProvenanceFile.
+type Provenance struct {
+	// LocalizedAt is when the destination was last localized or updated.
+	LocalizedAt string `json:"localizedAt"`
+
+	// Vendored is the remote content of the destination, sorted by path.
+	Vendored []Vendored `json:"vendored,omitempty"`
+}
+
+// Vendored is remote content vendored into the localize destination.
+type Vendored struct {
+	// Path is the file or directory of the content, relative to the
+	// localize destination.
+	Path string `json:"path"`
+
+	// Type is one of VendoredRemoteTarget, VendoredRemoteRoot,
+	// VendoredRemoteFile, VendoredHelmChart and VendoredFunctionImage.
+	Type string `json:"type"`
+
+	// Source is the reference to the content: the URL of a remote target,
+	// root or file, the repo and name of a helm chart or a container image.
+	Source string `json:"source"`
+
+	// Ref is the git ref of a remote target or root, or the version of
+	// a chart.
+	Ref string `json:"ref,omitempty"`
+
+	// Commit is the commit the ref of a remote target or root pointed to.
+	Commit string `json:"commit,omitempty"`
+
+	// SHA256 is the hash of the file at Path or, for a directory, of
+	// the listing of the hashes and paths of its files in the format
+	// of sha256sum, sorted by path.
+	SHA256 string `json:"sha256"`
+
+	// FetchedAt is when the content was fetched, or last changed on
+	// update.
+	FetchedAt string `json:"fetchedAt"`
+}
+
+// provenance records the content vendored into the localize destination.
+type provenance struct {
+	newDir string
+	now    string
+
+	// previous is the content vendored by the localization being
+	// updated, by path; nil if not updating
+	previous map[string]Vendored
+
+	vendored map[string]Vendored
+
+	// commits caches the commits refs of remote roots point to
+	commits map[string]string
+}
+
+// newProvenance returns the provenance of newDir on fSys.  If update
+// is set, it reads the provenance of the previous localization, and
+// removes the files of newDir other than the remote roots and function
+// images it vendored, which may be reused.
+func newProvenance(fSys filesys.FileSystem, newDir string, update bool) (*provenance, error) {
+	p := &provenance{
+		newDir:   newDir,
+		now:      time.Now().UTC().Format(time.RFC3339),
+		vendored: map[string]Vendored{},
+		commits:  map[string]string{},
+	}
+	if !update {
+		return p, nil
+	}
+	content, err := fSys.ReadFile(filepath.Join(newDir, ProvenanceFile))
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "unable to read provenance of localize destination %q", newDir)
+	}
+	var previous Provenance
+	if err = yaml.Unmarshal(content, &previous); err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid provenance of localize destination %q", newDir)
+	}
+	p.previous = map[string]Vendored{}
+	var reusable []string
+	for _, v := range previous.Vendored {
+		p.previous[v.Path] = v
+		if v.Type == VendoredRemoteRoot || v.Type == VendoredFunctionImage {
+			reusable = append(reusable, filepath.Join(newDir, v.Path))
+		}
+	}
+	return p, errors.WrapPrefixf(removeAllBut(fSys, newDir, reusable),
+		"unable to clean localize destination %q for update", newDir)
+}
+
+// previousRoot returns the remote root at source that the localization
+// being updated vendored into the root whose destination is dst.
+func (p *provenance) previousRoot(dst, source string) (Vendored, bool) {
+	prefix := filepath.Join(dst, LocalizeDir) + string(filepath.Separator)
+	var found Vendored
+	for path, v := range p.previous {
+		if v.Type == VendoredRemoteRoot && v.Source == source &&
+			strings.HasPrefix(filepath.Join(p.newDir, path), prefix) &&
+			(found.Path == "" || len(path) < len(found.Path)) {
+			found = v
+		}
+	}
+	return found, found.Path != ""
+}
+
+// record records v, fetched now, at its path relative to newDir.
+func (p *provenance) record(path string, v Vendored) {
+	v.Path = p.rel(path)
+	v.FetchedAt = p.now
+	p.vendored[v.Path] = v
+}
+
+// reuse records the content at path, relative to newDir, that the
+// localization being updated vendored, including nested content.
+func (p *provenance) reuse(path string) {
+	for prevPath, v := range p.previous {
+		if prevPath == path || strings.HasPrefix(prevPath, path+string(filepath.Separator)) {
+			p.vendored[prevPath] = v
+		}
+	}
+}
+
+// resolveCommit returns the commit the ref of repoSpec points to.
+func (p *provenance) resolveCommit(repoSpec *git.RepoSpec) (string, error) {
+	key := repoSpec.CloneSpec() + "?ref=" + repoSpec.Ref
+	if commit, ok := p.commits[key]; ok {
+		return commit, nil
+	}
+	commit, err := git.ResolveCommit(repoSpec)
+	if err != nil {
+		return "", err
+	}
+	p.commits[key] = commit
+	return commit, nil
+}
+
+// rel returns abs relative to newDir.
+func (p *provenance) rel(abs string) string {
+	path, err := filepath.Rel(p.newDir, abs)
+	if err != nil {
+		return abs
+	}
+	return path
+}
+
+// write removes the content the localization being updated vendored
+// that is no longer referenced, and writes the ProvenanceFile, unless
+// nothing is vendored by a new localization.
+func (p *provenance) write(fSys filesys.FileSystem) error {
+	if p.previous == nil && len(p.vendored) == 0 {
+		return nil
+	}
+	for path := range p.previous {
+		abs := filepath.Join(p.newDir, path)
+		if p.covers(path) || !fSys.Exists(abs) {
+			continue
+		}
+		if err := fSys.RemoveAll(abs); err != nil {
+			return errors.WrapPrefixf(err, "unable to remove unreferenced %q", path)
+		}
+		// remove the directories left empty
+		for dir := filepath.Dir(abs); dir != p.newDir; dir = filepath.Dir(dir) {
+			if entries, err := fSys.ReadDir(dir); err != nil || len(entries) > 0 {
+				break
+			}
+			if err := fSys.RemoveAll(dir); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+	}
+	result := Provenance{LocalizedAt: p.now}
+	for _, v := range p.vendored {
+		sum, err := hashPath(fSys, filepath.Join(p.newDir, v.Path))
+		if err != nil {
+			return errors.WrapPrefixf(err, "unable to hash vendored %q", v.Path)
+		}
+		if prev, ok := p.previous[v.Path]; ok && prev.SHA256 == sum {
+			v.FetchedAt = prev.FetchedAt
+		}
+		v.SHA256 = sum
+		result.Vendored = append(result.Vendored, v)
+	}
+	sort.Slice(result.Vendored, func(i, j int) bool {
+		return result.Vendored[i].Path < result.Vendored[j].Path
+	})
+	content, err := yaml.Marshal(result)
+	if err != nil {
+		return errors.WrapPrefixf(err, "unable to serialize provenance")
+	}
+	return errors.WrapPrefixf(fSys.WriteFile(filepath.Join(p.newDir, ProvenanceFile), content),
+		"unable to write provenance")
+}
+
+// covers returns whether path, relative to newDir, is vendored content
+// or in it.
+func (p *provenance) covers(path string) bool {
+	for vendored := range p.vendored {
+		if path == vendored || strings.HasPrefix(path, vendored+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitRepoSpec returns the repo spec of path if path is a remote git root.
+func gitRepoSpec(path string) (*git.RepoSpec, bool) {
+	if oci.IsOCIArtifact(path) {
+		return nil, false
+	}
+	repoSpec, err := git.NewRepoSpecFromURL(path)
+	return repoSpec, err == nil
+}
+
+// reuseRoot returns the localized path of the remote root at path if lc
+// is updating a localization that vendored it at the commit its ref
+// still points to, in which case reuseRoot reuses the vendored copy.
+// Otherwise, reuseRoot removes the outdated copy.
+func (lc *localizer) reuseRoot(path string) (string, bool, error) {
+	if lc.prov.previous == nil {
+		return "", false, nil
+	}
+	repoSpec, isGit := gitRepoSpec(path)
+	if !isGit {
+		return "", false, nil
+	}
+	prev, found := lc.prov.previousRoot(lc.dst, path)
+	if !found {
+		return "", false, nil
+	}
+	abs := filepath.Join(lc.newDir, prev.Path)
+	commit, err := lc.prov.resolveCommit(repoSpec)
+	if err != nil {
+		return "", false, errors.WrapPrefixf(err, "unable to resolve ref of remote root %q", path)
+	}
+	if commit != prev.Commit || !lc.fSys.Exists(abs) {
+		if lc.fSys.Exists(abs) {
+			if err = lc.fSys.RemoveAll(abs); err != nil {
+				return "", false, errors.WrapPrefixf(err, "unable to remove outdated remote root %q", path)
+			}
+		}
+		return "", false, nil
+	}
+	lc.prov.reuse(prev.Path)
+	locPath, err := filepath.Rel(lc.dst, abs)
+	if err != nil {
+		return "", false, errors.WrapPrefixf(err, "no path to vendored remote root %q", path)
+	}
+	return locPath, true, nil
+}
+
+// recordRoot records the remote target or root, by typ, at path,
+// localized at dst.
+func (lc *localizer) recordRoot(typ, path, dst string) error {
+	v := Vendored{Type: typ, Source: path}
+	if repoSpec, isGit := gitRepoSpec(path); isGit {
+		commit, err := lc.prov.resolveCommit(repoSpec)
+		if err != nil {
+			return errors.WrapPrefixf(err, "unable to resolve ref of remote root %q", path)
+		}
+		v.Ref, v.Commit = repoSpec.Ref, commit
+	}
+	lc.prov.record(dst, v)
+	return nil
+}
+
+// hashPath returns the hex SHA256 of the file at path or, for a
+// directory, of the sha256sum listing of its files, sorted by path.
+func hashPath(fSys filesys.FileSystem, path string) (string, error) {
+	if !fSys.IsDir(path) {
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+	}
+	lines := map[string]string{}
+	err := fSys.Walk(path, func(file string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := fSys.ReadFile(file)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		rel = filepath.ToSlash(rel)
+		lines[rel] = fmt.Sprintf("%x  %s\n", sha256.Sum256(content), rel)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	files := make([]string, 0, len(lines))
+	for f := range lines {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	h := sha256.New()
+	for _, f := range files {
+		h.Write([]byte(lines[f]))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// removeAllBut removes the files in dir on fSys other than those in
+// keep, and the directories left empty.
+func removeAllBut(fSys filesys.FileSystem, dir string, keep []string) error {
+	kept := func(path string) bool {
+		for _, k := range keep {
+			if path == k || strings.HasPrefix(path, k+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+	var files, dirs []string
+	err := fSys.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case path == dir || kept(path):
+		case info.IsDir():
+			dirs = append(dirs, path)
+		default:
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	for _, f := range files {
+		if err = fSys.RemoveAll(f); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	// remove nested directories first
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, d := range dirs {
+		entries, err := fSys.ReadDir(d)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if len(entries) == 0 {
+			if err = fSys.RemoveAll(d); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
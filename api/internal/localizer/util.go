@@ -74,6 +74,19 @@ func createNewDir(rawNewDir string, targetLdr ifc.Loader, spec *git.RepoSpec, fS
 	return newDir, nil
 }
 
+// existingNewDir returns the existing localize destination directory to update or error.
+// Note that spec is nil if targetLdr is at local target.
+func existingNewDir(rawNewDir string, targetLdr ifc.Loader, spec *git.RepoSpec, fSys filesys.FileSystem) (filesys.ConfirmedDir, error) {
+	if rawNewDir == "" {
+		rawNewDir = defaultNewDir(targetLdr, spec)
+	}
+	if !fSys.Exists(filepath.Join(rawNewDir, ProvenanceFile)) {
+		return "", errors.Errorf("localize destination %q to update has no %s", rawNewDir, ProvenanceFile)
+	}
+	newDir, err := filesys.ConfirmDir(fSys, rawNewDir)
+	return newDir, errors.WrapPrefixf(err, "unable to establish localize destination")
+}
+
 // defaultNewDir calculates the default localize destination directory name from targetLdr at the localize target
 // and spec of target, which is nil if target is local
 func defaultNewDir(targetLdr ifc.Loader, spec *git.RepoSpec) string {
@@ -0,0 +1,174 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package localizer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// oldFunctionAnnotationKey and containerAnnotationKey are the
+	// legacy annotations declaring functions.
+	oldFunctionAnnotationKey = "config.k8s.io/function"
+	containerAnnotationKey   = "config.kubernetes.io/container"
+)
+
+// vendorHelmChart pulls chart into the localized chart home, at the
+// location the helm chart inflation generator looks for it, if lc has a
+// helm command and chart has a repo that isn't already in home.
+func (lc *localizer) vendorHelmChart(chart *types.HelmChart, home string) error {
+	if lc.opts.HelmCommand == "" || chart.Repo == "" {
+		return nil
+	}
+	untarDir := filepath.Join(lc.dst, home)
+	if chart.Version != "" {
+		untarDir = filepath.Join(untarDir, fmt.Sprintf("%s-%s", chart.Name, chart.Version))
+	}
+	if lc.fSys.Exists(filepath.Join(untarDir, chart.Name)) {
+		return nil
+	}
+	tmpDir, err := os.MkdirTemp("", "kustomize-localize-helm-")
+	if err != nil {
+		return errors.WrapPrefixf(err, "unable to create directory to pull chart %q", chart.Name)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	args := []string{"pull", "--untar", "--untardir", tmpDir}
+	if strings.HasPrefix(chart.Repo, "oci://") {
+		args = append(args, strings.TrimSuffix(chart.Repo, "/")+"/"+chart.Name)
+	} else {
+		args = append(args, "--repo", chart.Repo, chart.Name)
+	}
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+	cmd := exec.Command(lc.opts.HelmCommand, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return errors.Errorf("unable to run '%s %s': %v: %s",
+			lc.opts.HelmCommand, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	err = lc.copyDirFrom(filesys.MakeFsOnDisk(),
+		filepath.Join(tmpDir, chart.Name), filepath.Join(untarDir, chart.Name))
+	if err != nil {
+		return errors.WrapPrefixf(err, "unable to vendor chart %q", chart.Name)
+	}
+	lc.prov.record(filepath.Join(untarDir, chart.Name), Vendored{
+		Type:   VendoredHelmChart,
+		Source: strings.TrimSuffix(chart.Repo, "/") + "/" + chart.Name,
+		Ref:    chart.Version,
+	})
+	return nil
+}
+
+// localizeFunctionImages exports the images of the container functions
+// among plugins and rewrites their references to the exported images.
+func (lc *localizer) localizeFunctionImages(plugins []*yaml.RNode) ([]*yaml.RNode, error) {
+	for _, plugin := range plugins {
+		for _, key := range []string{runtimeutil.FunctionAnnotationKey, oldFunctionAnnotationKey} {
+			fn, ok := plugin.GetAnnotations()[key]
+			if !ok {
+				continue
+			}
+			spec, err := yaml.Parse(fn)
+			if err != nil {
+				return nil, errors.WrapPrefixf(err, "invalid %s annotation", key)
+			}
+			if err = lc.localizeFunctionImage(spec); err != nil {
+				return nil, err
+			}
+			fn, err = spec.String()
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			if err = plugin.PipeE(yaml.SetAnnotation(key, fn)); err != nil {
+				return nil, errors.Wrap(err)
+			}
+		}
+		if image, ok := plugin.GetAnnotations()[containerAnnotationKey]; ok {
+			locImage, err := lc.exportFunctionImage(image)
+			if err != nil {
+				return nil, err
+			}
+			if err = plugin.PipeE(yaml.SetAnnotation(containerAnnotationKey, locImage)); err != nil {
+				return nil, errors.Wrap(err)
+			}
+		}
+		spec, err := plugin.Pipe(yaml.Lookup(yaml.MetadataField, "configFn"))
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if err = lc.localizeFunctionImage(spec); err != nil {
+			return nil, err
+		}
+	}
+	return plugins, nil
+}
+
+// localizeFunctionImage exports the container image of function spec,
+// if any, and rewrites spec to reference the exported image.
+func (lc *localizer) localizeFunctionImage(spec *yaml.RNode) error {
+	node, err := spec.Pipe(yaml.Lookup("container", "image"))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if yaml.IsMissingOrNull(node) {
+		return nil
+	}
+	locImage, err := lc.exportFunctionImage(yaml.GetValue(node))
+	if err != nil {
+		return err
+	}
+	node.YNode().Value = locImage
+	return nil
+}
+
+var unsafeImageChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// exportFunctionImage exports image to an OCI layout in the function
+// images directory, unless already there, and returns its reference
+// relative to lc dst.
+func (lc *localizer) exportFunctionImage(image string) (string, error) {
+	if strings.HasPrefix(image, container.OCILayoutPrefix) {
+		return image, nil
+	}
+	layout := filepath.Join(lc.newDir, lc.opts.FunctionImagesDir,
+		unsafeImageChars.ReplaceAllString(image, "_"))
+	if lc.fSys.Exists(layout) {
+		lc.prov.reuse(lc.prov.rel(layout))
+	} else {
+		tmpDir, err := os.MkdirTemp("", "kustomize-localize-image-")
+		if err != nil {
+			return "", errors.WrapPrefixf(err, "unable to create directory to export image %q", image)
+		}
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+		if err = container.ExportImage(lc.opts.SkopeoCommand, image, filepath.Join(tmpDir, "layout")); err != nil {
+			return "", errors.Wrap(err)
+		}
+		err = lc.copyDirFrom(filesys.MakeFsOnDisk(), filepath.Join(tmpDir, "layout"), layout)
+		if err != nil {
+			return "", errors.WrapPrefixf(err, "unable to localize image %q", image)
+		}
+		lc.prov.record(layout, Vendored{Type: VendoredFunctionImage, Source: image})
+	}
+	locPath, err := filepath.Rel(lc.dst, layout)
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "no path to exported image %q", image)
+	}
+	return container.OCILayoutPrefix + locPath, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package oci obtains kustomizations packaged as OCI artifacts.
+package oci
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	ociPrefix  = "oci://"
+	pathSep    = "//"
+	defaultTag = "latest"
+)
+
+var digestRegexp = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// ArtifactSpec specifies an OCI artifact and a path therein.
+type ArtifactSpec struct {
+	// Raw, original spec, used to look for cycles.
+	raw string
+
+	// Registry hosting the artifact, e.g. ghcr.io
+	Registry string
+
+	// Repository within the registry,
+	// e.g. org/base-manifests
+	Repository string
+
+	// Tag of the artifact, e.g. v1.2.3
+	Tag string
+
+	// Digest pinning the artifact's manifest,
+	// e.g. sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b
+	Digest string
+
+	// Dir is where the artifact is extracted to.
+	Dir filesys.ConfirmedDir
+
+	// Relative path in the artifact, and in Dir,
+	// to a Kustomization.
+	KustRootPath string
+}
+
+func (x *ArtifactSpec) Raw() string {
+	return x.raw
+}
+
+// Reference returns the digest if the artifact is pinned,
+// else its tag.
+func (x *ArtifactSpec) Reference() string {
+	if x.Digest != "" {
+		return x.Digest
+	}
+	return x.Tag
+}
+
+func (x *ArtifactSpec) ArtifactDir() filesys.ConfirmedDir {
+	return x.Dir
+}
+
+func (x *ArtifactSpec) AbsPath() string {
+	return x.Dir.Join(x.KustRootPath)
+}
+
+// IsOCIArtifact returns true if the argument names an OCI artifact.
+func IsOCIArtifact(s string) bool {
+	return strings.HasPrefix(s, ociPrefix)
+}
+
+// NewArtifactSpecFromURL parses references of the form
+//
+//	oci://{registry}/{repository}[:{tag}][@{digest}][//{path}]
+//
+// e.g. oci://ghcr.io/org/base-manifests:v1.2.3//overlays/prod
+// If neither tag nor digest is given, the tag is "latest".
+func NewArtifactSpecFromURL(n string) (*ArtifactSpec, error) {
+	if !IsOCIArtifact(n) {
+		return nil, errors.Errorf("OCI artifact reference %q must start with %q", n, ociPrefix)
+	}
+	spec := &ArtifactSpec{raw: n}
+	ref := strings.TrimPrefix(n, ociPrefix)
+	if i := strings.Index(ref, pathSep); i >= 0 {
+		spec.KustRootPath = filepath.Clean(ref[i+len(pathSep):])
+		ref = ref[:i]
+		if spec.KustRootPath == ".." || strings.HasPrefix(spec.KustRootPath, "../") ||
+			filepath.IsAbs(spec.KustRootPath) {
+			return nil, errors.Errorf("OCI artifact path in %q must be within the artifact", n)
+		}
+	}
+	if i := strings.Index(ref, "@"); i >= 0 {
+		spec.Digest = ref[i+1:]
+		ref = ref[:i]
+		if !digestRegexp.MatchString(spec.Digest) {
+			return nil, errors.Errorf("OCI artifact digest in %q must be a sha256 digest", n)
+		}
+	}
+	registry, repository, found := strings.Cut(ref, "/")
+	if !found || registry == "" || repository == "" {
+		return nil, errors.Errorf("OCI artifact reference %q must name a registry and repository", n)
+	}
+	// A colon before the last slash belongs to the registry's port.
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		spec.Tag = repository[i+1:]
+		repository = repository[:i]
+	}
+	if spec.Tag == "" && spec.Digest == "" {
+		spec.Tag = defaultTag
+	}
+	spec.Registry = registry
+	spec.Repository = repository
+	return spec, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b"
+
+func TestNewArtifactSpecFromURL(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected ArtifactSpec
+	}{
+		"tag": {
+			input: "oci://ghcr.io/org/base-manifests:v1.2.3",
+			expected: ArtifactSpec{
+				Registry:   "ghcr.io",
+				Repository: "org/base-manifests",
+				Tag:        "v1.2.3",
+			},
+		},
+		"default tag": {
+			input: "oci://ghcr.io/org/base-manifests",
+			expected: ArtifactSpec{
+				Registry:   "ghcr.io",
+				Repository: "org/base-manifests",
+				Tag:        "latest",
+			},
+		},
+		"digest": {
+			input: "oci://ghcr.io/org/base-manifests@" + testDigest,
+			expected: ArtifactSpec{
+				Registry:   "ghcr.io",
+				Repository: "org/base-manifests",
+				Digest:     testDigest,
+			},
+		},
+		"tag, digest and path": {
+			input: "oci://localhost:5000/base:v1@" + testDigest + "//overlays/prod",
+			expected: ArtifactSpec{
+				Registry:     "localhost:5000",
+				Repository:   "base",
+				Tag:          "v1",
+				Digest:       testDigest,
+				KustRootPath: "overlays/prod",
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			spec, err := NewArtifactSpecFromURL(tc.input)
+			require.NoError(t, err)
+			tc.expected.raw = tc.input
+			assert.Equal(t, &tc.expected, spec)
+		})
+	}
+}
+
+func TestNewArtifactSpecFromURLErrors(t *testing.T) {
+	testCases := map[string]struct {
+		input  string
+		errMsg string
+	}{
+		"not oci": {
+			input:  "https://github.com/org/repo",
+			errMsg: `must start with "oci://"`,
+		},
+		"no repository": {
+			input:  "oci://ghcr.io",
+			errMsg: "must name a registry and repository",
+		},
+		"bad digest": {
+			input:  "oci://ghcr.io/org/base@sha256:abc",
+			errMsg: "must be a sha256 digest",
+		},
+		"path escapes": {
+			input:  "oci://ghcr.io/org/base:v1//../other",
+			errMsg: "must be within the artifact",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewArtifactSpecFromURL(tc.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+const (
+	dockerHub         = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+
+	// Image indexes come first, so that the digest of a
+	// multi-platform image is that of the whole image.
+	imageMediaTypes = "application/vnd.oci.image.index.v1+json, " +
+		"application/vnd.docker.distribution.manifest.list.v2+json, " +
+		manifestMediaTypes
+)
+
+// DigestResolver looks up the digests of image tags in their
+// registries, remembering the digests it has looked up.
+type DigestResolver struct {
+	client   *http.Client
+	keychain Keychain
+
+	mu      sync.Mutex
+	digests map[string]string
+}
+
+// NewDigestResolver returns a resolver querying registries with
+// the client, authenticated with the credentials of the keychain.
+func NewDigestResolver(client *http.Client, keychain Keychain) *DigestResolver {
+	return &DigestResolver{client: client, keychain: keychain, digests: map[string]string{}}
+}
+
+// Resolve returns the digest of the image name at the tag, which
+// defaults to latest, e.g. sha256:24a0c4b4a4c0eb97a1aa...
+func (r *DigestResolver) Resolve(name, tag string) (string, error) {
+	if tag == "" {
+		tag = "latest"
+	}
+	key := name + ":" + tag
+	r.mu.Lock()
+	digest, found := r.digests[key]
+	r.mu.Unlock()
+	if found {
+		return digest, nil
+	}
+	registry, repository := splitImageName(name)
+	credentials, err := r.keychain(registry)
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "unable to get credentials for %s", registry)
+	}
+	if registry == dockerHub {
+		registry = dockerHubRegistry
+	}
+	c := &repoClient{
+		client: r.client, registry: registry, repository: repository, credentials: credentials,
+	}
+	body, header, err := c.get("manifests/"+tag, imageMediaTypes)
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "unable to resolve the digest of %s", key)
+	}
+	digest = header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = sha256Digest(body)
+	} else if strings.HasPrefix(digest, "sha256:") && digest != sha256Digest(body) {
+		return "", errors.Errorf("manifest of %s does not match its digest %s", key, digest)
+	}
+	r.mu.Lock()
+	r.digests[key] = digest
+	r.mu.Unlock()
+	return digest, nil
+}
+
+// splitImageName splits an image name into its registry and
+// repository, e.g. nginx into docker.io and library/nginx.
+func splitImageName(name string) (string, string) {
+	registry, repository, found := strings.Cut(name, "/")
+	if !found || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		registry, repository = dockerHub, name
+	}
+	if registry == dockerHub && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return registry, repository
+}
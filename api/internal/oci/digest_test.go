@@ -0,0 +1,129 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeImageRegistry serves an image index for team/app:v1 to
+// clients holding a token obtained with alice's password.
+func newFakeImageRegistry(t *testing.T, index []byte, requests *int) *httptest.Server {
+	t.Helper()
+	var s *httptest.Server
+	s = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			if user, password, ok := req.BasicAuth(); !ok || user != "alice" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"alice-token"}`))
+			return
+		}
+		if req.Header.Get("Authorization") != "Bearer alice-token" {
+			w.Header().Set("WWW-Authenticate",
+				`Bearer realm="`+s.URL+`/token",service="test",scope="repository:team/app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if req.URL.Path != "/v2/team/app/manifests/v1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		*requests++
+		assert.Contains(t, req.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json")
+		w.Header().Set("Docker-Content-Digest", sha256Digest(index))
+		_, _ = w.Write(index)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func writeDockerConfig(t *testing.T, registry string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:secret"))
+	require.NoError(t, os.WriteFile(path,
+		[]byte(`{"auths":{"https://`+registry+`":{"auth":"`+auth+`"}}}`), 0o600))
+	return path
+}
+
+func TestDigestResolver(t *testing.T) {
+	index := []byte(`{"schemaVersion":2,"manifests":[]}`)
+	var requests int
+	s := newFakeImageRegistry(t, index, &requests)
+	registry := strings.TrimPrefix(s.URL, "https://")
+	r := NewDigestResolver(s.Client(), DockerConfigFileKeychain(writeDockerConfig(t, registry)))
+
+	digest, err := r.Resolve(registry+"/team/app", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, sha256Digest(index), digest)
+
+	// Each tag is looked up once.
+	digest, err = r.Resolve(registry+"/team/app", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, sha256Digest(index), digest)
+	assert.Equal(t, 1, requests)
+
+	_, err = r.Resolve(registry+"/team/app", "v2")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to resolve the digest of "+registry+"/team/app:v2")
+}
+
+func TestDigestResolverWithoutCredentials(t *testing.T) {
+	var requests int
+	s := newFakeImageRegistry(t, []byte(`{}`), &requests)
+	registry := strings.TrimPrefix(s.URL, "https://")
+	_, err := NewDigestResolver(s.Client(), AnonymousKeychain).Resolve(registry+"/team/app", "v1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to fetch token")
+}
+
+func TestDockerConfigFileKeychain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"auths":{
+  "https://index.docker.io/v1/": {"auth": "`+base64.StdEncoding.EncodeToString([]byte("bob:pw"))+`"},
+  "ghcr.io": {"username": "carol", "password": "pw2"},
+  "quay.io": {"identitytoken": "refresh"}
+}}`), 0o600))
+	keychain := DockerConfigFileKeychain(path)
+	for registry, expected := range map[string]*Credentials{
+		"docker.io":    {Username: "bob", Password: "pw"},
+		"ghcr.io":      {Username: "carol", Password: "pw2"},
+		"quay.io":      {IdentityToken: "refresh"},
+		"gcr.io":       nil,
+		"example.com":  nil,
+		"ghcr.io:5000": nil,
+	} {
+		c, err := keychain(registry)
+		require.NoError(t, err)
+		assert.Equal(t, expected, c, registry)
+	}
+	c, err := DockerConfigFileKeychain(filepath.Join(t.TempDir(), "missing.json"))("ghcr.io")
+	require.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+func TestSplitImageName(t *testing.T) {
+	for name, expected := range map[string][2]string{
+		"nginx":                         {"docker.io", "library/nginx"},
+		"team/app":                      {"docker.io", "team/app"},
+		"docker.io/nginx":               {"docker.io", "library/nginx"},
+		"ghcr.io/team/app":              {"ghcr.io", "team/app"},
+		"localhost/app":                 {"localhost", "app"},
+		"registry.example.com:5000/app": {"registry.example.com:5000", "app"},
+	} {
+		registry, repository := splitImageName(name)
+		assert.Equal(t, expected, [2]string{registry, repository}, name)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const manifestMediaTypes = "application/vnd.oci.image.manifest.v1+json, " +
+	"application/vnd.docker.distribution.manifest.v2+json"
+
+// Puller is a function that can obtain an OCI artifact.
+type Puller func(spec *ArtifactSpec) error
+
+// PullerUsingHTTP fetches artifacts over the OCI distribution
+// API into the content-addressed cache in DefaultCacheDir.
+func PullerUsingHTTP(spec *ArtifactSpec) error {
+	dir, err := DefaultCacheDir()
+	if err != nil {
+		return err
+	}
+	return NewHTTPPuller(&http.Client{}, dir)(spec)
+}
+
+// DoNothingPuller returns a puller that only sets the
+// Dir field in the spec.  It's assumed that the dir is
+// associated with some fake filesystem used in a test.
+func DoNothingPuller(dir filesys.ConfirmedDir) Puller {
+	return func(spec *ArtifactSpec) error {
+		spec.Dir = dir
+		return nil
+	}
+}
+
+// DefaultCacheDir returns the directory holding extracted artifacts.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "unable to locate OCI artifact cache")
+	}
+	return filepath.Join(dir, "kustomize", "oci"), nil
+}
+
+// NewHTTPPuller returns a Puller fetching artifacts with the
+// given client. Each artifact is extracted to a directory of
+// cacheDir named by its manifest digest, so artifacts pinned
+// by digest are only fetched once.
+func NewHTTPPuller(client *http.Client, cacheDir string) Puller {
+	return func(spec *ArtifactSpec) error {
+		p := &httpPuller{
+			repoClient: &repoClient{client: client, registry: spec.Registry, repository: spec.Repository},
+			spec:       spec,
+		}
+		if spec.Digest != "" {
+			dir := digestDir(cacheDir, spec.Digest)
+			if _, err := os.Stat(dir); err == nil {
+				spec.Dir = filesys.ConfirmedDir(dir)
+				return nil
+			}
+		}
+		m, digest, err := p.manifest()
+		if err != nil {
+			return err
+		}
+		if spec.Digest != "" && spec.Digest != digest {
+			return errors.Errorf(
+				"OCI artifact %s has digest %s, expected %s", spec.Raw(), digest, spec.Digest)
+		}
+		dir := digestDir(cacheDir, digest)
+		if _, err := os.Stat(dir); err != nil {
+			if err = p.extract(m, cacheDir, dir); err != nil {
+				return err
+			}
+		}
+		spec.Dir = filesys.ConfirmedDir(dir)
+		return nil
+	}
+}
+
+func digestDir(cacheDir, digest string) string {
+	return filepath.Join(cacheDir, strings.Replace(digest, ":", string(filepath.Separator), 1))
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+}
+
+type manifest struct {
+	Layers []descriptor `json:"layers"`
+}
+
+type httpPuller struct {
+	*repoClient
+	spec *ArtifactSpec
+}
+
+// manifest fetches the artifact's manifest, returning its digest.
+func (p *httpPuller) manifest() (*manifest, string, error) {
+	body, _, err := p.get("manifests/"+p.spec.Reference(), manifestMediaTypes)
+	if err != nil {
+		return nil, "", err
+	}
+	var m manifest
+	if err = json.Unmarshal(body, &m); err != nil {
+		return nil, "", errors.WrapPrefixf(err, "invalid manifest for OCI artifact %s", p.spec.Raw())
+	}
+	return &m, sha256Digest(body), nil
+}
+
+// extract unpacks the artifact's layers into a temporary
+// directory of cacheDir, then moves it into place as dir.
+func (p *httpPuller) extract(m *manifest, cacheDir, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return errors.Wrap(err)
+	}
+	tmp, err := os.MkdirTemp(cacheDir, ".pull-")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer os.RemoveAll(tmp)
+	var found bool
+	for _, layer := range m.Layers {
+		if !strings.HasSuffix(layer.MediaType, "tar+gzip") &&
+			!strings.HasSuffix(layer.MediaType, "tar") {
+			continue
+		}
+		found = true
+		blob, _, err := p.get("blobs/"+layer.Digest, "")
+		if err != nil {
+			return err
+		}
+		if sha256Digest(blob) != layer.Digest {
+			return errors.Errorf(
+				"layer %s of OCI artifact %s does not match its digest", layer.Digest, p.spec.Raw())
+		}
+		if err = untar(blob, strings.HasSuffix(layer.MediaType, "+gzip"), tmp); err != nil {
+			return errors.WrapPrefixf(err, "unable to extract OCI artifact %s", p.spec.Raw())
+		}
+	}
+	if !found {
+		return errors.Errorf("OCI artifact %s has no tar layers", p.spec.Raw())
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		// Another build may have cached the same content meanwhile.
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return nil
+		}
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+func sha256Digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// untar extracts the regular files and directories of the
+// archive into dir, refusing entries that would escape it.
+func untar(blob []byte, gzipped bool, dir string) error {
+	var r io.Reader = bytes.NewReader(blob)
+	if gzipped {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(hdr.Name)
+		if filepath.IsAbs(name) || name == ".." ||
+			strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("entry %q is outside the artifact", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err = writeFile(target, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil { //nolint:gosec
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
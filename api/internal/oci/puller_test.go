@@ -0,0 +1,135 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeLayer(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// fakeRegistry serves a single artifact at org/base, requiring
+// an anonymous bearer token, and counts manifest requests.
+type fakeRegistry struct {
+	*httptest.Server
+	manifest  []byte
+	layer     []byte
+	manifests int
+}
+
+func newFakeRegistry(t *testing.T, layer []byte) *fakeRegistry {
+	t.Helper()
+	r := &fakeRegistry{layer: layer}
+	var err error
+	r.manifest, err = json.Marshal(manifest{Layers: []descriptor{{
+		MediaType: "application/vnd.oci.image.layer.v1.tar+gzip",
+		Digest:    sha256Digest(layer),
+	}}})
+	require.NoError(t, err)
+	r.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			assert.Equal(t, "repository:org/base:pull", req.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token":"anonymous"}`))
+			return
+		}
+		if req.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("WWW-Authenticate",
+				`Bearer realm="`+r.URL+`/token",service="test",scope="repository:org/base:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// Any reference resolves to the manifest, as if the
+		// registry did not verify the digests it serves.
+		switch {
+		case strings.HasPrefix(req.URL.Path, "/v2/org/base/manifests/"):
+			r.manifests++
+			_, _ = w.Write(r.manifest)
+		case req.URL.Path == "/v2/org/base/blobs/"+sha256Digest(r.layer):
+			_, _ = w.Write(r.layer)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *fakeRegistry) spec(t *testing.T, ref string) *ArtifactSpec {
+	t.Helper()
+	spec, err := NewArtifactSpecFromURL(
+		"oci://" + strings.TrimPrefix(r.URL, "https://") + "/org/base" + ref)
+	require.NoError(t, err)
+	return spec
+}
+
+func TestHTTPPuller(t *testing.T) {
+	r := newFakeRegistry(t, makeLayer(t, map[string]string{
+		"kustomization.yaml": "resources:\n- cm.yaml\n",
+		"cm.yaml":            "apiVersion: v1\nkind: ConfigMap\n",
+	}))
+	cacheDir := t.TempDir()
+	pull := NewHTTPPuller(r.Client(), cacheDir)
+
+	spec := r.spec(t, ":v1")
+	require.NoError(t, pull(spec))
+	digest := sha256Digest(r.manifest)
+	assert.Equal(t, digestDir(cacheDir, digest), spec.Dir.String())
+	content, err := os.ReadFile(filepath.Join(spec.Dir.String(), "cm.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\n", string(content))
+
+	// An artifact pinned by digest is served from the cache.
+	pinned := r.spec(t, "@"+digest)
+	require.NoError(t, pull(pinned))
+	assert.Equal(t, spec.Dir, pinned.Dir)
+	assert.Equal(t, 1, r.manifests)
+}
+
+func TestHTTPPullerDigestMismatch(t *testing.T) {
+	r := newFakeRegistry(t, makeLayer(t, map[string]string{
+		"kustomization.yaml": "resources: []\n",
+	}))
+	err := NewHTTPPuller(r.Client(), t.TempDir())(r.spec(t, ":v1@"+testDigest))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected "+testDigest)
+}
+
+func TestHTTPPullerRejectsEscapingEntries(t *testing.T) {
+	r := newFakeRegistry(t, makeLayer(t, map[string]string{
+		"../evil.yaml": "apiVersion: v1\n",
+	}))
+	cacheDir := t.TempDir()
+	err := NewHTTPPuller(r.Client(), cacheDir)(r.spec(t, ":v1"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `entry "../evil.yaml" is outside the artifact`)
+	_, err = os.Stat(filepath.Join(filepath.Dir(cacheDir), "evil.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,277 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// Credentials authenticate to a registry, either with
+// a username and password or with an identity token.
+type Credentials struct {
+	Username      string
+	Password      string
+	IdentityToken string
+}
+
+// Keychain returns the credentials for a registry host,
+// or nil to access the registry anonymously.
+type Keychain func(registry string) (*Credentials, error)
+
+// AnonymousKeychain never returns credentials.
+func AnonymousKeychain(string) (*Credentials, error) {
+	return nil, nil
+}
+
+// DockerConfigKeychain reads credentials from the docker config at
+// $DOCKER_CONFIG/config.json, or ~/.docker/config.json by default,
+// using its credential helpers if it names any.
+func DockerConfigKeychain(registry string) (*Credentials, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil //nolint:nilerr
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	return DockerConfigFileKeychain(filepath.Join(dir, "config.json"))(registry)
+}
+
+// DockerConfigFileKeychain reads credentials from the docker config
+// at path; a missing file means anonymous access.
+func DockerConfigFileKeychain(path string) Keychain {
+	return func(registry string) (*Credentials, error) {
+		b, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		var config struct {
+			Auths map[string]struct {
+				Auth          string `json:"auth"`
+				Username      string `json:"username"`
+				Password      string `json:"password"`
+				IdentityToken string `json:"identitytoken"`
+			} `json:"auths"`
+			CredHelpers map[string]string `json:"credHelpers"`
+			CredsStore  string            `json:"credsStore"`
+		}
+		if err = json.Unmarshal(b, &config); err != nil {
+			return nil, errors.WrapPrefixf(err, "invalid docker config %s", path)
+		}
+		keys := configKeys(registry)
+		for _, k := range keys {
+			if helper, found := config.CredHelpers[k]; found {
+				return credentialHelper(helper, k)
+			}
+		}
+		for _, k := range keys {
+			a, found := config.Auths[k]
+			if !found {
+				continue
+			}
+			c := &Credentials{Username: a.Username, Password: a.Password, IdentityToken: a.IdentityToken}
+			if a.Auth != "" {
+				decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+				if err != nil {
+					return nil, errors.WrapPrefixf(err, "invalid auth for %s in docker config %s", k, path)
+				}
+				c.Username, c.Password, _ = strings.Cut(string(decoded), ":")
+			}
+			if c.Username != "" || c.IdentityToken != "" {
+				return c, nil
+			}
+		}
+		if config.CredsStore != "" {
+			return credentialHelper(config.CredsStore, keys[0])
+		}
+		return nil, nil
+	}
+}
+
+// configKeys returns the keys a docker config may use for the registry.
+func configKeys(registry string) []string {
+	if registry == dockerHub || registry == dockerHubRegistry {
+		return []string{"https://index.docker.io/v1/", "index.docker.io", dockerHub}
+	}
+	return []string{registry, "https://" + registry, "http://" + registry}
+}
+
+// credentialHelper runs docker-credential-<helper>, which
+// answers with the credentials of the registry on stdin.
+func credentialHelper(helper, registry string) (*Credentials, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get") //nolint:gosec
+	cmd.Stdin = strings.NewReader(registry)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(string(out), "credentials not found") {
+			return nil, nil
+		}
+		return nil, errors.WrapPrefixf(err, "docker-credential-%s: %s",
+			helper, strings.TrimSpace(stderr.String()+string(out)))
+	}
+	var c struct {
+		Username string
+		Secret   string
+	}
+	if err = json.Unmarshal(out, &c); err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid output of docker-credential-%s", helper)
+	}
+	if c.Username == "<token>" {
+		return &Credentials{IdentityToken: c.Secret}, nil
+	}
+	return &Credentials{Username: c.Username, Password: c.Secret}, nil
+}
+
+// repoClient requests the API endpoints of a repository,
+// authenticating when the registry asks.
+type repoClient struct {
+	client      *http.Client
+	registry    string
+	repository  string
+	credentials *Credentials
+	token       string
+	basic       bool
+}
+
+// get fetches a path below the repository's API endpoint.
+func (c *repoClient) get(path, accept string) ([]byte, http.Header, error) {
+	u := fmt.Sprintf("https://%s/v2/%s/%s", c.registry, c.repository, path)
+	resp, err := c.do(u, accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.token == "" && !c.basic {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err = c.authenticate(challenge); err != nil {
+			return nil, nil, err
+		}
+		if resp, err = c.do(u, accept); err != nil {
+			return nil, nil, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, errors.Errorf("unable to fetch %s: status code %d (%s)",
+			u, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	body, err := io.ReadAll(resp.Body)
+	return body, resp.Header, errors.Wrap(err)
+}
+
+func (c *repoClient) do(u, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.basic:
+		req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+	resp, err := c.client.Do(req)
+	return resp, errors.Wrap(err)
+}
+
+// authenticate answers a challenge of the form
+//
+//	Bearer realm="https://auth.example.com/token",service="registry",scope="repository:org/base:pull"
+//
+// or, given a username and password, a Basic challenge.
+func (c *repoClient) authenticate(challenge string) error {
+	if strings.HasPrefix(challenge, "Basic ") && c.credentials != nil && c.credentials.Username != "" {
+		c.basic = true
+		return nil
+	}
+	params, found := strings.CutPrefix(challenge, "Bearer ")
+	if !found {
+		return errors.Errorf(
+			"registry %s requires unsupported authentication %q", c.registry, challenge)
+	}
+	values := url.Values{}
+	var realm string
+	for _, param := range strings.Split(params, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+		v = strings.Trim(v, `"`)
+		if k == "realm" {
+			realm = v
+		} else {
+			values.Set(k, v)
+		}
+	}
+	if realm == "" {
+		return errors.Errorf("registry %s sent no token realm", c.registry)
+	}
+	token, err := c.fetchToken(realm, values)
+	if err != nil {
+		return err
+	}
+	c.token = token
+	return nil
+}
+
+func (c *repoClient) fetchToken(realm string, values url.Values) (string, error) {
+	var req *http.Request
+	var err error
+	if c.credentials != nil && c.credentials.IdentityToken != "" {
+		// Identity tokens are exchanged with the OAuth2 flow.
+		values.Set("grant_type", "refresh_token")
+		values.Set("refresh_token", c.credentials.IdentityToken)
+		values.Set("client_id", "kustomize")
+		req, err = http.NewRequest(http.MethodPost, realm, strings.NewReader(values.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequest(http.MethodGet, realm+"?"+values.Encode(), nil)
+		if err == nil && c.credentials != nil && c.credentials.Username != "" {
+			req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+		}
+	}
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unable to fetch token from %s: status code %d (%s)",
+			realm, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	var t struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"` //nolint:tagliatelle
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", errors.Wrap(err)
+	}
+	if t.Token != "" {
+		return t.Token, nil
+	}
+	return t.AccessToken, nil
+}
@@ -5,14 +5,18 @@ package builtinconfig
 
 import (
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/yaml"
 )
 
 // loadDefaultConfig returns a TranformerConfig
-// object from a list of files.
+// object from a list of files.  The fields a file
+// overrides replace those of the preceding files,
+// and are overridden by the result.
 func loadDefaultConfig(
 	ldr ifc.Loader, paths []string) (*TransformerConfig, error) {
 	result := &TransformerConfig{}
+	var override []string
 	for _, path := range paths {
 		data, err := ldr.Load(path)
 		if err != nil {
@@ -22,11 +26,13 @@ func loadDefaultConfig(
 		if err != nil {
 			return nil, err
 		}
+		override = append(override, t.Override...)
 		result, err = result.Merge(t)
 		if err != nil {
-			return nil, err
+			return nil, errors.WrapPrefixf(err, "merging config %s", path)
 		}
 	}
+	result.Override = override
 	return result, nil
 }
 
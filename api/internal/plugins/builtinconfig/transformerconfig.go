@@ -27,6 +27,12 @@ type TransformerConfig struct {
 	VarReference      types.FsSlice `json:"varReference,omitempty" yaml:"varReference,omitempty"`
 	Images            types.FsSlice `json:"images,omitempty" yaml:"images,omitempty"`
 	Replicas          types.FsSlice `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	ResourceOverrides types.FsSlice `json:"resourceOverrides,omitempty" yaml:"resourceOverrides,omitempty"`
+	Scheduling        types.FsSlice `json:"scheduling,omitempty" yaml:"scheduling,omitempty"`
+
+	// Override names the fields, e.g. nameReference, whose specs
+	// replace, rather than add to, those of the config merged with.
+	Override []string `json:"override,omitempty" yaml:"override,omitempty"`
 }
 
 // MakeEmptyConfig returns an empty TransformerConfig object
@@ -47,6 +53,9 @@ func (t *TransformerConfig) DeepCopy() *TransformerConfig {
 		VarReference:      t.VarReference.DeepCopy(),
 		Images:            t.Images.DeepCopy(),
 		Replicas:          t.Replicas.DeepCopy(),
+		ResourceOverrides: t.ResourceOverrides.DeepCopy(),
+		Scheduling:        t.Scheduling.DeepCopy(),
+		Override:          append([]string(nil), t.Override...),
 	}
 }
 
@@ -74,7 +83,10 @@ func MakeDefaultConfig() *TransformerConfig {
 }
 
 // MakeTransformerConfig returns a merger of custom config,
-// if any, with default config.
+// if any, with default config.  The fields the custom config
+// overrides replace those of the default config, and stay
+// overridden in the result, so that they also replace those
+// of the config it's merged with.
 func MakeTransformerConfig(
 	ldr ifc.Loader, paths []string) (*TransformerConfig, error) {
 	t1 := MakeDefaultConfig()
@@ -85,7 +97,12 @@ func MakeTransformerConfig(
 	if err != nil {
 		return nil, err
 	}
-	return t1.Merge(t2)
+	merged, err := t1.Merge(t2)
+	if err != nil {
+		return nil, err
+	}
+	merged.Override = t2.Override
+	return merged, nil
 }
 
 // sortFields provides determinism in logging, tests, etc.
@@ -100,6 +117,8 @@ func (t *TransformerConfig) sortFields() {
 	sort.Sort(t.VarReference)
 	sort.Sort(t.Images)
 	sort.Sort(t.Replicas)
+	sort.Sort(t.ResourceOverrides)
+	sort.Sort(t.Scheduling)
 }
 
 // AddPrefixFieldSpec adds a FieldSpec to NamePrefix
@@ -133,13 +152,56 @@ func (t *TransformerConfig) AddNamereferenceFieldSpec(
 	return err
 }
 
+// clear empties the fields of t with the given names, as named
+// in config files.
+func (t *TransformerConfig) clear(names []string) error {
+	for _, name := range names {
+		switch name {
+		case "namePrefix":
+			t.NamePrefix = nil
+		case "nameSuffix":
+			t.NameSuffix = nil
+		case "namespace":
+			t.NameSpace = nil
+		case "commonLabels":
+			t.CommonLabels = nil
+		case "templateLabels":
+			t.TemplateLabels = nil
+		case "commonAnnotations":
+			t.CommonAnnotations = nil
+		case "nameReference":
+			t.NameReference = nil
+		case "varReference":
+			t.VarReference = nil
+		case "images":
+			t.Images = nil
+		case "replicas":
+			t.Replicas = nil
+		case "resourceOverrides":
+			t.ResourceOverrides = nil
+		case "scheduling":
+			t.Scheduling = nil
+		default:
+			return errors.Errorf("cannot override unknown transformer config field %q", name)
+		}
+	}
+	return nil
+}
+
 // Merge merges two TransformerConfigs objects into
-// a new TransformerConfig object
+// a new TransformerConfig object.  The fields input
+// overrides replace those of t.
 func (t *TransformerConfig) Merge(input *TransformerConfig) (
 	merged *TransformerConfig, err error) {
 	if input == nil {
 		return t, nil
 	}
+	if len(input.Override) > 0 {
+		t = t.DeepCopy()
+		if err = t.clear(input.Override); err != nil {
+			return nil, err
+		}
+	}
 	merged = &TransformerConfig{}
 	merged.NamePrefix, err = t.NamePrefix.MergeAll(input.NamePrefix)
 	if err != nil {
@@ -182,6 +244,14 @@ func (t *TransformerConfig) Merge(input *TransformerConfig) (
 	if err != nil {
 		return nil, errors.WrapPrefixf(err, "failed to merge Replicas fieldSpec")
 	}
+	merged.ResourceOverrides, err = t.ResourceOverrides.MergeAll(input.ResourceOverrides)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "failed to merge ResourceOverrides fieldSpec")
+	}
+	merged.Scheduling, err = t.Scheduling.MergeAll(input.Scheduling)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "failed to merge Scheduling fieldSpec")
+	}
 	merged.sortFields()
 	return merged, nil
 }
@@ -174,6 +174,35 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestMergeOverride(t *testing.T) {
+	cfga := &TransformerConfig{}
+	cfga.AddPrefixFieldSpec(types.FieldSpec{Gvk: resid.Gvk{Kind: "KindA"}, Path: "a"})
+	cfga.AddLabelFieldSpec(types.FieldSpec{Gvk: resid.Gvk{Kind: "KindA"}, Path: "a"})
+
+	cfgb := &TransformerConfig{Override: []string{"commonLabels"}}
+	cfgb.AddLabelFieldSpec(types.FieldSpec{Gvk: resid.Gvk{Kind: "KindB"}, Path: "b"})
+
+	actual, err := cfga.Merge(cfgb)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	expected := &TransformerConfig{}
+	expected.AddPrefixFieldSpec(types.FieldSpec{Gvk: resid.Gvk{Kind: "KindA"}, Path: "a"})
+	expected.AddLabelFieldSpec(types.FieldSpec{Gvk: resid.Gvk{Kind: "KindB"}, Path: "b"})
+	if !reflect.DeepEqual(actual.NamePrefix, expected.NamePrefix) ||
+		!reflect.DeepEqual(actual.CommonLabels, expected.CommonLabels) {
+		t.Fatalf("expected: %v\n but got: %v\n", expected, actual)
+	}
+	if len(cfga.CommonLabels) != 1 {
+		t.Fatal("merge mutated the overridden config")
+	}
+
+	_, err = cfga.Merge(&TransformerConfig{Override: []string{"labels"}})
+	if err == nil || err.Error() != `cannot override unknown transformer config field "labels"` {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
 func TestMakeDefaultConfig_mutation(t *testing.T) {
 	a := MakeDefaultConfig()
 
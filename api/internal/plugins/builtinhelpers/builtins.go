@@ -31,6 +31,9 @@ const (
 	ValueAddTransformer
 	HelmChartInflationGenerator
 	ReplacementTransformer
+	ResourceOverrideTransformer
+	SchedulingTransformer
+	NetworkPolicyGenerator
 )
 
 var stringToBuiltinPluginTypeMap map[string]BuiltinPluginType
@@ -63,6 +66,7 @@ var GeneratorFactories = map[BuiltinPluginType]func() resmap.GeneratorPlugin{
 	IAMPolicyGenerator:          builtins.NewIAMPolicyGeneratorPlugin,
 	SecretGenerator:             builtins.NewSecretGeneratorPlugin,
 	HelmChartInflationGenerator: builtins.NewHelmChartInflationGeneratorPlugin,
+	NetworkPolicyGenerator:      builtins.NewNetworkPolicyGeneratorPlugin,
 }
 
 type MultiTransformer struct {
@@ -108,6 +112,8 @@ var TransformerFactories = map[BuiltinPluginType]func() resmap.TransformerPlugin
 	SuffixTransformer:              builtins.NewSuffixTransformerPlugin,
 	ReplacementTransformer:         builtins.NewReplacementTransformerPlugin,
 	ReplicaCountTransformer:        builtins.NewReplicaCountTransformerPlugin,
+	ResourceOverrideTransformer:    builtins.NewResourceOverrideTransformerPlugin,
+	SchedulingTransformer:          builtins.NewSchedulingTransformerPlugin,
 	ValueAddTransformer:            builtins.NewValueAddTransformerPlugin,
 	// Do not wired SortOrderTransformer as a builtin plugin.
 	// We only want it to be available in the top-level kustomization.
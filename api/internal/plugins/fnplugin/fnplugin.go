@@ -6,9 +6,12 @@ package fnplugin
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 
+	"sigs.k8s.io/kustomize/api/internal/oci"
 	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
@@ -29,6 +32,13 @@ type FnPlugin struct {
 	// Plugin name cache for error output
 	pluginName string
 
+	// Kind and name of the function config, identifying the
+	// function in its results.
+	fnName string
+
+	// Receives the results of the function, if set.
+	resultsSink func(function string, results []byte)
+
 	// PluginHelpers
 	h *resmap.PluginHelpers
 }
@@ -71,19 +81,56 @@ func toStorageMounts(mounts []string) []runtimeutil.StorageMount {
 	return sms
 }
 
+// wasmImageLoader returns a loader pulling the OCI artifact of a wasm
+// function image with puller, which returns the path of the module: the
+// path given in the image reference, or else the one .wasm file of the
+// artifact.
+func wasmImageLoader(puller oci.Puller) func(image string) (string, error) {
+	return func(image string) (string, error) {
+		spec, err := oci.NewArtifactSpecFromURL(image)
+		if err != nil {
+			return "", err
+		}
+		if err = puller(spec); err != nil {
+			return "", err
+		}
+		if spec.KustRootPath != "" {
+			return spec.AbsPath(), nil
+		}
+		modules, err := filepath.Glob(spec.Dir.Join("*.wasm"))
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		if len(modules) != 1 {
+			return "", errors.Errorf(
+				"OCI artifact %s must hold one .wasm module, or its path must be given, found %d",
+				image, len(modules))
+		}
+		return modules[0], nil
+	}
+}
+
 // NewFnPlugin creates a FnPlugin struct
 func NewFnPlugin(o *types.FnPluginLoadingOptions) *FnPlugin {
 	return &FnPlugin{
 		runFns: runfn.RunFns{
-			Functions:      []*yaml.RNode{},
-			Network:        o.Network,
-			EnableStarlark: o.EnableStar,
-			EnableExec:     o.EnableExec,
-			StorageMounts:  toStorageMounts(o.Mounts),
-			Env:            o.Env,
-			AsCurrentUser:  o.AsCurrentUser,
-			WorkingDir:     o.WorkingDir,
+			Functions:        []*yaml.RNode{},
+			Network:          o.Network,
+			EnableStarlark:   o.EnableStar,
+			EnableExec:       o.EnableExec,
+			EnableWasm:       o.EnableWasm,
+			WasmImageLoader:  wasmImageLoader(oci.PullerUsingHTTP),
+			ContainerRuntime: o.ContainerRuntime,
+			VerifyImage:      o.VerifyImage,
+			StorageMounts:    toStorageMounts(o.Mounts),
+			Env:              o.Env,
+			AsCurrentUser:    o.AsCurrentUser,
+			WorkingDir:       o.WorkingDir,
+			// starlark scripts and wasm modules are relative to the
+			// kustomization rather than to the current directory
+			Path: o.WorkingDir,
 		},
+		resultsSink: o.ResultsSink,
 	}
 }
 
@@ -109,6 +156,7 @@ func (p *FnPlugin) Config(h *resmap.PluginHelpers, config []byte) error {
 
 	p.pluginName = fmt.Sprintf("api: %s, kind: %s, name: %s",
 		meta.APIVersion, meta.Kind, meta.Name)
+	p.fnName = meta.Kind + "/" + meta.Name
 
 	return nil
 }
@@ -192,6 +240,17 @@ func (p *FnPlugin) invokePlugin(input []byte) ([]byte, error) {
 	p.runFns.Functions = append(p.runFns.Functions, functionConfig)
 	p.runFns.Output = &ouputBuffer
 
+	if p.resultsSink != nil {
+		// The runner only writes results to files.
+		dir, err := os.MkdirTemp("", "kustomize-fn-results-")
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		defer os.RemoveAll(dir)
+		p.runFns.ResultsDir = dir
+		defer p.sendResults(dir)
+	}
+
 	err = p.runFns.Execute()
 	if err != nil {
 		return nil, errors.WrapPrefixf(
@@ -200,3 +259,15 @@ func (p *FnPlugin) invokePlugin(input []byte) ([]byte, error) {
 
 	return ouputBuffer.Bytes(), nil
 }
+
+// sendResults passes the results files the function runner wrote
+// in dir, if any, to the results sink.
+func (p *FnPlugin) sendResults(dir string) {
+	files, _ := filepath.Glob(filepath.Join(dir, "results-*.yaml"))
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err == nil && len(b) > 0 {
+			p.resultsSink(p.fnName, b)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fnplugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/oci"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestWasmImageLoader(t *testing.T) {
+	dir := t.TempDir()
+	load := wasmImageLoader(oci.DoNothingPuller(filesys.ConfirmedDir(dir)))
+
+	_, err := load("oci://registry.example.com/fn:v1")
+	assert.ErrorContains(t, err, "must hold one .wasm module, or its path must be given, found 0")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fn.wasm"), nil, 0600))
+	p, err := load("oci://registry.example.com/fn:v1")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "fn.wasm"), p)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.wasm"), nil, 0600))
+	_, err = load("oci://registry.example.com/fn:v1")
+	assert.ErrorContains(t, err, "found 2")
+
+	p, err = load("oci://registry.example.com/fn:v1//other.wasm")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "other.wasm"), p)
+
+	_, err = load("registry.example.com/fn:v1")
+	assert.ErrorContains(t, err, `must start with "oci://"`)
+}
@@ -46,10 +46,12 @@ func NewLoader(
 // NOTE: This is not really a new loader since some of the Loader struct fields are pointers.
 func (l *Loader) LoaderWithWorkingDir(wd string) *Loader {
 	lpc := &types.PluginConfig{
-		PluginRestrictions: l.pc.PluginRestrictions,
-		BpLoadingOptions:   l.pc.BpLoadingOptions,
-		FnpLoadingOptions:  l.pc.FnpLoadingOptions,
-		HelmConfig:         l.pc.HelmConfig,
+		PluginRestrictions:    l.pc.PluginRestrictions,
+		BpLoadingOptions:      l.pc.BpLoadingOptions,
+		FnpLoadingOptions:     l.pc.FnpLoadingOptions,
+		HelmConfig:            l.pc.HelmConfig,
+		SopsConfig:            l.pc.SopsConfig,
+		SecretProvidersConfig: l.pc.SecretProvidersConfig,
 	}
 	lpc.FnpLoadingOptions.WorkingDir = wd
 	return &Loader{pc: lpc, rf: l.rf, fs: l.fs}
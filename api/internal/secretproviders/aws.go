@@ -0,0 +1,237 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package secretproviders
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+const (
+	awsService   = "secretsmanager"
+	awsTarget    = "secretsmanager.GetSecretValue"
+	awsAlgorithm = "AWS4-HMAC-SHA256"
+	awsTimestamp = "20060102T150405Z"
+)
+
+// AWSCredentials are the access keys of an AWS account.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSProvider reads secrets from AWS Secrets Manager.
+type AWSProvider struct {
+	Client *http.Client
+
+	// Region of the secrets, unless given by their arn.
+	Region string
+
+	// Endpoint, if set, replaces the regional endpoint
+	// https://secretsmanager.<region>.amazonaws.com.
+	Endpoint string
+
+	// Credentials to sign requests with.
+	Credentials AWSCredentials
+
+	now func() time.Time
+}
+
+// NewAWSProvider returns a provider configured as the aws command
+// line is, with AWS_REGION, AWS_ENDPOINT_URL_SECRETS_MANAGER and the
+// credentials of AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN or else of the AWS_PROFILE profile of the
+// shared credentials file ~/.aws/credentials.
+func NewAWSProvider() *AWSProvider {
+	p := &AWSProvider{
+		Region:   firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		Endpoint: firstEnv("AWS_ENDPOINT_URL_SECRETS_MANAGER", "AWS_ENDPOINT_URL"),
+		Credentials: AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+	}
+	if p.Credentials.AccessKeyID == "" {
+		p.Credentials = sharedAWSCredentials()
+	}
+	return p
+}
+
+// GetSecret reads the current version of the secret with the name
+// or arn path. The key selects a field of a secret holding a JSON
+// object; without it, the whole secret is returned.
+func (p *AWSProvider) GetSecret(path, key string) ([]byte, error) {
+	if p.Credentials.AccessKeyID == "" || p.Credentials.SecretAccessKey == "" {
+		return nil, errors.Errorf(
+			"no aws credentials, set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or AWS_PROFILE")
+	}
+	region := p.Region
+	// arn:aws:secretsmanager:<region>:<account>:secret:<name>
+	if parts := strings.Split(path, ":"); len(parts) > 3 && parts[0] == "arn" {
+		region = parts[3]
+	}
+	if region == "" {
+		return nil, errors.Errorf("no aws region, set AWS_REGION")
+	}
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + awsService + "." + region + ".amazonaws.com"
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", awsTarget)
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+	signAWSRequest(req, body, p.Credentials, region, awsService, now())
+	var secret struct {
+		SecretString string
+		SecretBinary string
+	}
+	if err = do(p.Client, req, &secret); err != nil {
+		return nil, errors.WrapPrefixf(err, "unable to read %s", path)
+	}
+	value := []byte(secret.SecretString)
+	if secret.SecretBinary != "" {
+		if value, err = base64.StdEncoding.DecodeString(secret.SecretBinary); err != nil {
+			return nil, errors.WrapPrefixf(err, "invalid binary value of %s", path)
+		}
+	}
+	return selectJSONKey(value, path, key)
+}
+
+// signAWSRequest signs the request with version 4 of the AWS
+// signature, see https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+func signAWSRequest(req *http.Request, body []byte, c AWSCredentials, region, service string, t time.Time) {
+	timestamp := t.UTC().Format(awsTimestamp)
+	req.Header.Set("X-Amz-Date", timestamp)
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+	scope := timestamp[:8] + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		awsAlgorithm, timestamp, scope, hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+	key := []byte("AWS4" + c.SecretAccessKey)
+	for _, s := range []string{timestamp[:8], region, service, "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	req.Header.Set("Authorization", awsAlgorithm+" Credential="+c.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+hex.EncodeToString(hmacSHA256(key, stringToSign)))
+}
+
+func hexSHA256(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+// sharedAWSCredentials reads the credentials of the AWS_PROFILE
+// profile, or the default one, from the shared credentials file.
+func sharedAWSCredentials() AWSCredentials {
+	var c AWSCredentials
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return c
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return c
+	}
+	defer f.Close()
+	var section string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		k, v, found := strings.Cut(line, "=")
+		if !found || section != profile {
+			continue
+		}
+		switch strings.TrimSpace(k) {
+		case "aws_access_key_id":
+			c.AccessKeyID = strings.TrimSpace(v)
+		case "aws_secret_access_key":
+			c.SecretAccessKey = strings.TrimSpace(v)
+		case "aws_session_token":
+			c.SessionToken = strings.TrimSpace(v)
+		}
+	}
+	return c
+}
+
+// firstEnv returns the first of the environment variables that is set.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
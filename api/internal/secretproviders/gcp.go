@@ -0,0 +1,90 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package secretproviders
+
+import (
+	"bytes"
+	"encoding/base64"
+	"hash/crc32"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+const defaultGCPEndpoint = "https://secretmanager.googleapis.com"
+
+// GCPProvider reads secrets from GCP Secret Manager.
+type GCPProvider struct {
+	Client *http.Client
+
+	// Endpoint, if set, replaces https://secretmanager.googleapis.com.
+	Endpoint string
+
+	// Token is the OAuth2 access token to authenticate with. If
+	// empty, it is printed by gcloud auth print-access-token.
+	Token string
+}
+
+// NewGCPProvider returns a provider authenticating with the
+// token of GOOGLE_OAUTH_ACCESS_TOKEN, or else that of gcloud.
+func NewGCPProvider() *GCPProvider {
+	return &GCPProvider{
+		Endpoint: os.Getenv("CLOUDSDK_API_ENDPOINT_OVERRIDES_SECRETMANAGER"),
+		Token:    os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+	}
+}
+
+// GetSecret reads the secret version at path, e.g.
+// projects/p/secrets/s/versions/3, or the latest version given
+// projects/p/secrets/s. The key selects a field of a secret
+// holding a JSON object; without it, the whole secret is returned.
+func (p *GCPProvider) GetSecret(path, key string) ([]byte, error) {
+	path = strings.Trim(path, "/")
+	if !strings.Contains(path, "/versions/") {
+		path += "/versions/latest"
+	}
+	token := p.Token
+	if token == "" {
+		out, err := exec.Command("gcloud", "auth", "print-access-token").Output()
+		if err != nil {
+			return nil, errors.WrapPrefixf(err,
+				"no gcp access token, set GOOGLE_OAUTH_ACCESS_TOKEN or log in with gcloud")
+		}
+		token = string(bytes.TrimSpace(out))
+	}
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = defaultGCPEndpoint
+	}
+	endpoint = strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/v1")
+	req, err := http.NewRequest(http.MethodGet, endpoint+"/v1/"+path+":access", nil)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var version struct {
+		Payload struct {
+			Data       string `json:"data"`
+			DataCrc32c string `json:"dataCrc32c"`
+		} `json:"payload"`
+	}
+	if err = do(p.Client, req, &version); err != nil {
+		return nil, errors.WrapPrefixf(err, "unable to read %s", path)
+	}
+	value, err := base64.StdEncoding.DecodeString(version.Payload.Data)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid value of %s", path)
+	}
+	if version.Payload.DataCrc32c != "" {
+		sum := crc32.Checksum(value, crc32.MakeTable(crc32.Castagnoli))
+		if version.Payload.DataCrc32c != strconv.FormatUint(uint64(sum), 10) {
+			return nil, errors.Errorf("value of %s does not match its checksum", path)
+		}
+	}
+	return selectJSONKey(value, path, key)
+}
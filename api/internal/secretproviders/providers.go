@@ -0,0 +1,133 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package secretproviders pulls the values of secrets from external
+// secret providers: HashiCorp Vault, AWS Secrets Manager and GCP
+// Secret Manager. The builtin providers authenticate with the
+// credentials their command line tools use, found in the environment.
+package secretproviders
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// Names of the builtin providers.
+const (
+	Vault = "vault"
+	AWS   = "aws"
+	GCP   = "gcp"
+)
+
+// GetValue returns the value located by v, using the configured
+// provider of its name or else the builtin one.
+func GetValue(c types.SecretProvidersConfig, v *types.SecretValueFrom) ([]byte, error) {
+	if v.Path == "" {
+		return nil, errors.Errorf("secret provider %s: path is required", v.Provider)
+	}
+	p, err := provider(c, v.Provider)
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.GetSecret(v.Path, v.Key)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "secret provider %s", v.Provider)
+	}
+	return value, nil
+}
+
+func provider(c types.SecretProvidersConfig, name string) (types.SecretProvider, error) {
+	if p, found := c.Providers[name]; found {
+		return p, nil
+	}
+	switch name {
+	case Vault:
+		return NewVaultProvider(), nil
+	case AWS:
+		return NewAWSProvider(), nil
+	case GCP:
+		return NewGCPProvider(), nil
+	case "":
+		return nil, errors.Errorf("secret value must specify a provider")
+	default:
+		return nil, errors.Errorf("unknown secret provider %q", name)
+	}
+}
+
+// selectKey returns the value of the key in fields, which may
+// be omitted if there is only one.
+func selectKey(fields map[string]interface{}, path, key string) ([]byte, error) {
+	if key == "" {
+		if len(fields) != 1 {
+			keys := make([]string, 0, len(fields))
+			for k := range fields {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return nil, errors.Errorf(
+				"secret %s has keys %s, specify one with key", path, strings.Join(keys, ", "))
+		}
+		for k := range fields {
+			key = k
+		}
+	}
+	v, found := fields[key]
+	if !found {
+		return nil, errors.Errorf("secret %s has no key %s", path, key)
+	}
+	if s, ok := v.(string); ok {
+		return []byte(s), nil
+	}
+	b, err := json.Marshal(v)
+	return b, errors.Wrap(err)
+}
+
+// selectJSONKey returns value, or the value of the key
+// of value parsed as a JSON object if key is set.
+func selectJSONKey(value []byte, path, key string) ([]byte, error) {
+	if key == "" {
+		return value, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return nil, errors.Errorf("secret %s is not a JSON object, its key %s cannot be selected", path, key)
+	}
+	return selectKey(fields, path, key)
+}
+
+// do sends the request, decoding the JSON response into out.
+func do(client *http.Client, req *http.Request, out interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Errors  []string `json:"errors"`
+			Message string   `json:"message"`
+			Error   struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		message := fmt.Sprintf("unable to get secret: status code %d (%s)",
+			resp.StatusCode, http.StatusText(resp.StatusCode))
+		for _, m := range append(body.Errors, body.Message, body.Error.Message) {
+			if m != "" {
+				message += " " + m
+			}
+		}
+		return errors.Errorf("%s", message)
+	}
+	return errors.Wrap(json.NewDecoder(resp.Body).Decode(out))
+}
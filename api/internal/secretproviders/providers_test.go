@@ -0,0 +1,218 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package secretproviders
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+type fakeProvider map[string]string
+
+func (p fakeProvider) GetSecret(path, key string) ([]byte, error) {
+	v, found := p[path+"#"+key]
+	if !found {
+		return nil, fmt.Errorf("no secret %s", path)
+	}
+	return []byte(v), nil
+}
+
+func TestGetValue(t *testing.T) {
+	c := types.SecretProvidersConfig{
+		Enabled:   true,
+		Providers: map[string]types.SecretProvider{"fake": fakeProvider{"app#password": "hunter2"}},
+	}
+	value, err := GetValue(c, &types.SecretValueFrom{Provider: "fake", Path: "app", Key: "password"})
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(value))
+
+	_, err = GetValue(c, &types.SecretValueFrom{Provider: "fake", Path: "db", Key: "password"})
+	require.EqualError(t, err, "secret provider fake: no secret db")
+	_, err = GetValue(c, &types.SecretValueFrom{Provider: "keepass", Path: "app"})
+	require.EqualError(t, err, `unknown secret provider "keepass"`)
+	_, err = GetValue(c, &types.SecretValueFrom{Path: "app"})
+	require.EqualError(t, err, "secret value must specify a provider")
+	_, err = GetValue(c, &types.SecretValueFrom{Provider: Vault})
+	require.EqualError(t, err, "secret provider vault: path is required")
+}
+
+func TestSelectKey(t *testing.T) {
+	fields := map[string]interface{}{"user": "admin", "port": 5432.0}
+	value, err := selectKey(fields, "db", "user")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", string(value))
+	value, err = selectKey(fields, "db", "port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", string(value))
+	_, err = selectKey(fields, "db", "")
+	require.EqualError(t, err, "secret db has keys port, user, specify one with key")
+	_, err = selectKey(fields, "db", "password")
+	require.EqualError(t, err, "secret db has no key password")
+
+	value, err = selectKey(map[string]interface{}{"password": "hunter2"}, "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(value))
+}
+
+func TestVaultProvider(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		assert.Equal(t, "team", req.Header.Get("X-Vault-Namespace"))
+		switch req.URL.Path {
+		case "/v1/secret/data/app":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2"},"metadata":{"version":3}}}`))
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data":{"password":"hunter3"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer s.Close()
+	p := &VaultProvider{Client: s.Client(), Address: s.URL, Token: "s.token", Namespace: "team"}
+
+	value, err := p.GetSecret("secret/data/app", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(value))
+	value, err = p.GetSecret("kv/app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter3", string(value))
+
+	_, err = p.GetSecret("secret/data/db", "password")
+	require.EqualError(t, err,
+		"unable to read secret/data/db: unable to get secret: status code 404 (Not Found)")
+	p.Token = "s.other"
+	_, err = p.GetSecret("secret/data/app", "password")
+	require.EqualError(t, err,
+		"unable to read secret/data/app: unable to get secret: status code 403 (Forbidden) permission denied")
+	p.Token = ""
+	_, err = p.GetSecret("secret/data/app", "password")
+	require.EqualError(t, err, "no vault token, set VAULT_TOKEN or log in with vault login")
+}
+
+// TestSignAWSRequest checks the example of the AWS documentation.
+func TestSignAWSRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet,
+		"https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	now, err := time.Parse(awsTimestamp, "20150830T123600Z")
+	require.NoError(t, err)
+	signAWSRequest(req, nil, AWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, "us-east-1", "iam", now)
+	assert.Equal(t, "AWS4-HMAC-SHA256 "+
+		"Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		req.Header.Get("Authorization"))
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+}
+
+func TestAWSProvider(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, awsTarget, req.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "session", req.Header.Get("X-Amz-Security-Token"))
+		assert.True(t, strings.HasPrefix(req.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKID/20230102/eu-west-1/secretsmanager/aws4_request, "+
+				"SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, "))
+		var body struct{ SecretId string } //nolint:revive,stylecheck
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		switch body.SecretId {
+		case "prod/db", "arn:aws:secretsmanager:eu-west-1:123456789012:secret:prod/db":
+			_, _ = w.Write([]byte(`{"SecretString":"{\"user\":\"admin\",\"password\":\"hunter2\"}"}`))
+		case "prod/cert":
+			_, _ = w.Write([]byte(`{"SecretBinary":"AAEC"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer s.Close()
+	p := &AWSProvider{
+		Client:      s.Client(),
+		Region:      "eu-west-1",
+		Endpoint:    s.URL,
+		Credentials: AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"},
+		now:         func() time.Time { return time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+
+	value, err := p.GetSecret("prod/db", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(value))
+	value, err = p.GetSecret("prod/db", "")
+	require.NoError(t, err)
+	assert.Equal(t, `{"user":"admin","password":"hunter2"}`, string(value))
+	p.Region = ""
+	value, err = p.GetSecret("arn:aws:secretsmanager:eu-west-1:123456789012:secret:prod/db", "user")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", string(value))
+	_, err = p.GetSecret("prod/db", "user")
+	require.EqualError(t, err, "no aws region, set AWS_REGION")
+	p.Region = "eu-west-1"
+	value, err = p.GetSecret("prod/cert", "")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2}, value)
+
+	_, err = p.GetSecret("prod/cert", "password")
+	require.EqualError(t, err, "secret prod/cert is not a JSON object, its key password cannot be selected")
+	_, err = p.GetSecret("prod/app", "")
+	require.EqualError(t, err, "unable to read prod/app: unable to get secret: "+
+		"status code 400 (Bad Request) Secrets Manager can't find the specified secret.")
+}
+
+func TestGCPProvider(t *testing.T) {
+	payload := func(data string, sum uint32) string {
+		return fmt.Sprintf(`{"payload":{"data":%q,"dataCrc32c":"%d"}}`,
+			data, sum)
+	}
+	value := []byte(`{"password":"hunter2"}`)
+	sum := crc32.Checksum(value, crc32.MakeTable(crc32.Castagnoli))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer ya29.token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch req.URL.Path {
+		case "/v1/projects/p/secrets/app/versions/latest:access":
+			_, _ = io.WriteString(w, payload("eyJwYXNzd29yZCI6Imh1bnRlcjIifQ==", sum))
+		case "/v1/projects/p/secrets/app/versions/1:access":
+			_, _ = io.WriteString(w, payload("eyJwYXNzd29yZCI6Imh1bnRlcjIifQ==", sum+1))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"Secret [projects/p/secrets/db] not found."}}`))
+		}
+	}))
+	defer s.Close()
+	p := &GCPProvider{Client: s.Client(), Endpoint: s.URL, Token: "ya29.token"}
+
+	v, err := p.GetSecret("projects/p/secrets/app", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(v))
+	v, err = p.GetSecret("projects/p/secrets/app/versions/latest", "")
+	require.NoError(t, err)
+	assert.Equal(t, value, v)
+
+	_, err = p.GetSecret("projects/p/secrets/app/versions/1", "password")
+	require.EqualError(t, err, "value of projects/p/secrets/app/versions/1 does not match its checksum")
+	_, err = p.GetSecret("projects/p/secrets/db", "")
+	require.EqualError(t, err, "unable to read projects/p/secrets/db/versions/latest: "+
+		"unable to get secret: status code 404 (Not Found) Secret [projects/p/secrets/db] not found.")
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package secretproviders
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+const defaultVaultAddress = "https://127.0.0.1:8200"
+
+// VaultProvider reads secrets from the KV secrets
+// engine of HashiCorp Vault, at either version.
+type VaultProvider struct {
+	Client *http.Client
+
+	// Address of the server, e.g. https://vault.example.com:8200.
+	Address string
+
+	// Token to authenticate with.
+	Token string
+
+	// Namespace of the secrets, for Vault Enterprise.
+	Namespace string
+}
+
+// NewVaultProvider returns a provider configured as the vault
+// command line is, with VAULT_ADDR, VAULT_NAMESPACE and
+// VAULT_TOKEN or else the token helper file ~/.vault-token.
+func NewVaultProvider() *VaultProvider {
+	p := &VaultProvider{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+	}
+	if p.Address == "" {
+		p.Address = defaultVaultAddress
+	}
+	if p.Token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			b, _ := os.ReadFile(filepath.Join(home, ".vault-token"))
+			p.Token = strings.TrimSpace(string(b))
+		}
+	}
+	return p
+}
+
+// GetSecret reads the secret at path, e.g. secret/data/app for
+// version 2 of the KV engine, returning the value of its key.
+func (p *VaultProvider) GetSecret(path, key string) ([]byte, error) {
+	if p.Token == "" {
+		return nil, errors.Errorf("no vault token, set VAULT_TOKEN or log in with vault login")
+	}
+	req, err := http.NewRequest(http.MethodGet,
+		strings.TrimSuffix(p.Address, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err = do(p.Client, req, &secret); err != nil {
+		return nil, errors.WrapPrefixf(err, "unable to read %s", path)
+	}
+	fields := secret.Data
+	// Version 2 of the KV engine nests the fields of the
+	// secret in data, next to its metadata.
+	if data, ok := fields["data"].(map[string]interface{}); ok {
+		if _, ok = fields["metadata"]; ok && len(fields) == 2 {
+			fields = data
+		}
+	}
+	return selectKey(fields, path, key)
+}
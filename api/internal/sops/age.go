@@ -0,0 +1,68 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sops
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// ageIdentities reads the age identities from the environment as SOPS
+// does: from $SOPS_AGE_KEY, from the file $SOPS_AGE_KEY_FILE, and
+// from sops/age/keys.txt in the user's config directory.
+func ageIdentities() ([]age.Identity, error) {
+	var identities []age.Identity
+	add := func(content, source string) error {
+		ids, err := age.ParseIdentities(strings.NewReader(content))
+		if err != nil {
+			return errors.WrapPrefixf(err, "invalid age identities in %s", source)
+		}
+		identities = append(identities, ids...)
+		return nil
+	}
+	if keys := os.Getenv("SOPS_AGE_KEY"); keys != "" {
+		if err := add(keys, "$SOPS_AGE_KEY"); err != nil {
+			return nil, err
+		}
+	}
+	var paths []string
+	if path := os.Getenv("SOPS_AGE_KEY_FILE"); path != "" {
+		paths = append(paths, path)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "sops", "age", "keys.txt"))
+	}
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if err = add(string(b), path); err != nil {
+			return nil, err
+		}
+	}
+	return identities, nil
+}
+
+// ageDecrypt decrypts an armored age file with one of the identities.
+func ageDecrypt(armored string, identities []age.Identity) ([]byte, error) {
+	if len(identities) == 0 {
+		return nil, errors.Errorf("no age identities found")
+	}
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(strings.TrimSpace(armored))), identities...)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	b, err := io.ReadAll(r)
+	return b, errors.Wrap(err)
+}
@@ -0,0 +1,472 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sops decrypts files encrypted with SOPS
+// (https://github.com/getsops/sops), so that secrets can be
+// generated from them without running the sops binary.
+package sops
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Format is the format of an encrypted file, which SOPS
+// infers from the file extension.
+type Format int
+
+const (
+	// Binary files are stored as JSON with their content in a data field.
+	Binary Format = iota
+	YAML
+	JSON
+	Dotenv
+)
+
+const metadataKey = "sops"
+
+// masterKeyTypes are the master key types of SOPS metadata,
+// with the metadata field identifying their keys.
+var masterKeyTypes = []struct{ name, id string }{
+	{"age", "recipient"},
+	{"pgp", "fp"},
+	{"kms", "arn"},
+	{"gcp_kms", "resource_id"},
+	{"azure_kv", "vault_url"},
+	{"hc_vault", "vault_address"},
+}
+
+var encryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.*),tag:(.*),type:(.*)\]$`)
+
+// FormatForPath returns the format of the file at path.
+func FormatForPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAML
+	case ".json":
+		return JSON
+	case ".env":
+		return Dotenv
+	default:
+		return Binary
+	}
+}
+
+// IsEncrypted returns true if the content is a SOPS-encrypted file.
+func IsEncrypted(content []byte, format Format) bool {
+	if format == Dotenv {
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(line, metadataKey+"_mac=") {
+				return true
+			}
+		}
+		return false
+	}
+	if !strings.Contains(string(content), metadataKey) {
+		return false
+	}
+	node, err := yaml.Parse(string(content))
+	if err != nil || node.YNode().Kind != yaml.MappingNode {
+		return false
+	}
+	md := node.Field(metadataKey)
+	return md != nil && md.Value.Field("mac") != nil
+}
+
+// Decrypt decrypts SOPS-encrypted content. The data key of the content
+// is decrypted by the key service if there is one, and otherwise with
+// the age identities of the environment.
+func Decrypt(content []byte, format Format, keyService types.SopsKeyService) ([]byte, error) {
+	if format == Dotenv {
+		return decryptDotenv(content, keyService)
+	}
+	node, err := yaml.Parse(string(content))
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid SOPS file")
+	}
+	md := node.Field(metadataKey)
+	if md == nil {
+		return nil, errors.Errorf("SOPS metadata not found")
+	}
+	m, err := md.Value.Map()
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid SOPS metadata")
+	}
+	d, err := newDecrypter(m, keyService)
+	if err != nil {
+		return nil, err
+	}
+	if err = node.PipeE(yaml.Clear(metadataKey)); err != nil {
+		return nil, err
+	}
+	if err = d.walk(node.YNode(), nil); err != nil {
+		return nil, err
+	}
+	if err = d.verify(); err != nil {
+		return nil, err
+	}
+	switch format {
+	case Binary:
+		data := node.Field("data")
+		if data == nil {
+			return nil, errors.Errorf("SOPS binary file has no data")
+		}
+		return []byte(data.Value.YNode().Value), nil
+	case JSON:
+		b, err := node.MarshalJSON()
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		var v interface{}
+		if err = json.Unmarshal(b, &v); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		b, err = json.MarshalIndent(v, "", "  ")
+		return append(b, '\n'), errors.Wrap(err)
+	default:
+		s, err := node.String()
+		return []byte(s), errors.Wrap(err)
+	}
+}
+
+// decrypter decrypts the values of a file with its data key,
+// hashing them for the verification of the file's mac.
+type decrypter struct {
+	key              []byte
+	hash             hash.Hash
+	mac              string
+	lastModified     string
+	macOnlyEncrypted bool
+}
+
+func newDecrypter(md map[string]interface{}, keyService types.SopsKeyService) (*decrypter, error) {
+	if groups, ok := md["key_groups"].([]interface{}); ok && len(groups) > 0 {
+		return nil, errors.Errorf("SOPS key groups are not supported")
+	}
+	var d decrypter
+	d.hash = sha512.New()
+	d.mac, _ = md["mac"].(string)
+	d.macOnlyEncrypted = fmt.Sprint(md["mac_only_encrypted"]) == "true"
+	switch t := md["lastmodified"].(type) {
+	case time.Time:
+		d.lastModified = t.Format(time.RFC3339)
+	case string:
+		lastModified, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "invalid SOPS lastmodified")
+		}
+		d.lastModified = lastModified.Format(time.RFC3339)
+	}
+	var err error
+	d.key, err = dataKey(masterKeys(md), keyService)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// masterKeys lists the master keys the data key is encrypted with.
+func masterKeys(md map[string]interface{}) []types.SopsMasterKey {
+	var keys []types.SopsMasterKey
+	for _, t := range masterKeyTypes {
+		entries, _ := md[t.name].([]interface{})
+		for _, e := range entries {
+			fields, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			k := types.SopsMasterKey{Type: t.name, Metadata: map[string]string{}}
+			for name, v := range fields {
+				s, ok := v.(string)
+				switch {
+				case !ok:
+				case name == "enc":
+					k.EncryptedKey = s
+				default:
+					k.Metadata[name] = s
+				}
+			}
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// dataKey decrypts the data key with the first master key that can.
+func dataKey(keys []types.SopsMasterKey, keyService types.SopsKeyService) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, errors.Errorf("SOPS metadata has no master keys")
+	}
+	var identities []age.Identity
+	var identitiesErr error
+	identitiesLoaded := false
+	var failures []string
+	for _, k := range keys {
+		var key []byte
+		var err error
+		switch {
+		case keyService != nil:
+			key, err = keyService.Decrypt(k)
+		case k.Type == "age":
+			if !identitiesLoaded {
+				identities, identitiesErr = ageIdentities()
+				identitiesLoaded = true
+			}
+			if err = identitiesErr; err == nil {
+				key, err = ageDecrypt(k.EncryptedKey, identities)
+			}
+		default:
+			err = errors.Errorf("decrypting %s keys requires a key service", k.Type)
+		}
+		if err == nil {
+			return key, nil
+		}
+		failures = append(failures, fmt.Sprintf("%s key %s: %v", k.Type, keyID(k), err))
+	}
+	return nil, errors.Errorf(
+		"unable to decrypt the SOPS data key:\n  %s", strings.Join(failures, "\n  "))
+}
+
+func keyID(k types.SopsMasterKey) string {
+	for _, t := range masterKeyTypes {
+		if t.name == k.Type {
+			return k.Metadata[t.id]
+		}
+	}
+	return ""
+}
+
+// walk decrypts the scalar values below the node. SOPS authenticates
+// each value with the path of mapping keys leading to it.
+func (d *decrypter) walk(node *yaml.Node, path []string) error {
+	removeEncryptedComments(node)
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, n := range node.Content {
+			if err := d.walk(n, path); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			removeEncryptedComments(node.Content[i])
+			p := append(path[:len(path):len(path)], node.Content[i].Value)
+			if err := d.walk(node.Content[i+1], p); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return d.decryptScalar(node, path)
+	}
+	return nil
+}
+
+func (d *decrypter) decryptScalar(node *yaml.Node, path []string) error {
+	if node.Tag != yaml.NodeTagString || !encryptedValue.MatchString(node.Value) {
+		if !d.macOnlyEncrypted {
+			d.hash.Write([]byte(macValue(node.Value, scalarType(node))))
+		}
+		return nil
+	}
+	plaintext, typ, err := d.decrypt(node.Value, strings.Join(path, ":")+":")
+	if err != nil {
+		return errors.WrapPrefixf(err, "unable to decrypt %s", strings.Join(path, "."))
+	}
+	node.Value = plaintext
+	node.Style = 0
+	switch typ {
+	case "int":
+		node.Tag = yaml.NodeTagInt
+	case "float":
+		node.Tag = yaml.NodeTagFloat
+	case "bool":
+		node.Tag = yaml.NodeTagBool
+		if b, err := strconv.ParseBool(plaintext); err == nil {
+			node.Value = strconv.FormatBool(b)
+		}
+	default:
+		node.Tag = yaml.NodeTagString
+	}
+	d.hash.Write([]byte(macValue(plaintext, typ)))
+	return nil
+}
+
+// decrypt decrypts a value of the form
+//
+//	ENC[AES256_GCM,data:<data>,iv:<iv>,tag:<tag>,type:<type>]
+//
+// returning its plaintext and type.
+func (d *decrypter) decrypt(value, additionalData string) (string, string, error) {
+	m := encryptedValue.FindStringSubmatch(value)
+	if m == nil {
+		return "", "", errors.Errorf("invalid encrypted value")
+	}
+	var parts [3][]byte
+	for i := range parts {
+		var err error
+		if parts[i], err = base64.StdEncoding.DecodeString(m[i+1]); err != nil {
+			return "", "", errors.WrapPrefixf(err, "invalid encrypted value")
+		}
+	}
+	block, err := aes.NewCipher(d.key)
+	if err != nil {
+		return "", "", errors.Wrap(err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(parts[1]))
+	if err != nil {
+		return "", "", errors.Wrap(err)
+	}
+	plaintext, err := gcm.Open(nil, parts[1], append(parts[0], parts[2]...), []byte(additionalData))
+	if err != nil {
+		return "", "", errors.Errorf("message authentication failed")
+	}
+	return string(plaintext), m[4], nil
+}
+
+// verify checks the mac of the file, the encrypted hash of its
+// values, which detects values that were changed or removed.
+func (d *decrypter) verify() error {
+	mac, _, err := d.decrypt(d.mac, d.lastModified)
+	if err != nil {
+		return errors.WrapPrefixf(err, "unable to decrypt the SOPS mac")
+	}
+	if !strings.EqualFold(mac, fmt.Sprintf("%X", d.hash.Sum(nil))) {
+		return errors.Errorf("SOPS mac mismatch: the file was modified after its encryption")
+	}
+	return nil
+}
+
+func scalarType(node *yaml.Node) string {
+	switch node.ShortTag() {
+	case yaml.NodeTagInt:
+		return "int"
+	case yaml.NodeTagFloat:
+		return "float"
+	case yaml.NodeTagBool:
+		return "bool"
+	case yaml.NodeTagNull:
+		return "null"
+	default:
+		return "str"
+	}
+}
+
+// macValue returns a value as SOPS hashes it.
+func macValue(value, typ string) string {
+	switch typ {
+	case "int":
+		if i, err := strconv.ParseInt(value, 0, 64); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	case "bool":
+		if b, err := strconv.ParseBool(value); err == nil {
+			if b {
+				return "True"
+			}
+			return "False"
+		}
+	case "null":
+		return ""
+	}
+	return value
+}
+
+func removeEncryptedComments(node *yaml.Node) {
+	for _, c := range []*string{&node.HeadComment, &node.LineComment, &node.FootComment} {
+		if strings.Contains(*c, "ENC[AES256_GCM,") {
+			*c = ""
+		}
+	}
+}
+
+// decryptDotenv decrypts a dotenv file, whose SOPS metadata is
+// flattened into sops_ prefixed variables, e.g.
+//
+//	sops_age__list_0__map_recipient=age1...
+func decryptDotenv(content []byte, keyService types.SopsKeyService) ([]byte, error) {
+	md := map[string]interface{}{}
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			if !strings.Contains(line, "ENC[AES256_GCM,") {
+				lines = append(lines, line)
+			}
+			continue
+		}
+		if name, ok := strings.CutPrefix(key, metadataKey+"_"); ok {
+			setFlattened(md, name, strings.ReplaceAll(value, `\n`, "\n"))
+			continue
+		}
+		lines = append(lines, line)
+	}
+	d, err := newDecrypter(md, keyService)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if !encryptedValue.MatchString(value) {
+			if !d.macOnlyEncrypted {
+				d.hash.Write([]byte(strings.ReplaceAll(value, `\n`, "\n")))
+			}
+			continue
+		}
+		plaintext, typ, err := d.decrypt(value, key+":")
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "unable to decrypt %s", key)
+		}
+		d.hash.Write([]byte(macValue(plaintext, typ)))
+		lines[i] = key + "=" + strings.ReplaceAll(plaintext, "\n", `\n`)
+	}
+	if err = d.verify(); err != nil {
+		return nil, err
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+var flattenedKey = regexp.MustCompile(`^(.+?)__list_(\d+)__map_(.+)$`)
+
+// setFlattened sets a flattened metadata value, either a top-level
+// value or a field of a master key, e.g. age__list_0__map_enc.
+func setFlattened(md map[string]interface{}, name, value string) {
+	m := flattenedKey.FindStringSubmatch(name)
+	if m == nil {
+		md[name] = value
+		return
+	}
+	i, err := strconv.Atoi(m[2])
+	if err != nil {
+		return
+	}
+	list, _ := md[m[1]].([]interface{})
+	for len(list) <= i {
+		list = append(list, map[string]interface{}{})
+	}
+	if fields, ok := list[i].(map[string]interface{}); ok {
+		fields[m[3]] = value
+	}
+	md[m[1]] = list
+}
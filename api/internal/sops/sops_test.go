@@ -0,0 +1,228 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package sops
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// The files below are encrypted for the second recipient of
+// yamlFile, the only recipient of the other files.
+const ageIdentity = "AGE-SECRET-KEY-1A86J9K55TJF92GDRHL7H48V34ESVP9SPGEQXS254N647SDLHQ3QS3LHML5"
+
+const dataKeyBase64 = "PEgO51m2cz/CfDRSkjgC62FR19h4NlDYezuJGp7dJYE="
+
+const yamlFile = `
+username: ENC[AES256_GCM,data:6EBBnFw=,iv:XJ48itO7wD4OwJsbft8JWt1pv3hVpanHHh8qN0XHzSc=,tag:Rh+5cQahhJL8q2wm7h1rxA==,type:str]
+password: ENC[AES256_GCM,data:rDwbh72H,iv:RHi9lw+X7rR+5usZaiMCI9GFVRwwGebd+ML4rzfnRoc=,tag:ez8r78pUhh52OAaMJ5tJwQ==,type:str]
+port: ENC[AES256_GCM,data:ZYiZFg==,iv:37h5azli0kmLx4Er+jZXkNSpDnQlbpbvJfwto8hGzzA=,tag:NZELJYmw8UBE+ge5sRwlFw==,type:int]
+ratio: ENC[AES256_GCM,data:YZJP,iv:8d6BYrEssPZhK6LoBb4kYn1ACrZsEkdIjPi29BzpN10=,tag:7DxEdaD9mRzyky4re2ehzA==,type:float]
+debug: ENC[AES256_GCM,data:WKenGw==,iv:nE/Lueo9FoofCt7oyvLS7Fik4FzRgNfLpckll2EGaIo=,tag:cUP4DT17BOVEgnZK2lIBFw==,type:bool]
+nested:
+    token: ENC[AES256_GCM,data:bPwS,iv:NXegWrdTao8+JDSiCSC8pVMSV5XQTK/YUmEzNsPH98g=,tag:izJ4bwhqgVcTE7n/Pgxw9A==,type:str]
+    hosts:
+        - ENC[AES256_GCM,data:pwm/UpCnG0goaaCKtg==,iv:W+atLVYHGnuqL7cNfUtm6X74aZsUYoEQfJy26s0AERM=,tag:M8pEbPtcEXGENSQ0WWqW5Q==,type:str]
+        - ENC[AES256_GCM,data:FBcQovLTQcnRjudh3A==,iv:Ndj++0wqTkQtQ1WXuvWpzqYshyy2gYuHStj5IdWk9oA=,tag:fuqVSJvxBBU8Ylk8OeOFkA==,type:str]
+replicas_unencrypted: 3
+sops:
+    age:
+        - recipient: age1hmdp4xaeyq9up4p4s6ky7qdqyjpykf2fazyg65m8x5w8fskd93kqvxe40s
+          enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSB0TmdpcW0wN1dzb2RRUEhD
+            cnlBUHJXUjV5MEM4YTBMWmtybmROYnlJVFM0CmxaMm5vWksrVktGdysrY1lrS1k4
+            YlhGTlZ1dFRJRlQvZ21yMERJSlFDSlEKLS0tIFRjVGtaV2szcjNXK3FHeURBWkMw
+            RUtBRUU4KzVoWGZLVHgrcmE3elErSncKkvzZ2rPLS8XoB9jw4z0/vEzcJ3DXeawa
+            jMbIPpZu24++MNwDGflayPcTDA9BKsUfkoZYcZupX3fZ2wik5icRsg==
+            -----END AGE ENCRYPTED FILE-----
+        - recipient: age1aj2zxlpynsy7l9mallnzve2v9ad82autu54d7hvukm9kwrp6rgxqkrrgfd
+          enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBZNTZxanZoejlqcDBROTNU
+            aDc1bGpndGUweE5wcVAwbjAzdjU2ZzRxRUNRCi9OR3JMUy9DYlJZalYyK0hwUWsz
+            M3p2akw1bzlLSFdMa0lsck9KdEc4eUkKLS0tIGI1ZHVxbWZxajBvdUluV0JTUWtG
+            bEJvaFIzamRURWRsT2MxVFZiV2VDS3MKMjLLDVGYXFIDL7R+Y0LuRME3aLSysCH0
+            9gtWW06j96bc8HiS+63SToplQANfTDuwG4hSB2v8bg+sQaOCCsv09Q==
+            -----END AGE ENCRYPTED FILE-----
+    lastmodified: "2023-10-14T10:00:00Z"
+    mac: ENC[AES256_GCM,data:hUq4WjKkoNiGaxHqYd5LjIlnPTdYUwXWPs35+2SOBfaKtMqmEPZ604C3En6wjmGx+ni/6BYuuPryQOzIamGHaJV+T9z+FAqdAKMMq6Bk94yw7SmRie2uZT6D2GauJMV0AR9rTM2VfIGHd6w98c2gVqWOKHXl9O9VnZcpHDnqRCQ=,iv:6c4NLmprFSNOeDxy+AP9q9Y+hU2G5rMo5o6uSnzvjDg=,tag:IJo21RVBAO8ufOlHV/47nQ==,type:str]
+    unencrypted_suffix: _unencrypted
+    version: 3.8.1
+`
+
+const jsonFile = `
+{"token": "ENC[AES256_GCM,data:1hdO,iv:K9fr/UCHyQt1eqRjyv/FoH3oeXCqtWQR+f4s3obIQHU=,tag:rHWIPP8osoJNbnsxd2HWWA==,type:str]", "port": "ENC[AES256_GCM,data:uGGRiw==,iv:TT1AUlaFBL3IT3pY0EKB+5PAyWYf2bvEXBLFJ4LNDc4=,tag:AK2gshKVX3E3bgwSo2FpMg==,type:int]", "sops": {"age": [{"recipient": "age1aj2zxlpynsy7l9mallnzve2v9ad82autu54d7hvukm9kwrp6rgxqkrrgfd", "enc": "-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBZNTZxanZoejlqcDBROTNU\naDc1bGpndGUweE5wcVAwbjAzdjU2ZzRxRUNRCi9OR3JMUy9DYlJZalYyK0hwUWsz\nM3p2akw1bzlLSFdMa0lsck9KdEc4eUkKLS0tIGI1ZHVxbWZxajBvdUluV0JTUWtG\nbEJvaFIzamRURWRsT2MxVFZiV2VDS3MKMjLLDVGYXFIDL7R+Y0LuRME3aLSysCH0\n9gtWW06j96bc8HiS+63SToplQANfTDuwG4hSB2v8bg+sQaOCCsv09Q==\n-----END AGE ENCRYPTED FILE-----\n"}], "lastmodified": "2023-10-14T10:00:00Z", "mac": "ENC[AES256_GCM,data:1NwtxS3uekdaY2cyGnwIQE2YSHYU9MxCGK9iwpY/70wSxIhlMh30TEAyEbj7/auzv6fme2oJOHyoxs5HrdnER++IGkOE6Ie0Mqk3gwHHZQ3wDaMWVgtOjCsT9uoSAH4szvdG387K+Gjqmq3MFvqFw5lSZ3AdZ+4fnpaDVpgbC8w=,iv:v8EIunhSAVTLJxxxJ3N4aboO7Fl89tM91e7qkI4whe0=,tag:Om/OLcgwUCZZDlJhYYJZfw==,type:str]", "version": "3.8.1"}}
+`
+
+const binaryFile = `
+{"data": "ENC[AES256_GCM,data:/tSFuUBxLAT9A0TiPXRZdOv5lv/sprcHZiD5w7KU9yJabHH8MPIAcelfFnLHge696yM0QR+x6cQlBrM=,iv:2W1ZNrZ6DecW8zWbOmKwezrigwJFzM6BsCQSNiiZVBc=,tag:V5B7Uo7y3PBOCTAr+3CB2g==,type:str]", "sops": {"age": [{"recipient": "age1aj2zxlpynsy7l9mallnzve2v9ad82autu54d7hvukm9kwrp6rgxqkrrgfd", "enc": "-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBZNTZxanZoejlqcDBROTNU\naDc1bGpndGUweE5wcVAwbjAzdjU2ZzRxRUNRCi9OR3JMUy9DYlJZalYyK0hwUWsz\nM3p2akw1bzlLSFdMa0lsck9KdEc4eUkKLS0tIGI1ZHVxbWZxajBvdUluV0JTUWtG\nbEJvaFIzamRURWRsT2MxVFZiV2VDS3MKMjLLDVGYXFIDL7R+Y0LuRME3aLSysCH0\n9gtWW06j96bc8HiS+63SToplQANfTDuwG4hSB2v8bg+sQaOCCsv09Q==\n-----END AGE ENCRYPTED FILE-----\n"}], "lastmodified": "2023-10-14T10:00:00Z", "mac": "ENC[AES256_GCM,data:cE6xtFlEjQ7w0uodsVfpQmzqHb6ZckN1gdlh5pujMh7LP/n3Dqh9qsEasYA+mMWCeEaAoKAsSdvNqfYddD7W+4wKqF6816VOJy1zPoXrUqnXRaTmjFNxU76zE2XgvHSEQHLdCDoDuiMlBPB3Mr5Aw20djX+vWd/Cz0GCrjiEzr4=,iv:nBzPHkaz1XWsVdJRUaoLyva4IJRgYW11cB/MIMLy4E8=,tag:5cKyrK1rEYjSnJ2zjgJmlg==,type:str]", "version": "3.8.1"}}
+`
+
+const dotenvFile = `
+DB_USER=ENC[AES256_GCM,data:poQUkvc=,iv:hqKUHG9HJ9wAY4ZfEgpcUTOwSj46dgffQQNQjoFQQOk=,tag:pN7a/eBXgNYXuCR8VoDUrw==,type:str]
+DB_PASSWORD=ENC[AES256_GCM,data:6cyhWMnlv1Kz,iv:2hkGjLY7/n44vh3Zv65rNzGukG7wPqvmSVOl6P2TpoI=,tag:1cQfgGVKHKoH8oBuwXKLCQ==,type:str]
+sops_age__list_0__map_enc=-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBZNTZxanZoejlqcDBROTNU\naDc1bGpndGUweE5wcVAwbjAzdjU2ZzRxRUNRCi9OR3JMUy9DYlJZalYyK0hwUWsz\nM3p2akw1bzlLSFdMa0lsck9KdEc4eUkKLS0tIGI1ZHVxbWZxajBvdUluV0JTUWtG\nbEJvaFIzamRURWRsT2MxVFZiV2VDS3MKMjLLDVGYXFIDL7R+Y0LuRME3aLSysCH0\n9gtWW06j96bc8HiS+63SToplQANfTDuwG4hSB2v8bg+sQaOCCsv09Q==\n-----END AGE ENCRYPTED FILE-----\n
+sops_age__list_0__map_recipient=age1aj2zxlpynsy7l9mallnzve2v9ad82autu54d7hvukm9kwrp6rgxqkrrgfd
+sops_lastmodified=2023-10-14T10:00:00Z
+sops_mac=ENC[AES256_GCM,data:p0DIFaMfmIc/ss1gDkEGgoMhP1BEqY1x7VfqMvORR/+L8ozbuJIOelA0ZT9mspTh8pgJDRRCelzKpTnboqZ1HQAeoKNbduUHGozqu7oY5grggzA+XZP92W7KounHgemMHdEJqxSjN5EcW/NRBV6+ZFjUhuEkk4o92UejVROcXpQ=,iv:oareksNBFdM98yEyAst/9paiydG5JpN8qA6g7mKeZNc=,tag:OYUAzxPvIip0/tq4Tz0hZQ==,type:str]
+sops_unencrypted_suffix=_unencrypted
+sops_version=3.8.1
+`
+
+func setAgeIdentities(t *testing.T, keys string) {
+	t.Helper()
+	t.Setenv("SOPS_AGE_KEY", keys)
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+}
+
+type fakeKeyService struct {
+	keys []types.SopsMasterKey
+}
+
+func (ks *fakeKeyService) Decrypt(key types.SopsMasterKey) ([]byte, error) {
+	ks.keys = append(ks.keys, key)
+	return base64.StdEncoding.DecodeString(dataKeyBase64)
+}
+
+func TestDecrypt(t *testing.T) {
+	setAgeIdentities(t, "# created: 2023-10-14T10:00:00Z\n"+ageIdentity+"\n")
+	testCases := map[string]struct {
+		content  string
+		format   Format
+		expected string
+	}{
+		"yaml": {
+			content: yamlFile,
+			format:  YAML,
+			expected: `
+username: admin
+password: s3cr3t
+port: 5432
+ratio: 0.5
+debug: true
+nested:
+  token: abc
+  hosts:
+  - a.example.com
+  - b.example.com
+replicas_unencrypted: 3
+`,
+		},
+		"json": {
+			content: jsonFile,
+			format:  JSON,
+			expected: `
+{
+  "port": 8080,
+  "token": "abc"
+}
+`,
+		},
+		"binary": {
+			content: binaryFile,
+			format:  Binary,
+			expected: `
+-----BEGIN CERTIFICATE-----
+MIIB
+-----END CERTIFICATE-----
+`,
+		},
+		"dotenv": {
+			content: dotenvFile,
+			format:  Dotenv,
+			expected: `
+DB_USER=admin
+DB_PASSWORD=p@ss=word
+`,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			content := strings.TrimPrefix(tc.content, "\n")
+			require.True(t, IsEncrypted([]byte(content), tc.format))
+			actual, err := Decrypt([]byte(content), tc.format, nil)
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tc.expected), strings.TrimSpace(string(actual)))
+		})
+	}
+}
+
+func TestDecryptWithKeyService(t *testing.T) {
+	setAgeIdentities(t, "")
+	ks := &fakeKeyService{}
+	actual, err := Decrypt([]byte(dotenvFile), Dotenv, ks)
+	require.NoError(t, err)
+	assert.Equal(t, "DB_USER=admin\nDB_PASSWORD=p@ss=word", strings.TrimSpace(string(actual)))
+	require.Len(t, ks.keys, 1)
+	assert.Equal(t, "age", ks.keys[0].Type)
+	assert.Equal(t, "age1aj2zxlpynsy7l9mallnzve2v9ad82autu54d7hvukm9kwrp6rgxqkrrgfd",
+		ks.keys[0].Metadata["recipient"])
+	assert.True(t, strings.HasPrefix(ks.keys[0].EncryptedKey, armor.Header+"\n"))
+}
+
+func TestDecryptErrors(t *testing.T) {
+	const otherIdentity = "AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX"
+	lines := strings.Split(strings.TrimPrefix(yamlFile, "\n"), "\n")
+	username, password := lines[0], lines[1]
+	testCases := map[string]struct {
+		identities string
+		content    string
+		errMsg     string
+	}{
+		"no identity": {
+			identities: otherIdentity,
+			content:    yamlFile,
+			errMsg:     "no identity matched any of the recipients",
+		},
+		"invalid identity": {
+			identities: ageIdentity[:len(ageIdentity)-1] + "6",
+			content:    yamlFile,
+			errMsg:     "malformed secret key: invalid checksum",
+		},
+		"moved value": {
+			identities: ageIdentity,
+			content: strings.Replace(yamlFile, password,
+				"password:"+strings.TrimPrefix(username, "username:"), 1),
+			errMsg: "unable to decrypt password: message authentication failed",
+		},
+		"removed value": {
+			identities: ageIdentity,
+			content:    strings.Replace(yamlFile, password+"\n", "", 1),
+			errMsg:     "SOPS mac mismatch",
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			setAgeIdentities(t, tc.identities)
+			_, err := Decrypt([]byte(tc.content), YAML, nil)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.False(t, IsEncrypted([]byte("username: admin\n"), YAML))
+	assert.False(t, IsEncrypted([]byte("sops: is a tool\n"), YAML))
+	assert.False(t, IsEncrypted([]byte("USER=admin\n"), Dotenv))
+	assert.False(t, IsEncrypted([]byte{0x89, 'P', 'N', 'G'}, Binary))
+}
+
+func TestFormatForPath(t *testing.T) {
+	assert.Equal(t, YAML, FormatForPath("secrets.yaml"))
+	assert.Equal(t, YAML, FormatForPath("dir/secrets.YML"))
+	assert.Equal(t, JSON, FormatForPath("secrets.json"))
+	assert.Equal(t, Dotenv, FormatForPath("secrets.env"))
+	assert.Equal(t, Binary, FormatForPath("tls.crt"))
+}
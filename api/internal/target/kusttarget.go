@@ -21,6 +21,7 @@ import (
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/trace"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
@@ -36,6 +37,18 @@ type KustTarget struct {
 	rFactory      *resmap.Factory
 	pLdr          *loader.Loader
 	origin        *resource.Origin
+	overrides     *overrides
+	prefetcher    *prefetcher
+	trace         *trace.Trace
+	// kubectlCommand reads the CRDs of the cluster.
+	kubectlCommand string
+
+	// clusterCrdsEnabled allows reading the CRDs of the cluster.
+	clusterCrdsEnabled bool
+	// allowedEnv names the environment variables the build may read.
+	allowedEnv []string
+	// inputs holds the values of the inputs of a component.
+	inputs map[string]string
 }
 
 // NewKustTarget returns a new instance of KustTarget.
@@ -120,6 +133,16 @@ func LoadKustFile(ldr ifc.Loader) ([]byte, string, error) {
 	}
 }
 
+// AddBuildMetadata enables the given build metadata options, as if
+// listed by the buildMetadata field of the kustomization.
+func (kt *KustTarget) AddBuildMetadata(options ...string) {
+	for _, o := range options {
+		if !utils.StringSliceContains(kt.kustomization.BuildMetadata, o) {
+			kt.kustomization.BuildMetadata = append(kt.kustomization.BuildMetadata, o)
+		}
+	}
+}
+
 // MakeCustomizedResMap creates a fully customized ResMap
 // per the instructions contained in its kustomization instance.
 func (kt *KustTarget) MakeCustomizedResMap() (resmap.ResMap, error) {
@@ -128,7 +151,8 @@ func (kt *KustTarget) MakeCustomizedResMap() (resmap.ResMap, error) {
 
 func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 	var origin *resource.Origin
-	if len(kt.kustomization.BuildMetadata) != 0 {
+	// the trace names plugins by the configs origins record
+	if len(kt.kustomization.BuildMetadata) != 0 || kt.trace != nil {
 		origin = &resource.Origin{}
 	}
 	kt.origin = origin
@@ -173,7 +197,11 @@ func (kt *KustTarget) addHashesToNames(
 	if err != nil {
 		return err
 	}
-	return ra.Transform(p)
+	return ra.Transform(tracedFunc{
+		trace: kt.trace,
+		step:  traceStep(kt.ldr.Root(), trace.Transformer, p, nil),
+		fn:    p.Transform,
+	})
 }
 
 // AccumulateTarget returns a new ResAccumulator,
@@ -198,7 +226,14 @@ func (kt *KustTarget) AccumulateTarget() (
 // (or empty if the Component does not have a parent).
 func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	resRa *accumulator.ResAccumulator, err error) {
-	ra, err = kt.accumulateResources(ra, kt.kustomization.Resources)
+	if err = kt.expandNamespace(); err != nil {
+		return nil, err
+	}
+	resources, err := kt.includedPaths(kt.kustomization.ConditionalResources)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "accumulating resources")
+	}
+	ra, err = kt.accumulateResources(ra, append(kt.kustomization.Resources, resources...))
 	if err != nil {
 		return nil, errors.WrapPrefixf(err, "accumulating resources")
 	}
@@ -229,7 +264,14 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 
 	// components are expected to execute after reading resources and adding generators ,before applying transformers and validation.
 	// https://github.com/kubernetes-sigs/kustomize/pull/5170#discussion_r1212101287
-	ra, err = kt.accumulateComponents(ra, kt.kustomization.Components)
+	components, err := kt.includedPaths(kt.kustomization.ConditionalComponents)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "accumulating components")
+	}
+	if err = kt.checkComponentInputs(); err != nil {
+		return nil, errors.WrapPrefixf(err, "accumulating components")
+	}
+	ra, err = kt.accumulateComponents(ra, append(kt.kustomization.Components, components...))
 	if err != nil {
 		return nil, errors.WrapPrefixf(err, "accumulating components")
 	}
@@ -288,12 +330,16 @@ func (kt *KustTarget) runGenerators(
 				return errors.WrapPrefixf(err, "adding origin annotations for generator %v", g)
 			}
 		}
-		err = ra.AbsorbAll(resMap)
+		err = ra.Transform(tracedFunc{
+			trace: kt.trace,
+			step:  traceStep(kt.ldr.Root(), trace.Generator, g.Generator, g.Origin),
+			fn:    func(m resmap.ResMap) error { return m.AbsorbAll(resMap) },
+		})
 		if err != nil {
 			return errors.WrapPrefixf(err, "merging from generator %v", g)
 		}
 	}
-	return nil
+	return kt.generateNamespace(ra)
 }
 
 func (kt *KustTarget) configureExternalGenerators() (
@@ -335,12 +381,28 @@ func (kt *KustTarget) runTransformers(ra *accumulator.ResAccumulator) error {
 		return err
 	}
 	r = append(r, lts...)
+	if len(kt.kustomization.Substitutions) > 0 {
+		st, err := kt.configureSubstitutions()
+		if err != nil {
+			return err
+		}
+		r = append(r, &resmap.TransformerWithProperties{Transformer: st})
+	}
 	lts, err = kt.configureExternalTransformers(kt.kustomization.Transformers)
 	if err != nil {
 		return err
 	}
 	r = append(r, lts...)
-	return ra.Transform(newMultiTransformer(r))
+	if len(kt.kustomization.Pipeline) > 0 {
+		pt, err := kt.configurePipeline()
+		if err != nil {
+			return err
+		}
+		r = append(r, &resmap.TransformerWithProperties{Transformer: pt})
+	}
+	mt := newMultiTransformer(r)
+	mt.trace, mt.root = kt.trace, kt.ldr.Root()
+	return ra.Transform(mt)
 }
 
 func (kt *KustTarget) configureExternalTransformers(transformers []string) ([]*resmap.TransformerWithProperties, error) {
@@ -416,14 +478,26 @@ func (kt *KustTarget) removeValidatedByLabel(rm resmap.ResMap) error {
 // with resources read from the given list of paths.
 func (kt *KustTarget) accumulateResources(
 	ra *accumulator.ResAccumulator, paths []string) (*accumulator.ResAccumulator, error) {
-	for _, path := range paths {
+	var results []*prefetched
+	if kt.prefetcher != nil && len(paths) > 1 {
+		results = kt.prefetchResources(paths)
+		defer cleanupPrefetched(results)
+	}
+	for i, path := range paths {
+		var p *prefetched
+		if results != nil {
+			p = results[i]
+		} else {
+			p = &prefetched{}
+			p.load(kt, path)
+		}
 		// try loading resource as file then as base (directory or git repository)
-		if errF := kt.accumulateFile(ra, path); errF != nil {
+		if errF := kt.accumulateFile(ra, path, p.resources, p.errF); errF != nil {
 			// not much we can do if the error is an HTTP error so we bail out
 			if errors.Is(errF, load.ErrHTTP) {
 				return nil, errF
 			}
-			ldr, err := kt.ldr.New(path)
+			ldr, err := p.newLoader(kt, path)
 			if err != nil {
 				if kusterr.IsMalformedYAMLError(errF) { // Some error occurred while tyring to decode YAML file
 					return nil, errF
@@ -435,11 +509,11 @@ func (kt *KustTarget) accumulateResources(
 			origin := kt.origin.Copy()
 			if kt.origin != nil {
 				kt.origin = kt.origin.Append(path)
-				ra, err = kt.accumulateDirectory(ra, ldr, false)
+				ra, err = kt.accumulateDirectory(ra, ldr, false, nil)
 				// after we are done recursing through the directory, reset the origin
 				kt.origin = &origin
 			} else {
-				ra, err = kt.accumulateDirectory(ra, ldr, false)
+				ra, err = kt.accumulateDirectory(ra, ldr, false, nil)
 			}
 			if err != nil {
 				if kusterr.IsMalformedYAMLError(errF) { // Some error occurred while tyring to decode YAML file
@@ -468,11 +542,11 @@ func (kt *KustTarget) accumulateComponents(
 		origin := kt.origin.Copy()
 		if kt.origin != nil {
 			kt.origin = kt.origin.Append(path)
-			ra, errD = kt.accumulateDirectory(ra, ldr, true)
+			ra, errD = kt.accumulateDirectory(ra, ldr, true, kt.kustomization.ComponentInputs[path])
 			// after we are done recursing through the directory, reset the origin
 			kt.origin = &origin
 		} else {
-			ra, errD = kt.accumulateDirectory(ra, ldr, true)
+			ra, errD = kt.accumulateDirectory(ra, ldr, true, kt.kustomization.ComponentInputs[path])
 		}
 		if errD != nil {
 			return nil, fmt.Errorf("accumulateDirectory: %q", errD)
@@ -482,7 +556,8 @@ func (kt *KustTarget) accumulateComponents(
 }
 
 func (kt *KustTarget) accumulateDirectory(
-	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool) (*accumulator.ResAccumulator, error) {
+	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool,
+	inputs map[string]interface{}) (*accumulator.ResAccumulator, error) {
 	defer ldr.Cleanup()
 	subKt := NewKustTarget(ldr, kt.validator, kt.rFactory, kt.pLdr)
 	err := subKt.Load()
@@ -492,17 +567,22 @@ func (kt *KustTarget) accumulateDirectory(
 	}
 	subKt.kustomization.BuildMetadata = kt.kustomization.BuildMetadata
 	subKt.origin = kt.origin
-	var bytes []byte
-	if openApiPath, exists := subKt.Kustomization().OpenAPI["path"]; exists {
-		bytes, err = ldr.Load(openApiPath)
-		if err != nil {
-			return nil, err
-		}
+	subKt.overrides = kt.overrides
+	subKt.prefetcher = kt.prefetcher
+	subKt.trace = kt.trace
+	subKt.kubectlCommand = kt.kubectlCommand
+	subKt.allowedEnv = kt.allowedEnv
+	openAPI, bytes, err := LoadOpenAPISchema(ldr, subKt.Kustomization().OpenAPI, "", "")
+	if err != nil {
+		return nil, err
 	}
-	err = openapi.SetSchema(subKt.Kustomization().OpenAPI, bytes, false)
+	err = openapi.SetSchema(openAPI, bytes, false)
 	if err != nil {
 		return nil, err
 	}
+	if err = subKt.AddCrdSchemas(); err != nil {
+		return nil, err
+	}
 	if isComponent && subKt.kustomization.Kind != types.ComponentKind {
 		return nil, fmt.Errorf(
 			"expected kind '%s' for path '%s' but got '%s'", types.ComponentKind, ldr.Root(), subKt.kustomization.Kind)
@@ -510,6 +590,14 @@ func (kt *KustTarget) accumulateDirectory(
 		return nil, fmt.Errorf(
 			"expected kind != '%s' for path '%s'", types.ComponentKind, ldr.Root())
 	}
+	if isComponent {
+		subKt.inputs, err = subKt.resolveInputs(inputs)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "component '%s'", ldr.Root())
+		}
+	} else if len(subKt.kustomization.Inputs) > 0 {
+		return nil, fmt.Errorf("only components may declare inputs, not '%s'", ldr.Root())
+	}
 
 	var subRa *accumulator.ResAccumulator
 	if isComponent {
@@ -534,8 +622,8 @@ func (kt *KustTarget) accumulateDirectory(
 }
 
 func (kt *KustTarget) accumulateFile(
-	ra *accumulator.ResAccumulator, path string) error {
-	resources, err := kt.rFactory.FromFile(kt.ldr, path)
+	ra *accumulator.ResAccumulator, path string,
+	resources resmap.ResMap, err error) error {
 	if err != nil {
 		return errors.WrapPrefixf(err, "accumulating resources from '%s'", path)
 	}
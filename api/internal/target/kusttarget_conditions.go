@@ -0,0 +1,209 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// includedPaths returns the paths of the entries whose condition holds.
+func (kt *KustTarget) includedPaths(entries []types.ConditionalPath) ([]string, error) {
+	var paths []string
+	for _, e := range entries {
+		if e.Path == "" {
+			return nil, fmt.Errorf("conditional entries must specify a path")
+		}
+		ok, err := evalCondition(e.Condition, kt.parameter)
+		if err != nil {
+			return nil, fmt.Errorf("condition of %s: %w", e.Path, err)
+		}
+		if ok {
+			paths = append(paths, e.Path)
+		}
+	}
+	return paths, nil
+}
+
+// parameter returns the value of the named build parameter, an input of
+// this component, set as an override or read from an environment variable
+// the build allows, see SetAllowedEnv.
+func (kt *KustTarget) parameter(name string) (string, bool) {
+	if v, ok := kt.inputs[name]; ok {
+		return v, true
+	}
+	if v, ok := kt.overrides.substitution(name); ok {
+		return v, true
+	}
+	if utils.StringSliceContains(kt.allowedEnv, name) {
+		return os.LookupEnv(name)
+	}
+	return "", false
+}
+
+// evalCondition evaluates the expression with the given parameters.
+func evalCondition(expr string, lookup func(string) (string, bool)) (bool, error) {
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return false, err
+	}
+	if len(tokens) == 0 {
+		return false, fmt.Errorf("condition is empty")
+	}
+	p := &conditionParser{tokens: tokens, lookup: lookup}
+	v, err := p.or()
+	if err != nil {
+		return false, err
+	}
+	if p.pos < len(p.tokens) {
+		return false, fmt.Errorf("unexpected %q in condition %q", p.tokens[p.pos].text, expr)
+	}
+	return v, nil
+}
+
+type conditionToken struct {
+	text string
+	// word is set for parameter names and values, whether bare or
+	// quoted, as opposed to operators.
+	word bool
+}
+
+func tokenizeCondition(expr string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, conditionToken{text: string(c)})
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="):
+			tokens = append(tokens, conditionToken{text: expr[i : i+2]})
+			i += 2
+		case c == '!':
+			tokens = append(tokens, conditionToken{text: "!"})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in condition %q", expr)
+			}
+			tokens = append(tokens, conditionToken{text: expr[i+1 : i+1+end], word: true})
+			i += end + 2
+		case isConditionWordChar(rune(c)):
+			j := i
+			for j < len(expr) && isConditionWordChar(rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, conditionToken{text: expr[i:j], word: true})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q in condition %q", c, expr)
+		}
+	}
+	return tokens, nil
+}
+
+func isConditionWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-./", r)
+}
+
+// conditionParser evaluates a condition by recursive descent, with
+// ! binding tighter than &&, which binds tighter than ||.
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+	lookup func(string) (string, bool)
+}
+
+func (p *conditionParser) accept(op string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].word && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) or() (bool, error) {
+	v, err := p.and()
+	for err == nil && p.accept("||") {
+		var w bool
+		w, err = p.and()
+		v = v || w
+	}
+	return v, err
+}
+
+func (p *conditionParser) and() (bool, error) {
+	v, err := p.not()
+	for err == nil && p.accept("&&") {
+		var w bool
+		w, err = p.not()
+		v = v && w
+	}
+	return v, err
+}
+
+func (p *conditionParser) not() (bool, error) {
+	if p.accept("!") {
+		v, err := p.not()
+		return !v, err
+	}
+	return p.primary()
+}
+
+func (p *conditionParser) primary() (bool, error) {
+	if p.accept("(") {
+		v, err := p.or()
+		if err != nil {
+			return false, err
+		}
+		if !p.accept(")") {
+			return false, fmt.Errorf("missing ) in condition")
+		}
+		return v, nil
+	}
+	name, err := p.word()
+	if err != nil {
+		return false, err
+	}
+	value, set := p.lookup(name)
+	for _, op := range []string{"==", "!="} {
+		if p.accept(op) {
+			operand, err := p.word()
+			if err != nil {
+				return false, err
+			}
+			return (value == operand) == (op == "=="), nil
+		}
+	}
+	if !set {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("parameter %s is %q, not a bool; compare it with == instead", name, value)
+	}
+	return b, nil
+}
+
+func (p *conditionParser) word() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("condition ends unexpectedly")
+	}
+	t := p.tokens[p.pos]
+	if !t.word {
+		return "", fmt.Errorf("unexpected %q in condition", t.text)
+	}
+	p.pos++
+	return t.text, nil
+}
@@ -5,10 +5,12 @@ package target
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/kv"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
@@ -37,6 +39,7 @@ func (kt *KustTarget) configureBuiltinGenerators() (
 		builtinhelpers.ConfigMapGenerator,
 		builtinhelpers.SecretGenerator,
 		builtinhelpers.HelmChartInflationGenerator,
+		builtinhelpers.NetworkPolicyGenerator,
 	} {
 		r, err := generatorConfigurators[bpt](
 			kt, bpt, builtinhelpers.GeneratorFactories[bpt])
@@ -79,6 +82,8 @@ func (kt *KustTarget) configureBuiltinTransformers(
 		builtinhelpers.AnnotationsTransformer,
 		builtinhelpers.PatchJson6902Transformer,
 		builtinhelpers.ReplicaCountTransformer,
+		builtinhelpers.ResourceOverrideTransformer,
+		builtinhelpers.SchedulingTransformer,
 		builtinhelpers.ImageTagTransformer,
 		builtinhelpers.ReplacementTransformer,
 	} {
@@ -137,13 +142,45 @@ var generatorConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		result []resmap.Generator, err error) {
 		var c struct {
 			types.ConfigMapArgs
+			TemplateData *types.LiteralTemplateData `json:"templateData,omitempty" yaml:"templateData,omitempty"`
 		}
+		// Templated literals may read the literals of the
+		// configMapGenerators listed before them.
+		literals := map[string]map[string]string{}
 		for _, args := range kt.kustomization.ConfigMapGenerator {
 			c.ConfigMapArgs = args
 			c.ConfigMapArgs.Options = types.MergeGlobalOptionsIntoLocal(
 				c.ConfigMapArgs.Options, kt.kustomization.GeneratorOptions)
+			c.TemplateData = nil
+			kvLdr := kv.NewLoader(kt.ldr, kt.validator)
+			if args.TemplateLiterals {
+				c.TemplateData = &types.LiteralTemplateData{
+					Overlay:    filepath.Base(kt.ldr.Root()),
+					Namespace:  args.Namespace,
+					Name:       args.Name,
+					ConfigMaps: copyLiterals(literals),
+				}
+				if c.TemplateData.Namespace == "" {
+					c.TemplateData.Namespace = kt.kustomization.Namespace
+				}
+				c.TemplateData.Env = make(map[string]string, len(kt.allowedEnv))
+				for _, name := range kt.allowedEnv {
+					c.TemplateData.Env[name] = os.Getenv(name)
+				}
+				kvLdr = kv.NewTemplateLoader(kt.ldr, kt.validator, *c.TemplateData)
+			}
+			pairs, err := kvLdr.Load(types.KvPairSources{LiteralSources: args.LiteralSources})
+			if err != nil {
+				return nil, errors.WrapPrefixf(err, "configMapGenerator %s", args.Name)
+			}
+			if literals[args.Name] == nil {
+				literals[args.Name] = map[string]string{}
+			}
+			for _, pair := range pairs {
+				literals[args.Name][pair.Key] = pair.Value
+			}
 			p := f()
-			err := kt.configureBuiltinPlugin(p, c, bpt)
+			err = kt.configureBuiltinPlugin(p, c, bpt)
 			if err != nil {
 				return nil, err
 			}
@@ -174,6 +211,38 @@ var generatorConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		}
 		return
 	},
+
+	builtinhelpers.NetworkPolicyGenerator: func(
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f gFactory) (
+		result []resmap.Generator, err error) {
+		var c struct {
+			types.NetworkPolicyArgs
+			SelectorLabels map[string]string
+		}
+		// The commonLabels, and labels including selectors,
+		// are added to the pod selectors of the policies.
+		selectorLabels := make(map[string]string)
+		for k, v := range kt.kustomization.CommonLabels {
+			selectorLabels[k] = v
+		}
+		for _, label := range kt.kustomization.Labels {
+			if label.IncludeSelectors {
+				for k, v := range label.Pairs {
+					selectorLabels[k] = v
+				}
+			}
+		}
+		for _, args := range kt.kustomization.NetworkPolicyGenerator {
+			c.NetworkPolicyArgs = args
+			c.SelectorLabels = selectorLabels
+			p := f()
+			if err = kt.configureBuiltinPlugin(p, c, bpt); err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		return
+	},
 }
 
 type tFactory func() resmap.TransformerPlugin
@@ -275,13 +344,20 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		if len(kt.kustomization.Labels) == 0 && len(kt.kustomization.CommonLabels) == 0 {
 			return
 		}
-		for _, label := range kt.kustomization.Labels {
+		for i, label := range kt.kustomization.Labels {
 			var c struct {
 				Labels     map[string]string
 				FieldSpecs []types.FieldSpec
 			}
 			c.Labels = label.Pairs
 			fss := types.FsSlice(label.FieldSpecs)
+			if label.ExcludeTemplates {
+				if label.IncludeSelectors || label.IncludeTemplates {
+					return nil, fmt.Errorf(
+						"labels[%d] cannot exclude templates and include selectors or templates", i)
+				}
+				fss = withoutTemplates(fss)
+			}
 			// merge the custom fieldSpecs with the default
 			if label.IncludeSelectors {
 				fss, err = fss.MergeAll(tc.CommonLabels)
@@ -305,7 +381,7 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			if err != nil {
 				return nil, err
 			}
-			result = append(result, p)
+			result = append(result, newScopedTransformer(scope{selects: label.Select, rejects: label.Reject}, p))
 		}
 		var c struct {
 			Labels     map[string]string
@@ -324,61 +400,95 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 	builtinhelpers.AnnotationsTransformer: func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
-		if len(kt.kustomization.CommonAnnotations) == 0 {
-			return
-		}
 		var c struct {
 			Annotations map[string]string
 			FieldSpecs  []types.FieldSpec
 		}
-		c.Annotations = kt.kustomization.CommonAnnotations
-		c.FieldSpecs = tc.CommonAnnotations
-		p := f()
-		err = kt.configureBuiltinPlugin(p, c, bpt)
-		if err != nil {
-			return nil, err
+		if len(kt.kustomization.CommonAnnotations) > 0 {
+			c.Annotations = kt.kustomization.CommonAnnotations
+			c.FieldSpecs = tc.CommonAnnotations
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		for _, a := range kt.kustomization.Annotations {
+			c.Annotations = a.Pairs
+			c.FieldSpecs = tc.CommonAnnotations
+			if a.ExcludeTemplates {
+				c.FieldSpecs = withoutTemplates(tc.CommonAnnotations)
+			}
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, newScopedTransformer(scope{selects: a.Select, rejects: a.Reject}, p))
 		}
-		result = append(result, p)
 		return
 	},
 	builtinhelpers.PrefixTransformer: func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
-		if kt.kustomization.NamePrefix == "" {
-			return
-		}
 		var c struct {
 			Prefix     string            `json:"prefix,omitempty" yaml:"prefix,omitempty"`
 			FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
 		}
-		c.Prefix = kt.kustomization.NamePrefix
 		c.FieldSpecs = tc.NamePrefix
-		p := f()
-		err = kt.configureBuiltinPlugin(p, c, bpt)
-		if err != nil {
-			return nil, err
+		if kt.kustomization.NamePrefix != "" {
+			c.Prefix = kt.kustomization.NamePrefix
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		for i, a := range kt.kustomization.NamePrefixes {
+			if a.Value == "" {
+				return nil, fmt.Errorf("namePrefixes[%d] must specify a value", i)
+			}
+			c.Prefix = a.Value
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, newScopedTransformer(scope{a.Select, a.Reject, a.SkipClusterScoped}, p))
 		}
-		result = append(result, p)
 		return
 	},
 	builtinhelpers.SuffixTransformer: func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
-		if kt.kustomization.NameSuffix == "" {
-			return
-		}
 		var c struct {
 			Suffix     string            `json:"suffix,omitempty" yaml:"suffix,omitempty"`
 			FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
 		}
-		c.Suffix = kt.kustomization.NameSuffix
 		c.FieldSpecs = tc.NameSuffix
-		p := f()
-		err = kt.configureBuiltinPlugin(p, c, bpt)
-		if err != nil {
-			return nil, err
+		if kt.kustomization.NameSuffix != "" {
+			c.Suffix = kt.kustomization.NameSuffix
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		for i, a := range kt.kustomization.NameSuffixes {
+			if a.Value == "" {
+				return nil, fmt.Errorf("nameSuffixes[%d] must specify a value", i)
+			}
+			c.Suffix = a.Value
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, newScopedTransformer(scope{a.Select, a.Reject, a.SkipClusterScoped}, p))
 		}
-		result = append(result, p)
 		return
 	},
 	builtinhelpers.ImageTagTransformer: func(
@@ -437,6 +547,44 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		}
 		return
 	},
+	builtinhelpers.ResourceOverrideTransformer: func(
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
+		result []resmap.Transformer, err error) {
+		var c struct {
+			ResourceOverride types.ResourceOverride
+			FieldSpecs       []types.FieldSpec
+		}
+		for _, args := range kt.kustomization.ResourceOverrides {
+			c.ResourceOverride = args
+			c.FieldSpecs = tc.ResourceOverrides
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		return
+	},
+	builtinhelpers.SchedulingTransformer: func(
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
+		result []resmap.Transformer, err error) {
+		var c struct {
+			Scheduling types.Scheduling
+			FieldSpecs []types.FieldSpec
+		}
+		for _, args := range kt.kustomization.Scheduling {
+			c.Scheduling = args
+			c.FieldSpecs = tc.Scheduling
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		return
+	},
 	// No kustomization file keyword for this yet.
 	builtinhelpers.ValueAddTransformer: func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
@@ -444,3 +592,11 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		return nil, fmt.Errorf("valueadd keyword not yet defined")
 	},
 }
+
+func copyLiterals(literals map[string]map[string]string) map[string]map[string]string {
+	c := make(map[string]map[string]string, len(literals))
+	for name, m := range literals {
+		c[name] = types.CopyMap(m)
+	}
+	return c
+}
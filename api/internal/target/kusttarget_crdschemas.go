@@ -0,0 +1,103 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+const (
+	// openAPICrdsKey is the key of the openapi field naming the
+	// CRDs whose schemas strategic merge patches honor.
+	openAPICrdsKey = "crds"
+
+	// crdsFromCluster is the value of openAPICrdsKey naming the
+	// CRDs of the cluster.
+	crdsFromCluster = "cluster"
+
+	defaultKubectlCommand = "kubectl"
+)
+
+// SetKubectlCommand sets the kubectl executable reading the CRDs
+// of the cluster, kubectl on the PATH by default.
+func (kt *KustTarget) SetKubectlCommand(command string) {
+	kt.kubectlCommand = command
+}
+
+// SetClusterCrdsEnabled sets whether the kustomization may read the
+// CRDs of the cluster, with kubectl in its current context.
+func (kt *KustTarget) SetClusterCrdsEnabled(enabled bool) {
+	kt.clusterCrdsEnabled = enabled
+}
+
+// AddCrdSchemas adds the schemas of the CRDs named by the crds key
+// of the openapi field of the kustomization, a file, a directory of
+// files or "cluster" for those kubectl reads from the cluster, to
+// the schema strategic merge patches honor. Reading the CRDs of the
+// cluster must be enabled, and a remote kustomization can't.
+func (kt *KustTarget) AddCrdSchemas() error {
+	path, ok := kt.kustomization.OpenAPI[openAPICrdsKey]
+	if !ok {
+		return nil
+	}
+	if path != crdsFromCluster {
+		return AddCrdSchemas(kt.ldr, path)
+	}
+	if kt.ldr.Repo() != "" {
+		return errors.Errorf("remote kustomization %s can't read the CRDs of the cluster", kt.ldr.Repo())
+	}
+	if !kt.clusterCrdsEnabled {
+		return errors.Errorf("must specify --enable-cluster-crds to read the CRDs of the cluster")
+	}
+	command := kt.kubectlCommand
+	if command == "" {
+		command = defaultKubectlCommand
+	}
+	cmd := exec.Command(command, "get", "customresourcedefinitions", "--output", "yaml")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Errorf("reading the CRDs of the cluster with %s: %v: %s",
+			command, err, strings.TrimSpace(stderr.String()))
+	}
+	return errors.WrapPrefixf(openapi.AddCrdSchemas(stdout.Bytes()), "CRDs of the cluster")
+}
+
+// AddCrdSchemas adds the schemas of the CRDs in the file at path, or
+// in the YAML and JSON files of the directory at path, to the schema
+// strategic merge patches honor.
+func AddCrdSchemas(ldr ifc.Loader, path string) error {
+	files := []string{path}
+	if lister, ok := ldr.(ifc.FileLister); ok && lister.IsDir(path) {
+		all, err := lister.ListFiles(path)
+		if err != nil {
+			return err
+		}
+		files = nil
+		for _, f := range all {
+			switch filepath.Ext(f) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, f)
+			}
+		}
+	}
+	for _, f := range files {
+		b, err := ldr.Load(f)
+		if err != nil {
+			return errors.WrapPrefixf(err, "loading CRDs")
+		}
+		if err = openapi.AddCrdSchemas(b); err != nil {
+			return errors.WrapPrefixf(err, "CRDs of %s", f)
+		}
+	}
+	return nil
+}
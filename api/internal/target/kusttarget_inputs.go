@@ -0,0 +1,114 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// resolveInputs checks the values passed to the inputs of this
+// component against their declarations and returns them, with the
+// defaults of those not passed.
+func (kt *KustTarget) resolveInputs(values map[string]interface{}) (map[string]string, error) {
+	inputs := map[string]string{}
+	for _, in := range kt.kustomization.Inputs {
+		if in.Name == "" {
+			return nil, fmt.Errorf("inputs must specify a name")
+		}
+		if _, seen := inputs[in.Name]; seen {
+			return nil, fmt.Errorf("input %q is declared more than once", in.Name)
+		}
+		raw, passed := values[in.Name]
+		var v string
+		switch {
+		case passed:
+			s, err := inputString(raw)
+			if err != nil {
+				return nil, fmt.Errorf("input %q: %w", in.Name, err)
+			}
+			v = s
+		case in.Default != nil:
+			v = *in.Default
+		case in.Required:
+			return nil, fmt.Errorf("input %q is required but has no value", in.Name)
+		default:
+			continue
+		}
+		switch in.Type {
+		case "", types.StringSubstitutionType:
+		case types.IntSubstitutionType:
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				return nil, fmt.Errorf("input %q: %q is not an int", in.Name, v)
+			}
+		case types.BoolSubstitutionType:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("input %q: %q is not a bool", in.Name, v)
+			}
+			v = strconv.FormatBool(b)
+		default:
+			return nil, fmt.Errorf(
+				"input %q has illegal type %q; legal types: %v", in.Name, in.Type,
+				[]types.SubstitutionType{types.StringSubstitutionType, types.IntSubstitutionType, types.BoolSubstitutionType})
+		}
+		inputs[in.Name] = v
+	}
+	var unknown []string
+	for name := range values {
+		if !kt.declaresInput(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("no inputs named %v", unknown)
+	}
+	return inputs, nil
+}
+
+// declaresInput tells if this component declares the named input.
+func (kt *KustTarget) declaresInput(name string) bool {
+	for _, in := range kt.kustomization.Inputs {
+		if in.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkComponentInputs checks that inputs are only passed to the
+// components of this kustomization.
+func (kt *KustTarget) checkComponentInputs() error {
+	listed := map[string]bool{}
+	for _, path := range kt.kustomization.Components {
+		listed[path] = true
+	}
+	for _, c := range kt.kustomization.ConditionalComponents {
+		listed[c.Path] = true
+	}
+	for path := range kt.kustomization.ComponentInputs {
+		if !listed[path] {
+			return fmt.Errorf("inputs passed to %s, which is not a component of this kustomization", path)
+		}
+	}
+	return nil
+}
+
+// inputString formats a scalar input value as a string.
+func inputString(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("value must be a scalar")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// namespaceParameterRegexp matches the build parameters read by the
+// namespace field, e.g. ${env}.
+var namespaceParameterRegexp = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// dns1123LabelRegexp matches a DNS-1123 label, as namespace names must be.
+var dns1123LabelRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// expandNamespace replaces the build parameters read by the namespace
+// field with their values: the inputs of the component, the values set
+// with --set, and the environment variables allowed by the build. The
+// namespace they expand to must be a DNS-1123 label.
+func (kt *KustTarget) expandNamespace() error {
+	ns := kt.kustomization.Namespace
+	if !namespaceParameterRegexp.MatchString(ns) {
+		return nil
+	}
+	var err error
+	expanded := namespaceParameterRegexp.ReplaceAllStringFunc(ns, func(m string) string {
+		name := namespaceParameterRegexp.FindStringSubmatch(m)[1]
+		v, ok := kt.parameter(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("namespace %q reads unset parameter %q", ns, name)
+		}
+		return v
+	})
+	if err != nil {
+		return err
+	}
+	if len(expanded) > 63 || !dns1123LabelRegexp.MatchString(expanded) {
+		return fmt.Errorf("namespace %q expands to %q, which is not a valid DNS-1123 label", ns, expanded)
+	}
+	kt.kustomization.Namespace = expanded
+	return nil
+}
+
+// generateNamespace adds the Namespace object named by the namespace
+// field to ra if namespaceOptions asks to create it, unless ra
+// already includes it.
+func (kt *KustTarget) generateNamespace(ra *accumulator.ResAccumulator) error {
+	ns := kt.kustomization.Namespace
+	if ns == "" || kt.kustomization.NamespaceOptions == nil || !kt.kustomization.NamespaceOptions.Create {
+		return nil
+	}
+	for _, r := range ra.ResMap().Resources() {
+		if r.GetKind() == "Namespace" && r.GetName() == ns {
+			return nil
+		}
+	}
+	r := kt.rFactory.RF().FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name": ns,
+		},
+	})
+	m := kt.rFactory.FromResource(r)
+	if kt.origin != nil {
+		err := m.AddOriginAnnotation(&resource.Origin{
+			Repo:         kt.origin.Repo,
+			Ref:          kt.origin.Ref,
+			ConfiguredIn: filepath.Join(kt.origin.Path, kt.kustFileName),
+			ConfiguredBy: yaml.ResourceIdentifier{
+				TypeMeta: yaml.TypeMeta{
+					APIVersion: "builtin",
+					Kind:       builtinhelpers.NamespaceTransformer.String(),
+				},
+			},
+		})
+		if err != nil {
+			return errors.WrapPrefixf(err, "adding origin annotations for namespace %s", ns)
+		}
+	}
+	return errors.WrapPrefixf(ra.AbsorbAll(m), "creating namespace %s", ns)
+}
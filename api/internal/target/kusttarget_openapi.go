@@ -0,0 +1,93 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+// kubernetesOpenAPIURL locates the OpenAPI schema of a Kubernetes
+// release by its tag, e.g. v1.29.3.
+const kubernetesOpenAPIURL = "https://raw.githubusercontent.com/kubernetes/kubernetes/%s/api/openapi-spec/swagger.json"
+
+var kubeVersionRegexp = regexp.MustCompile(`^v?(1\.\d+)(\.\d+)?$`)
+
+// KubernetesOpenAPIURL returns the URL of the OpenAPI schema of the
+// Kubernetes release, e.g. v1.29.3.  Releases are named in full, as
+// their tags pin the schema, unlike the release branches of minor
+// versions.
+func KubernetesOpenAPIURL(version string) (string, error) {
+	m := kubeVersionRegexp.FindStringSubmatch(version)
+	if m == nil {
+		return "", fmt.Errorf(
+			"invalid Kubernetes version %q; expected a version like 1.29 or v1.29.3", version)
+	}
+	if m[2] == "" {
+		return "", fmt.Errorf(
+			"the OpenAPI schema of Kubernetes %s isn't built in; name a release, e.g. v%s.0, to fetch its schema",
+			version, m[1])
+	}
+	return fmt.Sprintf(kubernetesOpenAPIURL, "v"+m[1]+m[2]), nil
+}
+
+// LoadOpenAPISchema returns the openapi field of a kustomization,
+// with its version replaced by kubeVersion if set, and the custom
+// schema to use with it, if any: the file at its path or, if
+// kubeVersion isn't built in, the schema of that release, fetched with
+// ldr, e.g. through the remote cache, and checked against its sha256
+// checksum, which must then be given as a hex string.  A version of
+// the field that isn't built in is left for openapi.SetSchema to
+// reject; only kubeVersion is ever fetched.
+func LoadOpenAPISchema(
+	ldr ifc.Loader, field map[string]string, kubeVersion, kubeVersionSHA256 string) (map[string]string, []byte, error) {
+	result := make(map[string]string, len(field))
+	for k, v := range field {
+		result[k] = v
+	}
+	if kubeVersion != "" {
+		result["version"] = kubeVersion
+	}
+	if path, ok := result["path"]; ok {
+		if kubeVersion != "" {
+			// the custom schema stands in for the builtin ones
+			delete(result, "version")
+		}
+		b, err := ldr.Load(path)
+		return result, b, err
+	}
+	if kubeVersion == "" {
+		return result, nil, nil
+	}
+	if _, builtin := openapi.BuiltinVersion(kubeVersion); builtin {
+		return result, nil, nil
+	}
+	url, err := KubernetesOpenAPIURL(kubeVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	if kubeVersionSHA256 == "" {
+		return nil, nil, fmt.Errorf(
+			"the OpenAPI schema of Kubernetes %s isn't built in; fetching it requires its sha256 checksum", kubeVersion)
+	}
+	b, err := ldr.Load(url)
+	if err != nil {
+		return nil, nil, errors.WrapPrefixf(err, "fetching the OpenAPI schema of Kubernetes %s", kubeVersion)
+	}
+	sum := sha256.Sum256(b)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, kubeVersionSHA256) {
+		return nil, nil, fmt.Errorf(
+			"the OpenAPI schema of Kubernetes %s fetched from %s has sha256 checksum %s, not %s",
+			kubeVersion, url, actual, kubeVersionSHA256)
+	}
+	delete(result, "version")
+	return result, b, nil
+}
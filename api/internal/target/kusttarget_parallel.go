@@ -0,0 +1,87 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	load "sigs.k8s.io/kustomize/api/internal/loader"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// prefetcher bounds the number of resources entries loaded
+// concurrently across all the kustomizations of a build.
+type prefetcher struct {
+	slots chan struct{}
+}
+
+// SetParallel loads up to n resources entries at once, i.e.
+// parses resource files and fetches remote bases concurrently.
+// The entries are still accumulated in order, so the output
+// doesn't depend on n.
+func (kt *KustTarget) SetParallel(n int) {
+	if n > 1 {
+		kt.prefetcher = &prefetcher{slots: make(chan struct{}, n)}
+	}
+}
+
+// prefetched holds the outcome of loading a resources entry
+// as a file and, failing that, as a base.
+type prefetched struct {
+	resources resmap.ResMap
+	errF      error
+	ldr       ifc.Loader
+	errL      error
+}
+
+// load does what accumulateResources would do for a path,
+// short of accumulating the result.
+func (p *prefetched) load(kt *KustTarget, path string) {
+	p.resources, p.errF = kt.rFactory.FromFile(kt.ldr, path)
+	if p.errF != nil && !errors.Is(p.errF, load.ErrHTTP) {
+		p.ldr, p.errL = kt.ldr.New(path)
+	}
+}
+
+// newLoader returns the loader for the path, handing over
+// its cleanup to the caller. A loader is made if none was,
+// which happens when the path's resources failed to merge.
+func (p *prefetched) newLoader(kt *KustTarget, path string) (ifc.Loader, error) {
+	if p.ldr == nil && p.errL == nil {
+		return kt.ldr.New(path)
+	}
+	ldr, err := p.ldr, p.errL
+	p.ldr = nil
+	return ldr, err
+}
+
+// prefetchResources loads the paths concurrently.
+func (kt *KustTarget) prefetchResources(paths []string) []*prefetched {
+	results := make([]*prefetched, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		results[i] = &prefetched{}
+		wg.Add(1)
+		go func(p *prefetched, path string) {
+			defer wg.Done()
+			kt.prefetcher.slots <- struct{}{}
+			defer func() { <-kt.prefetcher.slots }()
+			p.load(kt, path)
+		}(results[i], path)
+	}
+	wg.Wait()
+	return results
+}
+
+// cleanupPrefetched cleans up the loaders that weren't handed
+// over because accumulation failed at an earlier path.
+func cleanupPrefetched(results []*prefetched) {
+	for _, p := range results {
+		if p.ldr != nil {
+			_ = p.ldr.Cleanup()
+		}
+	}
+}
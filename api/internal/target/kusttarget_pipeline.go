@@ -0,0 +1,125 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"log"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// pipelineStage is a stage of the pipeline with its transformers.
+type pipelineStage struct {
+	types.PipelineStage
+	transformer resmap.Transformer
+}
+
+// pipelineTransformer runs the stages of the pipeline in order.
+type pipelineTransformer struct {
+	stages []*pipelineStage
+}
+
+var _ resmap.Transformer = &pipelineTransformer{}
+
+// configurePipeline validates the stages of the pipeline of the
+// kustomization and loads their transformers.
+func (kt *KustTarget) configurePipeline() (*pipelineTransformer, error) {
+	t := &pipelineTransformer{}
+	for i, s := range kt.kustomization.Pipeline {
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("#%d", i+1)
+		}
+		switch s.OnFailure {
+		case "":
+			s.OnFailure = types.FailOnFailure
+		case types.FailOnFailure, types.ContinueOnFailure, types.SkipOnFailure:
+		default:
+			return nil, fmt.Errorf(
+				"pipeline stage %s has illegal onFailure %q; legal values: %v", s.Name, s.OnFailure,
+				[]types.FailurePolicy{types.FailOnFailure, types.ContinueOnFailure, types.SkipOnFailure})
+		}
+		if s.Transformer == "" {
+			return nil, fmt.Errorf("pipeline stage %s must specify a transformer", s.Name)
+		}
+		ts, err := kt.configureExternalTransformers([]string{s.Transformer})
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "pipeline stage %s", s.Name)
+		}
+		t.stages = append(t.stages, &pipelineStage{
+			PipelineStage: s,
+			transformer:   newMultiTransformer(ts),
+		})
+	}
+	return t, nil
+}
+
+// Transform runs each stage on copies of the resources it selects,
+// which replace them, where the first of them was, if it succeeds.
+func (t *pipelineTransformer) Transform(m resmap.ResMap) error {
+	for _, s := range t.stages {
+		selected := m.Resources()
+		if s.Select != nil {
+			var err error
+			if selected, err = m.Select(*s.Select); err != nil {
+				return errors.WrapPrefixf(err, "pipeline stage %s", s.Name)
+			}
+		}
+		if len(selected) == 0 {
+			continue
+		}
+		sub := resmap.New()
+		for _, r := range selected {
+			if err := sub.Append(r.DeepCopy()); err != nil {
+				return err
+			}
+		}
+		if err := s.transformer.Transform(sub); err != nil {
+			switch s.OnFailure {
+			case types.ContinueOnFailure:
+				log.Printf("pipeline stage %s failed, continuing: %v", s.Name, err)
+				continue
+			case types.SkipOnFailure:
+				log.Printf("pipeline stage %s failed, skipping the remaining stages: %v", s.Name, err)
+				return nil
+			default:
+				return errors.WrapPrefixf(err, "pipeline stage %s", s.Name)
+			}
+		}
+		if err := splice(m, selected, sub); err != nil {
+			return errors.WrapPrefixf(err, "pipeline stage %s", s.Name)
+		}
+	}
+	return nil
+}
+
+// splice replaces the selected resources of m with those of sub.
+func splice(m resmap.ResMap, selected []*resource.Resource, sub resmap.ResMap) error {
+	isSelected := make(map[*resource.Resource]bool, len(selected))
+	for _, r := range selected {
+		isSelected[r] = true
+	}
+	all := m.Resources()
+	m.Clear()
+	spliced := false
+	for _, r := range all {
+		if !isSelected[r] {
+			if err := m.Append(r); err != nil {
+				return err
+			}
+			continue
+		}
+		if spliced {
+			continue
+		}
+		spliced = true
+		if err := m.AppendAll(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
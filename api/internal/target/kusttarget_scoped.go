@@ -0,0 +1,116 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// scope is the set of resources a transformer configured by an entry
+// of e.g. namePrefixes or labels applies to: those matching any of
+// selects, all of them if empty, but none of rejects.
+type scope struct {
+	selects           []*types.Selector
+	rejects           []*types.Selector
+	skipClusterScoped bool
+}
+
+func (s scope) isEmpty() bool {
+	return len(s.selects) == 0 && len(s.rejects) == 0 && !s.skipClusterScoped
+}
+
+// scopedTransformer runs a transformer on the resources of its scope.
+type scopedTransformer struct {
+	scope
+	transformer resmap.Transformer
+}
+
+var _ resmap.Transformer = &scopedTransformer{}
+
+// newScopedTransformer returns t if s is empty, or t limited to s.
+func newScopedTransformer(s scope, t resmap.Transformer) resmap.Transformer {
+	if s.isEmpty() {
+		return t
+	}
+	return &scopedTransformer{scope: s, transformer: t}
+}
+
+// Transform runs the transformer on the resources of the scope, which
+// it changes in place.
+func (t *scopedTransformer) Transform(m resmap.ResMap) error {
+	selected, err := t.resources(m)
+	if err != nil {
+		return err
+	}
+	sub := resmap.New()
+	for _, r := range selected {
+		if err = sub.Append(r); err != nil {
+			return err
+		}
+	}
+	return t.transformer.Transform(sub)
+}
+
+// resources returns the resources of m in the scope, in the order of m.
+func (s scope) resources(m resmap.ResMap) ([]*resource.Resource, error) {
+	matches := func(selectors []*types.Selector) (map[*resource.Resource]bool, error) {
+		matched := map[*resource.Resource]bool{}
+		for _, sel := range selectors {
+			rs, err := m.Select(*sel)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range rs {
+				matched[r] = true
+			}
+		}
+		return matched, nil
+	}
+	selected, err := matches(s.selects)
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := matches(s.rejects)
+	if err != nil {
+		return nil, err
+	}
+	var result []*resource.Resource
+	for _, r := range m.Resources() {
+		if len(s.selects) > 0 && !selected[r] {
+			continue
+		}
+		if rejected[r] || (s.skipClusterScoped && r.GetGvk().IsClusterScoped()) {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// withoutTemplates returns the fieldSpecs of fss that aren't in
+// templates, e.g. spec/template/metadata/labels or
+// spec/volumeClaimTemplates[]/metadata/labels.
+func withoutTemplates(fss types.FsSlice) types.FsSlice {
+	var result types.FsSlice
+	for _, fs := range fss {
+		if !isTemplatePath(fs.Path) {
+			result = append(result, fs)
+		}
+	}
+	return result
+}
+
+func isTemplatePath(path string) bool {
+	for _, field := range strings.Split(path, "/") {
+		field = strings.ToLower(strings.TrimSuffix(field, "[]"))
+		if strings.HasSuffix(field, "template") || strings.HasSuffix(field, "templates") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filters/replacement"
+	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// substitution is a substitution of the kustomization along with the
+// value read from its environment variable or file, or set by an
+// override or passed to an input, if any.
+type substitution struct {
+	types.Substitution
+	value *string
+}
+
+// substitutionTransformer writes the values of substitutions to their
+// targets, reading values from resources at transformation time.
+type substitutionTransformer struct {
+	substitutions []substitution
+}
+
+var _ resmap.Transformer = &substitutionTransformer{}
+
+// configureSubstitutions validates the substitutions of the kustomization
+// and reads the values of those overridden or sourced from environment
+// variables, files and inputs.
+func (kt *KustTarget) configureSubstitutions() (*substitutionTransformer, error) {
+	t := &substitutionTransformer{}
+	seen := map[string]bool{}
+	for _, s := range kt.kustomization.Substitutions {
+		if s.Name == "" {
+			return nil, fmt.Errorf("substitutions must specify a name")
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("substitution %q is declared more than once", s.Name)
+		}
+		seen[s.Name] = true
+		switch s.Type {
+		case "", types.StringSubstitutionType, types.IntSubstitutionType, types.BoolSubstitutionType:
+		default:
+			return nil, fmt.Errorf(
+				"substitution %q has illegal type %q; legal types: %v", s.Name, s.Type,
+				[]types.SubstitutionType{types.StringSubstitutionType, types.IntSubstitutionType, types.BoolSubstitutionType})
+		}
+		if len(s.Targets) == 0 {
+			return nil, fmt.Errorf("substitution %q must specify at least one target", s.Name)
+		}
+
+		sub := substitution{Substitution: s}
+		if v, ok := kt.overrides.substitution(s.Name); ok {
+			sub.value = &v
+		} else if src := s.Source; src != nil {
+			n := 0
+			for _, set := range []bool{src.Resource != nil, src.Env != "", src.File != "", src.Input != ""} {
+				if set {
+					n++
+				}
+			}
+			if n > 1 {
+				return nil, fmt.Errorf("substitution %q must specify at most one of resource, env, file and input", s.Name)
+			}
+			if src.Env != "" {
+				if !utils.StringSliceContains(kt.allowedEnv, src.Env) {
+					return nil, fmt.Errorf(
+						"substitution %q reads environment variable %s, which the build doesn't allow", s.Name, src.Env)
+				}
+				if v, ok := os.LookupEnv(src.Env); ok {
+					sub.value = &v
+				}
+			}
+			if src.File != "" {
+				b, err := kt.ldr.Load(src.File)
+				if err != nil {
+					return nil, fmt.Errorf("substitution %q: %w", s.Name, err)
+				}
+				v := strings.TrimSpace(string(b))
+				sub.value = &v
+			}
+			if src.Input != "" {
+				if !kt.declaresInput(src.Input) {
+					return nil, fmt.Errorf("substitution %q reads undeclared input %q", s.Name, src.Input)
+				}
+				if v, ok := kt.inputs[src.Input]; ok {
+					sub.value = &v
+				}
+			}
+		}
+		t.substitutions = append(t.substitutions, sub)
+	}
+	return t, nil
+}
+
+// Transform writes the value of each substitution to its targets.
+func (t *substitutionTransformer) Transform(m resmap.ResMap) error {
+	return m.ApplyFilter(t)
+}
+
+// Filter writes the value of each substitution to its targets.
+func (t *substitutionTransformer) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	for _, s := range t.substitutions {
+		value, err := s.node(nodes)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		nodes, err = replacement.ValueFilter{Value: value, Targets: s.Targets}.Filter(nodes)
+		if err != nil {
+			return nil, fmt.Errorf("substitution %q: %w", s.Name, err)
+		}
+	}
+	return nodes, nil
+}
+
+// node returns the value of the substitution as a node of its type, or
+// nil if it has no value and isn't required.
+func (s *substitution) node(nodes []*yaml.RNode) (*yaml.RNode, error) {
+	value := s.value
+	if value == nil && s.Source != nil && s.Source.Resource != nil {
+		rn, err := replacement.SourceValue(nodes, s.Source.Resource)
+		if err != nil && s.Default == nil {
+			return nil, fmt.Errorf("substitution %q: %w", s.Name, err)
+		}
+		if err == nil {
+			if rn.YNode().Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("substitution %q: source %s is not a scalar", s.Name, s.Source.Resource)
+			}
+			v := rn.YNode().Value
+			value = &v
+		}
+	}
+	if value == nil {
+		value = s.Default
+	}
+	if value == nil {
+		if s.Required {
+			return nil, fmt.Errorf("substitution %q is required but has no value", s.Name)
+		}
+		return nil, nil
+	}
+
+	switch s.Type {
+	case types.IntSubstitutionType:
+		if _, err := strconv.ParseInt(*value, 10, 64); err != nil {
+			return nil, fmt.Errorf("substitution %q: %q is not an int", s.Name, *value)
+		}
+		return scalarNode(*value, yaml.NodeTagInt), nil
+	case types.BoolSubstitutionType:
+		b, err := strconv.ParseBool(*value)
+		if err != nil {
+			return nil, fmt.Errorf("substitution %q: %q is not a bool", s.Name, *value)
+		}
+		return scalarNode(strconv.FormatBool(b), yaml.NodeTagBool), nil
+	default:
+		return yaml.NewStringRNode(*value), nil
+	}
+}
+
+func scalarNode(value, tag string) *yaml.RNode {
+	rn := yaml.NewScalarRNode(value)
+	rn.YNode().Tag = tag
+	return rn
+}
+
+// overrides are values set at build time that take precedence over the
+// kustomization files.
+type overrides struct {
+	// substitutions holds values by substitution name.
+	substitutions map[string]string
+	// used records the substitutions declared by any kustomization.
+	used map[string]bool
+}
+
+// substitution returns the value overriding the named substitution.
+func (o *overrides) substitution(name string) (string, bool) {
+	if o == nil {
+		return "", false
+	}
+	v, ok := o.substitutions[name]
+	if ok {
+		o.used[name] = true
+	}
+	return v, ok
+}
+
+// SetAllowedEnv sets the names of the environment variables that the
+// build may read, in substitutions or as parameters. The kustomizations,
+// remote bases included, can't read any other, lest they leak the
+// environment of the build into its output.
+func (kt *KustTarget) SetAllowedEnv(names []string) {
+	kt.allowedEnv = names
+}
+
+// SetOverrides sets values that take precedence over those of the
+// kustomization files. The key "namespace" sets the namespace and a key
+// "commonLabels.<label>" sets a common label of this kustomization. Any
+// other key is the name of a substitution, in this kustomization or any
+// it accumulates, whose value is replaced, or of a parameter read by
+// conditions or the namespace. It must be called after Load.
+func (kt *KustTarget) SetOverrides(values map[string]string) {
+	kt.overrides = &overrides{substitutions: map[string]string{}, used: map[string]bool{}}
+	for key, v := range values {
+		switch {
+		case key == "namespace":
+			kt.kustomization.Namespace = v
+		case strings.HasPrefix(key, overrideCommonLabelsPrefix):
+			if kt.kustomization.CommonLabels == nil {
+				kt.kustomization.CommonLabels = map[string]string{}
+			}
+			kt.kustomization.CommonLabels[strings.TrimPrefix(key, overrideCommonLabelsPrefix)] = v
+		default:
+			kt.overrides.substitutions[key] = v
+		}
+	}
+}
+
+const overrideCommonLabelsPrefix = "commonLabels."
+
+// UnusedOverrides returns the sorted names of the substitutions set by
+// SetOverrides that no kustomization of the build declares or reads
+// in a condition.
+func (kt *KustTarget) UnusedOverrides() []string {
+	if kt.overrides == nil {
+		return nil
+	}
+	var unused []string
+	for name := range kt.overrides.substitutions {
+		if !kt.overrides.used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
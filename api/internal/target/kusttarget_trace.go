@@ -0,0 +1,60 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/trace"
+)
+
+// SetTrace sets the trace recording the changes each generator and
+// transformer of the build makes.
+func (kt *KustTarget) SetTrace(t *trace.Trace) {
+	kt.trace = t
+}
+
+// traceStep returns the step of plugin, of the given type, run by the
+// kustomization at root and configured per origin, if not nil.
+func traceStep(root, typ string, plugin interface{}, origin *resource.Origin) trace.Step {
+	step := trace.Step{
+		Kustomization: root,
+		Type:          typ,
+		Plugin:        strings.TrimPrefix(fmt.Sprintf("%T", plugin), "*"),
+	}
+	if origin != nil {
+		step.ConfiguredIn = origin.ConfiguredIn
+		if origin.ConfiguredBy.Kind != "" {
+			step.Plugin = origin.ConfiguredBy.Kind
+			if origin.ConfiguredBy.Name != "" {
+				step.Plugin += "/" + origin.ConfiguredBy.Name
+			}
+		}
+	}
+	return step
+}
+
+// tracedFunc is a transformer running fn, recording the changes it
+// makes as step in trace.
+type tracedFunc struct {
+	trace *trace.Trace
+	step  trace.Step
+	fn    func(resmap.ResMap) error
+}
+
+var _ resmap.Transformer = tracedFunc{}
+
+func (t tracedFunc) Transform(m resmap.ResMap) error {
+	s, err := t.trace.Begin(m)
+	if err != nil {
+		return err
+	}
+	if err = t.fn(m); err != nil {
+		return err
+	}
+	return t.trace.End(s, t.step, m)
+}
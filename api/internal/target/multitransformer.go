@@ -5,17 +5,22 @@ package target
 
 import (
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/trace"
 )
 
 // multiTransformer contains a list of transformers.
 type multiTransformer struct {
 	transformers []*resmap.TransformerWithProperties
+	// trace, if set, records the changes of each transformer
+	// of the kustomization at root.
+	trace *trace.Trace
+	root  string
 }
 
 var _ resmap.Transformer = &multiTransformer{}
 
 // newMultiTransformer constructs a multiTransformer.
-func newMultiTransformer(t []*resmap.TransformerWithProperties) resmap.Transformer {
+func newMultiTransformer(t []*resmap.TransformerWithProperties) *multiTransformer {
 	r := &multiTransformer{
 		transformers: make([]*resmap.TransformerWithProperties, len(t)),
 	}
@@ -27,7 +32,12 @@ func newMultiTransformer(t []*resmap.TransformerWithProperties) resmap.Transform
 // optionally detecting and erroring on commutation conflict.
 func (o *multiTransformer) Transform(m resmap.ResMap) error {
 	for _, t := range o.transformers {
-		if err := t.Transform(m); err != nil {
+		err := tracedFunc{
+			trace: o.trace,
+			step:  traceStep(o.root, trace.Transformer, t.Transformer, t.Origin),
+			fn:    t.Transform,
+		}.Transform(m)
+		if err != nil {
 			return err
 		}
 		if t.Origin != nil {
@@ -15,6 +15,7 @@ const (
 	BuildAnnotationsRefBy             = konfig.ConfigAnnoDomain + "/refBy"
 	BuildAnnotationsGenBehavior       = konfig.ConfigAnnoDomain + "/generatorBehavior"
 	BuildAnnotationsGenAddHashSuffix  = konfig.ConfigAnnoDomain + "/needsHashSuffix"
+	BuildAnnotationsGenPrune          = konfig.ConfigAnnoDomain + "/needsPruneAnnotation"
 
 	// the following are only for patches, to specify whether they can change names
 	// and kinds of their targets
@@ -25,5 +26,8 @@ const (
 	OriginAnnotationKey      = "config.kubernetes.io/origin"
 	TransformerAnnotationKey = "alpha.config.kubernetes.io/transformations"
 
+	// for keeping track of the build that output a resource
+	BuildProvenanceAnnotationKey = "alpha.config.kubernetes.io/build-provenance"
+
 	Enabled = "enabled"
 )
@@ -0,0 +1,44 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package konfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/konfig/builtinpluginconsts"
+)
+
+// DefaultFieldSpecNames returns the names of the builtin
+// transformer configurations, e.g. namereference, sorted.
+func DefaultFieldSpecNames() []string {
+	var names []string
+	for name := range builtinpluginconsts.GetDefaultFieldSpecsAsMap() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultFieldSpecs returns the builtin configuration of the named
+// transformers, or of all of them if none are named, in the format
+// of the files listed in the configurations field of a kustomization.
+func DefaultFieldSpecs(names ...string) (string, error) {
+	if len(names) == 0 {
+		return string(builtinpluginconsts.GetDefaultFieldSpecs()), nil
+	}
+	m := builtinpluginconsts.GetDefaultFieldSpecsAsMap()
+	var configs []string
+	for _, name := range names {
+		c, ok := m[strings.ToLower(name)]
+		if !ok {
+			return "", fmt.Errorf(
+				"no builtin transformer configuration named %q; legal names: %v",
+				name, DefaultFieldSpecNames())
+		}
+		configs = append(configs, c)
+	}
+	return strings.Join(configs, "\n"), nil
+}
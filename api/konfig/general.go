@@ -46,4 +46,9 @@ const (
 
 	// Label key that indicates the resources are validated by a validator
 	ValidatedByLabelKey = "validated-by"
+
+	// Annotations of generated resources with the pruneAnnotation
+	// option, holding their name without its hash suffix and the hash.
+	GeneratedNameAnnotation = "kustomize.config.k8s.io/generated-name"
+	GeneratedHashAnnotation = "kustomize.config.k8s.io/generated-hash"
 )
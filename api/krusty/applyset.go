@@ -0,0 +1,119 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	applySetPartOfLabel          = "applyset.kubernetes.io/part-of"
+	applySetIDLabel              = "applyset.kubernetes.io/id"
+	applySetToolingAnnotation    = "applyset.kubernetes.io/tooling"
+	applySetGroupKindsAnnotation = "applyset.kubernetes.io/contains-group-kinds"
+	applySetNamespacesAnnotation = "applyset.kubernetes.io/additional-namespaces"
+)
+
+// ApplySetID returns the ID of the ApplySet whose parent is the
+// object of the given kind, group, name and namespace, as kubectl
+// computes it.
+func ApplySetID(kind, group, name, namespace string) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{name, namespace, kind, group}, ".")))
+	return fmt.Sprintf("applyset-%s-v1", base64.RawURLEncoding.EncodeToString(hash[:]))
+}
+
+// addApplySet labels the resources of m as the members of the ApplySet
+// of s, and adds its parent, listing their kinds and namespaces, to m.
+func addApplySet(m resmap.ResMap, s *types.ApplySet, rf *resource.Factory) error {
+	kind := s.Kind
+	if kind == "" {
+		kind = "Secret"
+	}
+	if kind != "Secret" && kind != "ConfigMap" {
+		return fmt.Errorf("ApplySet parent must be a Secret or a ConfigMap, not a %s", kind)
+	}
+	if s.Name == "" {
+		return fmt.Errorf("ApplySet parent must have a name")
+	}
+	tooling := s.Tooling
+	if tooling == "" {
+		tooling = types.DefaultApplySetTooling
+	}
+
+	namespaces := map[string]bool{}
+	groupKinds := map[string]bool{}
+	for _, r := range m.Resources() {
+		gvk := r.GetGvk()
+		if gvk.Kind == kind && gvk.Group == "" && r.GetName() == s.Name {
+			return fmt.Errorf("ApplySet parent %s %s is already a resource of the build", kind, s.Name)
+		}
+		gk := gvk.Kind
+		if gvk.Group != "" {
+			gk += "." + gvk.Group
+		}
+		groupKinds[gk] = true
+		if ns := r.GetNamespace(); ns != "" && !gvk.IsClusterScoped() {
+			namespaces[ns] = true
+		}
+	}
+	namespace := s.Namespace
+	if namespace == "" {
+		switch len(namespaces) {
+		case 0:
+			return fmt.Errorf("ApplySet parent %s %s must have a namespace", kind, s.Name)
+		case 1:
+			for ns := range namespaces {
+				namespace = ns
+			}
+		default:
+			return fmt.Errorf(
+				"ApplySet parent %s %s must have a namespace; its members are in %s",
+				kind, s.Name, strings.Join(sortedKeys(namespaces), ", "))
+		}
+	}
+	delete(namespaces, namespace)
+
+	id := ApplySetID(kind, "", s.Name, namespace)
+	for _, r := range m.Resources() {
+		labels := r.GetLabels()
+		labels[applySetPartOfLabel] = id
+		if err := r.SetLabels(labels); err != nil {
+			return err
+		}
+	}
+	annotations := map[string]interface{}{
+		applySetToolingAnnotation:    tooling,
+		applySetGroupKindsAnnotation: strings.Join(sortedKeys(groupKinds), ","),
+	}
+	if len(namespaces) > 0 {
+		annotations[applySetNamespacesAnnotation] = strings.Join(sortedKeys(namespaces), ",")
+	}
+	return m.Append(rf.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":        s.Name,
+			"namespace":   namespace,
+			"labels":      map[string]interface{}{applySetIDLabel: id},
+			"annotations": annotations,
+		},
+	}))
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
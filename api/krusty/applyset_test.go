@@ -0,0 +1,147 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeApplySetResources(th kusttest_test.Harness) {
+	th.WriteK(".", `
+resources:
+- resources.yaml
+`)
+	th.WriteF("resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: test
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: test
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shared
+  namespace: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`)
+}
+
+func TestApplySetID(t *testing.T) {
+	// the ID kubectl computes for the same parent
+	require.Equal(t, "applyset-0eFHV8ySqp7XoShsGvyWFQD3s96yqwHmzc4e0HR1dsY-v1",
+		krusty.ApplySetID("Secret", "", "my-set", "test"))
+}
+
+func TestApplySet(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeApplySetResources(th)
+	opts := th.MakeDefaultOptions()
+	opts.ApplySet = &types.ApplySet{Name: "my-set", Namespace: "test"}
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    applyset.kubernetes.io/part-of: applyset-0eFHV8ySqp7XoShsGvyWFQD3s96yqwHmzc4e0HR1dsY-v1
+  name: web
+  namespace: test
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    applyset.kubernetes.io/part-of: applyset-0eFHV8ySqp7XoShsGvyWFQD3s96yqwHmzc4e0HR1dsY-v1
+  name: web
+  namespace: test
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    applyset.kubernetes.io/part-of: applyset-0eFHV8ySqp7XoShsGvyWFQD3s96yqwHmzc4e0HR1dsY-v1
+  name: shared
+  namespace: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  labels:
+    applyset.kubernetes.io/part-of: applyset-0eFHV8ySqp7XoShsGvyWFQD3s96yqwHmzc4e0HR1dsY-v1
+  name: reader
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  annotations:
+    applyset.kubernetes.io/additional-namespaces: other
+    applyset.kubernetes.io/contains-group-kinds: ClusterRole.rbac.authorization.k8s.io,ConfigMap,Deployment.apps,Service
+    applyset.kubernetes.io/tooling: kubectl/v1.27.0
+  labels:
+    applyset.kubernetes.io/id: applyset-0eFHV8ySqp7XoShsGvyWFQD3s96yqwHmzc4e0HR1dsY-v1
+  name: my-set
+  namespace: test
+`)
+}
+
+func TestApplySetNamespace(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeApplySetResources(th)
+	opts := th.MakeDefaultOptions()
+	opts.ApplySet = &types.ApplySet{Name: "my-set"}
+	err := th.RunWithErr(".", opts)
+	require.Contains(t, err.Error(),
+		"ApplySet parent Secret my-set must have a namespace; its members are in other, test")
+
+	opts.ApplySet = &types.ApplySet{Kind: "Deployment", Name: "my-set"}
+	err = th.RunWithErr(".", opts)
+	require.Contains(t, err.Error(), "ApplySet parent must be a Secret or a ConfigMap, not a Deployment")
+}
+
+func TestPruneLabels(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- service.yaml
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+`)
+	opts := th.MakeDefaultOptions()
+	opts.PruneLabels = map[string]string{"prune.example.com/set": "web"}
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    prune.example.com/set: web
+  name: web
+spec:
+  selector:
+    app: web
+`)
+}
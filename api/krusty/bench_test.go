@@ -0,0 +1,101 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// makeBenchmarkFs writes a base of n deployments, and an overlay
+// running a pipeline stage, which works on copies of the resources, with
+// the given transformer.
+func makeBenchmarkFs(b *testing.B, n int, transformer string) filesys.FileSystem {
+	b.Helper()
+	fSys := filesys.MakeFsInMemory()
+	var resources strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&resources, `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app-%d
+  labels:
+    app: app-%d
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.25
+        ports:
+        - containerPort: 80
+`, i, i)
+	}
+	for path, content := range map[string]string{
+		"base/resources.yaml":      resources.String(),
+		"base/kustomization.yaml":  "resources:\n- resources.yaml\n",
+		"overlay/transformer.yaml": transformer,
+		"overlay/kustomization.yaml": `resources:
+- ../base
+pipeline:
+- name: stage
+  transformer: transformer.yaml
+  select:
+    kind: Deployment
+`,
+	} {
+		if err := fSys.WriteFile(path, []byte(content)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return fSys
+}
+
+func runBenchmarkBuild(b *testing.B, fSys filesys.FileSystem) {
+	b.Helper()
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := k.Run(fSys, "overlay"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBuildChangeOne builds 10k resources through a pipeline stage
+// patching one of them, so that copy-on-write saves copying the others.
+func BenchmarkBuildChangeOne(b *testing.B) {
+	runBenchmarkBuild(b, makeBenchmarkFs(b, 10000, `apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: patch
+target:
+  name: app-0
+patch: |-
+  - op: replace
+    path: /spec/replicas
+    value: 2
+`))
+}
+
+// BenchmarkBuildChangeAll builds 10k resources through a pipeline stage
+// labelling all of them, copy-on-write's worst case.
+func BenchmarkBuildChangeAll(b *testing.B) {
+	runBenchmarkBuild(b, makeBenchmarkFs(b, 10000, `apiVersion: builtin
+kind: LabelTransformer
+metadata:
+  name: label
+labels:
+  stage: one
+fieldSpecs:
+- path: metadata/labels
+  create: true
+`))
+}
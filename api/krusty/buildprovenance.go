@@ -0,0 +1,50 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/provenance"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// buildProvenance is the value of the build provenance annotation.
+type buildProvenance struct {
+	// Kustomization is the path or URL of the kustomization built.
+	Kustomization string `json:"kustomization" yaml:"kustomization"`
+
+	// Commit is the git commit checked out in the work tree holding
+	// the kustomization, if any.
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+
+	// KustomizeVersion is the version of kustomize that built it.
+	KustomizeVersion string `json:"kustomizeVersion" yaml:"kustomizeVersion"`
+}
+
+// addBuildProvenance annotates the resources of m with the provenance of
+// the build of the kustomization at path, which ldr loads.
+func addBuildProvenance(m resmap.ResMap, path string, ldr ifc.Loader) error {
+	p := buildProvenance{
+		Kustomization:    path,
+		KustomizeVersion: provenance.GetProvenance().Semver(),
+	}
+	// the kustomization needn't be in a git work tree, or on disk
+	p.Commit, _ = git.HeadCommit(ldr.Root())
+	anno, err := yaml.Marshal(p)
+	if err != nil {
+		return errors.WrapPrefixf(err, "failed to marshal build provenance")
+	}
+	for _, r := range m.Resources() {
+		annotations := r.GetAnnotations()
+		annotations[utils.BuildProvenanceAnnotationKey] = string(anno)
+		if err = r.SetAnnotations(annotations); err != nil {
+			return errors.WrapPrefixf(err, "failed to annotate %s with build provenance", r.CurId())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/provenance"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestBuildProvenanceLocal(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: myService
+`)
+	th.WriteK(".", `
+resources:
+- service.yaml
+buildMetadata: [buildProvenance]
+namePrefix: foo-
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    alpha.config.kubernetes.io/build-provenance: |
+      kustomization: .
+      kustomizeVersion: `+provenance.GetProvenance().Semver()+`
+    alpha.config.kubernetes.io/transformations: |
+      - configuredIn: kustomization.yaml
+        configuredBy:
+          apiVersion: builtin
+          kind: PrefixTransformer
+    config.kubernetes.io/origin: |
+      path: service.yaml
+  name: foo-myService
+`)
+}
+
+func TestBuildProvenanceOption(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: myService
+`)
+	th.WriteK("base", `
+resources:
+- service.yaml
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+`)
+	options := th.MakeDefaultOptions()
+	options.AddBuildProvenance = true
+	m := th.Run("overlay", options)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    alpha.config.kubernetes.io/build-provenance: |
+      kustomization: overlay
+      kustomizeVersion: `+provenance.GetProvenance().Semver()+`
+    config.kubernetes.io/origin: |
+      path: ../base/service.yaml
+  name: myService
+`)
+}
+
+func TestBuildProvenanceGitCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "kustomization.yaml"), []byte(`
+configMapGenerator:
+- name: cm
+  literals:
+  - a=b
+`), 0o600))
+	var commit string
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"commit", "--quiet", "-m", "first"},
+		{"rev-parse", "HEAD"},
+	} {
+		cmd := exec.Command("git", append([]string{
+			"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		commit = strings.TrimSpace(string(out))
+	}
+
+	options := krusty.MakeDefaultOptions()
+	options.AddBuildProvenance = true
+	m, err := krusty.MakeKustomizer(options).Run(filesys.MakeFsOnDisk(), repo)
+	require.NoError(t, err)
+	require.Equal(t, `kustomization: `+repo+`
+commit: `+commit+`
+kustomizeVersion: `+provenance.GetProvenance().Semver()+`
+`, m.Resources()[0].GetAnnotations()[utils.BuildProvenanceAnnotationKey])
+}
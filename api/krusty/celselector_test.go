@@ -0,0 +1,164 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeCelSelectorResources(th kusttest_test.Harness) {
+	th.WriteF("resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: web
+spec:
+  replicas: 5
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: small
+  labels:
+    tier: web
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  labels:
+    tier: web
+data:
+  owner: team-a
+`)
+}
+
+func TestPatchTargetCelExpression(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeCelSelectorResources(th)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+patches:
+- target:
+    celExpression: resource.spec.replicas > 3 && resource.metadata.labels['tier'] == 'web'
+  patch: |-
+    - op: add
+      path: /metadata/annotations
+      value:
+        autoscaled: "true"
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    autoscaled: "true"
+  labels:
+    tier: web
+  name: web
+spec:
+  replicas: 5
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: small
+spec:
+  replicas: 1
+---
+apiVersion: v1
+data:
+  owner: team-a
+kind: ConfigMap
+metadata:
+  labels:
+    tier: web
+  name: settings
+`)
+}
+
+func TestReplacementTargetCelExpression(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeCelSelectorResources(th)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+replacements:
+- source:
+    kind: ConfigMap
+    name: settings
+    fieldPath: data.owner
+  targets:
+  - select:
+      kind: Deployment
+      celExpression: size(resource.metadata.name) > 2
+    reject:
+    - celExpression: resource.spec.replicas < 2
+    fieldPaths:
+    - metadata.annotations.owner
+    options:
+      create: true
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    owner: team-a
+  labels:
+    tier: web
+  name: web
+spec:
+  replicas: 5
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: small
+spec:
+  replicas: 1
+---
+apiVersion: v1
+data:
+  owner: team-a
+kind: ConfigMap
+metadata:
+  labels:
+    tier: web
+  name: settings
+`)
+}
+
+func TestPatchTargetInvalidCelExpression(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeCelSelectorResources(th)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+patches:
+- target:
+    celExpression: resource.spec.replicas >
+  patch: |-
+    - op: remove
+      path: /spec/replicas
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid CEL expression "resource.spec.replicas >"`)
+}
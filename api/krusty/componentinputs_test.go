@@ -0,0 +1,159 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writePVCComponent(th kusttest_test.Harness) {
+	th.WriteF("pvc/pvc.yaml", `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+spec:
+  accessModes:
+  - ReadWriteOnce
+  resources:
+    requests:
+      storage: 1Gi
+`)
+	th.WriteF("pvc/backup.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: backup
+`)
+	th.WriteF("pvc/kustomization.yaml", `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+inputs:
+- name: size
+  required: true
+- name: storageClass
+  default: standard
+- name: backup
+  type: bool
+resources:
+- pvc.yaml
+conditionalResources:
+- path: backup.yaml
+  condition: backup
+substitutions:
+- name: size
+  source:
+    input: size
+  targets:
+  - select:
+      kind: PersistentVolumeClaim
+    fieldPaths:
+    - spec.resources.requests.storage
+- name: storageClass
+  source:
+    input: storageClass
+  targets:
+  - select:
+      kind: PersistentVolumeClaim
+    fieldPaths:
+    - spec.storageClassName
+    options:
+      create: true
+`)
+}
+
+func TestComponentInputs(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePVCComponent(th)
+	th.WriteK("overlay", `
+components:
+- ../pvc
+componentInputs:
+  ../pvc:
+    size: 10Gi
+    backup: true
+`)
+	m := th.Run("overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+spec:
+  accessModes:
+  - ReadWriteOnce
+  resources:
+    requests:
+      storage: 10Gi
+  storageClassName: standard
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: backup
+`)
+}
+
+func TestComponentInputsErrors(t *testing.T) {
+	testCases := map[string]struct {
+		kustomization string
+		expectedErr   string
+	}{
+		"required": {
+			kustomization: `
+components:
+- ../pvc
+`,
+			expectedErr: `is required but has no value`,
+		},
+		"type": {
+			kustomization: `
+components:
+- ../pvc
+componentInputs:
+  ../pvc:
+    size: 10Gi
+    backup: 3
+`,
+			expectedErr: `is not a bool`,
+		},
+		"unknown input": {
+			kustomization: `
+components:
+- ../pvc
+componentInputs:
+  ../pvc:
+    size: 10Gi
+    sise: 20Gi
+`,
+			expectedErr: `no inputs named [sise]`,
+		},
+		"unknown component": {
+			kustomization: `
+components:
+- ../pvc
+componentInputs:
+  ../pvc:
+    size: 10Gi
+  ../other:
+    size: 10Gi
+`,
+			expectedErr: `inputs passed to ../other, which is not a component of this kustomization`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writePVCComponent(th)
+			th.WriteK("overlay", tc.kustomization)
+			err := th.RunWithErr("overlay", th.MakeDefaultOptions())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeConditionalOverlay(th kusttest_test.Harness) {
+	th.WriteF("base/app.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`)
+	th.WriteK("base", `
+resources:
+- app.yaml
+`)
+	th.WriteF("overlay/debug.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: debug
+`)
+	th.WriteF("monitoring/monitor.yaml", `
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: app
+`)
+	th.WriteF("monitoring/kustomization.yaml", `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+resources:
+- monitor.yaml
+commonAnnotations:
+  monitored: "true"
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+conditionalResources:
+- path: debug.yaml
+  condition: env != prod && !(KUSTOMIZE_TEST_QUIET)
+conditionalComponents:
+- path: ../monitoring
+  condition: monitoring
+`)
+}
+
+func TestConditionalResources(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConditionalOverlay(th)
+	opts := th.MakeDefaultOptions()
+
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: debug
+`)
+
+	opts.Overrides = map[string]string{"env": "prod", "monitoring": "true"}
+	m = th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    monitored: "true"
+  name: app
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  annotations:
+    monitored: "true"
+  name: app
+`)
+
+	// the environment variable is read only if the build allows it
+	t.Setenv("KUSTOMIZE_TEST_QUIET", "true")
+	opts = th.MakeDefaultOptions()
+	m = th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: debug
+`)
+
+	opts.AllowedEnv = []string{"KUSTOMIZE_TEST_QUIET"}
+	m = th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`)
+}
+
+func TestConditionalResourcesErrors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConditionalOverlay(th)
+	opts := th.MakeDefaultOptions()
+
+	opts.Overrides = map[string]string{"monitoring": "yes"}
+	err := th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		`condition of ../monitoring: parameter monitoring is "yes", not a bool; compare it with == instead`)
+
+	th.WriteK("bad", `
+conditionalResources:
+- path: debug.yaml
+  condition: env ==
+`)
+	err = th.RunWithErr("bad", th.MakeDefaultOptions())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "condition of debug.yaml: condition ends unexpectedly")
+}
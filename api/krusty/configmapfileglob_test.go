@@ -0,0 +1,55 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestConfigMapGeneratorFileGlobsAndDirectories(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("app/config/server.conf", "port: 8080\n")
+	th.WriteF("app/config/conf.d/log.conf", "level: info\n")
+	th.WriteF("app/config/conf.d/log.conf.bak", "level: debug\n")
+	th.WriteF("app/scripts/start.sh", "exec server\n")
+	th.WriteF("app/scripts/lib/env.sh", "export A=b\n")
+	th.WriteK("app", `
+configMapGenerator:
+- name: config
+  files:
+  - config/**/*.conf
+  fileOptions:
+    stripPrefix: config
+- name: scripts
+  files:
+  - scripts
+  fileOptions:
+    exclude:
+    - scripts/lib/**
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	m := th.Run("app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  conf.d_log.conf: |
+    level: info
+  server.conf: |
+    port: 8080
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+data:
+  start.sh: |
+    exec server
+kind: ConfigMap
+metadata:
+  name: scripts
+`)
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestConfigMapGeneratorTemplateLiterals(t *testing.T) {
+	t.Setenv("CLUSTER", "eu-1")
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("prod", `
+namespace: shop
+configMapGenerator:
+- name: db
+  literals:
+  - host=db
+- name: app
+  templateLiterals: true
+  literals:
+  - ENDPOINT=https://api.{{ .Namespace }}.svc
+  - DB_URL=postgres://{{ .ConfigMaps.db.host }}.{{ .Namespace }}.svc:5432
+  - PROFILE={{ .Overlay }}-{{ .Env.CLUSTER }}
+  - RAW={{ "{{" }} .Values {{ "}}" }}
+- name: plain
+  literals:
+  - RAW={{ .Values }}
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	opts := th.MakeDefaultOptions()
+	opts.AllowedEnv = []string{"CLUSTER"}
+	m := th.Run("prod", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  host: db
+kind: ConfigMap
+metadata:
+  name: db
+  namespace: shop
+---
+apiVersion: v1
+data:
+  DB_URL: postgres://db.shop.svc:5432
+  ENDPOINT: https://api.shop.svc
+  PROFILE: prod-eu-1
+  RAW: '{{ .Values }}'
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: shop
+---
+apiVersion: v1
+data:
+  RAW: '{{ .Values }}'
+kind: ConfigMap
+metadata:
+  name: plain
+  namespace: shop
+`)
+}
+
+func TestConfigMapGeneratorTemplateLiteralsEnvNotAllowed(t *testing.T) {
+	t.Setenv("CLUSTER", "eu-1")
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+configMapGenerator:
+- name: app
+  templateLiterals: true
+  literals:
+  - CLUSTER={{ .Env.CLUSTER }}
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "configMapGenerator app")
+	assert.Contains(t, err.Error(), `map has no entry for key "CLUSTER"`)
+}
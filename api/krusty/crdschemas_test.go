@@ -0,0 +1,172 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// Each test uses a group of its own, as the schemas are global.
+func crdWithListMap(group string) string {
+	return strings.ReplaceAll(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gateways.GROUP
+spec:
+  group: GROUP
+  scope: Namespaced
+  names:
+    kind: Gateway
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              listeners:
+                type: array
+                x-kubernetes-list-type: map
+                x-kubernetes-list-map-keys:
+                - name
+                items:
+                  type: object
+`, "GROUP", group)
+}
+
+func writeGateway(th kusttest_test.Harness, group string) {
+	th.WriteF("gateway.yaml", strings.ReplaceAll(`
+apiVersion: GROUP/v1
+kind: Gateway
+metadata:
+  name: gw
+spec:
+  listeners:
+  - name: http
+    port: 80
+  - name: https
+    port: 443
+`, "GROUP", group))
+	th.WriteF("patch.yaml", strings.ReplaceAll(`
+apiVersion: GROUP/v1
+kind: Gateway
+metadata:
+  name: gw
+spec:
+  listeners:
+  - name: https
+    port: 8443
+`, "GROUP", group))
+}
+
+const mergedGateway = `
+apiVersion: GROUP/v1
+kind: Gateway
+metadata:
+  name: gw
+spec:
+  listeners:
+  - name: https
+    port: 8443
+  - name: http
+    port: 80
+`
+
+func TestCrdSchemasReplaceListsWithoutSchema(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeGateway(th, "noschema.example.com")
+	th.WriteK(".", `
+resources:
+- gateway.yaml
+patches:
+- path: patch.yaml
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: noschema.example.com/v1
+kind: Gateway
+metadata:
+  name: gw
+spec:
+  listeners:
+  - name: https
+    port: 8443
+`)
+}
+
+func TestCrdSchemasFromFile(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeGateway(th, "file.example.com")
+	th.WriteF("crds/gateway.yaml", crdWithListMap("file.example.com"))
+	th.WriteF("crds/README.md", "not a CRD")
+	th.WriteK(".", `
+openapi:
+  crds: crds
+resources:
+- gateway.yaml
+patches:
+- path: patch.yaml
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, strings.ReplaceAll(mergedGateway, "GROUP", "file.example.com"))
+}
+
+func TestCrdSchemasFromSchemaDir(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeGateway(th, "dir.example.com")
+	th.WriteF("/schemas/gateway.yaml", crdWithListMap("dir.example.com"))
+	th.WriteK(".", `
+resources:
+- gateway.yaml
+patches:
+- path: patch.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	opts.CrdSchemaDir = "/schemas"
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, strings.ReplaceAll(mergedGateway, "GROUP", "dir.example.com"))
+}
+
+func TestCrdSchemasFromCluster(t *testing.T) {
+	// kubectl prints a List of the CRDs
+	crd := strings.TrimPrefix(crdWithListMap("cluster.example.com"), "\n")
+	list := "apiVersion: v1\nkind: List\nitems:\n- " +
+		strings.ReplaceAll(strings.TrimSuffix(crd, "\n"), "\n", "\n  ") + "\n"
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crds.yaml"), []byte(list), 0o600))
+	kubectl := filepath.Join(dir, "kubectl")
+	require.NoError(t, os.WriteFile(kubectl, []byte(`#!/bin/sh
+[ "$*" = "get customresourcedefinitions --output yaml" ] || exit 1
+cat "$(dirname "$0")/crds.yaml"
+`), 0o700))
+
+	th := kusttest_test.MakeHarness(t)
+	writeGateway(th, "cluster.example.com")
+	th.WriteK(".", `
+openapi:
+  crds: cluster
+resources:
+- gateway.yaml
+patches:
+- path: patch.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	opts.KubectlCommand = kubectl
+	err := th.RunWithErr(".", opts)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "must specify --enable-cluster-crds to read the CRDs of the cluster")
+
+	opts.EnableClusterCrds = true
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, strings.ReplaceAll(mergedGateway, "GROUP", "cluster.example.com"))
+}
@@ -6,6 +6,7 @@ package krusty_test
 import (
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
 )
 
@@ -314,3 +315,139 @@ spec:
   location: Arizona
 `)
 }
+
+func TestCustomConfigAddsToDefaults(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namePrefix: dev-
+resources:
+- resources.yaml
+configurations:
+- config.yaml
+`)
+	th.WriteF("config.yaml", `
+nameReference:
+- kind: Secret
+  fieldSpecs:
+  - kind: Application
+    group: argoproj.io
+    path: spec/source/helm/valuesSecret
+`)
+	th.WriteF("resources.yaml", `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: values
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app
+spec:
+  source:
+    helm:
+      valuesSecret: values
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  volumes:
+  - name: values
+    secret:
+      secretName: values
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: dev-values
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: dev-app
+spec:
+  source:
+    helm:
+      valuesSecret: dev-values
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: dev-pod
+spec:
+  volumes:
+  - name: values
+    secret:
+      secretName: dev-values
+`)
+}
+
+func TestCustomConfigOverride(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+commonLabels:
+  team: a
+resources:
+- deployment.yaml
+`)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+`)
+	th.WriteK("overlay", `
+commonLabels:
+  env: dev
+resources:
+- ../base
+configurations:
+- config.yaml
+`)
+	th.WriteF("overlay/config.yaml", `
+override:
+- commonLabels
+commonLabels:
+- path: metadata/labels
+  create: true
+`)
+	m := th.Run("overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: dev
+    team: a
+  name: web
+spec:
+  selector:
+    matchLabels:
+      team: a
+  template:
+    metadata:
+      labels:
+        team: a
+    spec:
+      containers:
+      - image: nginx
+        name: web
+`)
+
+	th.WriteF("overlay/config.yaml", `
+override:
+- labels
+`)
+	err := th.RunWithErr("overlay", th.MakeDefaultOptions())
+	require.Contains(t, err.Error(), `cannot override unknown transformer config field "labels"`)
+}
@@ -507,6 +507,66 @@ func skipIfNoDocker(t *testing.T) {
 	}
 }
 
+func TestFnStarlarkTransformer(t *testing.T) {
+	fSys := filesys.MakeFsOnDisk()
+
+	th := kusttest_test.MakeHarnessWithFs(t, fSys)
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableStar = true
+
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	assert.NoError(t, err)
+	base := filepath.Join(tmpDir.String(), "base")
+	assert.NoError(t, fSys.Mkdir(base))
+	th.WriteK(base, `
+resources:
+- deployment.yaml
+transformers:
+- fn/set-replicas.yaml
+`)
+	th.WriteF(filepath.Join(base, "deployment.yaml"), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+`)
+	assert.NoError(t, fSys.Mkdir(filepath.Join(base, "fn")))
+	th.WriteF(filepath.Join(base, "fn", "set-replicas.star"), `
+def run(items, replicas):
+  for item in items:
+    if item["kind"] == "Deployment":
+      item["spec"]["replicas"] = replicas
+
+run(ctx.resource_list["items"], ctx.resource_list["functionConfig"]["spec"]["replicas"])
+`)
+	th.WriteF(filepath.Join(base, "fn", "set-replicas.yaml"), `
+apiVersion: examples.config.kubernetes.io/v1beta1
+kind: SetReplicas
+metadata:
+  name: notImportantHere
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        path: fn/set-replicas.star
+spec:
+  replicas: 3
+`)
+
+	m := th.Run(base, o)
+	yml, err := m.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+`, string(yml))
+	assert.NoError(t, fSys.RemoveAll(tmpDir.String()))
+}
+
 func TestFnContainerGenerator(t *testing.T) {
 	skipIfNoDocker(t)
 	th := kusttest_test.MakeHarness(t)
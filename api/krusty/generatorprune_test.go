@@ -0,0 +1,74 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestGeneratorImmutableWithPruneAnnotation(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+configMapGenerator:
+- name: app
+  literals:
+  - LEVEL=info
+  options:
+    immutable: true
+    pruneAnnotation: true
+secretGenerator:
+- name: creds
+  literals:
+  - password=hunter2
+- name: tls
+  literals:
+  - key=secret
+  options:
+    disableNameSuffixHash: true
+`)
+	th.WriteK("prod", `
+namePrefix: prod-
+resources:
+- ../base
+generatorOptions:
+  immutable: true
+  pruneAnnotation: true
+configMapGenerator:
+- name: app
+  behavior: merge
+  literals:
+  - LEVEL=warn
+`)
+	m := th.Run("prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  LEVEL: warn
+immutable: true
+kind: ConfigMap
+metadata:
+  annotations:
+    kustomize.config.k8s.io/generated-hash: 67794dg67c
+    kustomize.config.k8s.io/generated-name: prod-app
+  name: prod-app-67794dg67c
+---
+apiVersion: v1
+data:
+  password: aHVudGVyMg==
+kind: Secret
+metadata:
+  name: prod-creds-cf85kd65mm
+type: Opaque
+---
+apiVersion: v1
+data:
+  key: c2VjcmV0
+kind: Secret
+metadata:
+  name: prod-tls
+type: Opaque
+`)
+}
@@ -4,11 +4,13 @@
 package krusty_test
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/copyutil"
 )
 
@@ -886,6 +888,113 @@ spec:
 `)
 }
 
+// fakeHelmRenderer stands in for helm, recording the charts
+// it was asked to pull and untarring an empty chart for each.
+type fakeHelmRenderer struct {
+	pulled []string
+}
+
+func (r *fakeHelmRenderer) Pull(chart types.HelmChart, chartHome string) error {
+	r.pulled = append(r.pulled, chart.Name)
+	dir := filepath.Join(chartHome, chart.Name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("{}\n"), 0o600)
+}
+
+func (r *fakeHelmRenderer) Template(chart types.HelmChart, chartHome string) ([]byte, error) {
+	return []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ` + chart.ReleaseName + `-` + chart.Name + `
+data:
+  chartHome: ` + filepath.Base(chartHome) + `
+`), nil
+}
+
+func TestHelmChartInflationGeneratorWithRenderer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarnessWithTmpRoot(t)
+	defer th.Reset()
+
+	th.WriteK(th.GetRoot(), `
+helmCharts:
+- name: minecraft
+  repo: https://itzg.github.io/minecraft-server-charts
+  version: 3.1.3
+  releaseName: test
+`)
+
+	renderer := &fakeHelmRenderer{}
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.HelmConfig.Command = ""
+	o.PluginConfig.HelmConfig.Renderer = renderer
+	m := th.Run(th.GetRoot(), o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  chartHome: minecraft-3.1.3
+kind: ConfigMap
+metadata:
+  name: test-minecraft
+`)
+	require.Equal(t, []string{"minecraft"}, renderer.pulled)
+}
+
+// fakeHelmPull is a stand-in for the helm binary which records
+// the arguments of `helm pull` and untars an empty chart.
+const fakeHelmPull = `#!/bin/sh
+case "$1" in
+version)
+  echo v3.13.0
+  ;;
+pull)
+  echo "$@" > "$(dirname "$0")/pull-args"
+  while [ "$1" != "--untardir" ]; do shift; done
+  mkdir -p "$2/external-dns"
+  echo "{}" > "$2/external-dns/values.yaml"
+  ;;
+template)
+  printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n'
+  ;;
+esac
+`
+
+func TestHelmChartInflationGeneratorWithOciCredentialsFile(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarnessWithTmpRoot(t)
+	defer th.Reset()
+
+	binDir := t.TempDir()
+	helm := filepath.Join(binDir, "helm")
+	require.NoError(t, os.WriteFile(helm, []byte(fakeHelmPull), 0o700))
+
+	th.WriteK(th.GetRoot(), `
+helmCharts:
+- name: external-dns
+  repo: oci://registry.example.com/charts
+  version: 6.19.2
+  releaseName: test
+  credentialsFile: registry/config.json
+`)
+
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.HelmConfig.Command = helm
+	m := th.Run(th.GetRoot(), o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+`)
+	args, err := os.ReadFile(filepath.Join(binDir, "pull-args"))
+	require.NoError(t, err)
+	require.Equal(t, "pull --untar --untardir "+
+		filepath.Join(th.GetRoot(), "charts", "external-dns-6.19.2")+
+		" oci://registry.example.com/charts/external-dns --version 6.19.2"+
+		" --registry-config "+filepath.Join(th.GetRoot(), "registry", "config.json")+"\n",
+		string(args))
+}
+
 func copyValuesFilesTestChartsIntoHarness(t *testing.T, th *kusttest_test.HarnessEnhanced) {
 	t.Helper()
 
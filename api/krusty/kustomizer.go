@@ -6,6 +6,7 @@ package krusty
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"sigs.k8s.io/kustomize/api/internal/builtins"
 	fLdr "sigs.k8s.io/kustomize/api/internal/loader"
@@ -59,7 +60,7 @@ func (b *Kustomizer) Run(
 	if b.options.LoadRestrictions == types.LoadRestrictionsRootOnly {
 		lr = fLdr.RestrictionRootOnly
 	}
-	ldr, err := fLdr.NewLoader(lr, path, fSys)
+	ldr, err := fLdr.NewLoaderWithCache(lr, path, fSys, b.options.RemoteCache)
 	if err != nil {
 		return nil, err
 	}
@@ -75,15 +76,38 @@ func (b *Kustomizer) Run(
 	if err != nil {
 		return nil, err
 	}
-	var bytes []byte
-	if openApiPath, exists := kt.Kustomization().OpenAPI["path"]; exists {
-		bytes, err = ldr.Load(openApiPath)
+	if b.options.AddBuildProvenance {
+		kt.AddBuildMetadata(types.BuildProvenanceOption)
+	}
+	if len(b.options.Overrides) > 0 {
+		kt.SetOverrides(b.options.Overrides)
+	}
+	kt.SetAllowedEnv(b.options.AllowedEnv)
+	kt.SetParallel(b.options.Parallel)
+	kt.SetKubectlCommand(b.options.KubectlCommand)
+	kt.SetClusterCrdsEnabled(b.options.EnableClusterCrds)
+	kt.SetTrace(b.options.Trace)
+	openAPI, bytes, err := target.LoadOpenAPISchema(
+		ldr, kt.Kustomization().OpenAPI, b.options.KubeVersion, b.options.KubeVersionSHA256)
+	if err != nil {
+		return nil, err
+	}
+	err = openapi.SetSchema(openAPI, bytes, true)
+	if err != nil {
+		return nil, err
+	}
+	if b.options.CrdSchemaDir != "" {
+		crdLdr, err := fLdr.NewLoader(fLdr.RestrictionNone, b.options.CrdSchemaDir, fSys)
+		if err != nil {
+			return nil, err
+		}
+		err = target.AddCrdSchemas(crdLdr, filesys.SelfDir)
+		crdLdr.Cleanup()
 		if err != nil {
 			return nil, err
 		}
 	}
-	err = openapi.SetSchema(kt.Kustomization().OpenAPI, bytes, true)
-	if err != nil {
+	if err = kt.AddCrdSchemas(); err != nil {
 		return nil, err
 	}
 	var m resmap.ResMap
@@ -91,6 +115,9 @@ func (b *Kustomizer) Run(
 	if err != nil {
 		return nil, err
 	}
+	if unused := kt.UnusedOverrides(); len(unused) > 0 {
+		return nil, fmt.Errorf("no substitutions named %s to override", strings.Join(unused, ", "))
+	}
 	err = b.applySortOrder(m, kt)
 	if err != nil {
 		return nil, err
@@ -110,14 +137,37 @@ func (b *Kustomizer) Run(
 			return nil, err
 		}
 	}
+	if len(b.options.PruneLabels) > 0 {
+		t := builtins.LabelTransformerPlugin{
+			Labels: b.options.PruneLabels,
+			FieldSpecs: []types.FieldSpec{{
+				Path:               "metadata/labels",
+				CreateIfNotPresent: true,
+			}},
+		}
+		if err = t.Transform(m); err != nil {
+			return nil, err
+		}
+	}
+	if b.options.ApplySet != nil {
+		if err = addApplySet(m, b.options.ApplySet, resmapFactory.RF()); err != nil {
+			return nil, err
+		}
+	}
+	buildProvenance := utils.StringSliceContains(kt.Kustomization().BuildMetadata, types.BuildProvenanceOption)
+	if buildProvenance {
+		if err = addBuildProvenance(m, path, ldr); err != nil {
+			return nil, err
+		}
+	}
 	m.RemoveBuildAnnotations()
-	if !utils.StringSliceContains(kt.Kustomization().BuildMetadata, types.OriginAnnotations) {
+	if !buildProvenance && !utils.StringSliceContains(kt.Kustomization().BuildMetadata, types.OriginAnnotations) {
 		err = m.RemoveOriginAnnotations()
 		if err != nil {
 			return nil, errors.WrapPrefixf(err, "failed to clean up origin tracking annotations")
 		}
 	}
-	if !utils.StringSliceContains(kt.Kustomization().BuildMetadata, types.TransformerAnnotations) {
+	if !buildProvenance && !utils.StringSliceContains(kt.Kustomization().BuildMetadata, types.TransformerAnnotations) {
 		err = m.RemoveTransformerAnnotations()
 		if err != nil {
 			return nil, errors.WrapPrefixf(err, "failed to clean up transformer annotations")
@@ -158,6 +208,14 @@ func (b *Kustomizer) applySortOrder(m resmap.ResMap, kt *target.KustTarget) erro
 			},
 		}
 		return errors.Wrap(pl.Transform(m))
+	} else if b.options.Reorder == ReorderOptionCustom {
+		pl := &builtins.SortOrderTransformerPlugin{
+			SortOptions: &types.SortOptions{
+				Order:       types.CustomSortOrder,
+				CustomOrder: b.options.CustomSortOptions,
+			},
+		}
+		return errors.Wrap(pl.Transform(m))
 	}
 	return nil
 }
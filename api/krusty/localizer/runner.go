@@ -9,9 +9,38 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
+// Options configure the optional parts of `kustomize localize`.
+type Options struct {
+	// HelmCommand, if set, is the helm executable that vendors the
+	// charts of helmCharts entries with a repo into the localized
+	// chart home.
+	HelmCommand string
+
+	// FunctionImagesDir, if set, is the directory, relative to newDir,
+	// to which the images of container functions are exported as OCI
+	// layouts, and to which their references are rewritten.
+	FunctionImagesDir string
+
+	// SkopeoCommand is the skopeo executable that exports the images
+	// of container functions.
+	SkopeoCommand string
+
+	// Update, if set, updates the existing newDir of a previous
+	// localization, re-fetching only the remote roots whose refs point
+	// to other commits than recorded in its provenance file.
+	Update bool
+}
+
 // Run executes `kustomize localize` on fSys given the `localize` arguments and
-// returns the path to the created newDir.
+// returns the path to the created newDir.  The newDir records the remote
+// content vendored into it in its provenance file.
 func Run(fSys filesys.FileSystem, target, scope, newDir string) (string, error) {
 	dst, err := localizer.Run(target, scope, newDir, fSys)
 	return dst, errors.Wrap(err)
 }
+
+// RunWithOptions is Run, localizing the optional parts set in o.
+func RunWithOptions(fSys filesys.FileSystem, target, scope, newDir string, o Options) (string, error) {
+	dst, err := localizer.RunWithOptions(target, scope, newDir, fSys, localizer.Options(o))
+	return dst, errors.Wrap(err)
+}
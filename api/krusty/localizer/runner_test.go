@@ -6,7 +6,9 @@ package localizer_test
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -14,6 +16,7 @@ import (
 	"sigs.k8s.io/kustomize/api/krusty/localizer"
 	. "sigs.k8s.io/kustomize/api/testutils/localizertest"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -318,6 +321,32 @@ func TestLoaderSymlinks(t *testing.T) {
 	CheckFs(t, dst, fsExpected, fsActual)
 }
 
+// requireVendored requires the provenance file of dst on fSys to record
+// vendored, with hashes and times, and removes it.
+func requireVendored(t *testing.T, fSys filesys.FileSystem, dst string, vendored ...Vendored) {
+	t.Helper()
+
+	content, err := fSys.ReadFile(filepath.Join(dst, ProvenanceFile))
+	require.NoError(t, err)
+	var prov Provenance
+	require.NoError(t, yaml.Unmarshal(content, &prov))
+	require.NotEmpty(t, prov.LocalizedAt)
+	actual := map[string]Vendored{}
+	for _, v := range prov.Vendored {
+		require.Len(t, v.SHA256, 64)
+		require.NotEmpty(t, v.FetchedAt)
+		if v.Type == VendoredRemoteRoot || v.Type == VendoredRemoteTarget {
+			require.Len(t, v.Commit, 40)
+		}
+		v.SHA256, v.FetchedAt, v.Commit = "", "", ""
+		actual[v.Path] = v
+	}
+	for _, v := range vendored {
+		require.Equal(t, v, actual[v.Path])
+	}
+	require.NoError(t, fSys.RemoveAll(filepath.Join(dst, ProvenanceFile)))
+}
+
 func TestRemoteTargetDefaultDst(t *testing.T) {
 	fsExpected, fsActual, testDir := PrepareFs(t, nil, nil)
 	SetWorkingDir(t, testDir.String())
@@ -326,6 +355,12 @@ func TestRemoteTargetDefaultDst(t *testing.T) {
 	dst, err := localizer.Run(fsActual, target, "", "")
 	require.NoError(t, err)
 	require.Equal(t, testDir.Join("localized-simple-kustomize-v4.5.7"), dst)
+	requireVendored(t, fsActual, dst, Vendored{
+		Path:   ".",
+		Type:   VendoredRemoteTarget,
+		Source: target,
+		Ref:    "kustomize/v4.5.7",
+	})
 
 	_, files := simplePathAndFiles(t)
 	SetupDir(t, fsExpected,
@@ -392,6 +427,11 @@ openapi:
 	localizedPath := filepath.Join(LocalizeDir, "raw.githubusercontent.com",
 		"kubernetes-sigs", "kustomize", "kustomize", "v4.5.7", "api", "krusty",
 		"testdata", "customschema.json")
+	requireVendored(t, fsActual, dst, Vendored{
+		Path:   localizedPath,
+		Type:   VendoredRemoteFile,
+		Source: `https://raw.githubusercontent.com/kubernetes-sigs/kustomize/kustomize/v4.5.7/api/krusty/testdata/customschema.json`,
+	})
 	SetupDir(t, fsExpected, dst, map[string]string{
 		"kustomization.yaml": fmt.Sprintf(kustf, localizedPath),
 		localizedPath:        customSchema,
@@ -412,6 +452,12 @@ func TestRemoteRoot(t *testing.T) {
 	require.Equal(t, newDir, dst)
 
 	localizedPath, files := simplePathAndFiles(t)
+	requireVendored(t, fsActual, dst, Vendored{
+		Path:   localizedPath,
+		Type:   VendoredRemoteRoot,
+		Source: simpleURL + urlQuery,
+		Ref:    "kustomize/v4.5.7",
+	})
 	SetupDir(t, fsExpected, dst, map[string]string{
 		"kustomization.yaml": fmt.Sprintf(`resources:
 - %s
@@ -436,6 +482,12 @@ func TestNestedRemoteRoots(t *testing.T) {
 	require.Equal(t, newDir, dst)
 
 	localizedPath, files := remotePathAndFiles(t)
+	requireVendored(t, fsActual, dst, Vendored{
+		Path:   localizedPath,
+		Type:   VendoredRemoteRoot,
+		Source: "https://github.com/kubernetes-sigs/kustomize//api/krusty/testdata/localize/remote?submodules=0&ref=master&timeout=300",
+		Ref:    "master",
+	})
 	SetupDir(t, fsExpected, dst, map[string]string{
 		"kustomization.yaml": fmt.Sprintf(`resources:
 - %s
@@ -683,3 +735,235 @@ func TestSymlinkedFileSource(t *testing.T) {
 	})
 	CheckFs(t, dst, fsExpected, fsActual)
 }
+
+// fakeHelm pulls charts, named by their last argument but --version,
+// with an empty Chart.yaml.
+const fakeHelm = `#!/bin/sh
+dir="$4"
+if [ "$5" = "--repo" ]; then name="$7"; else name="${5##*/}"; fi
+mkdir -p "$dir/$name"
+echo "name: $name" > "$dir/$name/Chart.yaml"
+`
+
+// fakeSkopeo exports images to a layout with an oci-layout file only,
+// logging its arguments to $0.log.
+const fakeSkopeo = `#!/bin/sh
+echo "$@" >> "$0.log"
+mkdir -p "${3#oci:}"
+echo '{"imageLayoutVersion":"1.0.0"}' > "${3#oci:}/oci-layout"
+`
+
+func writeExecutable(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o700))
+	return path
+}
+
+func TestHelmVendorRemoteCharts(t *testing.T) {
+	kustomization := map[string]string{
+		"kustomization.yaml": `helmCharts:
+- name: minecraft
+  repo: https://itzg.github.io/minecraft-server-charts
+  version: 3.1.3
+- name: nginx
+  repo: oci://registry.example.com/charts
+- name: local
+  repo: https://example.com/charts
+`,
+		filepath.Join("charts", "local", "Chart.yaml"): "name: local\n",
+	}
+	fsExpected, fsActual, testDir := PrepareFs(t, []string{
+		filepath.Join("charts", "local"),
+	}, kustomization)
+
+	newDir := testDir.Join("dst")
+	dst, err := localizer.RunWithOptions(fsActual, testDir.String(), "", newDir, localizer.Options{
+		HelmCommand: writeExecutable(t, "helm", fakeHelm),
+	})
+	require.NoError(t, err)
+	require.Equal(t, newDir, dst)
+	requireVendored(t, fsActual, dst, Vendored{
+		Path:   filepath.Join("charts", "minecraft-3.1.3", "minecraft"),
+		Type:   VendoredHelmChart,
+		Source: "https://itzg.github.io/minecraft-server-charts/minecraft",
+		Ref:    "3.1.3",
+	}, Vendored{
+		Path:   filepath.Join("charts", "nginx"),
+		Type:   VendoredHelmChart,
+		Source: "oci://registry.example.com/charts/nginx",
+	})
+
+	SetupDir(t, fsExpected, dst, map[string]string{
+		"kustomization.yaml":                                                  kustomization["kustomization.yaml"],
+		filepath.Join("charts", "local", "Chart.yaml"):                        "name: local\n",
+		filepath.Join("charts", "minecraft-3.1.3", "minecraft", "Chart.yaml"): "name: minecraft\n",
+		filepath.Join("charts", "nginx", "Chart.yaml"):                        "name: nginx\n",
+	})
+	CheckFs(t, dst, fsExpected, fsActual)
+}
+
+func TestFunctionImages(t *testing.T) {
+	fn := `apiVersion: example.com/v1
+kind: MyFn
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: %s
+  name: %s
+`
+	files := map[string]string{
+		filepath.Join("target", "kustomization.yaml"): `transformers:
+- fn.yaml
+validators:
+- validator.yaml
+`,
+		filepath.Join("target", "fn.yaml"):        fmt.Sprintf(fn, "example.com/my-fn:v1", "fn"),
+		filepath.Join("target", "validator.yaml"): fmt.Sprintf(fn, "example.com/my-fn:v1", "validator"),
+	}
+	fsExpected, fsActual, testDir := PrepareFs(t, []string{"target"}, files)
+
+	skopeo := writeExecutable(t, "skopeo", fakeSkopeo)
+	newDir := testDir.Join("dst")
+	dst, err := localizer.RunWithOptions(fsActual, testDir.Join("target"), testDir.String(), newDir,
+		localizer.Options{FunctionImagesDir: "images", SkopeoCommand: skopeo})
+	require.NoError(t, err)
+	require.Equal(t, newDir, dst)
+
+	requireVendored(t, fsActual, dst, Vendored{
+		Path:   filepath.Join("images", "example.com_my-fn_v1"),
+		Type:   VendoredFunctionImage,
+		Source: "example.com/my-fn:v1",
+	})
+	image := filepath.Join("..", "images", "example.com_my-fn_v1")
+	SetupDir(t, fsExpected, dst, map[string]string{
+		filepath.Join("target", "kustomization.yaml"): files[filepath.Join("target", "kustomization.yaml")],
+		filepath.Join("target", "fn.yaml"):            fmt.Sprintf(fn, "oci:"+image, "fn"),
+		filepath.Join("target", "validator.yaml"):     fmt.Sprintf(fn, "oci:"+image, "validator"),
+		filepath.Join("images", "example.com_my-fn_v1", "oci-layout"): `{"imageLayoutVersion":"1.0.0"}
+`,
+	})
+	CheckFs(t, dst, fsExpected, fsActual)
+
+	// the image is exported once
+	log, err := os.ReadFile(skopeo + ".log")
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(string(log), "copy docker://example.com/my-fn:v1 oci:"))
+}
+
+func TestFunctionImagesDirOutsideDst(t *testing.T) {
+	_, fsActual, testDir := PrepareFs(t, nil, map[string]string{
+		"kustomization.yaml": "resources: []\n",
+	})
+	_, err := localizer.RunWithOptions(fsActual, testDir.String(), "", testDir.Join("dst"),
+		localizer.Options{FunctionImagesDir: "../images"})
+	require.ErrorContains(t, err,
+		`function images directory "../images" must be a relative path inside of the localize destination`)
+	require.NoDirExists(t, testDir.Join("dst"))
+}
+
+// gitIn runs git in dir and returns its trimmed output.
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func readProvenance(t *testing.T, fSys filesys.FileSystem, dst string) Provenance {
+	t.Helper()
+	content, err := fSys.ReadFile(filepath.Join(dst, ProvenanceFile))
+	require.NoError(t, err)
+	var prov Provenance
+	require.NoError(t, yaml.Unmarshal(content, &prov))
+	return prov
+}
+
+func TestUpdate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	gitIn(t, repo, "init", "--quiet", "--initial-branch=main")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "base"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "base", "kustomization.yaml"), []byte("namePrefix: v1-\n"), 0o600))
+	gitIn(t, repo, "add", ".")
+	gitIn(t, repo, "commit", "--quiet", "-m", "v1")
+	first := gitIn(t, repo, "rev-parse", "HEAD")
+
+	remote := "file://" + repo + "//base?ref=main"
+	_, fSys, testDir := PrepareFs(t, nil, map[string]string{
+		"kustomization.yaml": fmt.Sprintf("resources:\n- %s\n", remote),
+	})
+	newDir := testDir.Join("dst")
+	_, err := localizer.Run(fSys, testDir.String(), "", newDir)
+	require.NoError(t, err)
+
+	prov := readProvenance(t, fSys, newDir)
+	require.Len(t, prov.Vendored, 1)
+	vendored := prov.Vendored[0]
+	require.Equal(t, VendoredRemoteRoot, vendored.Type)
+	require.Equal(t, remote, vendored.Source)
+	require.Equal(t, "main", vendored.Ref)
+	require.Equal(t, first, vendored.Commit)
+	base := filepath.Join(newDir, vendored.Path)
+	content, err := fSys.ReadFile(filepath.Join(base, "kustomization.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "namePrefix: v1-\n", string(content))
+
+	update := func() {
+		t.Helper()
+		_, err := localizer.RunWithOptions(fSys, testDir.String(), "", newDir, localizer.Options{Update: true})
+		require.NoError(t, err)
+	}
+
+	// unchanged remote roots are reused, local files updated
+	require.NoError(t, fSys.WriteFile(filepath.Join(base, "marker"), []byte("reused")))
+	require.NoError(t, fSys.WriteFile(testDir.Join("kustomization.yaml"),
+		[]byte(fmt.Sprintf("namePrefix: local-\nresources:\n- %s\n", remote))))
+	update()
+	require.FileExists(t, filepath.Join(base, "marker"))
+	content, err = fSys.ReadFile(filepath.Join(newDir, "kustomization.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("namePrefix: local-\nresources:\n- %s\n", vendored.Path), string(content))
+	prov = readProvenance(t, fSys, newDir)
+	require.Len(t, prov.Vendored, 1)
+	// the hash tells of the marker
+	require.NotEqual(t, vendored.SHA256, prov.Vendored[0].SHA256)
+	prov.Vendored[0].SHA256 = vendored.SHA256
+	require.Equal(t, vendored, prov.Vendored[0])
+
+	// changed remote roots are fetched again
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "base", "kustomization.yaml"), []byte("namePrefix: v2-\n"), 0o600))
+	gitIn(t, repo, "commit", "--quiet", "-am", "v2")
+	second := gitIn(t, repo, "rev-parse", "HEAD")
+	update()
+	require.NoFileExists(t, filepath.Join(base, "marker"))
+	content, err = fSys.ReadFile(filepath.Join(base, "kustomization.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "namePrefix: v2-\n", string(content))
+	prov = readProvenance(t, fSys, newDir)
+	require.Len(t, prov.Vendored, 1)
+	require.Equal(t, second, prov.Vendored[0].Commit)
+
+	// remote roots no longer referenced are removed
+	require.NoError(t, fSys.WriteFile(testDir.Join("kustomization.yaml"), []byte("namePrefix: local-\n")))
+	update()
+	require.NoDirExists(t, filepath.Join(newDir, LocalizeDir))
+	prov = readProvenance(t, fSys, newDir)
+	require.Empty(t, prov.Vendored)
+}
+
+func TestUpdateNotLocalized(t *testing.T) {
+	_, fSys, testDir := PrepareFs(t, []string{"dst"}, map[string]string{
+		"kustomization.yaml": "namePrefix: test-\n",
+	})
+	_, err := localizer.RunWithOptions(fSys, testDir.String(), "", testDir.Join("dst"),
+		localizer.Options{Update: true})
+	require.ErrorContains(t, err, fmt.Sprintf(`localize destination "%s" to update has no %s`,
+		testDir.Join("dst"), ProvenanceFile))
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestNamespaceOptionsCreate(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namespace: web
+namespaceOptions:
+  create: true
+namePrefix: dev-
+commonLabels:
+  team: a
+resources:
+- service.yaml
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    team: a
+  name: dev-web
+  namespace: web
+spec:
+  selector:
+    team: a
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    team: a
+  name: web
+`)
+}
+
+func TestNamespaceOptionsCreateExisting(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namespace: web
+namespaceOptions:
+  create: true
+resources:
+- namespace.yaml
+`)
+	th.WriteF("namespace.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+  annotations:
+    owner: me
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  annotations:
+    owner: me
+  name: web
+`)
+}
+
+func TestNamespaceTemplate(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namespace: team-${env}
+namespaceOptions:
+  create: true
+resources:
+- configmap.yaml
+`)
+	th.WriteF("configmap.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Overrides = map[string]string{"env": "prod"}
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: team-prod
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: team-prod
+`)
+
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	require.Contains(t, err.Error(), `namespace "team-${env}" reads unset parameter "env"`)
+
+	opts.Overrides = map[string]string{"env": "Prod/1"}
+	err = th.RunWithErr(".", opts)
+	require.Contains(t, err.Error(),
+		`namespace "team-${env}" expands to "team-Prod/1", which is not a valid DNS-1123 label`)
+
+	// environment variables are read only if the build allows them
+	t.Setenv("KUSTOMIZE_TEST_ENV", "dev")
+	opts = th.MakeDefaultOptions()
+	th.WriteK(".", `
+namespace: team-${KUSTOMIZE_TEST_ENV}
+resources:
+- configmap.yaml
+`)
+	err = th.RunWithErr(".", opts)
+	require.Contains(t, err.Error(), `reads unset parameter "KUSTOMIZE_TEST_ENV"`)
+
+	opts.AllowedEnv = []string{"KUSTOMIZE_TEST_ENV"}
+	m = th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: team-dev
+`)
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestNetworkPolicyGenerator(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namespace: shop
+namePrefix: prod-
+commonLabels:
+  team: shop
+networkPolicyGenerator:
+- name: default-deny
+- app: api
+  ingress:
+  - apps:
+    - web
+    ports:
+    - "8080"
+  - namespaces:
+    - monitoring
+    ports:
+    - metrics
+  egress:
+  - apps:
+    - db
+    ports:
+    - "5432"
+  - namespaces:
+    - kube-system
+    ports:
+    - 53/UDP
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  labels:
+    team: shop
+  name: prod-default-deny
+  namespace: shop
+spec:
+  ingress: []
+  podSelector:
+    matchLabels:
+      team: shop
+  policyTypes:
+  - Ingress
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  labels:
+    team: shop
+  name: prod-api
+  namespace: shop
+spec:
+  egress:
+  - ports:
+    - port: 5432
+      protocol: TCP
+    to:
+    - podSelector:
+        matchLabels:
+          app: db
+          team: shop
+  - ports:
+    - port: 53
+      protocol: UDP
+    to:
+    - namespaceSelector:
+        matchLabels:
+          kubernetes.io/metadata.name: kube-system
+  ingress:
+  - from:
+    - podSelector:
+        matchLabels:
+          app: web
+          team: shop
+    ports:
+    - port: 8080
+      protocol: TCP
+  - from:
+    - namespaceSelector:
+        matchLabels:
+          kubernetes.io/metadata.name: monitoring
+    ports:
+    - port: metrics
+      protocol: TCP
+  podSelector:
+    matchLabels:
+      app: api
+      team: shop
+  policyTypes:
+  - Ingress
+  - Egress
+`)
+}
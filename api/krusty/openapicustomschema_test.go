@@ -4,10 +4,15 @@
 package krusty_test
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/remotecache"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/openapi/kubernetesapi"
@@ -642,3 +647,95 @@ spec:
 		assert.Equal(t, kubernetesapi.DefaultOpenAPI, openapi.GetSchemaVersion())
 	})
 }
+
+func TestKubeVersionBuiltin(t *testing.T) {
+	runOpenApiTest(t, func(t *testing.T) {
+		t.Helper()
+		th := kusttest_test.MakeHarness(t)
+		th.WriteK(".", `
+openapi:
+  version: v1.14.1
+resources:
+- deployment.yaml
+`)
+		th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeployment
+`)
+		opts := th.MakeDefaultOptions()
+		opts.KubeVersion = "1.21"
+		th.Run(".", opts)
+		assert.Equal(t, "v1.21.2", openapi.GetSchemaVersion())
+	})
+}
+
+func TestKubeVersionFetched(t *testing.T) {
+	runOpenApiTest(t, func(t *testing.T) {
+		t.Helper()
+		th := kusttest_test.MakeHarness(t)
+		th.WriteK(".", `
+resources:
+- mycrd.yaml
+`+customSchemaPatch)
+		writeCustomResource(th, "mycrd.yaml")
+		schema, err := os.ReadFile("./testdata/customschema.json")
+		require.NoError(t, err)
+		sum := sha256.Sum256(schema)
+		// the schema of Kubernetes v1.29.3 is in the cache, so isn't fetched
+		cache := &remotecache.Cache{Dir: t.TempDir(), Offline: true}
+		_, err = cache.StoreFile(
+			"https://raw.githubusercontent.com/kubernetes/kubernetes/v1.29.3/api/openapi-spec/swagger.json",
+			schema)
+		require.NoError(t, err)
+		opts := th.MakeDefaultOptions()
+		opts.RemoteCache = cache
+		opts.KubeVersion = "v1.29.3"
+		opts.KubeVersionSHA256 = hex.EncodeToString(sum[:])
+		m := th.Run(".", opts)
+		th.AssertActualEqualsExpected(m, patchedCustomResource)
+		assert.Equal(t, "using custom schema from file provided", openapi.GetSchemaVersion())
+
+		opts.KubeVersionSHA256 = strings.Repeat("0", 64)
+		err = th.RunWithErr(".", opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the OpenAPI schema of Kubernetes v1.29.3 fetched from "+
+			"https://raw.githubusercontent.com/kubernetes/kubernetes/v1.29.3/api/openapi-spec/swagger.json "+
+			"has sha256 checksum "+hex.EncodeToString(sum[:])+", not "+opts.KubeVersionSHA256)
+	})
+}
+
+func TestKubeVersionNotFetched(t *testing.T) {
+	for version, errMsg := range map[string]string{
+		"1.29": "the OpenAPI schema of Kubernetes 1.29 isn't built in; " +
+			"name a release, e.g. v1.29.0, to fetch its schema",
+		"v1.29.3": "the OpenAPI schema of Kubernetes v1.29.3 isn't built in; " +
+			"fetching it requires its sha256 checksum",
+	} {
+		runOpenApiTest(t, func(t *testing.T) {
+			t.Helper()
+			th := kusttest_test.MakeHarness(t)
+			th.WriteK(".", "resources: []\n")
+			opts := th.MakeDefaultOptions()
+			opts.KubeVersion = version
+			err := th.RunWithErr(".", opts)
+			require.Error(t, err)
+			assert.Equal(t, errMsg, err.Error())
+		})
+	}
+}
+
+func TestKubeVersionInvalid(t *testing.T) {
+	runOpenApiTest(t, func(t *testing.T) {
+		t.Helper()
+		th := kusttest_test.MakeHarness(t)
+		th.WriteK(".", "resources: []\n")
+		opts := th.MakeDefaultOptions()
+		opts.KubeVersion = "latest"
+		err := th.RunWithErr(".", opts)
+		require.Error(t, err)
+		assert.Equal(t,
+			`invalid Kubernetes version "latest"; expected a version like 1.29 or v1.29.3`, err.Error())
+	})
+}
@@ -5,6 +5,8 @@ package krusty
 
 import (
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/remotecache"
+	"sigs.k8s.io/kustomize/api/trace"
 	"sigs.k8s.io/kustomize/api/types"
 )
 
@@ -13,6 +15,7 @@ type ReorderOption string
 const (
 	ReorderOptionLegacy      ReorderOption = "legacy"
 	ReorderOptionNone        ReorderOption = "none"
+	ReorderOptionCustom      ReorderOption = "custom"
 	ReorderOptionUnspecified ReorderOption = "unspecified"
 )
 
@@ -30,21 +33,93 @@ type Options struct {
 	//   compatibility.
 	// - "none": Respect the depth-first resource input order as specified by the
 	//   kustomization file.
+	// - "custom": Refine the legacy order per CustomSortOptions.
 	// - "unspecified": The user didn't specify any preference. Kustomize will
 	//   select the appropriate default.
 	Reorder ReorderOption
 
+	// CustomSortOptions are the options of the "custom" Reorder.
+	CustomSortOptions *types.CustomSortOptions
+
 	// When true, a label
 	//     app.kubernetes.io/managed-by: kustomize-<version>
 	// is added to all the resources in the build out.
 	AddManagedbyLabel bool
 
+	// When true, an annotation recording the build provenance, i.e. the
+	// kustomization built, the git commit it's at and the kustomize
+	// version, is added to all the resources in the build out, along
+	// with the origin and transformer annotations, as if the
+	// buildMetadata field listed buildProvenance.
+	AddBuildProvenance bool
+
 	// Restrictions on what can be loaded from the file system.
 	// See type definition.
 	LoadRestrictions types.LoadRestrictions
 
 	// Options related to kustomize plugins.
 	PluginConfig *types.PluginConfig
+
+	// Overrides are values that take precedence over the kustomization
+	// files, e.g. per environment values set in CI. The key "namespace"
+	// sets the namespace and "commonLabels.<label>" a common label of the
+	// top kustomization; any other key names a substitution, declared by
+	// any of the kustomizations of the build, whose value is replaced.
+	Overrides map[string]string
+
+	// AllowedEnv names the environment variables that substitutions
+	// may read their values from. Reading any other fails the build.
+	AllowedEnv []string
+
+	// RemoteCache, if set, keeps the remote bases and files
+	// of the build on disk for use by later builds.
+	RemoteCache *remotecache.Cache
+
+	// Parallel is the number of resources entries, i.e. resource
+	// files and bases, loaded at once. Values below 2 load them
+	// one at a time. The output doesn't depend on it.
+	Parallel int
+
+	// KubeVersion, if set, is the Kubernetes version, e.g. 1.29,
+	// whose OpenAPI schema patches are merged by, in place of the
+	// version of the openapi field of the kustomization. The schema
+	// of a release that isn't built in, e.g. v1.29.3, is fetched,
+	// and kept in the RemoteCache if any, provided KubeVersionSHA256
+	// is set.
+	KubeVersion string
+
+	// KubeVersionSHA256 is the hex sha256 checksum that the fetched
+	// OpenAPI schema of KubeVersion must have.
+	KubeVersionSHA256 string
+
+	// CrdSchemaDir, if set, is a directory of CRDs whose schemas
+	// strategic merge patches of their custom resources honor, as
+	// do those named by the crds key of the openapi field.
+	CrdSchemaDir string
+
+	// KubectlCommand is the kubectl executable reading from the
+	// cluster, e.g. the CRDs named by "openapi: {crds: cluster}";
+	// kubectl on the PATH if empty.
+	KubectlCommand string
+
+	// EnableClusterCrds allows "openapi: {crds: cluster}" to read the
+	// CRDs of the cluster of the current kubectl context. Remote
+	// kustomizations can't, regardless.
+	EnableClusterCrds bool
+
+	// ApplySet, if set, is the parent of the ApplySet whose members
+	// are the resources of the build. They're labeled as such, and
+	// the parent is added to them, so that kubectl apply --prune
+	// --applyset prunes the resources that are no longer built.
+	ApplySet *types.ApplySet
+
+	// Trace, if set, records the changes each generator and transformer
+	// of the build makes to its resources.
+	Trace *trace.Trace
+
+	// PruneLabels, if set, are added to the resources of the build,
+	// so that kubectl apply --prune --selector selects exactly them.
+	PruneLabels map[string]string
 }
 
 // MakeDefaultOptions returns a default instance of Options.
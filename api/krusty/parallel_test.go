@@ -0,0 +1,87 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeParallelBases(th kusttest_test.Harness) {
+	resources := "resources:\n"
+	for i := 0; i < 6; i++ {
+		base := fmt.Sprintf("base%d", i)
+		resources += "- " + base + "\n"
+		th.WriteK(base, fmt.Sprintf(`
+namePrefix: b%d-
+resources:
+- cm.yaml
+- nested
+`, i))
+		th.WriteF(base+"/cm.yaml", fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  base: "%d"
+`, i))
+		th.WriteK(base+"/nested", `
+resources:
+- service.yaml
+`)
+		th.WriteF(base+"/nested/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`)
+	}
+	th.WriteK(".", resources+"- pod.yaml\n")
+	th.WriteF("pod.yaml", `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+`)
+}
+
+func TestParallelAccumulationIsDeterministic(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeParallelBases(th)
+
+	serial := th.Run(".", th.MakeDefaultOptions())
+	expected, err := serial.AsYaml()
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		o := th.MakeDefaultOptions()
+		o.Parallel = 4
+		m := th.Run(".", o)
+		actual, err := m.AsYaml()
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual))
+	}
+	assert.Equal(t, 13, serial.Size())
+}
+
+func TestParallelAccumulationReportsErrors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeParallelBases(th)
+	th.WriteK(".", `
+resources:
+- base0
+- missing
+- base1
+`)
+	o := th.MakeDefaultOptions()
+	o.Parallel = 4
+	err := th.RunWithErr(".", o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing")
+}
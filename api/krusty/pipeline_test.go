@@ -0,0 +1,159 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writePipelineResources(th kusttest_test.Harness) {
+	th.WriteF("resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+`)
+	th.WriteF("label.yaml", `
+apiVersion: builtin
+kind: LabelTransformer
+metadata:
+  name: label
+labels:
+  stage: one
+fieldSpecs:
+- path: metadata/labels
+  create: true
+`)
+	th.WriteF("prefix.yaml", `
+apiVersion: builtin
+kind: PrefixSuffixTransformer
+metadata:
+  name: prefix
+prefix: cm-
+fieldSpecs:
+- path: metadata/name
+`)
+	th.WriteF("failing.yaml", `
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: failing
+patch: |-
+  - op: test
+    path: /metadata/name
+    value: nope
+`)
+}
+
+func TestPipeline(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePipelineResources(th)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+pipeline:
+- name: prefix-configmaps
+  transformer: prefix.yaml
+  select:
+    kind: ConfigMap
+- name: broken
+  transformer: failing.yaml
+  onFailure: continue
+- transformer: label.yaml
+  select:
+    name: cm-a
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    stage: one
+  name: cm-a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm-c
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b
+`)
+}
+
+func TestPipelineSkipOnFailure(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePipelineResources(th)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+pipeline:
+- transformer: failing.yaml
+  onFailure: skip
+- transformer: label.yaml
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+`)
+}
+
+func TestPipelineErrors(t *testing.T) {
+	testCases := map[string]struct {
+		pipeline    string
+		expectedErr string
+	}{
+		"fail": {`
+- name: broken
+  transformer: failing.yaml
+`, "pipeline stage broken"},
+		"illegal policy": {`
+- transformer: label.yaml
+  onFailure: retry
+`, `pipeline stage #1 has illegal onFailure "retry"`},
+		"no transformer": {`
+- name: empty
+`, "pipeline stage empty must specify a transformer"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writePipelineResources(th)
+			th.WriteK(".", "resources:\n- resources.yaml\npipeline:"+tc.pipeline)
+			err := th.RunWithErr(".", th.MakeDefaultOptions())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestResourceOverrides(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("workloads.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: 250m
+            memory: 128Mi
+      - name: proxy
+        image: proxy
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: app
+            image: report
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  containers:
+  - name: shell
+    image: busybox
+`)
+	th.WriteK(".", `
+resources:
+- workloads.yaml
+resourceOverrides:
+- containers:
+  - app
+  limits:
+    memory: 512Mi
+- target:
+    kind: Deployment
+  scale:
+    requests:
+      cpu: 4
+      memory: 1.5
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: app
+        name: app
+        resources:
+          limits:
+            memory: 512Mi
+          requests:
+            cpu: 1000m
+            memory: 192Mi
+      - image: proxy
+        name: proxy
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - image: report
+            name: app
+            resources:
+              limits:
+                memory: 512Mi
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  containers:
+  - image: busybox
+    name: shell
+`)
+}
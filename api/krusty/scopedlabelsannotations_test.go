@@ -0,0 +1,184 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeScopedLabelsResources(th kusttest_test.Harness) {
+	th.WriteF("resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-legacy
+`)
+}
+
+func TestScopedLabelsAndAnnotations(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+labels:
+- pairs:
+    owner: team-a
+  reject:
+  - name: .*-legacy
+- pairs:
+    release: stable
+  includeSelectors: true
+  select:
+  - kind: Service
+annotations:
+- pairs:
+    checked: "true"
+  excludeTemplates: true
+  select:
+  - labelSelector: tier=web
+`)
+	writeScopedLabelsResources(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    checked: "true"
+  labels:
+    owner: team-a
+    tier: web
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - image: nginx
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    owner: team-a
+    release: stable
+  name: web
+spec:
+  selector:
+    app: web
+    release: stable
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-legacy
+`)
+}
+
+func TestLabelsExcludeTemplatesWithSelectors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+labels:
+- pairs:
+    owner: team-a
+  includeSelectors: true
+  excludeTemplates: true
+`)
+	writeScopedLabelsResources(th)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	require.Contains(t, err.Error(),
+		"labels[0] cannot exclude templates and include selectors or templates")
+}
+
+func TestLabelsExcludeTemplatesOfCustomFields(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+labels:
+- pairs:
+    owner: team-a
+  excludeTemplates: true
+  fields:
+  - path: spec/template/metadata/labels
+    kind: Deployment
+    create: true
+  select:
+  - kind: Deployment
+`)
+	writeScopedLabelsResources(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    owner: team-a
+    tier: web
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - image: nginx
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-legacy
+`)
+}
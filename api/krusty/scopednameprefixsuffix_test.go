@@ -0,0 +1,178 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeScopedNameAffixResources(th kusttest_test.Harness) {
+	th.WriteF("resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        envFrom:
+        - configMapRef:
+            name: config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker-legacy
+spec:
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: worker
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`)
+}
+
+func TestScopedNamePrefixesAndSuffixes(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+namePrefixes:
+- value: dev-
+  skipClusterScoped: true
+  reject:
+  - name: .*-legacy
+nameSuffixes:
+- value: -v2
+  select:
+  - kind: Deployment
+    labelSelector: tier=web
+  - kind: ConfigMap
+`)
+	writeScopedNameAffixResources(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dev-config-v2
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: dev-web-v2
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: dev-config-v2
+        image: nginx
+        name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker-legacy
+spec:
+  template:
+    spec:
+      containers:
+      - image: worker
+        name: worker
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`)
+}
+
+func TestScopedNamePrefixAfterNamePrefix(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+namePrefix: team-
+namePrefixes:
+- value: dev-
+  select:
+  - kind: ConfigMap
+`)
+	writeScopedNameAffixResources(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dev-team-config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: team-web
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: dev-team-config
+        image: nginx
+        name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: team-worker-legacy
+spec:
+  template:
+    spec:
+      containers:
+      - image: worker
+        name: worker
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: team-reader
+`)
+}
+
+func TestScopedNamePrefixWithoutValue(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+namePrefixes:
+- select:
+  - kind: ConfigMap
+`)
+	writeScopedNameAffixResources(th)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	require.Contains(t, err.Error(), "namePrefixes[0] must specify a value")
+}
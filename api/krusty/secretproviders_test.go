@@ -0,0 +1,115 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+type fakeSecretProvider map[string]string
+
+func (p fakeSecretProvider) GetSecret(path, key string) ([]byte, error) {
+	return []byte(p[path+"/"+key]), nil
+}
+
+func TestSecretGeneratorValuesFromVault(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "s.token", req.Header.Get("X-Vault-Token"))
+		assert.Equal(t, "/v1/secret/data/app", req.URL.Path)
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2","user":"admin"},"metadata":{}}}`))
+	}))
+	defer s.Close()
+	t.Setenv("VAULT_ADDR", s.URL)
+	t.Setenv("VAULT_TOKEN", "s.token")
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+secretGenerator:
+- name: app
+  literals:
+  - host=db.example.com
+  values:
+  - name: user
+    valueFrom:
+      provider: vault
+      path: secret/data/app
+      key: user
+  - name: password
+    valueFrom:
+      provider: vault
+      path: secret/data/app
+      key: password
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig.SecretProvidersConfig.Enabled = true
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  host: ZGIuZXhhbXBsZS5jb20=
+  password: aHVudGVyMg==
+  user: YWRtaW4=
+kind: Secret
+metadata:
+  name: app
+type: Opaque
+`)
+}
+
+func TestSecretGeneratorValuesFromConfiguredProvider(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+secretGenerator:
+- name: app
+  values:
+  - name: token
+    valueFrom:
+      provider: keyring
+      path: app
+      key: token
+`)
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig.SecretProvidersConfig = types.SecretProvidersConfig{
+		Enabled: true,
+		Providers: map[string]types.SecretProvider{
+			"keyring": fakeSecretProvider{"app/token": "abc"},
+		},
+	}
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  token: YWJj
+kind: Secret
+metadata:
+  name: app-dbdgd77ct8
+type: Opaque
+`)
+}
+
+func TestSecretGeneratorValuesDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+secretGenerator:
+- name: app
+  values:
+  - name: password
+    valueFrom:
+      provider: vault
+      path: secret/data/app
+      key: password
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"secret app: must specify --enable-secret-providers to pull secret values")
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// Encrypted by sops for the recipient
+// age1aj2zxlpynsy7l9mallnzve2v9ad82autu54d7hvukm9kwrp6rgxqkrrgfd.
+const sopsAgeIdentity = "AGE-SECRET-KEY-1A86J9K55TJF92GDRHL7H48V34ESVP9SPGEQXS254N647SDLHQ3QS3LHML5"
+
+func TestSopsSecretGenerator(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY", sopsAgeIdentity)
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("db.env", `DB_USER=ENC[AES256_GCM,data:poQUkvc=,iv:hqKUHG9HJ9wAY4ZfEgpcUTOwSj46dgffQQNQjoFQQOk=,tag:pN7a/eBXgNYXuCR8VoDUrw==,type:str]
+DB_PASSWORD=ENC[AES256_GCM,data:6cyhWMnlv1Kz,iv:2hkGjLY7/n44vh3Zv65rNzGukG7wPqvmSVOl6P2TpoI=,tag:1cQfgGVKHKoH8oBuwXKLCQ==,type:str]
+sops_age__list_0__map_enc=-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBZNTZxanZoejlqcDBROTNU\naDc1bGpndGUweE5wcVAwbjAzdjU2ZzRxRUNRCi9OR3JMUy9DYlJZalYyK0hwUWsz\nM3p2akw1bzlLSFdMa0lsck9KdEc4eUkKLS0tIGI1ZHVxbWZxajBvdUluV0JTUWtG\nbEJvaFIzamRURWRsT2MxVFZiV2VDS3MKMjLLDVGYXFIDL7R+Y0LuRME3aLSysCH0\n9gtWW06j96bc8HiS+63SToplQANfTDuwG4hSB2v8bg+sQaOCCsv09Q==\n-----END AGE ENCRYPTED FILE-----\n
+sops_age__list_0__map_recipient=age1aj2zxlpynsy7l9mallnzve2v9ad82autu54d7hvukm9kwrp6rgxqkrrgfd
+sops_lastmodified=2023-10-14T10:00:00Z
+sops_mac=ENC[AES256_GCM,data:p0DIFaMfmIc/ss1gDkEGgoMhP1BEqY1x7VfqMvORR/+L8ozbuJIOelA0ZT9mspTh8pgJDRRCelzKpTnboqZ1HQAeoKNbduUHGozqu7oY5grggzA+XZP92W7KounHgemMHdEJqxSjN5EcW/NRBV6+ZFjUhuEkk4o92UejVROcXpQ=,iv:oareksNBFdM98yEyAst/9paiydG5JpN8qA6g7mKeZNc=,tag:OYUAzxPvIip0/tq4Tz0hZQ==,type:str]
+sops_unencrypted_suffix=_unencrypted
+sops_version=3.8.1
+`)
+	th.WriteF("tls.crt", `{"data": "ENC[AES256_GCM,data:/tSFuUBxLAT9A0TiPXRZdOv5lv/sprcHZiD5w7KU9yJabHH8MPIAcelfFnLHge696yM0QR+x6cQlBrM=,iv:2W1ZNrZ6DecW8zWbOmKwezrigwJFzM6BsCQSNiiZVBc=,tag:V5B7Uo7y3PBOCTAr+3CB2g==,type:str]", "sops": {"age": [{"recipient": "age1aj2zxlpynsy7l9mallnzve2v9ad82autu54d7hvukm9kwrp6rgxqkrrgfd", "enc": "-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBZNTZxanZoejlqcDBROTNU\naDc1bGpndGUweE5wcVAwbjAzdjU2ZzRxRUNRCi9OR3JMUy9DYlJZalYyK0hwUWsz\nM3p2akw1bzlLSFdMa0lsck9KdEc4eUkKLS0tIGI1ZHVxbWZxajBvdUluV0JTUWtG\nbEJvaFIzamRURWRsT2MxVFZiV2VDS3MKMjLLDVGYXFIDL7R+Y0LuRME3aLSysCH0\n9gtWW06j96bc8HiS+63SToplQANfTDuwG4hSB2v8bg+sQaOCCsv09Q==\n-----END AGE ENCRYPTED FILE-----\n"}], "lastmodified": "2023-10-14T10:00:00Z", "mac": "ENC[AES256_GCM,data:cE6xtFlEjQ7w0uodsVfpQmzqHb6ZckN1gdlh5pujMh7LP/n3Dqh9qsEasYA+mMWCeEaAoKAsSdvNqfYddD7W+4wKqF6816VOJy1zPoXrUqnXRaTmjFNxU76zE2XgvHSEQHLdCDoDuiMlBPB3Mr5Aw20djX+vWd/Cz0GCrjiEzr4=,iv:nBzPHkaz1XWsVdJRUaoLyva4IJRgYW11cB/MIMLy4E8=,tag:5cKyrK1rEYjSnJ2zjgJmlg==,type:str]", "version": "3.8.1"}}
+`)
+	th.WriteK(".", `
+secretGenerator:
+- name: db
+  envs:
+  - db.env
+  files:
+  - tls.crt
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig.SopsConfig.Enabled = true
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  DB_PASSWORD: cEBzcz13b3Jk
+  DB_USER: YWRtaW4=
+  tls.crt: |
+    LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0tCk1JSUIKLS0tLS1FTkQgQ0VSVElGSUNBVE
+    UtLS0tLQo=
+kind: Secret
+metadata:
+  name: db
+type: Opaque
+`)
+}
+
+func TestSopsSecretGeneratorDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("db.env", `
+DB_USER=ENC[AES256_GCM,data:YQ==,iv:YQ==,tag:YQ==,type:str]
+sops_mac=ENC[AES256_GCM,data:YQ==,iv:YQ==,tag:YQ==,type:str]
+`)
+	th.WriteK(".", `
+secretGenerator:
+- name: db
+  envs:
+  - db.env
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	// Without decryption, encrypted sources are kept as they are,
+	// e.g. for decryption in the cluster.
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  DB_USER: RU5DW0FFUzI1Nl9HQ00sZGF0YTpZUT09LGl2OllRPT0sdGFnOllRPT0sdHlwZTpzdHJd
+  sops_mac: RU5DW0FFUzI1Nl9HQ00sZGF0YTpZUT09LGl2OllRPT0sdGFnOllRPT0sdHlwZTpzdHJd
+kind: Secret
+metadata:
+  name: db
+type: Opaque
+`)
+}
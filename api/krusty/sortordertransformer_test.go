@@ -4,11 +4,14 @@
 package krusty_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/api/krusty"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 )
 
 //nolint:gochecknoglobals
@@ -223,6 +226,97 @@ sortOptions:
 		th.Run("base", th.MakeDefaultOptions()), sortOrderResources)
 }
 
+func TestCustomSortOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- resources.yaml
+
+sortOptions:
+  order: custom
+  legacySortOptions:
+    orderFirst:
+    - Service
+    orderLast: []
+  customOrder:
+    orderFirst:
+    - kind: ValidatingWebhookConfiguration
+    orderLast:
+    - kind: Namespace
+`)
+	th.WriteF("base/resources.yaml", sortOrderResources)
+	th.AssertActualEqualsExpected(
+		th.Run("base", th.MakeDefaultOptions()), `
+apiVersion: v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: pomegranate
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: apricot
+---
+apiVersion: v1
+kind: Deployment
+metadata:
+  name: pear
+---
+apiVersion: v1
+kind: Ingress
+metadata:
+  name: durian
+---
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: peach
+---
+apiVersion: v1
+kind: Role
+metadata:
+  name: banana
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+`)
+}
+
+func TestCustomSortOrderInCLI(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- resources.yaml
+`)
+	th.WriteF("base/resources.yaml", sortOrderResources)
+	kustOptions := th.MakeDefaultOptions()
+	kustOptions.Reorder = krusty.ReorderOptionCustom
+	th.AssertActualEqualsExpected(th.Run("base", kustOptions), legacyOrderResources)
+	kustOptions.CustomSortOptions = &types.CustomSortOptions{
+		OrderLast: []resid.Gvk{{Kind: "Namespace"}},
+	}
+	namespace := `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+`
+	th.AssertActualEqualsExpected(th.Run("base", kustOptions),
+		strings.Replace(legacyOrderResources, namespace+"---", "", 1)+"---"+namespace)
+}
+
 func TestInvalidLegacySortOptionsWithoutOrderKey(t *testing.T) {
 	th := kusttest_test.MakeHarness(t)
 	th.WriteK("base", `
@@ -234,7 +328,7 @@ sortOptions:
 `)
 	th.WriteF("base/resources.yaml", sortOrderResources)
 	err := th.RunWithErr("base", th.MakeDefaultOptions())
-	require.ErrorContains(t, err, "the field 'sortOptions.order' must be one of [fifo, legacy]")
+	require.ErrorContains(t, err, "the field 'sortOptions.order' must be one of [fifo, legacy, custom]")
 }
 
 func TestInvalidLegacySortOptionsWithFIFOOrder(t *testing.T) {
@@ -0,0 +1,280 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeSubstitutionResources(th kusttest_test.Harness) {
+	th.WriteF("resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  tag: "1.2.3"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+  paused: true
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:latest
+        env:
+        - name: REGION
+          value: unset
+`)
+}
+
+func TestSubstitutions(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSubstitutionResources(th)
+	th.WriteF("region.txt", "eu-west-1\n")
+	t.Setenv("KUSTOMIZE_TEST_PAUSED", "false")
+	th.WriteK(".", `
+resources:
+- resources.yaml
+substitutions:
+- name: tag
+  source:
+    resource:
+      kind: ConfigMap
+      name: settings
+      fieldPath: data.tag
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - spec.template.spec.containers.[name=app].image
+    options:
+      delimiter: ':'
+      index: 1
+- name: replicas
+  type: int
+  source:
+    env: KUSTOMIZE_TEST_NO_SUCH_VARIABLE
+  default: "3"
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - spec.replicas
+- name: paused
+  type: bool
+  source:
+    env: KUSTOMIZE_TEST_PAUSED
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - spec.paused
+- name: region
+  source:
+    file: region.txt
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - spec.template.spec.containers.[name=app].env.[name=REGION].value
+- name: unused
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - metadata.name
+`)
+	opts := th.MakeDefaultOptions()
+	opts.AllowedEnv = []string{"KUSTOMIZE_TEST_NO_SUCH_VARIABLE", "KUSTOMIZE_TEST_PAUSED"}
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  tag: 1.2.3
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  paused: false
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - env:
+        - name: REGION
+          value: eu-west-1
+        image: app:1.2.3
+        name: app
+`)
+}
+
+func TestSubstitutionsTypedValueReplacesString(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSubstitutionResources(th)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+substitutions:
+- name: count
+  type: int
+  default: "5"
+  targets:
+  - select:
+      kind: ConfigMap
+    fieldPaths:
+    - data.tag
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	cm, err := m.Resources()[0].AsYAML()
+	assert.NoError(t, err)
+	assert.Contains(t, string(cm), "tag: 5\n")
+}
+
+func TestSubstitutionsErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		substitutions string
+		err           string
+	}{
+		"required": {`
+- name: tag
+  required: true
+  source:
+    env: KUSTOMIZE_TEST_NO_SUCH_VARIABLE
+  targets:
+  - select:
+      kind: Deployment
+`, `substitution "tag" is required but has no value`},
+		"not an int": {`
+- name: replicas
+  type: int
+  default: three
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - spec.replicas
+`, `substitution "replicas": "three" is not an int`},
+		"illegal type": {`
+- name: replicas
+  type: float
+  targets:
+  - select:
+      kind: Deployment
+`, `substitution "replicas" has illegal type "float"; legal types: [string int bool]`},
+		"two sources": {`
+- name: tag
+  source:
+    env: TAG
+    file: tag.txt
+  targets:
+  - select:
+      kind: Deployment
+`, `substitution "tag" must specify at most one of resource, env, file and input`},
+		"no targets": {`
+- name: tag
+  default: v1
+`, `substitution "tag" must specify at least one target`},
+		"missing source field": {`
+- name: tag
+  source:
+    resource:
+      kind: ConfigMap
+      fieldPath: data.missing
+  targets:
+  - select:
+      kind: Deployment
+`, "substitution \"tag\": fieldPath `data.missing` is missing for replacement source ConfigMap.[noVer].[noGrp]/[noName].[noNs]"},
+		"env not allowed": {`
+- name: tag
+  source:
+    env: HOME
+  targets:
+  - select:
+      kind: Deployment
+`, `substitution "tag" reads environment variable HOME, which the build doesn't allow`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writeSubstitutionResources(th)
+			th.WriteK(".", "resources:\n- resources.yaml\nsubstitutions:"+tc.substitutions)
+			opts := th.MakeDefaultOptions()
+			opts.AllowedEnv = []string{"KUSTOMIZE_TEST_NO_SUCH_VARIABLE"}
+			err := th.RunWithErr(".", opts)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestSubstitutionsOverrides(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("base/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+`)
+	th.WriteK("base", `
+resources:
+- resources.yaml
+substitutions:
+- name: replicas
+  type: int
+  default: "2"
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - spec.replicas
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+namespace: dev
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Overrides = map[string]string{
+		"replicas":         "4",
+		"namespace":        "prod",
+		"commonLabels.env": "prod",
+	}
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: prod
+  name: app
+  namespace: prod
+spec:
+  replicas: 4
+  selector:
+    matchLabels:
+      env: prod
+  template:
+    metadata:
+      labels:
+        env: prod
+`)
+
+	opts.Overrides = map[string]string{"replicas": "4", "tag": "v1", "image": "app"}
+	err := th.RunWithErr("overlay", opts)
+	assert.EqualError(t, err, "no substitutions named image, tag to override")
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/trace"
+)
+
+func TestTrace(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteK("base", `
+resources:
+- service.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - a=b
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+namePrefix: prod-
+`)
+	expected := th.Run("overlay", th.MakeDefaultOptions())
+	options := th.MakeDefaultOptions()
+	options.Trace = &trace.Trace{}
+	m := th.Run("overlay", options)
+	require.Equal(t, expected.Resources(), m.Resources())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: prod-web
+---
+apiVersion: v1
+data:
+  a: b
+kind: ConfigMap
+metadata:
+  name: prod-config-4h2mbtbbt6
+`)
+
+	var buf bytes.Buffer
+	require.NoError(t, options.Trace.WriteText(&buf))
+	assert.Equal(t, `generator ConfigMapGenerator configured in ../base/kustomization.yaml of /base
+  + ConfigMap.v1.[noGrp]/config.[noNs]
+transformer PrefixTransformer configured in kustomization.yaml of /overlay
+  ~ Service.v1.[noGrp]/prod-web.[noNs]
+      metadata.name: "web" -> "prod-web"
+  ~ ConfigMap.v1.[noGrp]/prod-config.[noNs]
+      metadata.name: "config" -> "prod-config"
+transformer builtins.HashTransformerPlugin of /overlay
+  ~ ConfigMap.v1.[noGrp]/prod-config-4h2mbtbbt6.[noNs]
+      metadata.name: "prod-config" -> "prod-config-4h2mbtbbt6"
+`, buf.String())
+}
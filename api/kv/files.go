@@ -0,0 +1,154 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// fileSource is a file to read, keyed by key.
+type fileSource struct {
+	key  string
+	path string
+}
+
+func isGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// expandFileSource returns the files of the source, which is a
+// single file unless its path is a directory or a glob.
+func (kvl *loader) expandFileSource(
+	source, key, fPath string, opts *types.FileSourceOptions) ([]fileSource, error) {
+	lister, canList := kvl.ldr.(ifc.FileLister)
+	if !isGlob(fPath) && (!canList || !lister.IsDir(fPath)) {
+		return []fileSource{{key: key, path: fPath}}, nil
+	}
+	if !canList {
+		return nil, errors.Errorf("file source %q: files cannot be listed here", source)
+	}
+	if strings.Contains(source, "=") {
+		return nil, errors.Errorf(
+			"file source %q: a key cannot be given to the files of a directory or glob", source)
+	}
+	if opts == nil {
+		opts = &types.FileSourceOptions{}
+	}
+	pattern := path.Clean(filepath.ToSlash(fPath))
+	for _, p := range append([]string{pattern}, opts.Exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(p, "**", "*"), ""); err != nil {
+			return nil, errors.Errorf("file source %q: invalid pattern %q", source, p)
+		}
+	}
+	dir := pattern
+	if isGlob(pattern) {
+		dir = staticDir(pattern)
+	}
+	files, err := lister.ListFiles(filepath.FromSlash(dir))
+	if err != nil {
+		return nil, err
+	}
+	var result []fileSource
+	for _, f := range files {
+		slashPath := filepath.ToSlash(f)
+		if isGlob(pattern) && !matchGlob(pattern, slashPath) {
+			continue
+		}
+		if excluded(opts.Exclude, slashPath) {
+			continue
+		}
+		k, err := fileKey(slashPath, opts.StripPrefix)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "file source %q", source)
+		}
+		result = append(result, fileSource{key: k, path: f})
+	}
+	if len(result) == 0 && isGlob(pattern) {
+		return nil, errors.Errorf("file source %q matches no files", source)
+	}
+	return result, nil
+}
+
+// staticDir returns the directory of the pattern above
+// its first path segment holding a glob.
+func staticDir(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, s := range segments {
+		if isGlob(s) {
+			if i == 1 && segments[0] == "" {
+				return "/"
+			}
+			if i == 0 {
+				return "."
+			}
+			return strings.Join(segments[:i], "/")
+		}
+	}
+	return pattern
+}
+
+// matchGlob reports whether the slash separated name matches
+// the pattern, in which ** matches any number of path segments.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// excluded reports whether the file matches one of the patterns,
+// against its path if the pattern has a slash, else its basename.
+func excluded(patterns []string, slashPath string) bool {
+	for _, p := range patterns {
+		if strings.Contains(p, "/") {
+			if matchGlob(path.Clean(p), slashPath) {
+				return true
+			}
+		} else if matched, _ := path.Match(p, path.Base(slashPath)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// fileKey returns the basename of the file, or with a prefix to
+// strip, the rest of its path with slashes replaced by underscores.
+func fileKey(slashPath, stripPrefix string) (string, error) {
+	if stripPrefix == "" {
+		return path.Base(slashPath), nil
+	}
+	prefix := path.Clean(filepath.ToSlash(stripPrefix))
+	rest := slashPath
+	if prefix != "." {
+		var found bool
+		if rest, found = strings.CutPrefix(slashPath, prefix+"/"); !found {
+			return "", errors.Errorf("file %s is not below stripPrefix %s", slashPath, stripPrefix)
+		}
+	}
+	return strings.ReplaceAll(rest, "/", "_"), nil
+}
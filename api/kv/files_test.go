@@ -0,0 +1,123 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestKeyValuesFromDirectoriesAndGlobs(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	for _, f := range []string{
+		"/config/app.yaml",
+		"/config/app/db.yaml",
+		"/config/app/cache.yaml",
+		"/config/app/README.md",
+		"/config/app/tmp/scratch.yaml",
+		"/other/app.yaml",
+	} {
+		require.NoError(t, fSys.WriteFile(f, []byte(f)))
+	}
+	kvl := makeKvLoader(fSys)
+
+	tests := map[string]struct {
+		sources  []string
+		opts     *types.FileSourceOptions
+		expected []types.Pair
+		err      string
+	}{
+		"directory": {
+			sources: []string{"config/app"},
+			opts:    &types.FileSourceOptions{Exclude: []string{"*.md"}},
+			expected: []types.Pair{
+				{Key: "cache.yaml", Value: "/config/app/cache.yaml"},
+				{Key: "db.yaml", Value: "/config/app/db.yaml"},
+				{Key: "scratch.yaml", Value: "/config/app/tmp/scratch.yaml"},
+			},
+		},
+		"glob": {
+			sources: []string{"config/app/*.yaml"},
+			expected: []types.Pair{
+				{Key: "cache.yaml", Value: "/config/app/cache.yaml"},
+				{Key: "db.yaml", Value: "/config/app/db.yaml"},
+			},
+		},
+		"recursive glob with prefix to strip": {
+			sources: []string{"config/**/*.yaml"},
+			opts: &types.FileSourceOptions{
+				StripPrefix: "config/",
+				Exclude:     []string{"config/app/tmp/**"},
+			},
+			expected: []types.Pair{
+				{Key: "app.yaml", Value: "/config/app.yaml"},
+				{Key: "app_cache.yaml", Value: "/config/app/cache.yaml"},
+				{Key: "app_db.yaml", Value: "/config/app/db.yaml"},
+			},
+		},
+		"glob in leading directory": {
+			sources: []string{"*/app.yaml"},
+			opts:    &types.FileSourceOptions{StripPrefix: "."},
+			expected: []types.Pair{
+				{Key: "config_app.yaml", Value: "/config/app.yaml"},
+				{Key: "other_app.yaml", Value: "/other/app.yaml"},
+			},
+		},
+		"single file": {
+			sources:  []string{"config.yaml=config/app.yaml"},
+			opts:     &types.FileSourceOptions{StripPrefix: "other"},
+			expected: []types.Pair{{Key: "config.yaml", Value: "/config/app.yaml"}},
+		},
+		"no match": {
+			sources: []string{"config/**/*.json"},
+			err:     `file source "config/**/*.json" matches no files`,
+		},
+		"key for a directory": {
+			sources: []string{"app=config/app"},
+			err:     `file source "app=config/app": a key cannot be given to the files of a directory or glob`,
+		},
+		"invalid pattern": {
+			sources: []string{"config/[a"},
+			err:     `file source "config/[a": invalid pattern "config/[a"`,
+		},
+		"file outside the prefix to strip": {
+			sources: []string{"*/app.yaml"},
+			opts:    &types.FileSourceOptions{StripPrefix: "config"},
+			err:     `file source "*/app.yaml": file other/app.yaml is not below stripPrefix config`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pairs, err := kvl.keyValuesFromFileSources(tc.sources, tc.opts)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, pairs)
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	for pattern, names := range map[string][2][]string{
+		"a/*.yaml":      {{"a/b.yaml"}, {"a/b/c.yaml", "b.yaml"}},
+		"a/**/*.yaml":   {{"a/b.yaml", "a/b/c/d.yaml"}, {"b/c.yaml", "a/b.json"}},
+		"**":            {{"a", "a/b/c"}, nil},
+		"a/**":          {{"a/b", "a/b/c"}, {"b/a"}},
+		"a/**/b/**/c":   {{"a/b/c", "a/x/b/y/z/c"}, {"a/c", "a/b/x"}},
+		"config/?.conf": {{"config/a.conf"}, {"config/ab.conf"}},
+	} {
+		for _, name := range names[0] {
+			assert.True(t, matchGlob(pattern, name), "%s should match %s", pattern, name)
+		}
+		for _, name := range names[1] {
+			assert.False(t, matchGlob(pattern, name), "%s should not match %s", pattern, name)
+		}
+	}
+}
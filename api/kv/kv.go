@@ -13,6 +13,8 @@ import (
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/generators"
+	"sigs.k8s.io/kustomize/api/internal/secretproviders"
+	"sigs.k8s.io/kustomize/api/internal/sops"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 )
@@ -26,12 +28,33 @@ type loader struct {
 
 	// Used to validate various k8s data fields.
 	validator ifc.Validator
+
+	// Used to decrypt SOPS-encrypted env and file sources.
+	sops types.SopsConfig
+
+	// Used to pull secret values from external providers.
+	secretProviders types.SecretProvidersConfig
+
+	// If set, literal sources are evaluated as templates with this data.
+	templateData *types.LiteralTemplateData
 }
 
 func NewLoader(ldr ifc.Loader, v ifc.Validator) ifc.KvLoader {
 	return &loader{ldr: ldr, validator: v}
 }
 
+// NewSecretLoader returns a loader that also decrypts SOPS-encrypted
+// env and file sources and pulls secret values from external
+// providers, as enabled by the plugin config.
+func NewSecretLoader(ldr ifc.Loader, v ifc.Validator, c *types.PluginConfig) ifc.SecretValueLoader {
+	kvl := &loader{ldr: ldr, validator: v}
+	if c != nil {
+		kvl.sops = c.SopsConfig
+		kvl.secretProviders = c.SecretProvidersConfig
+	}
+	return kvl
+}
+
 func (kvl *loader) Validator() ifc.Validator {
 	return kvl.validator
 }
@@ -47,13 +70,16 @@ func (kvl *loader) Load(
 	all = append(all, pairs...)
 
 	pairs, err = keyValuesFromLiteralSources(args.LiteralSources)
+	if err == nil && kvl.templateData != nil {
+		pairs, err = kvl.expandTemplates(pairs)
+	}
 	if err != nil {
 		return nil, errors.WrapPrefixf(err,
 			"literal sources %v", args.LiteralSources)
 	}
 	all = append(all, pairs...)
 
-	pairs, err = kvl.keyValuesFromFileSources(args.FileSources)
+	pairs, err = kvl.keyValuesFromFileSources(args.FileSources, args.FileOptions)
 	if err != nil {
 		return nil, errors.WrapPrefixf(err,
 			"file sources: %v", args.FileSources)
@@ -73,18 +99,29 @@ func keyValuesFromLiteralSources(sources []string) ([]types.Pair, error) {
 	return kvs, nil
 }
 
-func (kvl *loader) keyValuesFromFileSources(sources []string) ([]types.Pair, error) {
+func (kvl *loader) keyValuesFromFileSources(
+	sources []string, opts *types.FileSourceOptions) ([]types.Pair, error) {
 	var kvs []types.Pair
 	for _, s := range sources {
 		k, fPath, err := generators.ParseFileSource(s)
 		if err != nil {
 			return nil, err
 		}
-		content, err := kvl.ldr.Load(fPath)
+		files, err := kvl.expandFileSource(s, k, fPath, opts)
 		if err != nil {
 			return nil, err
 		}
-		kvs = append(kvs, types.Pair{Key: k, Value: string(content)})
+		for _, f := range files {
+			content, err := kvl.ldr.Load(f.path)
+			if err != nil {
+				return nil, err
+			}
+			content, err = kvl.decrypt(f.path, content, sops.FormatForPath(f.path))
+			if err != nil {
+				return nil, err
+			}
+			kvs = append(kvs, types.Pair{Key: f.key, Value: string(content)})
+		}
 	}
 	return kvs, nil
 }
@@ -96,6 +133,10 @@ func (kvl *loader) keyValuesFromEnvFiles(paths []string) ([]types.Pair, error) {
 		if err != nil {
 			return nil, err
 		}
+		content, err = kvl.decrypt(p, content, sops.Dotenv)
+		if err != nil {
+			return nil, err
+		}
 		more, err := kvl.keyValuesFromLines(content)
 		if err != nil {
 			return nil, err
@@ -105,6 +146,38 @@ func (kvl *loader) keyValuesFromEnvFiles(paths []string) ([]types.Pair, error) {
 	return kvs, nil
 }
 
+// LoadSecretValues pulls the values from their secret providers.
+func (kvl *loader) LoadSecretValues(values []types.SecretValue) ([]types.Pair, error) {
+	if len(values) > 0 && !kvl.secretProviders.Enabled {
+		return nil, errors.Errorf("must specify --enable-secret-providers to pull secret values")
+	}
+	var kvs []types.Pair
+	for _, v := range values {
+		if v.ValueFrom == nil {
+			return nil, errors.Errorf("secret value %s must specify valueFrom", v.Name)
+		}
+		value, err := secretproviders.GetValue(kvl.secretProviders, v.ValueFrom)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "secret value %s", v.Name)
+		}
+		kvs = append(kvs, types.Pair{Key: v.Name, Value: string(value)})
+	}
+	return kvs, nil
+}
+
+// decrypt returns the decrypted content of a SOPS-encrypted
+// source, leaving other content as is.
+func (kvl *loader) decrypt(path string, content []byte, format sops.Format) ([]byte, error) {
+	if !kvl.sops.Enabled || !sops.IsEncrypted(content, format) {
+		return content, nil
+	}
+	content, err := sops.Decrypt(content, format, kvl.sops.KeyService)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "unable to decrypt %s", path)
+	}
+	return content, nil
+}
+
 // keyValuesFromLines parses given content in to a list of key-value pairs.
 func (kvl *loader) keyValuesFromLines(content []byte) ([]types.Pair, error) {
 	var kvs []types.Pair
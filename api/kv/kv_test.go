@@ -113,7 +113,7 @@ func TestKeyValuesFromFileSources(t *testing.T) {
 	require.NoError(t, err)
 	kvl := makeKvLoader(fSys)
 	for _, tc := range tests {
-		kvs, err := kvl.keyValuesFromFileSources(tc.sources)
+		kvs, err := kvl.keyValuesFromFileSources(tc.sources, nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -0,0 +1,53 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// templateFuncs are the functions templated literals may
+// use, besides the builtin functions of Go templates.
+var templateFuncs = template.FuncMap{ //nolint:gochecknoglobals
+	"default": func(d, v string) string {
+		if v == "" {
+			return d
+		}
+		return v
+	},
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+}
+
+// NewTemplateLoader returns a loader that evaluates the values of
+// literal sources as Go templates with the data.
+func NewTemplateLoader(ldr ifc.Loader, v ifc.Validator, data types.LiteralTemplateData) ifc.KvLoader {
+	return &loader{ldr: ldr, validator: v, templateData: &data}
+}
+
+// expandTemplates evaluates the values of the pairs as templates.
+func (kvl *loader) expandTemplates(pairs []types.Pair) ([]types.Pair, error) {
+	for i, p := range pairs {
+		t, err := template.New(p.Key).Option("missingkey=error").Funcs(templateFuncs).Parse(p.Value)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "invalid template for %s", p.Key)
+		}
+		var b bytes.Buffer
+		if err = t.Execute(&b, kvl.templateData); err != nil {
+			return nil, errors.WrapPrefixf(err, "unable to evaluate the template for %s", p.Key)
+		}
+		pairs[i].Value = b.String()
+	}
+	return pairs, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ldr "sigs.k8s.io/kustomize/api/pkg/loader"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestTemplateLoader(t *testing.T) {
+	t.Setenv("HOME", "/home/alice")
+	kvl := NewTemplateLoader(
+		ldr.NewFileLoaderAtRoot(filesys.MakeFsInMemory()),
+		valtest_test.MakeFakeValidator(),
+		types.LiteralTemplateData{
+			Overlay:    "prod",
+			Namespace:  "shop",
+			Name:       "app",
+			ConfigMaps: map[string]map[string]string{"db": {"host": "db.shop.svc"}},
+			Env:        map[string]string{"CLUSTER": "eu-1", "REGION": ""},
+		})
+
+	pairs, err := kvl.Load(types.KvPairSources{LiteralSources: []string{
+		"ENDPOINT=https://api.{{ .Namespace }}.svc",
+		"PROFILE={{ .Overlay | upper }}-{{ .Name }}",
+		"DB_URL=postgres://{{ .ConfigMaps.db.host }}:5432",
+		"CLUSTER={{ .Env.CLUSTER }}",
+		`REGION={{ .Env.REGION | default "eu-west-1" }}`,
+		"PLAIN=value",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Pair{
+		{Key: "ENDPOINT", Value: "https://api.shop.svc"},
+		{Key: "PROFILE", Value: "PROD-app"},
+		{Key: "DB_URL", Value: "postgres://db.shop.svc:5432"},
+		{Key: "CLUSTER", Value: "eu-1"},
+		{Key: "REGION", Value: "eu-west-1"},
+		{Key: "PLAIN", Value: "value"},
+	}, pairs)
+
+	_, err = kvl.Load(types.KvPairSources{LiteralSources: []string{"HOME={{ .Env.HOME }}"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unable to evaluate the template for HOME`)
+	assert.Contains(t, err.Error(), `map has no entry for key "HOME"`)
+
+	_, err = kvl.Load(types.KvPairSources{LiteralSources: []string{"BAD={{ .Namespace"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid template for BAD")
+}
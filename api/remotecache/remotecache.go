@@ -0,0 +1,210 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package remotecache keeps remote bases and files on disk
+// between builds, so they are not fetched on every invocation.
+package remotecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/copyutil"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	entryFile   = "entry.json"
+	contentName = "content"
+)
+
+// ErrNotCached is returned when an entry must be fetched
+// while the cache is offline.
+var ErrNotCached = errors.Errorf("not in the remote cache")
+
+// Cache holds remote content under Dir, one entry per
+// URL and ref.
+type Cache struct {
+	// Dir holds the entries.
+	Dir string
+
+	// TTL is how long an entry is used before it's fetched again.
+	TTL time.Duration
+
+	// Offline uses entries regardless of their age, and
+	// fails rather than fetching content that isn't cached.
+	Offline bool
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// Entry describes cached content.
+type Entry struct {
+	URL     string    `json:"url"`
+	Ref     string    `json:"ref,omitempty"`
+	Fetched time.Time `json:"fetched"`
+
+	// Path to the cached file or directory.
+	Path string `json:"-"`
+}
+
+// DefaultDir returns the directory holding the cache,
+// i.e. $XDG_CACHE_HOME/kustomize/remote on linux.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "unable to locate remote cache")
+	}
+	return filepath.Join(dir, "kustomize", "remote"), nil
+}
+
+// New returns a cache in DefaultDir.
+func New(ttl time.Duration, offline bool) (*Cache, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: dir, TTL: ttl, Offline: offline}, nil
+}
+
+func (c *Cache) entryDir(url, ref string) string {
+	sum := sha256.Sum256([]byte(url + "\x00" + ref))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+func (c *Cache) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// Lookup returns the entry for the url and ref if it's usable,
+// i.e. younger than the TTL or the cache is offline. When the
+// cache is offline, a missing entry is an ErrNotCached error.
+func (c *Cache) Lookup(url, ref string) (*Entry, bool, error) {
+	e, err := readEntry(c.entryDir(url, ref))
+	if err != nil {
+		return nil, false, err
+	}
+	if e == nil {
+		if c.Offline {
+			return nil, false, fmt.Errorf("unable to fetch %s offline: %w", url, ErrNotCached)
+		}
+		return nil, false, nil
+	}
+	if c.Offline || c.timeNow().Sub(e.Fetched) < c.TTL {
+		return e, true, nil
+	}
+	return nil, false, nil
+}
+
+// Store copies the directory at src, less any .git
+// directory, into the entry for the url and ref.
+func (c *Cache) Store(url, ref, src string) (*Entry, error) {
+	return c.store(url, ref, func(path string) error {
+		return errors.Wrap(copyutil.CopyDir(filesys.MakeFsOnDisk(), src, path))
+	})
+}
+
+// StoreFile writes the content into the entry for the url.
+func (c *Cache) StoreFile(url string, content []byte) (*Entry, error) {
+	return c.store(url, "", func(path string) error {
+		return errors.Wrap(os.WriteFile(path, content, 0o600))
+	})
+}
+
+// store writes the content of an entry in a temporary
+// directory, then moves it into place, so that concurrent
+// builds see either the old entry or the new one.
+func (c *Cache) store(url, ref string, write func(path string) error) (*Entry, error) {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	tmp, err := os.MkdirTemp(c.Dir, ".store-")
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defer os.RemoveAll(tmp)
+	if err = write(filepath.Join(tmp, contentName)); err != nil {
+		return nil, err
+	}
+	e := &Entry{URL: url, Ref: ref, Fetched: c.timeNow()}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if err = os.WriteFile(filepath.Join(tmp, entryFile), b, 0o600); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	dir := c.entryDir(url, ref)
+	if err = os.RemoveAll(dir); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	e.Path = filepath.Join(dir, contentName)
+	return e, nil
+}
+
+// List returns the entries of the cache, by URL and ref.
+func (c *Cache) List() ([]*Entry, error) {
+	dirs, err := os.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var entries []*Entry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		e, err := readEntry(filepath.Join(c.Dir, d.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].URL != entries[j].URL {
+			return entries[i].URL < entries[j].URL
+		}
+		return entries[i].Ref < entries[j].Ref
+	})
+	return entries, nil
+}
+
+// Clean removes all entries of the cache.
+func (c *Cache) Clean() error {
+	return errors.Wrap(os.RemoveAll(c.Dir))
+}
+
+// readEntry returns nil if dir holds no complete entry.
+func readEntry(dir string) (*Entry, error) {
+	b, err := os.ReadFile(filepath.Join(dir, entryFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var e Entry
+	if err = json.Unmarshal(b, &e); err != nil {
+		return nil, errors.WrapPrefixf(err, "corrupt remote cache entry %s", dir)
+	}
+	e.Path = filepath.Join(dir, contentName)
+	return &e, nil
+}
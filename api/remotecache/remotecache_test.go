@@ -0,0 +1,93 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package remotecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheLookupHonorsTTL(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &Cache{Dir: t.TempDir(), TTL: time.Hour, now: func() time.Time { return now }}
+
+	_, ok, err := c.Lookup("https://example.com/a.yaml", "")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = c.StoreFile("https://example.com/a.yaml", []byte("a"))
+	require.NoError(t, err)
+	e, ok, err := c.Lookup("https://example.com/a.yaml", "")
+	require.NoError(t, err)
+	require.True(t, ok)
+	content, err := os.ReadFile(e.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+
+	now = now.Add(2 * time.Hour)
+	_, ok, err = c.Lookup("https://example.com/a.yaml", "")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Offline, expired entries are still used.
+	c.Offline = true
+	_, ok, err = c.Lookup("https://example.com/a.yaml", "")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, _, err = c.Lookup("https://example.com/b.yaml", "")
+	require.ErrorIs(t, err, ErrNotCached)
+	assert.Contains(t, err.Error(), "unable to fetch https://example.com/b.yaml offline")
+}
+
+func TestCacheStoreSkipsGitDir(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "kustomization.yaml"), []byte("k"), 0o600))
+
+	c := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	e, err := c.Store("https://github.com/org/repo", "v1", src)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(e.Path, "kustomization.yaml"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(e.Path, ".git"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCacheListAndClean(t *testing.T) {
+	c := &Cache{Dir: filepath.Join(t.TempDir(), "remote")}
+	entries, err := c.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	src := t.TempDir()
+	for _, ref := range []string{"v2", "v1"} {
+		_, err = c.Store("https://github.com/org/repo", ref, src)
+		require.NoError(t, err)
+	}
+	_, err = c.StoreFile("https://example.com/a.yaml", []byte("a"))
+	require.NoError(t, err)
+
+	entries, err = c.List()
+	require.NoError(t, err)
+	var listed []string
+	for _, e := range entries {
+		listed = append(listed, e.URL+" "+e.Ref)
+	}
+	assert.Equal(t, []string{
+		"https://example.com/a.yaml ",
+		"https://github.com/org/repo v1",
+		"https://github.com/org/repo v2",
+	}, listed)
+
+	require.NoError(t, c.Clean())
+	entries, err = c.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -276,7 +276,8 @@ type ResMap interface {
 	//
 	DeAnchor() error
 
-	// DeepCopy copies the ResMap and underlying resources.
+	// DeepCopy copies the ResMap and underlying resources, which
+	// share their nodes with the originals until changed.
 	DeepCopy() ResMap
 
 	// ShallowCopy copies the ResMap but
@@ -9,6 +9,7 @@ import (
 	"reflect"
 
 	"sigs.k8s.io/kustomize/api/filters/annotations"
+	"sigs.k8s.io/kustomize/api/internal/cel"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/errors"
@@ -631,6 +632,10 @@ func (m *resWrangler) Select(s types.Selector) ([]*resource.Resource, error) {
 	if err != nil {
 		return nil, err
 	}
+	celExpression, err := cel.Parse(s.CelExpression)
+	if err != nil {
+		return nil, err
+	}
 	for _, r := range m.rList {
 		curId := r.CurId()
 		orgId := r.OrgId()
@@ -671,6 +676,15 @@ func (m *resWrangler) Select(s types.Selector) ([]*resource.Resource, error) {
 		if !matched {
 			continue
 		}
+
+		// matches the CEL expression
+		matched, err = celExpression.MatchesNode(&r.RNode)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
 		result = append(result, r)
 	}
 	return result, nil
@@ -727,6 +741,7 @@ func (m *resWrangler) ApplyFilter(f kio.Filter) error {
 	reverseLookup := make(map[*kyaml.RNode]*resource.Resource, len(m.rList))
 	nodes := make([]*kyaml.RNode, len(m.rList))
 	for i, r := range m.rList {
+		r.Own()
 		ptr := &(r.RNode)
 		nodes[i] = ptr
 		reverseLookup[ptr] = r
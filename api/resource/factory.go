@@ -84,6 +84,9 @@ func (rf *Factory) makeOne(rn *yaml.RNode, o *types.GeneratorArgs) *Resource {
 	if o != nil {
 		if o.Options == nil || !o.Options.DisableNameSuffixHash {
 			resource.EnableHashSuffix()
+			if o.Options != nil && o.Options.PruneAnnotation {
+				resource.EnablePruneAnnotation()
+			}
 		}
 		resource.SetBehavior(types.NewGenerationBehavior(o.Behavior))
 	}
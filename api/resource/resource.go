@@ -24,6 +24,9 @@ import (
 type Resource struct {
 	kyaml.RNode
 	refVarNames []string
+	// shared, if not nil, counts the resources sharing the node of
+	// the RNode, copy-on-write.
+	shared *sharedNode
 }
 
 var BuildAnnotations = []string{
@@ -37,6 +40,7 @@ var BuildAnnotations = []string{
 	utils.BuildAnnotationsRefBy,
 	utils.BuildAnnotationsGenBehavior,
 	utils.BuildAnnotationsGenAddHashSuffix,
+	utils.BuildAnnotationsGenPrune,
 
 	kioutil.PathAnnotation,
 	kioutil.IndexAnnotation,
@@ -50,7 +54,7 @@ var BuildAnnotations = []string{
 }
 
 func (r *Resource) ResetRNode(incoming *Resource) {
-	r.RNode = *incoming.Copy()
+	r.shareNodeOf(incoming)
 }
 
 func (r *Resource) GetGvk() resid.Gvk {
@@ -144,11 +148,11 @@ type ResCtx interface {
 // modified in the same kustomize context.
 type ResCtxMatcher func(ResCtx) bool
 
-// DeepCopy returns a new copy of resource
+// DeepCopy returns a new copy of resource, sharing the node of r
+// until either changes it.
 func (r *Resource) DeepCopy() *Resource {
-	rc := &Resource{
-		RNode: *r.Copy(),
-	}
+	rc := &Resource{}
+	rc.shareNodeOf(r)
 	rc.copyKustomizeSpecificFields(r)
 	return rc
 }
@@ -264,7 +268,7 @@ func (r *Resource) appendCsvAnnotation(name, value string) {
 	}
 	currentValue := r.getCsvAnnotation(name)
 	newValue := strings.Join(append(currentValue, value), ",")
-	if err := r.RNode.PipeE(kyaml.SetAnnotation(name, newValue)); err != nil {
+	if err := r.PipeE(kyaml.SetAnnotation(name, newValue)); err != nil {
 		panic(err)
 	}
 }
@@ -410,6 +414,18 @@ func (r *Resource) EnableHashSuffix() {
 	r.enable(utils.BuildAnnotationsGenAddHashSuffix)
 }
 
+// NeedPruneAnnotation returns true if the resource should
+// be annotated with its name and hash once it has a hash suffix.
+func (r *Resource) NeedPruneAnnotation() bool {
+	return r.isEnabled(utils.BuildAnnotationsGenPrune)
+}
+
+// EnablePruneAnnotation marks the resource as needing the
+// annotations of its name and hash.
+func (r *Resource) EnablePruneAnnotation() {
+	r.enable(utils.BuildAnnotationsGenPrune)
+}
+
 // OrgId returns the original, immutable ResId for the resource.
 // This doesn't have to be unique in a ResMap.
 func (r *Resource) OrgId() resid.ResId {
@@ -481,6 +497,8 @@ func (r *Resource) ApplySmPatch(patch *Resource) error {
 	if patch.NameChangeAllowed() || patch.KindChangeAllowed() {
 		r.StorePreviousId()
 	}
+	// the merge may change the patch
+	patch.Own()
 	if err := r.ApplyFilter(patchstrategicmerge.Filter{
 		Patch: &patch.RNode,
 	}); err != nil {
@@ -500,6 +518,7 @@ func (r *Resource) ApplySmPatch(patch *Resource) error {
 }
 
 func (r *Resource) ApplyFilter(f kio.Filter) error {
+	r.Own()
 	l, err := f.Filter([]*kyaml.RNode{&r.RNode})
 	if len(l) == 0 {
 		// The node was deleted, which means the entire resource
@@ -6,6 +6,7 @@ package resource_test
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,6 +14,7 @@ import (
 	"sigs.k8s.io/kustomize/api/provider"
 	. "sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/resid"
 	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -126,6 +128,33 @@ func TestDeepCopy(t *testing.T) {
 	}
 }
 
+func TestDeepCopyIsCopyOnWrite(t *testing.T) {
+	r := factory.FromMap(
+		map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name": "pooh",
+			},
+		})
+	c1 := r.DeepCopy()
+	c2 := c1.DeepCopy()
+	assert.NoError(t, c1.SetLabels(map[string]string{"copy": "one"}))
+	assert.NoError(t, c2.ApplyFilter(kio.FilterFunc(
+		func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+			return nodes, nodes[0].SetName("piglet")
+		})))
+	r.SetKind("StatefulSet")
+
+	assert.Equal(t, `{"apiVersion":"apps/v1","kind":"StatefulSet","metadata":{"name":"pooh"}}`, r.String())
+	assert.Equal(t, `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"labels":{"copy":"one"},"name":"pooh"}}`, c1.String())
+	assert.Equal(t, `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"piglet"}}`, c2.String())
+
+	r.ResetRNode(c1)
+	r.YNode().Content = nil
+	assert.Equal(t, `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"labels":{"copy":"one"},"name":"pooh"}}`, c1.String())
+}
+
 func TestApplySmPatch_1(t *testing.T) {
 	resource, err := factory.FromBytes([]byte(`
 apiVersion: apps/v1
@@ -1554,3 +1583,63 @@ spec:
   numReplicas: 1
 `, r.MustString())
 }
+
+// makeDeployments returns n Deployments.
+func makeDeployments(b *testing.B, n int) []*Resource {
+	b.Helper()
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app-%d
+  labels:
+    app: app-%d
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.25
+        ports:
+        - containerPort: 8080
+`, i, i)
+	}
+	resources, err := factory.SliceFromBytes([]byte(sb.String()))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return resources
+}
+
+// BenchmarkDeepCopy copies 10k resources, e.g. to run a pipeline
+// stage or validator on, and changes but one of them.
+func BenchmarkDeepCopy(b *testing.B) {
+	resources := makeDeployments(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copies := make([]*Resource, len(resources))
+		for j, r := range resources {
+			copies[j] = r.DeepCopy()
+		}
+		if err := copies[0].SetLabels(map[string]string{"app": "changed"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDeepCopyChangeAll copies 10k resources and changes all of
+// them, copy-on-write's worst case.
+func BenchmarkDeepCopyChangeAll(b *testing.B) {
+	resources := makeDeployments(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, r := range resources {
+			if err := r.DeepCopy().SetLabels(map[string]string{"app": "changed"}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"sync/atomic"
+
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// A copy of a Resource shares the node of the original, copy-on-write:
+// the first of them to change the node copies it, unless the others
+// have given it up already.  Resources hence override the methods of
+// kyaml.RNode that change the node, or return parts of it that callers
+// might change, to copy a shared node first.  A copy no longer used
+// still holds the node, so the copies of resources that are never
+// changed again are the ones saved.
+
+// sharedNode counts the resources holding a node.
+type sharedNode struct {
+	holders int32
+}
+
+// shareNodeOf makes r hold the node of other, sharing it.
+func (r *Resource) shareNodeOf(other *Resource) {
+	r.release()
+	if other.shared == nil {
+		other.shared = &sharedNode{holders: 1}
+	}
+	atomic.AddInt32(&other.shared.holders, 1)
+	r.RNode, r.shared = other.RNode, other.shared
+}
+
+// release stops r sharing its node, and reports whether other
+// resources still hold it.
+func (r *Resource) release() bool {
+	if r.shared == nil {
+		return false
+	}
+	held := atomic.AddInt32(&r.shared.holders, -1) > 0
+	r.shared = nil
+	return held
+}
+
+// Own gives r a node of its own, copying the node it shares with its
+// copies, if any.  Callers changing r.RNode other than through the
+// methods of r must call Own first.
+func (r *Resource) Own() {
+	if r.shared == nil {
+		return
+	}
+	// Copy before releasing, so that the last holder changes the
+	// node only after the others are done copying it.
+	if atomic.LoadInt32(&r.shared.holders) > 1 {
+		r.RNode = *r.RNode.Copy()
+	}
+	r.release()
+}
+
+func (r *Resource) YNode() *kyaml.Node {
+	r.Own()
+	return r.RNode.YNode()
+}
+
+func (r *Resource) Document() *kyaml.Node {
+	r.Own()
+	return r.RNode.Document()
+}
+
+func (r *Resource) Content() []*kyaml.Node {
+	r.Own()
+	return r.RNode.Content()
+}
+
+// SetYNode sets the node of r, writing over the node itself only if r
+// doesn't share it.
+func (r *Resource) SetYNode(node *kyaml.Node) {
+	if r.release() {
+		r.RNode.SetYNode(nil)
+	}
+	r.RNode.SetYNode(node)
+}
+
+func (r *Resource) UnmarshalJSON(b []byte) error {
+	r.release()
+	return r.RNode.UnmarshalJSON(b)
+}
+
+func (r *Resource) Pipe(functions ...kyaml.Filter) (*kyaml.RNode, error) {
+	r.Own()
+	return r.RNode.Pipe(functions...)
+}
+
+func (r *Resource) PipeE(functions ...kyaml.Filter) error {
+	r.Own()
+	return r.RNode.PipeE(functions...)
+}
+
+func (r *Resource) SetKind(k string) {
+	r.Own()
+	r.RNode.SetKind(k)
+}
+
+func (r *Resource) SetApiVersion(av string) {
+	r.Own()
+	r.RNode.SetApiVersion(av)
+}
+
+func (r *Resource) SetName(name string) error {
+	r.Own()
+	return r.RNode.SetName(name)
+}
+
+func (r *Resource) SetNamespace(ns string) error {
+	r.Own()
+	return r.RNode.SetNamespace(ns)
+}
+
+func (r *Resource) SetAnnotations(m map[string]string) error {
+	r.Own()
+	return r.RNode.SetAnnotations(m)
+}
+
+func (r *Resource) SetLabels(m map[string]string) error {
+	r.Own()
+	return r.RNode.SetLabels(m)
+}
+
+func (r *Resource) SetMapField(value *kyaml.RNode, path ...string) error {
+	r.Own()
+	return r.RNode.SetMapField(value, path...)
+}
+
+func (r *Resource) SetDataMap(m map[string]string) {
+	r.Own()
+	r.RNode.SetDataMap(m)
+}
+
+func (r *Resource) SetBinaryDataMap(m map[string]string) {
+	r.Own()
+	r.RNode.SetBinaryDataMap(m)
+}
+
+func (r *Resource) LoadMapIntoConfigMapData(m map[string]string) error {
+	r.Own()
+	return r.RNode.LoadMapIntoConfigMapData(m)
+}
+
+func (r *Resource) LoadMapIntoConfigMapBinaryData(m map[string]string) error {
+	r.Own()
+	return r.RNode.LoadMapIntoConfigMapBinaryData(m)
+}
+
+func (r *Resource) LoadMapIntoSecretData(m map[string]string) error {
+	r.Own()
+	return r.RNode.LoadMapIntoSecretData(m)
+}
+
+func (r *Resource) DeAnchor() error {
+	r.Own()
+	return r.RNode.DeAnchor()
+}
+
+func (r *Resource) Field(field string) *kyaml.MapNode {
+	r.Own()
+	return r.RNode.Field(field)
+}
+
+func (r *Resource) FieldRNodes() ([]*kyaml.RNode, error) {
+	r.Own()
+	return r.RNode.FieldRNodes()
+}
+
+func (r *Resource) VisitFields(fn func(node *kyaml.MapNode) error) error {
+	r.Own()
+	return r.RNode.VisitFields(fn)
+}
+
+func (r *Resource) Elements() ([]*kyaml.RNode, error) {
+	r.Own()
+	return r.RNode.Elements()
+}
+
+func (r *Resource) Element(key, value string) *kyaml.RNode {
+	r.Own()
+	return r.RNode.Element(key, value)
+}
+
+func (r *Resource) ElementList(keys []string, values []string) *kyaml.RNode {
+	r.Own()
+	return r.RNode.ElementList(keys, values)
+}
+
+func (r *Resource) VisitElements(fn func(node *kyaml.RNode) error) error {
+	r.Own()
+	return r.RNode.VisitElements(fn)
+}
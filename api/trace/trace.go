@@ -0,0 +1,274 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package trace records the changes that each generator and
+// transformer of a build makes to its resources.
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// Types of steps.
+const (
+	Generator   = "generator"
+	Transformer = "transformer"
+)
+
+// Trace is the list of steps of a build, in the order they end.
+// Bases loaded in parallel record their steps concurrently. A nil
+// Trace records nothing.
+type Trace struct {
+	mu    sync.Mutex
+	Steps []Step `json:"steps"`
+}
+
+// Step records the changes one run of a generator or transformer made.
+type Step struct {
+	// Kustomization is the root of the kustomization running the plugin.
+	Kustomization string `json:"kustomization"`
+
+	// Type is Generator or Transformer.
+	Type string `json:"type"`
+
+	// Plugin is the kind and name of the plugin's config or, lacking
+	// one, the plugin's Go type.
+	Plugin string `json:"plugin"`
+
+	// ConfiguredIn is the file of the plugin's config, if any.
+	ConfiguredIn string `json:"configuredIn,omitempty"`
+
+	// Added, Removed and Modified are the ids of the resources the run
+	// added, removed and modified.
+	Added    []string   `json:"added,omitempty"`
+	Removed  []string   `json:"removed,omitempty"`
+	Modified []Modified `json:"modified,omitempty"`
+}
+
+// Modified records the changes to the fields of a resource.
+type Modified struct {
+	// Resource is the id of the resource after the change.
+	Resource string `json:"resource"`
+
+	Fields []FieldChange `json:"fields"`
+}
+
+// FieldChange records the change of a field, by path, e.g.
+// spec.template.spec.containers[0].image or, for keys holding dots,
+// metadata.labels[app.kubernetes.io/name]. Before is missing for
+// added fields, and After for removed ones.
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Snapshot holds the resources of a ResMap at the beginning of a step.
+type Snapshot struct {
+	resources []*resource.Resource
+	states    map[*resource.Resource]*state
+}
+
+// state holds the id and flattened fields of a resource.
+type state struct {
+	id     string
+	fields map[string]interface{}
+}
+
+// Begin snapshots the resources of m before a step changes them.
+func (t *Trace) Begin(m resmap.ResMap) (*Snapshot, error) {
+	if t == nil {
+		return nil, nil
+	}
+	s := &Snapshot{
+		resources: m.Resources(),
+		states:    map[*resource.Resource]*state{},
+	}
+	for _, r := range s.resources {
+		st, err := makeState(r)
+		if err != nil {
+			return nil, err
+		}
+		s.states[r] = st
+	}
+	return s, nil
+}
+
+// End records step, with the changes from s to the resources of m.
+// Resources are matched by identity or, failing that, by id.
+func (t *Trace) End(s *Snapshot, step Step, m resmap.ResMap) error {
+	if t == nil {
+		return nil
+	}
+	matched := map[*resource.Resource]bool{}
+	unmatched := map[string]*state{}
+	for _, r := range s.resources {
+		unmatched[s.states[r].id] = s.states[r]
+	}
+	after := m.Resources()
+	for _, r := range after {
+		if _, ok := s.states[r]; ok {
+			matched[r] = true
+			delete(unmatched, s.states[r].id)
+		}
+	}
+	for _, r := range after {
+		st, err := makeState(r)
+		if err != nil {
+			return err
+		}
+		before, ok := s.states[r]
+		if !ok {
+			if before, ok = unmatched[st.id]; !ok {
+				step.Added = append(step.Added, st.id)
+				continue
+			}
+			delete(unmatched, st.id)
+		}
+		if fields := diff(before.fields, st.fields); len(fields) > 0 {
+			step.Modified = append(step.Modified, Modified{Resource: st.id, Fields: fields})
+		}
+	}
+	for _, r := range s.resources {
+		if st := s.states[r]; !matched[r] && unmatched[st.id] == st {
+			step.Removed = append(step.Removed, st.id)
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Steps = append(t.Steps, step)
+	return nil
+}
+
+// WriteJSON writes t to w as JSON.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return errors.WrapPrefixf(err, "failed to marshal trace")
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// WriteText writes t to w as a log, a paragraph per step.
+func (t *Trace) WriteText(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var b strings.Builder
+	for _, step := range t.Steps {
+		fmt.Fprintf(&b, "%s %s", step.Type, step.Plugin)
+		if step.ConfiguredIn != "" {
+			fmt.Fprintf(&b, " configured in %s", step.ConfiguredIn)
+		}
+		fmt.Fprintf(&b, " of %s\n", step.Kustomization)
+		for _, id := range step.Added {
+			fmt.Fprintf(&b, "  + %s\n", id)
+		}
+		for _, id := range step.Removed {
+			fmt.Fprintf(&b, "  - %s\n", id)
+		}
+		for _, mod := range step.Modified {
+			fmt.Fprintf(&b, "  ~ %s\n", mod.Resource)
+			for _, f := range mod.Fields {
+				fmt.Fprintf(&b, "      %s: %s -> %s\n", f.Path, textValue(f.Before), textValue(f.After))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// textValue formats a field value for WriteText.
+func textValue(v interface{}) string {
+	if v == nil {
+		return "<none>"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func makeState(r *resource.Resource) (*state, error) {
+	m, err := r.Map()
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "failed to trace %s", r.CurId())
+	}
+	st := &state{id: r.CurId().String(), fields: map[string]interface{}{}}
+	flatten("", m, st.fields)
+	return st, nil
+}
+
+// flatten adds the leaves of v, i.e. its scalars and empty maps and
+// lists, to fields by path, but for the annotations kustomize uses
+// internally.
+func flatten(path string, v interface{}, fields map[string]interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			fields[path] = v
+		}
+		for k, x := range v {
+			if path == "metadata.annotations" && isInternalAnnotation(k) {
+				continue
+			}
+			if strings.Contains(k, ".") {
+				flatten(path+"["+k+"]", x, fields)
+			} else if path == "" {
+				flatten(k, x, fields)
+			} else {
+				flatten(path+"."+k, x, fields)
+			}
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			fields[path] = v
+		}
+		for i, x := range v {
+			flatten(fmt.Sprintf("%s[%d]", path, i), x, fields)
+		}
+	default:
+		fields[path] = v
+	}
+}
+
+func isInternalAnnotation(key string) bool {
+	return strings.HasPrefix(key, konfig.ConfigAnnoDomain+"/") ||
+		key == utils.OriginAnnotationKey || key == utils.TransformerAnnotationKey
+}
+
+// diff returns the changes from the fields before to those after,
+// sorted by path.
+func diff(before, after map[string]interface{}) []FieldChange {
+	var changes []FieldChange
+	for path, b := range before {
+		a, ok := after[path]
+		if !ok {
+			changes = append(changes, FieldChange{Path: path, Before: b})
+		} else if !reflect.DeepEqual(a, b) {
+			changes = append(changes, FieldChange{Path: path, Before: b, After: a})
+		}
+	}
+	for path, a := range after {
+		if _, ok := before[path]; !ok {
+			changes = append(changes, FieldChange{Path: path, After: a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
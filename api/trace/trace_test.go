@@ -0,0 +1,106 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package trace_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	. "sigs.k8s.io/kustomize/api/trace"
+)
+
+func TestTrace(t *testing.T) {
+	rmF := resmap.NewFactory(provider.NewDefaultDepProvider().GetResourceFactory())
+	m, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  annotations:
+    internal.config.kubernetes.io/previousNames: a
+data:
+  x: "1"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+spec:
+  replicas: 1
+`))
+	require.NoError(t, err)
+	tr := &Trace{}
+	s, err := tr.Begin(m)
+	require.NoError(t, err)
+
+	a := m.Resources()[0]
+	require.NoError(t, a.SetLabels(map[string]string{"app.kubernetes.io/name": "a"}))
+	a.SetDataMap(map[string]string{"x": "2"})
+	require.NoError(t, a.SetAnnotations(map[string]string{"internal.config.kubernetes.io/previousNames": "b"}))
+	require.NoError(t, m.Remove(m.Resources()[1].CurId()))
+	added, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+spec:
+  replicas: 2
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: d
+`))
+	require.NoError(t, err)
+	_, err = m.Replace(added.Resources()[0])
+	require.NoError(t, err)
+	require.NoError(t, m.Append(added.Resources()[1]))
+
+	require.NoError(t, tr.End(s, Step{Kustomization: "/app", Type: Transformer, Plugin: "Test"}, m))
+	assert.Equal(t, []Step{{
+		Kustomization: "/app",
+		Type:          Transformer,
+		Plugin:        "Test",
+		Added:         []string{"Secret.v1.[noGrp]/d.[noNs]"},
+		Removed:       []string{"Service.v1.[noGrp]/b.[noNs]"},
+		Modified: []Modified{{
+			Resource: "ConfigMap.v1.[noGrp]/a.[noNs]",
+			Fields: []FieldChange{
+				{Path: "data.x", Before: "1", After: "2"},
+				{Path: "metadata.labels[app.kubernetes.io/name]", After: "a"},
+			},
+		}, {
+			Resource: "Deployment.v1.apps/c.[noNs]",
+			Fields:   []FieldChange{{Path: "spec.replicas", Before: 1, After: 2}},
+		}},
+	}}, tr.Steps)
+
+	var buf bytes.Buffer
+	require.NoError(t, tr.WriteText(&buf))
+	assert.Equal(t, `transformer Test of /app
+  + Secret.v1.[noGrp]/d.[noNs]
+  - Service.v1.[noGrp]/b.[noNs]
+  ~ ConfigMap.v1.[noGrp]/a.[noNs]
+      data.x: "1" -> "2"
+      metadata.labels[app.kubernetes.io/name]: <none> -> "a"
+  ~ Deployment.v1.apps/c.[noNs]
+      spec.replicas: 1 -> 2
+`, buf.String())
+}
+
+func TestTraceNil(t *testing.T) {
+	var tr *Trace
+	s, err := tr.Begin(resmap.New())
+	require.NoError(t, err)
+	require.NoError(t, tr.End(s, Step{}, resmap.New()))
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// DefaultApplySetTooling is the default tooling of ApplySet parents:
+// kubectl's, as of the first kubectl release supporting ApplySets,
+// so that kubectl apply --prune --applyset adopts them.
+const DefaultApplySetTooling = "kubectl/v1.27.0"
+
+// ApplySet is the parent object of an ApplySet, per
+// https://git.k8s.io/enhancements/keps/sig-cli/3659-kubectl-apply-prune,
+// whose members are the resources of a build.
+type ApplySet struct {
+	// Kind of the parent, Secret (the default) or ConfigMap.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// Name of the parent.
+	Name string `json:"name" yaml:"name"`
+
+	// Namespace of the parent; that of the namespaced members if
+	// empty, which must then all be in the same namespace.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Tooling managing the ApplySet, e.g. kubectl/v1.27.0;
+	// DefaultApplySetTooling if empty.
+	Tooling string `json:"tooling,omitempty" yaml:"tooling,omitempty"`
+}
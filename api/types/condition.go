@@ -0,0 +1,19 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// ConditionalPath is a resource or component that is only included in
+// the build if its condition holds.
+type ConditionalPath struct {
+	// Path of the resource or component, as given in resources or
+	// components.
+	Path string `json:"path" yaml:"path"`
+
+	// Condition is an expression over build parameters, e.g.
+	// `monitoring`, `!monitoring` or `env == "prod" && region != eu`.
+	// A bare parameter must be a bool and is false if unset; an unset
+	// parameter compares equal to the empty string. Operators are ==,
+	// !=, !, && and ||, and parentheses group.
+	Condition string `json:"condition" yaml:"condition"`
+}
@@ -7,4 +7,31 @@ package types
 type ConfigMapArgs struct {
 	// GeneratorArgs for the configmap.
 	GeneratorArgs `json:",inline,omitempty" yaml:",inline,omitempty"`
+
+	// TemplateLiterals, if true, evaluates the values of the
+	// literals as Go templates with LiteralTemplateData, e.g.
+	// ENDPOINT=https://api.{{ .Namespace }}.svc
+	TemplateLiterals bool `json:"templateLiterals,omitempty" yaml:"templateLiterals,omitempty"`
+}
+
+// LiteralTemplateData is the data the templated
+// literals of a configMapGenerator are evaluated with.
+type LiteralTemplateData struct {
+	// Overlay is the name of the directory of the kustomization.
+	Overlay string `json:"overlay,omitempty" yaml:"overlay,omitempty"`
+
+	// Namespace of the configmap, by default
+	// that of the kustomization.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Name of the configmap, without its hash suffix.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// ConfigMaps holds the literals of the configMapGenerators
+	// listed before this one in the kustomization, by name and key.
+	ConfigMaps map[string]map[string]string `json:"configMaps,omitempty" yaml:"configMaps,omitempty"`
+
+	// Env holds the environment variables the build allows,
+	// e.g. {{ .Env.CLUSTER }}. Others are not readable.
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
 }
@@ -18,6 +18,13 @@ type GeneratorOptions struct {
 
 	// Immutable if true add to all generated resources.
 	Immutable bool `json:"immutable,omitempty" yaml:"immutable,omitempty"`
+
+	// PruneAnnotation if true annotates generated resources whose
+	// names get a hash suffix with their name without it and their
+	// hash, so that controllers can find the superseded versions of
+	// a generated resource, i.e. those of the same name but another
+	// hash, and garbage collect them.
+	PruneAnnotation bool `json:"pruneAnnotation,omitempty" yaml:"pruneAnnotation,omitempty"`
 }
 
 // MergeGlobalOptionsIntoLocal merges two instances of GeneratorOptions.
@@ -48,6 +55,9 @@ func MergeGlobalOptionsIntoLocal(
 	if globalOpts.Immutable {
 		localOpts.Immutable = true
 	}
+	if globalOpts.PruneAnnotation {
+		localOpts.PruneAnnotation = true
+	}
 	return localOpts
 }
 
@@ -96,6 +96,19 @@ func TestMergeGlobalOptionsIntoLocal(t *testing.T) {
 				Immutable:             true,
 			},
 		},
+		{
+			name: "global prune annotation trumps local",
+			local: &GeneratorOptions{
+				Immutable: true,
+			},
+			global: &GeneratorOptions{
+				PruneAnnotation: true,
+			},
+			expected: &GeneratorOptions{
+				Immutable:       true,
+				PruneAnnotation: true,
+			},
+		},
 		{
 			name: "local disable works",
 			local: &GeneratorOptions{
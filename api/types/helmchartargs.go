@@ -46,6 +46,14 @@ type HelmChart struct {
 	// `https://itzg.github.io/minecraft-server-charts`.
 	Repo string `json:"repo,omitempty" yaml:"repo,omitempty"`
 
+	// CredentialsFile is the path to a registry config holding
+	// credentials for the Repo, in the format of a docker config.
+	// It is the argument to helm's `--registry-config` flag, and
+	// is typically used with `oci://` repos. Relative paths are
+	// resolved against the kustomization root. If omitted, helm
+	// falls back to the credentials in the docker config.
+	CredentialsFile string `json:"credentialsFile,omitempty" yaml:"credentialsFile,omitempty"`
+
 	// ReleaseName replaces RELEASE-NAME in chart template output,
 	// making a particular inflation of a chart unique with respect to
 	// other inflations of the same chart in a cluster. It's the first
@@ -22,4 +22,9 @@ type Image struct {
 	// Digest is the value used to replace the original image tag.
 	// If digest is present NewTag value is ignored.
 	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+
+	// ResolveDigests replaces the tag of the image with its digest,
+	// looked up in the registry with the credentials of the docker
+	// config, unless Digest is present.
+	ResolveDigests bool `json:"resolveDigests,omitempty" yaml:"resolveDigests,omitempty"`
 }
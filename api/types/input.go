@@ -0,0 +1,21 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Input declares a value that the kustomizations listing a component
+// pass to it in componentInputs. The component reads it in the source
+// of a substitution or as a parameter of its conditions.
+type Input struct {
+	// Name of the input.
+	Name string `json:"name" yaml:"name"`
+
+	// Type of the value, string (the default), int or bool.
+	Type SubstitutionType `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Default value, given as a string, used if none is passed.
+	Default *string `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Required makes the build fail if no value is passed.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+}
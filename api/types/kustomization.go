@@ -26,9 +26,11 @@ const (
 	OriginAnnotations      = "originAnnotations"
 	TransformerAnnotations = "transformerAnnotations"
 	ManagedByLabelOption   = "managedByLabel"
+	BuildProvenanceOption  = "buildProvenance"
 )
 
-var BuildMetadataOptions = []string{OriginAnnotations, TransformerAnnotations, ManagedByLabelOption}
+var BuildMetadataOptions = []string{
+	OriginAnnotations, TransformerAnnotations, ManagedByLabelOption, BuildProvenanceOption}
 
 // Kustomization holds the information needed to generate customized k8s api resources.
 type Kustomization struct {
@@ -52,9 +54,21 @@ type Kustomization struct {
 	// file including generated configmaps and secrets.
 	NameSuffix string `json:"nameSuffix,omitempty" yaml:"nameSuffix,omitempty"`
 
-	// Namespace to add to all objects.
+	// NamePrefixes prefix the names of the resources each of them
+	// selects, after NamePrefix.
+	NamePrefixes []NameAffix `json:"namePrefixes,omitempty" yaml:"namePrefixes,omitempty"`
+
+	// NameSuffixes suffix the names of the resources each of them
+	// selects, after NameSuffix.
+	NameSuffixes []NameAffix `json:"nameSuffixes,omitempty" yaml:"nameSuffixes,omitempty"`
+
+	// Namespace to add to all objects. It may read build parameters,
+	// e.g. team-${env}, like conditions do.
 	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 
+	// NamespaceOptions refine how the Namespace is applied.
+	NamespaceOptions *NamespaceOptions `json:"namespaceOptions,omitempty" yaml:"namespaceOptions,omitempty"`
+
 	// CommonLabels to add to all objects and selectors.
 	CommonLabels map[string]string `json:"commonLabels,omitempty" yaml:"commonLabels,omitempty"`
 
@@ -64,6 +78,9 @@ type Kustomization struct {
 	// CommonAnnotations to add to all objects.
 	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty" yaml:"commonAnnotations,omitempty"`
 
+	// Annotations to add to the objects they select.
+	Annotations []Annotation `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+
 	// Deprecated: Use the Patches field instead, which provides a superset of the functionality of PatchesStrategicMerge.
 	// PatchesStrategicMerge specifies the relative path to a file
 	// containing a strategic merge patch.  Format documented at
@@ -94,10 +111,23 @@ type Kustomization struct {
 	// specified source to N specified targets.
 	Replacements []ReplacementField `json:"replacements,omitempty" yaml:"replacements,omitempty"`
 
+	// Substitutions is a list of typed values, each read from a resource
+	// field, an environment variable or a file, and written to N
+	// specified targets.
+	Substitutions []Substitution `json:"substitutions,omitempty" yaml:"substitutions,omitempty"`
+
 	// Replicas is a list of {resourcename, count} that allows for simpler replica
 	// specification. This can also be done with a patch.
 	Replicas []Replica `json:"replicas,omitempty" yaml:"replicas,omitempty"`
 
+	// ResourceOverrides set or scale the resource requests and limits
+	// of the containers of workloads. This can also be done with patches.
+	ResourceOverrides []ResourceOverride `json:"resourceOverrides,omitempty" yaml:"resourceOverrides,omitempty"`
+
+	// Scheduling adds tolerations, node selectors, topology spread
+	// constraints and affinity to the pods of workloads.
+	Scheduling []Scheduling `json:"scheduling,omitempty" yaml:"scheduling,omitempty"`
+
 	// Deprecated: Vars will be removed in future release. Migrate to Replacements instead.
 	// Vars allow things modified by kustomize to be injected into a
 	// kubernetes object specification. A var is a name (e.g. FOO) associated
@@ -120,10 +150,26 @@ type Kustomization struct {
 	// via relative paths, absolute paths, or URLs.
 	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
 
+	// ConditionalResources are resources included after those of Resources
+	// if their condition holds.
+	ConditionalResources []ConditionalPath `json:"conditionalResources,omitempty" yaml:"conditionalResources,omitempty"`
+
 	// Components specifies relative paths to specifications of other Components
 	// via relative paths, absolute paths, or URLs.
 	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
 
+	// ConditionalComponents are components included after those of
+	// Components if their condition holds.
+	ConditionalComponents []ConditionalPath `json:"conditionalComponents,omitempty" yaml:"conditionalComponents,omitempty"`
+
+	// ComponentInputs holds, by the path of a component, the scalar values
+	// passed to its inputs.
+	ComponentInputs map[string]map[string]interface{} `json:"componentInputs,omitempty" yaml:"componentInputs,omitempty"`
+
+	// Inputs declares the values a Component accepts from the
+	// kustomizations listing it.
+	Inputs []Input `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+
 	// Crds specifies relative paths to Custom Resource Definition files.
 	// This allows custom resources to be recognized as operands, making
 	// it possible to add them to the Resources list.
@@ -151,6 +197,11 @@ type Kustomization struct {
 	// the map will have a suffix hash generated from its contents.
 	SecretGenerator []SecretArgs `json:"secretGenerator,omitempty" yaml:"secretGenerator,omitempty"`
 
+	// NetworkPolicyGenerator is a list of network policies to generate
+	// from compact lists of allowed peers (one policy per list item).
+	// The pod selectors of the policies include the commonLabels.
+	NetworkPolicyGenerator []NetworkPolicyArgs `json:"networkPolicyGenerator,omitempty" yaml:"networkPolicyGenerator,omitempty"`
+
 	// HelmGlobals contains helm configuration that isn't chart specific.
 	HelmGlobals *HelmGlobals `json:"helmGlobals,omitempty" yaml:"helmGlobals,omitempty"`
 
@@ -164,7 +215,9 @@ type Kustomization struct {
 	// GeneratorOptions modify behavior of all ConfigMap and Secret generators.
 	GeneratorOptions *GeneratorOptions `json:"generatorOptions,omitempty" yaml:"generatorOptions,omitempty"`
 
-	// Configurations is a list of transformer configuration files
+	// Configurations is a list of transformer configuration files,
+	// whose field specs add to the builtin ones, except for the fields
+	// a file lists under override, whose field specs replace them.
 	Configurations []string `json:"configurations,omitempty" yaml:"configurations,omitempty"`
 
 	// Generators is a list of files containing custom generators
@@ -173,6 +226,10 @@ type Kustomization struct {
 	// Transformers is a list of files containing transformers
 	Transformers []string `json:"transformers,omitempty" yaml:"transformers,omitempty"`
 
+	// Pipeline is a list of stages of transformers, run in order
+	// after Transformers, each on the resources it selects.
+	Pipeline []PipelineStage `json:"pipeline,omitempty" yaml:"pipeline,omitempty"`
+
 	// Validators is a list of files containing validators
 	Validators []string `json:"validators,omitempty" yaml:"validators,omitempty"`
 
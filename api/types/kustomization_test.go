@@ -97,7 +97,7 @@ func TestKustomization_CheckDeprecatedFields(t *testing.T) {
 func TestFixKustomizationPostUnmarshalling(t *testing.T) {
 	var k Kustomization
 	k.Bases = append(k.Bases, "foo")
-	k.ConfigMapGenerator = []ConfigMapArgs{{GeneratorArgs{
+	k.ConfigMapGenerator = []ConfigMapArgs{{GeneratorArgs: GeneratorArgs{
 		KvPairSources: KvPairSources{
 			EnvSources: []string{"a", "b"},
 			EnvSource:  "c",
@@ -114,7 +114,7 @@ func TestFixKustomizationPostUnmarshalling(t *testing.T) {
 			APIVersion: KustomizationVersion,
 		},
 		Resources: []string{"foo"},
-		ConfigMapGenerator: []ConfigMapArgs{{GeneratorArgs{
+		ConfigMapGenerator: []ConfigMapArgs{{GeneratorArgs: GeneratorArgs{
 			KvPairSources: KvPairSources{
 				EnvSources: []string{"a", "b", "c"},
 			},
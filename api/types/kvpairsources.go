@@ -17,11 +17,18 @@ type KvPairSources struct {
 	// path's basename. If they "key=" part is present,
 	// it becomes the key (replacing the basename).
 	// In either case, the value is the file contents.
-	// Specifying a directory will iterate each named
-	// file in the directory whose basename is a
-	// valid configmap key.
+	// Specifying a directory will iterate each file
+	// in the directory and its subdirectories, and a
+	// glob, e.g. config/**/*.yaml where ** matches any
+	// number of directories, each file it matches.
+	// These files are keyed by their basename, unless
+	// FileOptions says otherwise.
 	FileSources []string `json:"files,omitempty" yaml:"files,omitempty"`
 
+	// FileOptions tune how the directories and globs
+	// of FileSources are expanded.
+	FileOptions *FileSourceOptions `json:"fileOptions,omitempty" yaml:"fileOptions,omitempty"`
+
 	// EnvSources is a list of file paths.
 	// The contents of each file should be one
 	// key=value pair per line, e.g. a Docker
@@ -34,3 +41,19 @@ type KvPairSources struct {
 	// for consistency with LiteralSources and FileSources.
 	EnvSource string `json:"env,omitempty" yaml:"env,omitempty"`
 }
+
+// FileSourceOptions tune how the directories and
+// globs of FileSources are expanded.
+type FileSourceOptions struct {
+	// StripPrefix, if set, keys the files found in directories
+	// and by globs by their path with this prefix removed, and
+	// slashes replaced by underscores, rather than by their
+	// basename, e.g. app_db.yaml for config/app/db.yaml
+	// given the prefix config/.
+	StripPrefix string `json:"stripPrefix,omitempty" yaml:"stripPrefix,omitempty"`
+
+	// Exclude lists glob patterns of files not to include.
+	// Patterns with a slash are matched against the path
+	// of the files, others against their basename.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
@@ -17,6 +17,31 @@ type Label struct {
 	// is true. If IncludeSelectors is true, IncludeTemplates is not needed.
 	IncludeTemplates bool        `json:"includeTemplates,omitempty" yaml:"includeTemplates,omitempty"`
 	FieldSpecs       []FieldSpec `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// ExcludeTemplates removes the fieldSpecs of templates, e.g.
+	// spec/template/metadata/labels, from the custom fieldSpecs.
+	// It cannot be combined with IncludeSelectors or IncludeTemplates.
+	ExcludeTemplates bool `json:"excludeTemplates,omitempty" yaml:"excludeTemplates,omitempty"`
+	// Select limits the resources the labels are added to to those
+	// matching any of these. All resources if empty.
+	Select []*Selector `json:"select,omitempty" yaml:"select,omitempty"`
+	// Reject removes the resources matching any of these.
+	Reject []*Selector `json:"reject,omitempty" yaml:"reject,omitempty"`
+}
+
+// Annotation is a set of annotations added to the resources it
+// selects, like CommonAnnotations.
+type Annotation struct {
+	// Pairs contains the key-value pairs for annotations to add
+	Pairs map[string]string `json:"pairs,omitempty" yaml:"pairs,omitempty"`
+	// ExcludeTemplates removes the fieldSpecs of templates, e.g.
+	// spec/template/metadata/annotations, from the builtin fieldSpecs,
+	// so that the annotations don't change the pods of workloads.
+	ExcludeTemplates bool `json:"excludeTemplates,omitempty" yaml:"excludeTemplates,omitempty"`
+	// Select limits the resources the annotations are added to to
+	// those matching any of these. All resources if empty.
+	Select []*Selector `json:"select,omitempty" yaml:"select,omitempty"`
+	// Reject removes the resources matching any of these.
+	Reject []*Selector `json:"reject,omitempty" yaml:"reject,omitempty"`
 }
 
 func labelFromCommonLabels(commonLabels map[string]string) *Label {
@@ -0,0 +1,22 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// NameAffix is a prefix or suffix of the names of the resources it
+// selects, and of the references to them.
+type NameAffix struct {
+	// Value is the prefix or suffix.
+	Value string `json:"value" yaml:"value"`
+
+	// Select limits the resources to those matching any of these.
+	// All resources if empty.
+	Select []*Selector `json:"select,omitempty" yaml:"select,omitempty"`
+
+	// Reject removes the resources matching any of these.
+	Reject []*Selector `json:"reject,omitempty" yaml:"reject,omitempty"`
+
+	// SkipClusterScoped removes the cluster-scoped resources,
+	// e.g. ClusterRoles.
+	SkipClusterScoped bool `json:"skipClusterScoped,omitempty" yaml:"skipClusterScoped,omitempty"`
+}
@@ -0,0 +1,12 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// NamespaceOptions refine how the namespace field is applied.
+type NamespaceOptions struct {
+	// Create adds a Namespace object named by the namespace field,
+	// which the other transformers, e.g. commonLabels, apply to,
+	// unless the resources already include it.
+	Create bool `json:"create,omitempty" yaml:"create,omitempty"`
+}
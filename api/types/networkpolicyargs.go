@@ -0,0 +1,48 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// NetworkPolicyArgs contains the metadata of a NetworkPolicy to
+// generate, and an allowlist of the peers of the pods it applies to.
+type NetworkPolicyArgs struct {
+	// Name of the NetworkPolicy; the app by default.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Namespace of the NetworkPolicy.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// App selects the pods the policy applies to by their app
+	// label; all pods of the namespace by default.
+	App string `json:"app,omitempty" yaml:"app,omitempty"`
+
+	// AppLabel is the key of the app label, app by default.
+	AppLabel string `json:"appLabel,omitempty" yaml:"appLabel,omitempty"`
+
+	// Ingress lists the allowed sources of traffic to the pods.
+	// Any other ingress traffic is denied.
+	Ingress []NetworkPolicyRule `json:"ingress,omitempty" yaml:"ingress,omitempty"`
+
+	// Egress lists the allowed destinations of traffic from the
+	// pods. Egress traffic is only restricted if this is set.
+	Egress []NetworkPolicyRule `json:"egress,omitempty" yaml:"egress,omitempty"`
+}
+
+// NetworkPolicyRule allows traffic with peers on ports.
+// A rule without peers allows all peers.
+type NetworkPolicyRule struct {
+	// Apps are the app labels of peer pods.
+	Apps []string `json:"apps,omitempty" yaml:"apps,omitempty"`
+
+	// Namespaces are the names of the namespaces of peer pods,
+	// the namespace of the policy by default. Without apps,
+	// all pods of the namespaces are peers.
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+
+	// CIDRs are the IP blocks of peers, e.g. 10.0.0.0/8.
+	CIDRs []string `json:"cidrs,omitempty" yaml:"cidrs,omitempty"`
+
+	// Ports are port numbers or names with an optional protocol,
+	// TCP by default, e.g. 8080, 53/UDP or http. All ports by default.
+	Ports []string `json:"ports,omitempty" yaml:"ports,omitempty"`
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// FailurePolicy tells what a pipeline does when a stage fails.
+type FailurePolicy string
+
+const (
+	// FailOnFailure fails the build. It is the default.
+	FailOnFailure FailurePolicy = "fail"
+	// ContinueOnFailure drops the changes of the failed stage
+	// and runs the next stage.
+	ContinueOnFailure FailurePolicy = "continue"
+	// SkipOnFailure drops the changes of the failed stage and
+	// skips the remaining stages.
+	SkipOnFailure FailurePolicy = "skip"
+)
+
+// PipelineStage is a stage of the pipeline of a kustomization,
+// running transformers, typically KRM functions, on the resources.
+type PipelineStage struct {
+	// Name of the stage, used in errors and warnings.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Transformer is the config of the transformers of the stage,
+	// given as an entry of transformers: a path or an inline config.
+	Transformer string `json:"transformer" yaml:"transformer"`
+
+	// Select limits the resources the stage sees to those selected.
+	// All resources if nil.
+	Select *Selector `json:"select,omitempty" yaml:"select,omitempty"`
+
+	// OnFailure is the failure policy of the stage: fail (the
+	// default), continue or skip.
+	OnFailure FailurePolicy `json:"onFailure,omitempty" yaml:"onFailure,omitempty"`
+}
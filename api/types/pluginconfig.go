@@ -8,6 +8,73 @@ type HelmConfig struct {
 	Command     string
 	ApiVersions []string
 	KubeVersion string
+
+	// Renderer, if set, is used to pull and template charts in
+	// place of running Command, so that helm charts can be
+	// inflated without a helm binary on the PATH.
+	Renderer HelmRenderer
+}
+
+// HelmRenderer renders helm charts on behalf of the
+// HelmChartInflationGenerator.
+type HelmRenderer interface {
+	// Pull fetches the chart from its repo, untarring it
+	// into chartHome, as `helm pull --untar` would.
+	Pull(chart HelmChart, chartHome string) error
+
+	// Template renders the chart found in chartHome, as
+	// `helm template` would, returning a YAML stream.
+	// The chart's ValuesFile holds the merged values.
+	Template(chart HelmChart, chartHome string) ([]byte, error)
+}
+
+// SopsConfig allows and configures the decryption of
+// SOPS-encrypted secretGenerator files and envs.
+type SopsConfig struct {
+	Enabled bool
+
+	// KeyService, if set, decrypts the data keys of SOPS files
+	// in place of the age identities found in the environment,
+	// e.g. to use cloud KMS or PGP keys.
+	KeyService SopsKeyService
+}
+
+// SopsKeyService decrypts the data keys of SOPS files,
+// as a SOPS key service would.
+type SopsKeyService interface {
+	// Decrypt returns the data key encrypted with the master key.
+	Decrypt(key SopsMasterKey) ([]byte, error)
+}
+
+// SopsMasterKey is a key the data key of a SOPS file is encrypted with.
+type SopsMasterKey struct {
+	// Type is the type of the key in the metadata of the
+	// file: age, pgp, kms, gcp_kms, azure_kv or hc_vault.
+	Type string
+
+	// Metadata holds the other fields of the key, e.g. the
+	// recipient of an age key or the arn of a kms key.
+	Metadata map[string]string
+
+	// EncryptedKey is the data key encrypted with this key.
+	EncryptedKey string
+}
+
+// SecretProvidersConfig allows and configures pulling the
+// values of secretGenerator secrets from external providers.
+type SecretProvidersConfig struct {
+	Enabled bool
+
+	// Providers, if set, are used by name in place of, or in
+	// addition to, the builtin vault, aws and gcp providers.
+	Providers map[string]SecretProvider
+}
+
+// SecretProvider reads secrets from an external secret store.
+type SecretProvider interface {
+	// GetSecret returns the value of the key of the secret at
+	// path, or its only value if key is empty.
+	GetSecret(path, key string) ([]byte, error)
 }
 
 // PluginConfig holds plugin configuration.
@@ -23,6 +90,13 @@ type PluginConfig struct {
 
 	// HelmConfig contains metadata needed for allowing and running helm.
 	HelmConfig HelmConfig
+
+	// SopsConfig allows the decryption of SOPS-encrypted secret sources.
+	SopsConfig SopsConfig
+
+	// SecretProvidersConfig allows pulling secret values from
+	// external secret providers.
+	SecretProvidersConfig SecretProvidersConfig
 }
 
 func EnabledPluginConfig(b BuiltinPluginLoadingOptions) (pc *PluginConfig) {
@@ -48,6 +48,14 @@ type FnPluginLoadingOptions struct {
 	EnableExec bool
 	// Allow to run starlark
 	EnableStar bool
+	// Allow to run WebAssembly modules
+	EnableWasm bool
+	// Container runtime executable that runs container functions
+	ContainerRuntime string
+	// VerifyImage, if set, is called with the image of each container
+	// function before it runs, and returns the image to run or an error
+	// if it mustn't run
+	VerifyImage func(image string) (string, error) `json:"-" yaml:"-"`
 	// Allow container access to network
 	Network     bool
 	NetworkName string
@@ -59,4 +67,8 @@ type FnPluginLoadingOptions struct {
 	AsCurrentUser bool
 	// Run in this working directory
 	WorkingDir string
+	// ResultsSink, if set, receives the results, as YAML, that each
+	// function reports in the results field of its ResourceList,
+	// along with the kind and name of the function config.
+	ResultsSink func(function string, results []byte) `json:"-" yaml:"-"`
 }
@@ -71,6 +71,17 @@ type FieldOptions struct {
 	// Which position in the split to consider.
 	Index int `json:"index,omitempty" yaml:"index,omitempty"`
 
+	// A regular expression matching the field. In a source, the value
+	// becomes Replacement, expanded with the capture groups of the match.
+	// In a target, the value replaces the first capture group, or the
+	// whole match if the regular expression has no groups.
+	Regex string `json:"regex,omitempty" yaml:"regex,omitempty"`
+
+	// The template, e.g. "$1" or "${tag}", a source field matched by
+	// Regex is rewritten to. It defaults to the first capture group,
+	// or the whole match if the regular expression has no groups.
+	Replacement string `json:"replacement,omitempty" yaml:"replacement,omitempty"`
+
 	// TODO (#3492): Implement use of this option
 	// None, Base64, URL, Hex, etc
 	Encoding string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
@@ -80,8 +91,12 @@ type FieldOptions struct {
 }
 
 func (fo *FieldOptions) String() string {
-	if fo == nil || (fo.Delimiter == "" && !fo.Create) {
+	if fo == nil || (fo.Delimiter == "" && fo.Regex == "" && !fo.Create) {
 		return ""
 	}
-	return fmt.Sprintf("%s(%d), create=%t", fo.Delimiter, fo.Index, fo.Create)
+	s := fmt.Sprintf("%s(%d), create=%t", fo.Delimiter, fo.Index, fo.Create)
+	if fo.Regex != "" {
+		s += fmt.Sprintf(", regex=%q", fo.Regex)
+	}
+	return s
 }
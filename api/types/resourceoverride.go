@@ -0,0 +1,34 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// ResourceOverride sets or scales the compute resource requests
+// and limits of containers. This struct is used by the
+// ResourceOverrideTransformer, and spares a patch per workload
+// when sizing workloads per environment.
+type ResourceOverride struct {
+	// Target selects the workloads; all workloads by default.
+	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// Containers are the names of the containers to override;
+	// all containers, including init containers, by default.
+	Containers []string `json:"containers,omitempty" yaml:"containers,omitempty"`
+
+	// Requests are the requests to set, e.g. cpu: 500m.
+	Requests map[string]string `json:"requests,omitempty" yaml:"requests,omitempty"`
+
+	// Limits are the limits to set, e.g. memory: 1Gi.
+	Limits map[string]string `json:"limits,omitempty" yaml:"limits,omitempty"`
+
+	// Scale multiplies the requests and limits the containers have,
+	// after setting Requests and Limits.
+	Scale *ResourceScale `json:"scale,omitempty" yaml:"scale,omitempty"`
+}
+
+// ResourceScale holds the factors resource quantities
+// are multiplied by, e.g. cpu: 1.5 or memory: 0.5.
+type ResourceScale struct {
+	Requests map[string]float64 `json:"requests,omitempty" yaml:"requests,omitempty"`
+	Limits   map[string]float64 `json:"limits,omitempty" yaml:"limits,omitempty"`
+}
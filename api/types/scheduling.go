@@ -0,0 +1,29 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Scheduling adds scheduling constraints to the pod templates of
+// workloads. This struct is used by the SchedulingTransformer, and
+// spares a patch per workload for the scheduling policy of a cluster.
+type Scheduling struct {
+	// Target selects the workloads; all workloads by default.
+	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// NodeSelector entries are added to the nodeSelector of the pods,
+	// replacing entries with the same keys.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the tolerations of the pods,
+	// unless the pods already have them.
+	Tolerations []map[string]interface{} `json:"tolerations,omitempty" yaml:"tolerations,omitempty"`
+
+	// TopologySpreadConstraints are added to those of the pods,
+	// replacing constraints with the same topologyKey and
+	// whenUnsatisfiable.
+	TopologySpreadConstraints []map[string]interface{} `json:"topologySpreadConstraints,omitempty" yaml:"topologySpreadConstraints,omitempty"`
+
+	// Affinity is merged into the affinity of the pods: fields are
+	// merged recursively, and missing list elements are appended.
+	Affinity map[string]interface{} `json:"affinity,omitempty" yaml:"affinity,omitempty"`
+}
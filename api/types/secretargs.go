@@ -16,4 +16,9 @@ type SecretArgs struct {
 	// If type is "kubernetes.io/tls", then "literals" or "files" must have exactly two
 	// keys: "tls.key" and "tls.crt"
 	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Values is a list of keys whose values are pulled from
+	// external secret providers, e.g. HashiCorp Vault, rather
+	// than read from files in the kustomization.
+	Values []SecretValue `json:"values,omitempty" yaml:"values,omitempty"`
 }
@@ -0,0 +1,30 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// SecretValue is a key of a secret whose value is
+// pulled from an external secret provider.
+type SecretValue struct {
+	// Name is the key of the value in the secret.
+	Name string `json:"name" yaml:"name"`
+
+	// ValueFrom locates the value in the provider.
+	ValueFrom *SecretValueFrom `json:"valueFrom" yaml:"valueFrom"`
+}
+
+// SecretValueFrom locates a value in an external secret provider.
+type SecretValueFrom struct {
+	// Provider is the name of the provider:
+	// vault, aws or gcp, unless others are configured.
+	Provider string `json:"provider" yaml:"provider"`
+
+	// Path of the secret in the provider, e.g. secret/data/app
+	// in vault, the name or arn of the secret in AWS Secrets
+	// Manager or projects/p/secrets/s in GCP Secret Manager.
+	Path string `json:"path" yaml:"path"`
+
+	// Key selects a field of the secret at the path. It may be
+	// omitted if the secret holds a single value.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+}
@@ -26,6 +26,12 @@ type Selector struct {
 	// https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#api
 	// It matches with the resource labels.
 	LabelSelector string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
+
+	// CelExpression is a CEL expression evaluated against the resource,
+	// bound to the variable resource, e.g.
+	// resource.spec.replicas > 3 && resource.metadata.labels['tier'] == 'web'
+	// It matches the resources for which it evaluates to true.
+	CelExpression string `json:"celExpression,omitempty" yaml:"celExpression,omitempty"`
 }
 
 func (s *Selector) Copy() Selector {
@@ -3,13 +3,18 @@
 
 package types
 
+import "sigs.k8s.io/kustomize/kyaml/resid"
+
 // SortOptions defines the order that kustomize outputs resources.
 type SortOptions struct {
 	// Order selects the ordering strategy.
 	Order SortOrder `json:"order,omitempty" yaml:"order,omitempty"`
 	// LegacySortOptions tweaks the sorting for the "legacy" sort ordering
-	// strategy.
+	// strategy, and the "custom" one it underlies.
 	LegacySortOptions *LegacySortOptions `json:"legacySortOptions,omitempty" yaml:"legacySortOptions,omitempty"`
+	// CustomOrder tweaks the sorting for the "custom" sort ordering
+	// strategy.
+	CustomOrder *CustomSortOptions `json:"customOrder,omitempty" yaml:"customOrder,omitempty"`
 }
 
 // SortOrder defines different ordering strategies.
@@ -17,6 +22,7 @@ type SortOrder string
 
 const LegacySortOrder SortOrder = "legacy"
 const FIFOSortOrder SortOrder = "fifo"
+const CustomSortOrder SortOrder = "custom"
 
 // LegacySortOptions define various options for tweaking the "legacy" ordering
 // strategy.
@@ -26,3 +32,26 @@ type LegacySortOptions struct {
 	// OrderLast selects the resource kinds to order last.
 	OrderLast []string `json:"orderLast" yaml:"orderLast"`
 }
+
+// Keys of CustomSortOptions.SortBy.
+const (
+	SortByNamespace   = "namespace"
+	SortByName        = "name"
+	SortByLabelPrefix = "label:"
+)
+
+// CustomSortOptions define the "custom" ordering strategy, which orders
+// resources of the types it lists before or after the others, which keep
+// their "legacy" order.
+type CustomSortOptions struct {
+	// OrderFirst selects the resource types to order first, in the given
+	// order. Empty group, version or kind fields match any value.
+	OrderFirst []resid.Gvk `json:"orderFirst,omitempty" yaml:"orderFirst,omitempty"`
+	// OrderLast selects the resource types to order last, in the given
+	// order. Empty group, version or kind fields match any value.
+	OrderLast []resid.Gvk `json:"orderLast,omitempty" yaml:"orderLast,omitempty"`
+	// SortBy lists the keys sorting the resources of a type: "namespace",
+	// "name" or "label:<key>", the value of the label <key>, empty if
+	// unset. Ties are sorted by namespace, then name.
+	SortBy []string `json:"sortBy,omitempty" yaml:"sortBy,omitempty"`
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Substitution declares a named, typed value and the fields it is
+// written to. Unlike the deprecated Vars, the value is written into
+// whole fields by the replacement engine rather than by expanding
+// $(VAR) references inside strings.
+type Substitution struct {
+	// Name identifies the substitution, e.g. in error messages.
+	Name string `json:"name" yaml:"name"`
+
+	// Type of the value, string (the default), int or bool.
+	Type SubstitutionType `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Source of the value. If the source is unset or yields no value,
+	// the Default is used.
+	Source *SubstitutionSource `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// Default value, given as a string, e.g. "3" for an int.
+	Default *string `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Required makes the build fail if no value is found, rather than
+	// leaving the targets unchanged.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+
+	// The N fields to write the value to.
+	Targets []*TargetSelector `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+// SubstitutionType is the type of a substitution value.
+type SubstitutionType string
+
+const StringSubstitutionType SubstitutionType = "string"
+const IntSubstitutionType SubstitutionType = "int"
+const BoolSubstitutionType SubstitutionType = "bool"
+
+// SubstitutionSource is where the value of a substitution is read
+// from. At most one of its fields may be set.
+type SubstitutionSource struct {
+	// Resource reads the value from a field of a resource, like the
+	// source of a replacement.
+	Resource *SourceSelector `json:"resource,omitempty" yaml:"resource,omitempty"`
+
+	// Env names the environment variable to read the value from. The
+	// build must allow it, e.g. with kustomize build --env-allow.
+	Env string `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// File is the path of a file, relative to the kustomization, whose
+	// content with surrounding whitespace trimmed is the value.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+
+	// Input names an input of the component declaring the substitution.
+	Input string `json:"input,omitempty" yaml:"input,omitempty"`
+}
@@ -50,7 +50,9 @@ type CountRunner struct {
 
 func (r *CountRunner) runE(c *cobra.Command, args []string) error {
 	if len(args) == 0 {
-		input := &kio.ByteReader{Reader: c.InOrStdin()}
+		// counting reads the kinds alone, so the resources are
+		// parsed lazily
+		input := &kio.ByteReader{Reader: c.InOrStdin(), LazyParse: true}
 
 		return runner.HandleError(c, kio.Pipeline{
 			Inputs:  []kio.Reader{input},
@@ -70,7 +72,7 @@ func (r *CountRunner) runE(c *cobra.Command, args []string) error {
 }
 
 func (r *CountRunner) ExecuteCmd(w io.Writer, pkgPath string) error {
-	input := kio.LocalPackageReader{PackagePath: pkgPath, PackageFileName: ext.KRMFileName()}
+	input := kio.LocalPackageReader{PackagePath: pkgPath, PackageFileName: ext.KRMFileName(), LazyParse: true}
 
 	err := kio.Pipeline{
 		Inputs:  []kio.Reader{input},
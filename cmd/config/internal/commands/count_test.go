@@ -71,6 +71,38 @@ spec:
 	}
 }
 
+func TestCountCommand_stdin(t *testing.T) {
+	b := &bytes.Buffer{}
+	r := commands.GetCountRunner("")
+	r.Command.SetArgs([]string{})
+	r.Command.SetIn(bytes.NewBufferString(`kind: Deployment
+metadata:
+  name: foo
+spec:
+  replicas: 1
+---
+{"kind": "Service", "metadata": {"name": "foo"}}
+`))
+	r.Command.SetOut(b)
+	if !assert.NoError(t, r.Command.Execute()) {
+		return
+	}
+	assert.Equal(t, "Deployment: 1\nService: 1\n", b.String())
+
+	// the resources are read lazily, yet malformed ones are rejected
+	r = commands.GetCountRunner("")
+	r.Command.SetArgs([]string{})
+	r.Command.SetIn(bytes.NewBufferString(`kind: ConfigMap
+metadata:
+  name: foo
+data:
+  k: [unclosed
+`))
+	r.Command.SetOut(&bytes.Buffer{})
+	r.Command.SetErr(&bytes.Buffer{})
+	assert.Error(t, r.Command.Execute())
+}
+
 func TestCountSubPackages(t *testing.T) {
 	var tests = []struct {
 		name        string
@@ -75,6 +75,10 @@ func GetRunFnRunner(name string) *RunFnRunner {
 		"a list of environment variables to be used by functions")
 	r.Command.Flags().BoolVar(
 		&r.AsCurrentUser, "as-current-user", false, "use the uid and gid of the command executor to run the function in the container")
+	r.Command.Flags().StringVar(
+		&r.ContainerRuntime, "container-runtime", "",
+		"the container runtime executable that runs container functions, "+
+			"the first of docker, podman and nerdctl on the PATH by default")
 
 	return r
 }
@@ -104,6 +108,7 @@ type RunFnRunner struct {
 	LogSteps           bool
 	Env                []string
 	AsCurrentUser      bool
+	ContainerRuntime   string
 }
 
 func (r *RunFnRunner) runE(c *cobra.Command, args []string) error {
@@ -339,21 +344,22 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 	}
 
 	r.RunFns = runfn.RunFns{
-		FunctionPaths:  r.FnPaths,
-		GlobalScope:    r.GlobalScope,
-		Functions:      fns,
-		Output:         output,
-		Input:          input,
-		Path:           path,
-		Network:        r.Network,
-		EnableStarlark: r.EnableStar,
-		EnableExec:     r.EnableExec,
-		StorageMounts:  storageMounts,
-		ResultsDir:     r.ResultsDir,
-		LogSteps:       r.LogSteps,
-		Env:            r.Env,
-		AsCurrentUser:  r.AsCurrentUser,
-		WorkingDir:     wd,
+		FunctionPaths:    r.FnPaths,
+		GlobalScope:      r.GlobalScope,
+		Functions:        fns,
+		Output:           output,
+		Input:            input,
+		Path:             path,
+		Network:          r.Network,
+		EnableStarlark:   r.EnableStar,
+		EnableExec:       r.EnableExec,
+		StorageMounts:    storageMounts,
+		ResultsDir:       r.ResultsDir,
+		LogSteps:         r.LogSteps,
+		Env:              r.Env,
+		AsCurrentUser:    r.AsCurrentUser,
+		ContainerRuntime: r.ContainerRuntime,
+		WorkingDir:       wd,
 	}
 
 	// don't consider args for the function
@@ -0,0 +1,143 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var cobraTestTemplate = template.Must(template.New("cobratest").Parse(`{{.Preamble}}
+// Code generated by "mdtogo"; DO NOT EDIT.
+package {{.Package}}
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDocsRenderWithCobra(t *testing.T) {
+	for _, tc := range []struct {
+		use      string
+		short    string
+		long     string
+		example  string
+		expected []string
+	}{
+{{- range .Commands}}
+		{
+			use:      {{.Use}},
+			short:    {{.Short}},
+			long:     {{.Long}},
+			example:  {{.Example}},
+			expected: []string{ {{- range $i, $s := .Expected}}{{if $i}}, {{end}}{{$s}}{{end -}} },
+		},
+{{- end}}
+	} {
+		cmd := &cobra.Command{
+			Use:     tc.use,
+			Short:   tc.short,
+			Long:    tc.long,
+			Example: tc.example,
+			Run:     func(*cobra.Command, []string) {},
+		}
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		if err := cmd.Help(); err != nil {
+			t.Fatalf("%s: %v", tc.use, err)
+		}
+		for _, s := range tc.expected {
+			if !strings.Contains(out.String(), s) {
+				t.Errorf("%s: expected help to contain %q, got:\n%s", tc.use, s, out.String())
+			}
+		}
+	}
+}
+`))
+
+// cobraTestCase is a row of the generated TestDocsRenderWithCobra.
+// Short, Long and Example are the Go expressions referring to the docs.
+type cobraTestCase struct {
+	Use      string
+	Short    string
+	Long     string
+	Example  string
+	Expected []string
+}
+
+// cobraTest returns a Go test rendering the help of a cobra.Command
+// documented by each of the docs.
+func (o *options) cobraTest(license, tag, pkg string, docs []doc) (string, error) {
+	var cases []cobraTestCase
+	for i := range docs {
+		d := docs[i]
+		defined := map[string]bool{}
+		for _, v := range o.variables(d) {
+			defined[v.name] = true
+		}
+		ref := func(section, field string) string {
+			if o.typed {
+				return d.Name + "Doc." + field
+			}
+			if o.outputFormat == structFormat {
+				if field == "Examples" {
+					field = "Example"
+				}
+				return cmdDocRef(d, field)
+			}
+			if defined[d.Name+section] && o.gzipped {
+				return d.Name + section + "()"
+			}
+			if defined[d.Name+section] {
+				return d.Name + section
+			}
+			return `""`
+		}
+		segments := strings.Fields(d.Command)
+		c := cobraTestCase{
+			Use:     strconv.Quote(segments[len(segments)-1]),
+			Short:   ref("Short", "Short"),
+			Long:    ref("Long", "Long"),
+			Example: ref("Examples", "Examples"),
+		}
+		// cobra shows Long in place of Short when it is set
+		description := d.Long
+		if strings.TrimSpace(description) == "" {
+			description = d.Short
+		}
+		for _, s := range []string{description, d.Examples} {
+			if line := firstLine(s); line != "" {
+				c.Expected = append(c.Expected, strconv.Quote(line))
+			}
+		}
+		cases = append(cases, c)
+	}
+	// the license, followed by the build constraint if any
+	preamble := license
+	if tag != "" {
+		preamble += "\n\n//go:build " + tag + "\n"
+	}
+	var b strings.Builder
+	err := cobraTestTemplate.Execute(&b, map[string]interface{}{
+		"Preamble": preamble,
+		"Package":  pkg,
+		"Commands": cases,
+	})
+	return b.String(), err
+}
+
+// firstLine returns the first non-blank line of s, without surrounding
+// whitespace.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
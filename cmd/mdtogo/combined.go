@@ -0,0 +1,40 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// combinedMarkdown returns a single markdown document holding all the
+// docs, in order, preceded by a table of contents linking to each.
+func (o *options) combinedMarkdown(docs []doc) string {
+	var b strings.Builder
+	b.WriteString("# Commands\n\n")
+	for i := range docs {
+		b.WriteString("- [" + docs[i].Command + "](#" + slugify(docs[i].Command) + ")\n")
+	}
+	for i := range docs {
+		d := docs[i]
+		b.WriteString("\n## " + d.Command + "\n")
+		if d.Short != "" {
+			b.WriteString("\n" + d.Short + "\n")
+		}
+		if long := strings.Trim(d.LongMarkdown, "\n"); long != "" {
+			if !o.full {
+				b.WriteString("\n### Synopsis\n")
+			}
+			b.WriteString("\n" + long + "\n")
+		}
+		if examples := strings.Trim(d.ExamplesRaw, "\n"); examples != "" {
+			b.WriteString("\n### Examples\n\n" + examples + "\n")
+		}
+	}
+	return b.String()
+}
+
+func (o *options) writeCombined(path string, docs []doc) error {
+	return os.WriteFile(path, []byte(o.combinedMarkdown(docs)), 0600)
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadCommands reads a list of command names, one per line.  Blank lines
+// and lines starting with '#' are skipped.
+func loadCommands(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var commands []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commands = append(commands, line)
+	}
+	return commands, scanner.Err()
+}
+
+// checkCommands returns a problem for each of the docs that isn't of one
+// of the commands, and for each command without docs.
+func checkCommands(docs []doc, commands []string) []string {
+	var problems []string
+	listed := map[string]bool{}
+	for _, c := range commands {
+		listed[c] = true
+	}
+	documented := map[string]bool{}
+	for i := range docs {
+		documented[docs[i].Command] = true
+		if !listed[docs[i].Command] {
+			problems = append(problems, fmt.Sprintf("%s: docs for unknown command %q", docs[i].File, docs[i].Command))
+		}
+	}
+	for _, c := range commands {
+		if !documented[c] {
+			problems = append(problems, fmt.Sprintf("command %q has no docs", c))
+		}
+	}
+	return problems
+}
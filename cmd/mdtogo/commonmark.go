@@ -0,0 +1,128 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	legacyRenderer     = "legacy"
+	commonmarkRenderer = "commonmark"
+)
+
+// fenceLine matches a line that may open or close a fenced code block,
+// with either backticks or tildes.
+var fenceLine = regexp.MustCompile("^[ \t]*(```|~~~)")
+
+// heading is an ATX or setext heading found by the commonmark renderer.
+type heading struct {
+	level int
+	title string
+}
+
+// blockLines classifies the lines of a markdown document, by their
+// index, according to a CommonMark parse of it.
+type blockLines struct {
+	// headings holds the first line of each top level heading.
+	headings map[int]heading
+	// skip holds the remaining lines of setext headings, including the
+	// underline, which are part of the heading on an earlier line.
+	skip map[int]bool
+	// fences holds the opening and closing lines of fenced code blocks.
+	fences map[int]bool
+	// code holds the content lines of fenced code blocks.
+	code map[int]bool
+}
+
+// commonmarkLines parses the markdown document with goldmark and
+// classifies its lines by the blocks they belong to.
+func commonmarkLines(value string) *blockLines {
+	source := []byte(value)
+	root := goldmark.New().Parser().Parse(text.NewReader(source))
+
+	// line i starts at offset starts[i]
+	starts := []int{0}
+	for i, c := range source {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	lineOf := func(offset int) int {
+		return sort.SearchInts(starts, offset+1) - 1
+	}
+
+	b := &blockLines{
+		headings: map[int]heading{},
+		skip:     map[int]bool{},
+		fences:   map[int]bool{},
+		code:     map[int]bool{},
+	}
+	// covered holds the lines holding the content of a block, so that
+	// a fence-like line among them isn't taken as a fence
+	covered := map[int]bool{}
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			covered[lineOf(lines.At(i).Start)] = true
+		}
+		switch n := n.(type) {
+		case *ast.FencedCodeBlock:
+			for i := 0; i < lines.Len(); i++ {
+				b.code[lineOf(lines.At(i).Start)] = true
+			}
+		case *ast.Heading:
+			if n.Parent() != root || lines.Len() == 0 {
+				break
+			}
+			var title []string
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				title = append(title, strings.TrimSpace(string(segment.Value(source))))
+			}
+			first := lineOf(lines.At(0).Start)
+			b.headings[first] = heading{level: n.Level, title: strings.Join(title, " ")}
+			if !strings.HasPrefix(strings.TrimLeft(value[starts[first]:], " "), "#") {
+				// a setext heading, underlined on the line after its last
+				last := lineOf(lines.At(lines.Len() - 1).Start)
+				for i := first + 1; i <= last+1; i++ {
+					b.skip[i] = true
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for i, start := range starts {
+		end := len(value)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		if !covered[i] && fenceLine.MatchString(value[start:end]) {
+			b.fences[i] = true
+		}
+	}
+	return b
+}
+
+// legacyHeading returns the level and title of line if it is a "## " or
+// "### " heading, as recognized by the legacy renderer, or 0.
+func legacyHeading(line string) (int, string) {
+	switch {
+	case strings.HasPrefix(line, "## "):
+		return 2, strings.TrimPrefix(line, "## ")
+	case strings.HasPrefix(line, "### "):
+		return 3, strings.TrimPrefix(line, "### ")
+	}
+	return 0, ""
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+// by unifiedDiff.
+const diffContext = 3
+
+// diffLines returns the lines that differ between a and b, prefixed
+// with "-" for lines only in a and "+" for lines only in b.
+func diffLines(a, b string) []string {
+	var out []string
+	for _, line := range editScript(a, b) {
+		if line[0] != ' ' {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// unifiedDiff returns the differences between a and b in the unified
+// format of diff -u, without the file header: hunks starting with an
+// "@@ -l,s +l,s @@" line, each change surrounded by diffContext lines
+// that are the same in both.
+func unifiedDiff(a, b string) []string {
+	script := editScript(a, b)
+
+	var out []string
+	// line numbers in a and b of script[i]
+	x, y := 1, 1
+	for i := 0; i < len(script); {
+		if script[i][0] == ' ' {
+			i++
+			x++
+			y++
+			continue
+		}
+		// the hunk starts diffContext lines before the change...
+		start := i
+		for start > 0 && i-start < diffContext && script[start-1][0] == ' ' {
+			start--
+		}
+		hx, hy := x-(i-start), y-(i-start)
+		// ...and ends diffContext lines after the last change that isn't
+		// separated from the one before by more than twice as many
+		end, same := i, 0
+		for ; end < len(script) && same <= 2*diffContext; end++ {
+			if script[end][0] == ' ' {
+				same++
+			} else {
+				same = 0
+			}
+		}
+		if same > diffContext {
+			end -= same - diffContext
+		}
+
+		var nx, ny int
+		for _, line := range script[start:end] {
+			if line[0] != '+' {
+				nx++
+			}
+			if line[0] != '-' {
+				ny++
+			}
+		}
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@", hunkRange(hx, nx), hunkRange(hy, ny)))
+		out = append(out, script[start:end]...)
+		x, y = hx+nx, hy+ny
+		i = end
+	}
+	return out
+}
+
+// hunkRange formats the start and length of a hunk like diff -u, which
+// omits a length of 1 and numbers an empty range after the line before.
+func hunkRange(start, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprint(start)
+	default:
+		return fmt.Sprintf("%d,%d", start, n)
+	}
+}
+
+// editScript returns every line of a and b, prefixed with " " for lines
+// in both, "-" for lines only in a and "+" for lines only in b.
+func editScript(a, b string) []string {
+	x := strings.Split(a, "\n")
+	y := strings.Split(b, "\n")
+	// a final newline in both doesn't make for another line
+	if strings.HasSuffix(a, "\n") && strings.HasSuffix(b, "\n") {
+		x, y = x[:len(x)-1], y[:len(y)-1]
+	}
+	if a == "" {
+		x = nil
+	}
+	if b == "" {
+		y = nil
+	}
+
+	// lines shared at either end don't take part in the comparison
+	var prefix, suffix int
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	for suffix < len(x)-prefix && suffix < len(y)-prefix &&
+		x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	var out []string
+	for _, line := range x[:prefix] {
+		out = append(out, " "+line)
+	}
+	common := x[len(x)-suffix:]
+	x = x[prefix : len(x)-suffix]
+	y = y[prefix : len(y)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			out = append(out, " "+x[i])
+			i++
+			j++
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "-"+x[i])
+			i++
+		default:
+			out = append(out, "+"+y[j])
+			j++
+		}
+	}
+	for _, line := range common {
+		out = append(out, " "+line)
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// embedPath returns the path, relative to DEST_GO_DIR/, of the file
+// --embed writes v into, e.g. docs/run-fns.short.txt.
+func (o *options) embedPath(d doc, v variable) string {
+	section := strings.ToLower(strings.TrimPrefix(v.name, d.Name))
+	return path.Join(o.generatedStem(), strings.ReplaceAll(d.Command, " ", "-")+"."+section+".txt")
+}
+
+// embedCode returns the variables holding d's docs, each loaded from
+// its own file with a //go:embed directive.
+func (o *options) embedCode(d doc) string {
+	var parts []string
+	for _, v := range o.variables(d) {
+		parts = append(parts, fmt.Sprintf("//go:embed %s\nvar %s string", o.embedPath(d, v), v.name))
+	}
+	return strings.Join(parts, "\n") + "\n"
+}
+
+// embedFiles returns the files read by the //go:embed directives of
+// the docs.
+func (o *options) embedFiles(dest string, docs []doc) []generatedFile {
+	var files []generatedFile
+	for i := range docs {
+		for _, v := range o.variables(docs[i]) {
+			files = append(files, generatedFile{
+				path:    filepath.Join(dest, filepath.FromSlash(o.embedPath(docs[i], v))),
+				content: v.value,
+			})
+		}
+	}
+	return files
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+const usage = `Usage: mdtogo SOURCE_MD_DIR/ DEST_GO_DIR/ [flags]
+       mdtogo GO_DIR/ MD_DIR/ --reverse [flags]
+
+Generates Go variables holding the cobra.Command docs read from the .md files
+of SOURCE_MD_DIR/ into DEST_GO_DIR/docs.go.
+
+Flags:
+`
+
+// options holds the flags of the command, described by newFlagSet.
+type options struct {
+	full              bool
+	fullStripHeading  bool
+	licenseFile       string
+	copyrightYear     string
+	genSuffix         string
+	outFile           string
+	packageName       string
+	schemaFile        string
+	strict            bool
+	registry          string
+	registryTemplate  string
+	typed             bool
+	examplesBanner    string
+	dualLong          bool
+	verify            bool
+	manOut            string
+	multilineShort    bool
+	split             bool
+	licenseFirstOnly  bool
+	emitBothExamples  bool
+	warnNameMismatch  bool
+	constants         bool
+	cobraRenderTests  bool
+	emitEmpty         bool
+	spellcheckEnabled bool
+	spellcheckWords   string
+	dictionaryFile    string
+	combinedOut       string
+	embed             bool
+	buildTag          string
+	jsonOut           string
+	jsonSchema        string
+	propertiesOut     string
+	lineEnding        string
+	deriveShort       bool
+	reverse           bool
+	examplesAliases   string
+	gzipped           bool
+	commandsFrom      string
+	renderer          string
+	recursive         bool
+	outputFormat      string
+	groupByParent     bool
+}
+
+// newFlagSet returns the flags of the command, bound to the fields of
+// o, which are set to their defaults.
+func newFlagSet(o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet("mdtogo", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(fs.Output(), "  --%s\n    \t%s", f.Name, f.Usage)
+			if f.DefValue != "" && f.DefValue != "false" {
+				fmt.Fprintf(fs.Output(), " (default %q)", f.DefValue)
+			}
+			fmt.Fprintln(fs.Output())
+		})
+	}
+
+	fs.BoolVar(&o.full, "full", false, "create a Long variable from the full .md files, rather than separate sections")
+	fs.BoolVar(&o.fullStripHeading, "full-strip-heading", false, "with --full, leave the document's title heading out of Long")
+	fs.StringVar(&o.licenseFile, "license", "", "path to the license header of the generated files, or \"none\"")
+	fs.StringVar(&o.copyrightYear, "copyright-year", "2019", "year in the default license header, or \"current\"")
+	fs.StringVar(&o.genSuffix, "gen-suffix", ".go", "suffix of the generated file name, appended to \"docs\"")
+	fs.StringVar(&o.outFile, "out-file", "", "name of the generated file in DEST_GO_DIR/, instead of docs.go")
+	fs.StringVar(&o.packageName, "package", "", "name of the generated package, instead of the DEST_GO_DIR/ base name")
+	fs.StringVar(&o.schemaFile, "schema", "", "path to a YAML file of documentation rules to check every .md file against")
+	fs.BoolVar(&o.strict, "strict", false, "fail if any validation problems are found instead of only warning about them")
+	fs.StringVar(&o.registry, "registry", "", "function to register each command's docs with from a generated func init()")
+	fs.StringVar(&o.registryTemplate, "registry-template", defaultRegistryTemplate, "text/template for each --registry call")
+	fs.BoolVar(&o.typed, "typed", false, "emit a CommandDoc variable per command instead of separate string variables")
+	fs.StringVar(&o.examplesBanner, "examples-banner", "", "text prepended to every non-empty Examples")
+	fs.BoolVar(&o.dualLong, "dual-long", false, "strip markdown from Long, and also emit the original as LongMarkdown")
+	fs.BoolVar(&o.verify, "verify", false, "check that the generated files on disk are up to date instead of writing them")
+	fs.StringVar(&o.manOut, "man-out", "", "directory to also write a man page per command into")
+	fs.BoolVar(&o.multilineShort, "multiline-short", false, "continue Short with the indented lines that follow it")
+	fs.BoolVar(&o.split, "split", false, "write each command's docs into its own file")
+	fs.BoolVar(&o.licenseFirstOnly, "license-first-only", false, "with --split, only add the license header to the first file")
+	fs.BoolVar(&o.emitBothExamples, "emit-both-examples", false, "also emit the original markdown of the examples as ExamplesRaw")
+	fs.BoolVar(&o.warnNameMismatch, "warn-name-mismatch", false, "warn when the \"## \" heading doesn't match the file name")
+	fs.BoolVar(&o.constants, "const", false, "emit the docs as constants in a single const block")
+	fs.BoolVar(&o.cobraRenderTests, "cobra-render-tests", false, "also generate a test rendering the docs with cobra")
+	fs.BoolVar(&o.emitEmpty, "emit-empty", false, "emit every command's Short, Long and Examples, even when empty")
+	fs.BoolVar(&o.spellcheckEnabled, "spellcheck", false, "warn about words in the docs that aren't in the dictionary")
+	fs.StringVar(&o.spellcheckWords, "spellcheck-words", defaultSpellcheckWords, "word list used by --spellcheck")
+	fs.StringVar(&o.dictionaryFile, "dictionary", "", "additional words allowed by --spellcheck")
+	fs.StringVar(&o.combinedOut, "combined-out", "", "path to also write a single markdown reference of all commands to")
+	fs.BoolVar(&o.embed, "embed", false, "write each section into a text file loaded with //go:embed")
+	fs.StringVar(&o.buildTag, "build-tag", "", "build constraint added to the generated files")
+	fs.StringVar(&o.jsonOut, "json-out", "", "path to also write the docs to as JSON")
+	fs.StringVar(&o.jsonSchema, "json-schema", "", "path to also write a JSON Schema of the --json-out file to")
+	fs.StringVar(&o.propertiesOut, "properties-out", "", "path to also write the docs to as a Java .properties file")
+	fs.StringVar(&o.lineEnding, "line-ending", "lf", "line ending of the generated Go files, lf or crlf")
+	fs.BoolVar(&o.deriveShort, "derive-short", false, "take a missing Short from the first sentence of Long")
+	fs.BoolVar(&o.reverse, "reverse", false, "write markdown files from the docs variables of GO_DIR/ into MD_DIR/")
+	fs.StringVar(&o.examplesAliases, "examples-aliases", examplesSection, "comma separated headings of the Examples section")
+	fs.BoolVar(&o.gzipped, "gzip", false, "store each section gzip compressed behind an accessor function")
+	fs.StringVar(&o.commandsFrom, "commands-from", "", "path to a list of the expected commands to check the docs against")
+	fs.StringVar(&o.renderer, "renderer", legacyRenderer, "how the markdown is read, legacy or commonmark")
+	fs.BoolVar(&o.recursive, "recursive", false, "also read the .md files of the directories below SOURCE_MD_DIR/")
+	fs.StringVar(&o.outputFormat, "format", varsFormat, "emit a variable per section, vars, or a CmdDocs map, struct")
+	fs.BoolVar(&o.groupByParent, "group-by-parent", false, "also emit a Children map of each parent command's sub-commands")
+	return fs
+}
+
+// parseFlags parses the command line arguments, following the program
+// name, and returns the options and the positional arguments.  Flags
+// may come before, between or after them.
+func parseFlags(args []string) (*options, []string, error) {
+	o := &options{}
+	fs := newFlagSet(o)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, nil, err
+		}
+		if fs.NArg() == 0 {
+			return o, positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const frontMatterDelimiter = "---"
+
+// frontMatter is the optional YAML metadata block at the top of a
+// markdown file, delimited by "---" lines.
+//
+// The block is decoded by a full YAML parser, so anchors, aliases
+// and merge keys may be used to share values between fields.
+type frontMatter struct {
+	// Short, if set, replaces the line following the command heading.
+	Short string `yaml:"short,omitempty"`
+	// Weight orders commands in the generated output; lower weights
+	// come first and equal weights are ordered by file name.
+	Weight int `yaml:"weight,omitempty"`
+	// Parent names the command this one is a sub-command of, for
+	// --group-by-parent.
+	Parent string `yaml:"parent,omitempty"`
+	// Deprecated is the deprecation message of the command, for
+	// --format=struct.
+	Deprecated string `yaml:"deprecated,omitempty"`
+	// Aliases are the alternative names of the command, for
+	// --format=struct.
+	Aliases []string `yaml:"aliases,omitempty"`
+
+	// fields holds the names of all fields set in the block.
+	fields map[string]bool
+}
+
+// splitFrontMatter separates a leading front matter block from the rest
+// of a markdown document. It returns the decoded front matter, the
+// remaining markdown and the number of lines consumed by the block.
+func splitFrontMatter(value string) (frontMatter, string, int, error) {
+	var fm frontMatter
+	lines := strings.SplitAfter(value, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != frontMatterDelimiter {
+		return fm, value, 0, nil
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != frontMatterDelimiter {
+			continue
+		}
+		data := []byte(strings.Join(lines[1:i], ""))
+		var fields map[string]interface{}
+		if err := yaml.Unmarshal(data, &fields); err != nil {
+			return fm, value, 0, fmt.Errorf("invalid front matter: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &fm); err != nil {
+			return fm, value, 0, fmt.Errorf("invalid front matter: %w", err)
+		}
+		fm.fields = make(map[string]bool, len(fields))
+		for k := range fields {
+			fm.fields[k] = true
+		}
+		return fm, strings.Join(lines[i+1:], ""), i + 1, nil
+	}
+	return fm, value, 0, fmt.Errorf("front matter is missing its closing %q", frontMatterDelimiter)
+}
+
+// has reports whether the named field was set in the front matter.
+func (fm frontMatter) has(field string) bool {
+	return fm.fields[field]
+}
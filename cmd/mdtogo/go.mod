@@ -1,3 +1,14 @@
 module sigs.k8s.io/kustomize/cmd/mdtogo
 
 go 1.20
+
+require (
+	github.com/yuin/goldmark v1.5.6
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/kr/pretty v0.3.0 // indirect
+	github.com/rogpeppe/go-internal v1.10.0 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+)
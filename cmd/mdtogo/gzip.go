@@ -0,0 +1,72 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// gzipImports are the imports of the generated gzipDoc type.
+const gzipImports = `import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+`
+
+// gzipDocType is the generated type holding a compressed section,
+// which is decompressed the first time it is read.
+const gzipDocType = `// gzipDoc is a gzip compressed docs section.
+type gzipDoc struct {
+	once sync.Once
+	data []byte
+	text string
+}
+
+// String returns the decompressed section.
+func (d *gzipDoc) String() string {
+	d.once.Do(func() {
+		r, err := gzip.NewReader(bytes.NewReader(d.data))
+		if err != nil {
+			panic(err)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			panic(err)
+		}
+		d.text, d.data = string(b), nil
+	})
+	return d.text
+}
+`
+
+// gzipCode returns the compressed sections of d, each with a function
+// of the name the variable would have, e.g. BuildShort(), returning it.
+func (o *options) gzipCode(d doc) string {
+	var parts []string
+	for _, v := range o.variables(d) {
+		r := []rune(v.name)
+		stored := string(unicode.ToLower(r[0])) + string(r[1:]) + "Gzip"
+		parts = append(parts, fmt.Sprintf("var %s = &gzipDoc{data: []byte(%s)}\n\nfunc %s() string { return %s.String() }\n",
+			stored, strconv.Quote(string(gzipBytes(v.value))), v.name, stored))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// gzipBytes returns s compressed with gzip.  No timestamp is recorded,
+// so the output only changes when s does.
+func gzipBytes(s string) []byte {
+	var b bytes.Buffer
+	// neither a valid level nor writing to a bytes.Buffer can fail
+	w, _ := gzip.NewWriterLevel(&b, gzip.BestCompression)
+	_, _ = w.Write([]byte(s))
+	_ = w.Close()
+	return b.Bytes()
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// jsonSections returns the section fields a docs.json object may hold
+// with the current flags, in the order of the generated variables.
+func (o *options) jsonSections() []string {
+	fields := []string{"short", "long"}
+	if o.dualLong {
+		fields = append(fields, "longMarkdown")
+	}
+	fields = append(fields, "examples")
+	if o.emitBothExamples {
+		fields = append(fields, "examplesRaw")
+	}
+	return fields
+}
+
+// jsonField returns the docs.json field holding variable v of d, e.g.
+// "longMarkdown" for BuildLongMarkdown.
+func jsonField(d doc, v variable) string {
+	suffix := strings.TrimPrefix(v.name, d.Name)
+	return strings.ToLower(suffix[:1]) + suffix[1:]
+}
+
+// docsJSON returns the docs as a JSON array with an object per command,
+// holding the same sections as the generated variables.
+func (o *options) docsJSON(docs []doc) ([]byte, error) {
+	objects := []map[string]string{}
+	for i := range docs {
+		object := map[string]string{
+			"command": docs[i].Command,
+			"name":    docs[i].Name,
+		}
+		for _, v := range o.variables(docs[i]) {
+			object[jsonField(docs[i], v)] = v.value
+		}
+		if o.groupByParent && docs[i].FrontMatter.Parent != "" {
+			object["parent"] = docs[i].FrontMatter.Parent
+		}
+		objects = append(objects, object)
+	}
+	b, err := json.MarshalIndent(objects, "", "  ")
+	return append(b, '\n'), err
+}
+
+// docsJSONSchema returns a JSON Schema describing the output of
+// docsJSON with the current flags.
+func (o *options) docsJSONSchema() ([]byte, error) {
+	str := map[string]string{"type": "string"}
+	properties := map[string]interface{}{
+		"command": str,
+		"name":    str,
+	}
+	required := []string{"command", "name"}
+	for _, field := range o.jsonSections() {
+		properties[field] = str
+		if o.emitsEmpty() {
+			required = append(required, field)
+		}
+	}
+	if o.groupByParent {
+		properties["parent"] = str
+	}
+	schema := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "mdtogo docs",
+		"type":    "array",
+		"items": map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		},
+	}
+	b, err := json.MarshalIndent(schema, "", "  ")
+	return append(b, '\n'), err
+}
+
+func (o *options) writeJSON(path string, docs []doc) error {
+	b, err := o.docsJSON(docs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+func (o *options) writeJSONSchema(path string) error {
+	b, err := o.docsJSONSchema()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
@@ -3,6 +3,7 @@
 
 // Package main generates cobra.Command go variables containing documentation read from .md files.
 // Usage: mdtogo SOURCE_MD_DIR/ DEST_GO_DIR/ [--full=true] [--license=license.txt|none]
+//        mdtogo GO_DIR/ MD_DIR/ --reverse
 //
 // The command will create a docs.go file under DEST_GO_DIR/ containing string variables to be
 // used by cobra commands for documentation.The variable names are generated from the SOURCE_MD_DIR/
@@ -29,174 +30,878 @@
 //
 //   All sections will be parsed into a Long string.
 //
-// Flags:
-//   --full=true
-//     Create a Long variable from the full .md files, rather than separate sections.
-//   --license
-//     Controls the license header added to the files.  Specify a path to a license file,
-//     or "none" to skip adding a license.
+// A document may start with a YAML front matter block delimited by "---" lines, e.g. to
+// override Short or to order the commands by weight.
+//
+// Flags may be given before, between or after the directories.  Run mdtogo --help for the
+// list of flags, e.g. --license to control the license header added to the files, or
+// --reverse to turn the generated variables back into .md files.
 package main
 
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"go/format"
+	"go/token"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
-var full bool
-var licenseFile string
+// stderr receives warnings about questionable input.
+var stderr io.Writer = os.Stderr
+
+// stdout receives reports such as the differences found by --verify.
+var stdout io.Writer = os.Stdout
 
 func main() {
-	for _, a := range os.Args {
-		if a == "--full=true" {
-			full = true
+	if err := run(os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	o, args, err := parseFlags(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 2 {
+		return fmt.Errorf("Usage: mdtogo SOURCE_MD_DIR/ DEST_GO_DIR/")
+	}
+	if o.reverse {
+		return reverseDocs(args[0], args[1])
+	}
+	if len(o.sectionAliases()[examplesSection]) == 0 {
+		return fmt.Errorf("--examples-aliases must name at least one heading")
+	}
+	if o.constants && o.typed {
+		return fmt.Errorf("--const and --typed cannot be combined")
+	}
+	if o.embed && (o.constants || o.typed) {
+		return fmt.Errorf("--embed cannot be combined with --const or --typed")
+	}
+	if o.renderer != legacyRenderer && o.renderer != commonmarkRenderer {
+		return fmt.Errorf("--renderer %q must be %s or %s", o.renderer, legacyRenderer, commonmarkRenderer)
+	}
+	if o.lineEnding != "lf" && o.lineEnding != "crlf" {
+		return fmt.Errorf("--line-ending %q must be lf or crlf", o.lineEnding)
+	}
+	if o.outputFormat != varsFormat && o.outputFormat != structFormat {
+		return fmt.Errorf("--format %q must be %s or %s", o.outputFormat, varsFormat, structFormat)
+	}
+	if o.outputFormat == structFormat && (o.constants || o.typed || o.embed || o.gzipped || o.split) {
+		return fmt.Errorf("--format=struct cannot be combined with --const, --typed, --embed, --gzip or --split")
+	}
+	if o.gzipped && (o.constants || o.typed || o.embed) {
+		return fmt.Errorf("--gzip cannot be combined with --const, --typed or --embed")
+	}
+	if !strings.HasSuffix(o.genSuffix, ".go") {
+		return fmt.Errorf("--gen-suffix %q must end in .go", o.genSuffix)
+	}
+	if o.outFile != "" {
+		if filepath.Ext(o.outFile) != ".go" || o.outFile != filepath.Base(o.outFile) {
+			return fmt.Errorf("--out-file %q must be a .go file name, without a directory", o.outFile)
 		}
-		if strings.HasPrefix(a, "--license=") {
-			licenseFile = strings.ReplaceAll(a, "--license=", "")
+		if o.genSuffix != ".go" || o.split {
+			return fmt.Errorf("--out-file cannot be combined with --gen-suffix or --split")
 		}
 	}
+	source := args[0]
+	dest := args[1]
+	pkg, err := o.destPackage(dest)
+	if err != nil {
+		return err
+	}
 
-	if len(os.Args) < 3 {
-		fmt.Fprintf(os.Stderr, "Usage: mdtogo SOURCE_MD_DIR/ DEST_GO_DIR/\n")
-		os.Exit(1)
+	var rules *schema
+	if o.schemaFile != "" {
+		var err error
+		if rules, err = loadSchema(o.schemaFile); err != nil {
+			return err
+		}
 	}
-	source := os.Args[1]
-	dest := os.Args[2]
 
-	files, err := os.ReadDir(source)
+	var dict dictionary
+	if o.spellcheckEnabled {
+		paths := []string{o.spellcheckWords}
+		if o.dictionaryFile != "" {
+			paths = append(paths, o.dictionaryFile)
+		}
+		var err error
+		if dict, err = loadDictionary(paths...); err != nil {
+			return fmt.Errorf("loading spellcheck dictionary: %w", err)
+		}
+	}
+
+	files, err := o.markdownFiles(source)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
+		return err
 	}
 
+	outStem := o.generatedStem()
+
 	var docs []doc
+	var structural []string
+	// fileOf holds the file each variable name prefix was generated from
+	fileOf := map[string]string{}
 	for _, f := range files {
-		if filepath.Ext(f.Name()) != ".md" {
-			continue
+		b, err := os.ReadFile(filepath.Join(source, filepath.FromSlash(f)))
+		if err != nil {
+			return err
 		}
-		b, err := os.ReadFile(filepath.Join(source, f.Name()))
+
+		d, err := o.parse(f, string(b))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
+			return err
+		}
+		if other, ok := fileOf[d.Name]; ok {
+			return fmt.Errorf("%s and %s both generate %s variables", other, f, d.Name)
+		}
+		fileOf[d.Name] = f
+		if strings.TrimSuffix(f, filepath.Ext(f)) == outStem {
+			warnf("%s: shares its name with the generated %s.go; its docs are generated as %s variables",
+				f, outStem, d.Name)
+		}
+		if o.warnNameMismatch {
+			checkHeadingName(d)
+		}
+		if dict != nil {
+			spellcheck(d, dict)
 		}
+		if o.examplesBanner != "" && d.Examples != "" {
+			d.Examples = o.examplesBanner + "\n" + d.Examples
+		}
+		structural = append(structural, d.Problems...)
+		docs = append(docs, d)
+	}
+	if o.strict && len(structural) > 0 {
+		return errors.New(strings.Join(structural, "\n"))
+	}
+	sortDocs(docs)
 
-		docs = append(docs, parse(f.Name(), string(b)))
+	if rules != nil {
+		var violations []string
+		for i := range docs {
+			violations = append(violations, rules.validate(docs[i])...)
+		}
+		for _, v := range violations {
+			warnf("%s", v)
+		}
+		if o.strict && len(violations) > 0 {
+			return fmt.Errorf("%d schema violation(s) found", len(violations))
+		}
+	}
+
+	if o.commandsFrom != "" {
+		commands, err := loadCommands(o.commandsFrom)
+		if err != nil {
+			return fmt.Errorf("reading --commands-from: %w", err)
+		}
+		problems := checkCommands(docs, commands)
+		for _, p := range problems {
+			warnf("%s", p)
+		}
+		if o.strict && len(problems) > 0 {
+			return fmt.Errorf("%d command(s) out of sync with %s", len(problems), o.commandsFrom)
+		}
 	}
 
 	var license string
 
-	if licenseFile == "" {
-		license = `// Copyright 2019 The Kubernetes Authors.
+	if o.licenseFile == "" {
+		year := o.copyrightYear
+		if year == "current" {
+			year = strconv.Itoa(time.Now().Year())
+		} else if _, err := strconv.Atoi(year); err != nil {
+			return fmt.Errorf("--copyright-year %q must be a year or \"current\"", year)
+		}
+		license = `// Copyright ` + year + ` The Kubernetes Authors.
 // SPDX-License-Identifier: Apache-2.0`
-	} else if licenseFile == "none" {
+	} else if o.licenseFile == "none" {
 		// no license -- maybe added by another tool
 	} else {
-		b, err := os.ReadFile(licenseFile)
+		b, err := os.ReadFile(o.licenseFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
+			return err
 		}
 		license = string(b)
 	}
 
-	out := []string{license, `
+	header := `
 // Code generated by "mdtogo"; DO NOT EDIT.
-package ` + filepath.Base(dest) + "\n"}
+package ` + pkg + "\n"
+	if o.buildTag != "" {
+		header = "\n//go:build " + o.buildTag + "\n" + header
+	}
+	if o.embed {
+		header += "\nimport _ \"embed\"\n"
+	}
 
-	for i := range docs {
-		out = append(out, docs[i].String())
+	var initFunc string
+	if o.registry != "" {
+		if initFunc, err = o.registryInit(docs); err != nil {
+			return err
+		}
+	}
+
+	var generated []generatedFile
+	if o.split {
+		for i := range docs {
+			out := []string{license, header}
+			if i > 0 && o.licenseFirstOnly {
+				out[0] = ""
+			}
+			if i == 0 && o.typed {
+				out = append(out, commandDocType)
+			}
+			if i == 0 && o.gzipped {
+				out = append(out, gzipImports, gzipDocType)
+			}
+			code, err := o.goCode(docs[i : i+1])
+			if err != nil {
+				return err
+			}
+			out = append(out, code)
+			if i == 0 && initFunc != "" {
+				out = append(out, initFunc)
+			}
+			if i == 0 && o.groupByParent {
+				out = append(out, childrenMap(docs))
+			}
+			generated = append(generated, generatedFile{
+				path:    filepath.Join(dest, o.splitFileName(docs[i])),
+				content: strings.Join(out, "\n"),
+			})
+		}
+	} else {
+		out := []string{license, header}
+		if o.typed {
+			out = append(out, commandDocType)
+		}
+		if o.gzipped {
+			out = append(out, gzipImports, gzipDocType)
+		}
+		code, err := o.goCode(docs)
+		if err != nil {
+			return err
+		}
+		out = append(out, code)
+		if initFunc != "" {
+			out = append(out, initFunc)
+		}
+		if o.groupByParent {
+			out = append(out, childrenMap(docs))
+		}
+		generated = append(generated, generatedFile{
+			path:    filepath.Join(dest, o.generatedStem()+".go"),
+			content: strings.Join(out, "\n"),
+		})
+	}
+
+	if o.embed {
+		generated = append(generated, o.embedFiles(dest, docs)...)
+	}
+
+	if o.cobraRenderTests && len(docs) > 0 {
+		test, err := o.cobraTest(license, o.buildTag, pkg, docs)
+		if err != nil {
+			return err
+		}
+		generated = append(generated, generatedFile{
+			path:    filepath.Join(dest, o.generatedStem()+"_test.go"),
+			content: test,
+		})
+	}
+
+	if o.constants || o.outputFormat == structFormat {
+		// align the constants, or struct fields, like gofmt would
+		for i := range generated {
+			if filepath.Ext(generated[i].path) != ".go" {
+				continue
+			}
+			b, err := format.Source([]byte(generated[i].content))
+			if err != nil {
+				return fmt.Errorf("formatting %s: %w", generated[i].path, err)
+			}
+			generated[i].content = string(b)
+		}
+	}
+
+	if o.lineEnding == "crlf" {
+		// after formatting, which would undo it
+		for i := range generated {
+			if filepath.Ext(generated[i].path) == ".go" {
+				lf := strings.ReplaceAll(generated[i].content, "\r\n", "\n")
+				generated[i].content = strings.ReplaceAll(lf, "\n", "\r\n")
+			}
+		}
+	}
+
+	if o.verify {
+		var stale []string
+		for _, f := range generated {
+			if err := verifyFile(f.path, f.content); err != nil {
+				stale = append(stale, err.Error())
+			}
+		}
+		if len(stale) > 0 {
+			return errors.New(strings.Join(stale, "\n"))
+		}
+		return nil
+	}
+
+	if o.manOut != "" {
+		if err := writeManPages(o.manOut, docs); err != nil {
+			return err
+		}
+	}
+	if o.combinedOut != "" {
+		if err := o.writeCombined(o.combinedOut, docs); err != nil {
+			return err
+		}
+	}
+	if o.jsonOut != "" {
+		if err := o.writeJSON(o.jsonOut, docs); err != nil {
+			return err
+		}
+	}
+	if o.jsonSchema != "" {
+		if err := o.writeJSONSchema(o.jsonSchema); err != nil {
+			return err
+		}
+	}
+	if o.propertiesOut != "" {
+		if err := o.writeProperties(o.propertiesOut, docs); err != nil {
+			return err
+		}
 	}
 
 	if _, err := os.Stat(dest); err != nil {
 		_ = os.Mkdir(dest, 0700)
 	}
 
-	o := strings.Join(out, "\n")
-	err = os.WriteFile(filepath.Join(dest, "docs.go"), []byte(o), 0600)
+	for _, f := range generated {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.path, []byte(f.content), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// destPackage returns the name of the generated package: the --package
+// flag, or else the base name of dest, which must be a valid package name.
+func (o *options) destPackage(dest string) (string, error) {
+	if o.packageName != "" {
+		if err := checkPackageName(o.packageName); err != nil {
+			return "", fmt.Errorf("--package %q %v", o.packageName, err)
+		}
+		return o.packageName, nil
+	}
+	name := filepath.Base(dest)
+	if err := checkPackageName(name); err != nil {
+		return "", fmt.Errorf("package name %q, from the DEST_GO_DIR/ name, %v; choose another with --package=NAME", name, err)
+	}
+	return name, nil
+}
+
+// checkPackageName returns an error describing why name can't be used
+// in a package clause, or nil if it can.
+func checkPackageName(name string) error {
+	switch {
+	case token.IsKeyword(name):
+		return errors.New("is a reserved Go keyword")
+	case name == "_":
+		return errors.New("is the blank identifier")
+	case !token.IsIdentifier(name):
+		return errors.New("is not a valid Go identifier")
+	}
+	return nil
+}
+
+// markdownFiles returns the paths, relative to source and separated by
+// '/', of the .md files directly in source, or with --recursive of all
+// the .md files below it.  Directories starting with '.' are skipped.
+func (o *options) markdownFiles(source string) ([]string, error) {
+	var files []string
+	if !o.recursive {
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && filepath.Ext(e.Name()) == ".md" {
+				files = append(files, e.Name())
+			}
+		}
+		return files, nil
+	}
+	err := filepath.WalkDir(source, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if e.IsDir() {
+			if path != source && strings.HasPrefix(e.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".md" {
+			return nil
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// generatedStem returns the name of the generated file without its
+// extension, e.g. "docs" for docs.go.
+func (o *options) generatedStem() string {
+	if o.outFile != "" {
+		return strings.TrimSuffix(o.outFile, ".go")
+	}
+	return "docs" + strings.TrimSuffix(o.genSuffix, ".go")
+}
+
+// generatedFile is a file to be written into DEST_GO_DIR/.
+type generatedFile struct {
+	path    string
+	content string
+}
+
+// splitFileName returns the name of the file holding d's docs in --split
+// mode, e.g. docs_run_fns.go.
+func (o *options) splitFileName(d doc) string {
+	return "docs_" + strings.NewReplacer(" ", "_", "-", "_").Replace(d.Command) + o.genSuffix
+}
+
+// verifyFile compares the generated content with the file on disk,
+// printing a unified diff if they don't match.
+func verifyFile(path, content string) error {
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && string(b) == content {
+		return nil
+	}
+	fmt.Fprintf(stdout, "--- %s\n+++ %s (generated)\n", path, path)
+	for _, line := range unifiedDiff(string(b), content) {
+		fmt.Fprintln(stdout, line)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("%s does not exist, rerun mdtogo to generate it", path)
 	}
+	return fmt.Errorf("%s is out of date, rerun mdtogo to regenerate it", path)
 }
 
-func parse(name, value string) doc {
-	name = strings.ReplaceAll(name, filepath.Ext(name), "")
-	name = strings.Title(name)
-	name = strings.ReplaceAll(name, "-", "")
+func (o *options) parse(name, value string) (doc, error) {
+	file := name
+	command := commandName(name)
+	name = deriveName(name)
+
+	var doc doc
+	fm, value, lineNo, err := splitFrontMatter(value)
+	if err != nil {
+		return doc, fmt.Errorf("%s: %w", file, err)
+	}
 
 	scanner := bufio.NewScanner(bytes.NewBufferString(value))
 
-	var long, examples []string
+	var blocks *blockLines
+	if o.renderer == commonmarkRenderer {
+		blocks = commonmarkLines(value)
+	}
+
+	var long, longMarkdown, examples, examplesRaw []string
 	var short string
 	var isLong, isExample, isIndent bool
-	var doc doc
+	// fence is the run of backticks that opened the current code block
+	var fence string
+	var seenCommand, wantShort, inShort bool
+	// structural problems reported by --strict, and the lines they refer to
+	type problem struct {
+		line int
+		text string
+	}
+	var problems []problem
+	firstLine, commandLine, fenceLine := lineNo+1, 0, 0
+	var fenceText string
+	problemf := func(line int, format string, args ...interface{}) {
+		problems = append(problems, problem{line, fmt.Sprintf(format, args...)})
+	}
 
-	for scanner.Scan() {
+	for i := 0; scanner.Scan(); i++ {
 		line := scanner.Text()
+		lineNo++
+		if blocks != nil {
+			if blocks.skip[i] {
+				continue
+			}
+			isIndent = blocks.code[i]
+		}
+
+		// Trim headings before any prefix comparisons so that a hand-edited
+		// "### Examples " is classified the same as "### Examples".
+		if !isIndent && strings.HasPrefix(line, "#") {
+			if trimmed := strings.TrimRightFunc(line, unicode.IsSpace); trimmed != line {
+				warnf("%s:%d: trailing whitespace in heading %q", file, lineNo, line)
+				line = trimmed
+			}
+		}
 
-		if strings.HasPrefix(line, "## ") && short == "" {
-			for scanner.Scan() {
-				if strings.TrimSpace(scanner.Text()) == "" {
+		level, title := legacyHeading(line)
+		isHeading := strings.HasPrefix(line, "#")
+		if blocks != nil {
+			level, title = blocks.headings[i].level, blocks.headings[i].title
+			isHeading = level > 0 || blocks.fences[i]
+		}
+
+		if level == 2 && !seenCommand {
+			seenCommand = true
+			commandLine = lineNo
+			wantShort = true
+			doc.Heading = stripDecoration(title)
+			continue
+		}
+		if inShort {
+			// indented lines continue the Short description
+			if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				if continuation := strings.TrimSpace(line); continuation != "" {
+					short += " " + continuation
 					continue
 				}
-				short = scanner.Text()
-				break
 			}
-			continue
+			inShort = false
 		}
-
-		if !full {
-			if strings.HasPrefix(line, "### Synopsis") {
-				isLong = true
-				isExample = false
+		if wantShort {
+			if strings.TrimSpace(line) == "" {
 				continue
 			}
-
-			if strings.HasPrefix(line, "### Examples") {
-				isLong = false
-				isExample = true
+			wantShort = false
+			if !isHeading {
+				short = stripDecoration(line)
+				inShort = o.multilineShort
 				continue
 			}
+			// a malformed doc with no text below the command heading
+			warnf("%s:%d: expected a Short description below the command heading, found heading %q", file, lineNo, line)
+		}
 
-			if strings.HasPrefix(line, "### ") {
-				isLong = false
-				isExample = false
-				continue
+		if !o.full && level == 3 {
+			title := stripDecoration(title)
+			section := o.sectionOf(title)
+			isLong = section == synopsisSection
+			isExample = section == examplesSection
+			if section == "" {
+				problemf(lineNo, "unknown heading %q", line)
+				section = title
 			}
+			doc.Sections = append(doc.Sections, section)
+			continue
 		}
 
-		if strings.HasPrefix(line, "```") {
+		raw := line
+		isFence := false
+		if blocks != nil {
+			isFence = blocks.fences[i]
+		} else if marker := fenceMarker(line); marker != "" && (!isIndent || closesFence(line, fence)) {
 			isIndent = !isIndent
+			fence = marker
+			isFence = true
+		}
+		if isFence {
+			if fenceLine == 0 {
+				fenceLine, fenceText = lineNo, strings.TrimSpace(line)
+			} else {
+				fenceLine = 0
+			}
+			if isLong || o.full {
+				longMarkdown = append(longMarkdown, raw)
+			} else if isExample {
+				examplesRaw = append(examplesRaw, raw)
+			}
 			continue
 		}
-		line = strings.ReplaceAll(line, "`", "` + \"`\" + `")
 		if isIndent {
 			line = "\t" + line
+		} else if o.dualLong && (isLong || o.full) {
+			line = stripMarkdown(line)
 		}
 
-		if isLong || full {
+		if isLong || o.full {
 			long = append(long, line)
+			longMarkdown = append(longMarkdown, raw)
 			continue
 		}
 		if isExample {
 			examples = append(examples, line)
+			examplesRaw = append(examplesRaw, raw)
 		}
 	}
 
+	if fm.Short != "" {
+		short = fm.Short
+	}
+
+	doc.File = file
+	doc.Command = command
+	doc.FrontMatter = fm
 	doc.Name = name
 	doc.Short = short
+	if o.full && o.fullStripHeading {
+		long = stripTitle(long)
+		longMarkdown = stripTitle(longMarkdown)
+	}
+
 	doc.Long = strings.Join(long, "\n")
+	doc.LongMarkdown = strings.Join(longMarkdown, "\n")
 	doc.Examples = strings.Join(examples, "\n")
+	if doc.Short == "" && o.deriveShort {
+		if doc.Short = firstSentence(long); doc.Short != "" {
+			logf("%s: derived Short %q from Long", file, doc.Short)
+		}
+	}
+	doc.ExamplesRaw = strings.Join(examplesRaw, "\n")
+
+	if fenceLine != 0 {
+		problemf(fenceLine, "code fence %q is never closed", fenceText)
+	}
+	if !seenCommand {
+		problemf(firstLine, "missing the \"## %s\" command heading", command)
+	} else if !o.full {
+		if doc.Short == "" {
+			problemf(commandLine, "empty Short below the command heading")
+		}
+		for _, section := range []string{synopsisSection, examplesSection} {
+			if !hasSection(doc, section) {
+				problemf(commandLine, "missing the \"### %s\" section", section)
+			}
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].line < problems[j].line })
+	for _, p := range problems {
+		doc.Problems = append(doc.Problems, fmt.Sprintf("%s:%d: %s", file, p.line, p.text))
+	}
 
 	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
+		return doc, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return doc, nil
+}
+
+// fenceMarker returns the run of three or more backticks that starts
+// line if it opens or closes a code block, or "" otherwise.
+func fenceMarker(line string) string {
+	n := len(line) - len(strings.TrimLeft(line, "`"))
+	if n < 3 {
+		return ""
+	}
+	return line[:n]
+}
+
+// closesFence reports whether line closes the code block opened by
+// fence: a block like "````" may contain "```" lines, e.g. to show
+// markdown, which are part of its content.
+func closesFence(line, fence string) bool {
+	marker := fenceMarker(line)
+	return len(marker) >= len(fence) && strings.TrimSpace(line[len(marker):]) == ""
+}
+
+const (
+	synopsisSection = "Synopsis"
+	examplesSection = "Examples"
+)
+
+// sectionAliases returns the "### " headings that introduce each of the
+// recognized sections.  Headings are matched by prefix, ignoring case.
+// The Examples headings are those of --examples-aliases.
+func (o *options) sectionAliases() map[string][]string {
+	aliases := map[string][]string{synopsisSection: {"Synopsis"}}
+	for _, alias := range strings.Split(o.examplesAliases, ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases[examplesSection] = append(aliases[examplesSection], alias)
+		}
+	}
+	return aliases
+}
+
+// stripDecoration removes leading decoration such as emoji from a
+// heading or description, e.g. "📚 Synopsis" becomes "Synopsis".
+func stripDecoration(s string) string {
+	return strings.TrimLeftFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || isDecoration(r)
+	})
+}
+
+// isDecoration reports whether r is an emoji or a similar symbol, or one
+// of the modifiers, selectors and joiners emoji are composed with.
+func isDecoration(r rune) bool {
+	switch {
+	case unicode.Is(unicode.So, r):
+		return true
+	case r > unicode.MaxASCII && (unicode.Is(unicode.Sk, r) || unicode.Is(unicode.Sm, r)):
+		return true
+	case unicode.Is(unicode.Variation_Selector, r), unicode.Is(unicode.Me, r):
+		return true
+	case r == '\u200d': // zero width joiner
+		return true
+	}
+	return false
+}
+
+// sectionOf returns the recognized section introduced by a "### "
+// heading with the given title, or "" if it is not recognized.
+func (o *options) sectionOf(title string) string {
+	aliases := o.sectionAliases()
+	for _, section := range []string{synopsisSection, examplesSection} {
+		for _, alias := range aliases[section] {
+			if len(title) >= len(alias) && strings.EqualFold(title[:len(alias)], alias) {
+				return section
+			}
+		}
+	}
+	return ""
+}
+
+// pathSegments splits a markdown file path relative to SOURCE_MD_DIR/
+// into its segments, dropping the extension.  Both '/' and '\' are
+// treated as separators so that names do not depend on the platform.
+func pathSegments(path string) []string {
+	path = strings.ReplaceAll(path, `\`, "/")
+	path = strings.TrimSuffix(path, filepath.Ext(path))
+	return strings.Split(path, "/")
+}
+
+// deriveName returns the variable name prefix for a markdown file path
+// relative to SOURCE_MD_DIR/, e.g. "build/foo-bar.md" becomes BuildFooBar.
+func deriveName(path string) string {
+	var name string
+	for _, s := range pathSegments(path) {
+		name += strings.ReplaceAll(strings.Title(s), "-", "")
+	}
+	return name
+}
+
+// commandName returns the command for a markdown file path relative to
+// SOURCE_MD_DIR/, e.g. "build/foo-bar.md" becomes "build foo-bar".
+func commandName(path string) string {
+	return strings.Join(pathSegments(path), " ")
+}
+
+// checkHeadingName warns if the command named by d's "## " heading is
+// not the one its file is named after, e.g. "## compile" in build.md.
+// A heading naming the full command path, e.g. "## kustomize build",
+// matches as well.
+func checkHeadingName(d doc) {
+	if d.Heading == "" {
+		return
+	}
+	segments := pathSegments(d.File)
+	stem := strings.ToLower(segments[len(segments)-1])
+	slug := slugify(d.Heading)
+	if slug != stem && !strings.HasSuffix(slug, "-"+stem) {
+		warnf("%s: command heading %q does not match the file name", d.File, "## "+d.Heading)
+	}
+}
+
+// slugify returns the lower case, dash separated form of s.
+func slugify(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	}) {
+		if b.Len() > 0 {
+			b.WriteString("-")
+		}
+		b.WriteString(word)
 	}
+	return b.String()
+}
+
+// stripTitle removes a leading heading, and the blank lines below it,
+// from lines of markdown.
+func stripTitle(lines []string) []string {
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			return lines
+		}
+		for i++; i < len(lines) && strings.TrimSpace(lines[i]) == ""; i++ {
+		}
+		return lines[i:]
+	}
+	return lines
+}
+
+// firstSentence returns the first sentence of the first paragraph of
+// prose in lines of Long, joined into a single line.  Headings and code
+// blocks are skipped.
+func firstSentence(lines []string) string {
+	var paragraph []string
+	for _, line := range lines {
+		prose := strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") &&
+			!strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "    ")
+		if prose {
+			paragraph = append(paragraph, strings.Fields(line)...)
+		} else if len(paragraph) > 0 {
+			break
+		}
+	}
+	for i, word := range paragraph {
+		if !strings.HasSuffix(word, ".") && !strings.HasSuffix(word, "!") && !strings.HasSuffix(word, "?") {
+			continue
+		}
+		// a capitalized word follows the end of a sentence, but not "e.g."
+		if i+1 == len(paragraph) || unicode.IsUpper([]rune(paragraph[i+1])[0]) {
+			return strings.Join(paragraph[:i+1], " ")
+		}
+	}
+	return strings.Join(paragraph, " ")
+}
+
+// sortDocs orders docs by front matter weight, then by file name.
+func sortDocs(docs []doc) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		if docs[i].FrontMatter.Weight != docs[j].FrontMatter.Weight {
+			return docs[i].FrontMatter.Weight < docs[j].FrontMatter.Weight
+		}
+		return docs[i].File < docs[j].File
+	})
+}
 
-	return doc
+// logf reports a decision made while reading the docs.
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(stderr, format+"\n", args...)
+}
+
+// warnf reports a non-fatal problem found while reading the docs.
+func warnf(format string, args ...interface{}) {
+	fmt.Fprintf(stderr, "warning: "+format+"\n", args...)
 }
 
 type doc struct {
@@ -204,23 +909,115 @@ type doc struct {
 	Short    string
 	Long     string
 	Examples string
+
+	// LongMarkdown is the unprocessed markdown Long was read from.
+	LongMarkdown string
+	// ExamplesRaw is the unprocessed markdown Examples was read from.
+	ExamplesRaw string
+
+	// File is the markdown file the doc was parsed from.
+	File string
+	// Command is the command name, taken from the file path.
+	Command string
+	// Heading is the text of the "## " command heading.
+	Heading string
+	// Sections lists the "### " headings found in the file, in order.
+	Sections []string
+	// FrontMatter is the metadata from the top of the file, if any.
+	FrontMatter frontMatter
+	// Problems lists the structural problems found in the file, such as
+	// missing sections, as "file:line: problem".  --strict fails on them.
+	Problems []string
 }
 
-func (d doc) String() string {
+// goCode returns the Go declarations holding the docs.
+func (o *options) goCode(docs []doc) (string, error) {
+	if o.constants {
+		return o.constBlock(docs), nil
+	}
+	if o.outputFormat == structFormat {
+		return cmdDocs(docs)
+	}
 	var parts []string
+	for i := range docs {
+		if o.embed {
+			parts = append(parts, o.embedCode(docs[i]))
+			continue
+		}
+		if o.typed {
+			parts = append(parts, docs[i].typedString())
+			continue
+		}
+		if o.gzipped {
+			parts = append(parts, o.gzipCode(docs[i]))
+			continue
+		}
+		parts = append(parts, o.varCode(docs[i]))
+	}
+	return strings.Join(parts, "\n"), nil
+}
 
-	if d.Short != "" {
-		parts = append(parts,
-			fmt.Sprintf("var %sShort=`%s`", d.Name, d.Short))
+// variable is a generated Go variable, or constant, holding a section.
+type variable struct {
+	name  string
+	value string
+}
+
+// emitsEmpty reports whether empty sections are emitted as variables
+// too, as with --emit-empty, or --registry, whose registrations
+// reference every variable.
+func (o *options) emitsEmpty() bool {
+	return o.emitEmpty || o.registry != ""
+}
+
+// variables returns the variables holding d's sections.
+func (o *options) variables(d doc) []variable {
+	var vars []variable
+	empty := o.emitsEmpty()
+
+	if d.Short != "" || empty {
+		vars = append(vars, variable{d.Name + "Short", d.Short})
 	}
-	if d.Long != "" {
-		parts = append(parts,
-			fmt.Sprintf("var %sLong=`%s`", d.Name, d.Long))
+	if d.Long != "" || empty {
+		vars = append(vars, variable{d.Name + "Long", d.Long})
 	}
-	if d.Examples != "" {
-		parts = append(parts,
-			fmt.Sprintf("var %sExamples=`%s`", d.Name, d.Examples))
+	if o.dualLong && (d.LongMarkdown != "" || empty) {
+		vars = append(vars, variable{d.Name + "LongMarkdown", d.LongMarkdown})
 	}
+	if d.Examples != "" || empty {
+		vars = append(vars, variable{d.Name + "Examples", d.Examples})
+	}
+	if o.emitBothExamples && (d.ExamplesRaw != "" || empty) {
+		vars = append(vars, variable{d.Name + "ExamplesRaw", d.ExamplesRaw})
+	}
+	return vars
+}
 
+// varCode returns the variable declarations holding d's sections.
+func (o *options) varCode(d doc) string {
+	var parts []string
+	for _, v := range o.variables(d) {
+		parts = append(parts, fmt.Sprintf("var %s=%s", v.name, goString(v.value)))
+	}
 	return strings.Join(parts, "\n") + "\n"
 }
+
+// constBlock returns a single const declaration holding the sections
+// of all the docs.
+func (o *options) constBlock(docs []doc) string {
+	var b strings.Builder
+	b.WriteString("const (\n")
+	for i := range docs {
+		for _, v := range o.variables(docs[i]) {
+			fmt.Fprintf(&b, "\t%s = %s\n", v.name, goString(v.value))
+		}
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// goString returns s as a Go raw string literal, splicing in any
+// backticks it contains as interpreted string literals.
+func goString(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "` + \"`\" + `") + "`"
+}
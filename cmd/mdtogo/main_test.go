@@ -0,0 +1,2158 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// testOptions returns the options set by the flags args.
+func testOptions(t *testing.T, args ...string) *options {
+	t.Helper()
+	o, _, err := parseFlags(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+// captureWarnings redirects warnings into a buffer for the duration of a test.
+func captureWarnings(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	old := stderr
+	stderr = &buf
+	t.Cleanup(func() { stderr = old })
+	return &buf
+}
+
+func TestParseTrailingHeadingWhitespace(t *testing.T) {
+	warnings := captureWarnings(t)
+	d, err := testOptions(t).parse("build.md", "## build \n\nBuild a thing.\n\n"+
+		"### Synopsis  \n\nLong text.\n\n"+
+		"### Examples\t\n\n    kustomize build\n\n"+
+		"### Flags \n\n    --foo\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Short != "Build a thing." {
+		t.Errorf("unexpected Short %q", d.Short)
+	}
+	if d.Long != "\nLong text.\n" {
+		t.Errorf("unexpected Long %q", d.Long)
+	}
+	if d.Examples != "\n    kustomize build\n" {
+		t.Errorf("unexpected Examples %q", d.Examples)
+	}
+	for _, want := range []string{"build.md:1:", "build.md:5:", "build.md:9:", "build.md:13:"} {
+		if !strings.Contains(warnings.String(), want) {
+			t.Errorf("expected warning for %s, got %q", want, warnings.String())
+		}
+	}
+}
+
+// writeDocs writes the given markdown files into a new temporary source
+// directory and returns it along with a destination directory path.
+func writeDocs(t *testing.T, files map[string]string) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	source := filepath.Join(root, "docs")
+	for name, content := range files {
+		p := filepath.Join(source, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return source, filepath.Join(root, "generateddocs")
+}
+
+const buildDoc = `## build
+
+Build a thing.
+
+### Synopsis
+
+Build a thing from a directory.
+
+### Examples
+
+    kustomize build
+`
+
+func TestRunGenSuffix(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{"build.md": buildDoc})
+	if err := run([]string{"mdtogo", source, dest, "--gen-suffix=_gen.go"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "var BuildShort=`Build a thing.`") {
+		t.Errorf("unexpected output:\n%s", b)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "docs.go")); !os.IsNotExist(err) {
+		t.Errorf("expected no docs.go, got %v", err)
+	}
+
+	err = run([]string{"mdtogo", source, dest, "--gen-suffix=_gen.txt"})
+	if err == nil || !strings.Contains(err.Error(), "must end in .go") {
+		t.Errorf("expected suffix validation error, got %v", err)
+	}
+}
+
+func TestParseFrontMatterAnchors(t *testing.T) {
+	warnings := captureWarnings(t)
+	d, err := testOptions(t).parse("build.md", `---
+shared: &shared
+  short: Build a kustomization target.
+<<: *shared
+---
+## build
+
+Build.
+
+### Synopsis
+
+Long text.
+### Examples 
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Short != "Build a kustomization target." {
+		t.Errorf("unexpected Short %q", d.Short)
+	}
+	if d.Long != "\nLong text." {
+		t.Errorf("unexpected Long %q", d.Long)
+	}
+	// line numbers in warnings account for the front matter block
+	if !strings.Contains(warnings.String(), "build.md:13:") {
+		t.Errorf("expected warning on line 13, got %q", warnings.String())
+	}
+
+	_, err = testOptions(t).parse("build.md", "---\nshort: [\n---\n## build\n")
+	if err == nil || !strings.Contains(err.Error(), "build.md: invalid front matter") {
+		t.Errorf("expected front matter error, got %v", err)
+	}
+}
+
+func TestRunSchema(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": buildDoc,
+		"edit.md":  "## edit\n\nEdit a kustomization file.\n\n### Synopsis\n\nEdit it.\n",
+	})
+	rules := filepath.Join(t.TempDir(), "schema.yaml")
+	err := os.WriteFile(rules, []byte(`requiredSections: [Synopsis, Examples]
+maxShortLength: 20
+requiredFrontMatter: [short]
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := captureWarnings(t)
+	if err := run([]string{"mdtogo", source, dest, "--schema=" + rules}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `warning: build.md: missing required front matter field "short"
+warning: edit.md: missing required section "Examples"
+warning: edit.md: Short is 26 characters long, exceeding the maximum of 20
+warning: edit.md: missing required front matter field "short"
+`
+	if warnings.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, warnings.String())
+	}
+
+	// --strict also requires every file to be well formed
+	err = os.WriteFile(filepath.Join(source, "edit.md"),
+		[]byte("## edit\n\nEdit a kustomization file.\n\n### Synopsis\n\nEdit it.\n\n### Examples\n\n    kustomize edit\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = run([]string{"mdtogo", source, dest, "--schema=" + rules, "--strict"})
+	if err == nil || err.Error() != "3 schema violation(s) found" {
+		t.Errorf("expected strict failure, got %v", err)
+	}
+}
+
+func TestRunRegistry(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md":   buildDoc,
+		"run-fns.md": "## run-fns\n\nRun functions.\n",
+	})
+	if err := run([]string{"mdtogo", source, dest, "--registry=help.Register"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `func init() {
+	help.Register("build", BuildShort, BuildLong, BuildExamples)
+	help.Register("run-fns", RunFnsShort, RunFnsLong, RunFnsExamples)
+}
+`
+	if !strings.HasSuffix(string(b), expected) {
+		t.Errorf("expected output to end with:\n%s\ngot:\n%s", expected, b)
+	}
+	for _, v := range []string{"var RunFnsLong=``", "var RunFnsExamples=``"} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("expected %s for registration, got:\n%s", v, b)
+		}
+	}
+
+	err = run([]string{"mdtogo", source, dest, "--registry=reg",
+		"--registry-template={{.Registry}}.Add({{.Name}}Doc{})"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(b), "\treg.Add("); got != 2 {
+		t.Errorf("expected one registration per command, got %d:\n%s", got, b)
+	}
+}
+
+func TestParseHeadingCase(t *testing.T) {
+	for _, tc := range []struct{ synopsis, examples string }{
+		{"Synopsis", "Examples"},
+		{"synopsis", "examples"},
+		{"SYNOPSIS", "EXAMPLES"},
+		{"SyNoPsIs", "eXaMpLeS"},
+	} {
+		d, err := testOptions(t).parse("build.md", "## build\n\nBuild.\n\n"+
+			"### "+tc.synopsis+"\n\nLong text.\n\n"+
+			"### "+tc.examples+"\n\n    kustomize build\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Long != "\nLong text.\n" {
+			t.Errorf("%s: unexpected Long %q", tc.synopsis, d.Long)
+		}
+		if d.Examples != "\n    kustomize build" {
+			t.Errorf("%s: unexpected Examples %q", tc.examples, d.Examples)
+		}
+		if len(d.Sections) != 2 || d.Sections[0] != "Synopsis" || d.Sections[1] != "Examples" {
+			t.Errorf("%s: unexpected sections %v", tc.synopsis, d.Sections)
+		}
+	}
+}
+
+func TestRunWeight(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"apply.md":  "---\nweight: 20\n---\n## apply\n\nApply.\n",
+		"build.md":  "---\nweight: 10\n---\n## build\n\nBuild.\n",
+		"create.md": "---\nweight: -5\n---\n## create\n\nCreate.\n",
+		"diff.md":   "---\nweight: 10\n---\n## diff\n\nDiff.\n",
+		"edit.md":   "## edit\n\nEdit.\n",
+	})
+	if err := run([]string{"mdtogo", source, dest}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(line, "var ") {
+			order = append(order, strings.SplitN(line[len("var "):], "=", 2)[0])
+		}
+	}
+	expected := "CreateShort EditShort BuildShort DiffShort ApplyShort"
+	if got := strings.Join(order, " "); got != expected {
+		t.Errorf("expected order %q, got %q", expected, got)
+	}
+}
+
+// runGenerated compiles the generated package main in dir together with
+// program, using the given go build flags, runs it and returns its output.
+func runGenerated(t *testing.T, dir, program string, buildFlags ...string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/generated\n\ngo 1.20\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "program.go"), []byte(program), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", append(append([]string{"run"}, buildFlags...), ".")...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v:\n%s", err, out)
+	}
+	return string(out)
+}
+
+func TestRunTyped(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{
+		"build.md": buildDoc,
+		"edit.md":  "## edit\n\nEdit a kustomization file.\n",
+	})
+	dest := filepath.Join(t.TempDir(), "main")
+	if err := run([]string{"mdtogo", source, dest, "--typed"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(b), "type CommandDoc struct"); got != 1 {
+		t.Errorf("expected CommandDoc to be declared once, got %d", got)
+	}
+	if strings.Contains(string(b), "BuildShort") {
+		t.Errorf("expected no loose variables, got:\n%s", b)
+	}
+
+	out := runGenerated(t, dest, `package main
+
+import "fmt"
+
+func main() {
+	for _, d := range []CommandDoc{BuildDoc, EditDoc} {
+		fmt.Printf("%q %q %q\n", d.GetShort(), d.GetLong(), d.GetExamples())
+	}
+}
+`)
+	expected := `"Build a thing." "\nBuild a thing from a directory.\n" "\n    kustomize build"
+"Edit a kustomization file." "" ""
+`
+	if out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestRunExamplesBanner(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{
+		"build.md": buildDoc,
+		"edit.md":  "## edit\n\nEdit a kustomization file.\n\n### Examples\n\n    kustomize edit `fix`\n",
+		"fmt.md":   "## fmt\n\nFormat resources.\n",
+	})
+	dest := filepath.Join(t.TempDir(), "main")
+	err := run([]string{"mdtogo", source, dest, "--examples-banner=Assumes `KUBECONFIG` is set."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "FmtExamples") {
+		t.Errorf("expected no banner for commands without examples, got:\n%s", b)
+	}
+
+	out := runGenerated(t, dest, `package main
+
+import "fmt"
+
+func main() {
+	fmt.Printf("%q\n%q\n", BuildExamples, EditExamples)
+}
+`)
+	expected := `"Assumes ` + "`KUBECONFIG`" + ` is set.\n\n    kustomize build"
+"Assumes ` + "`KUBECONFIG`" + ` is set.\n\n    kustomize edit ` + "`fix`" + `"
+`
+	if out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestDeriveNameSeparators(t *testing.T) {
+	for _, path := range []string{"build/foo.md", `build\foo.md`} {
+		if got := deriveName(path); got != "BuildFoo" {
+			t.Errorf("%s: expected BuildFoo, got %s", path, got)
+		}
+		if got := commandName(path); got != "build foo" {
+			t.Errorf("%s: expected command %q, got %q", path, "build foo", got)
+		}
+	}
+	for path, expected := range map[string]string{
+		"run-fns.md":             "RunFns",
+		`edit\add\config-map.md`: "EditAddConfigMap",
+		"edit/add/config-map.md": "EditAddConfigMap",
+	} {
+		if got := deriveName(path); got != expected {
+			t.Errorf("%s: expected %s, got %s", path, expected, got)
+		}
+	}
+
+	d, err := testOptions(t).parse(`edit\set\image.md`, "## image\n\nSet an image.\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "EditSetImage" || d.Command != "edit set image" {
+		t.Errorf("unexpected name %q and command %q", d.Name, d.Command)
+	}
+}
+
+func TestRunCopyrightYear(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{"build.md": buildDoc})
+	for year, expected := range map[string]string{
+		"":        "// Copyright 2019 The Kubernetes Authors.\n",
+		"2024":    "// Copyright 2024 The Kubernetes Authors.\n",
+		"current": fmt.Sprintf("// Copyright %d The Kubernetes Authors.\n", time.Now().Year()),
+	} {
+		args := []string{"mdtogo", source, dest}
+		if year != "" {
+			args = append(args, "--copyright-year="+year)
+		}
+		if err := run(args); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(b), expected+"// SPDX-License-Identifier: Apache-2.0\n") {
+			t.Errorf("%q: expected header %q, got:\n%s", year, expected, b)
+		}
+	}
+
+	err := run([]string{"mdtogo", source, dest, "--copyright-year=last"})
+	if err == nil || !strings.Contains(err.Error(), `must be a year or "current"`) {
+		t.Errorf("expected year validation error, got %v", err)
+	}
+}
+
+func TestRunDualLong(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{
+		"build.md": "## build\n\nBuild.\n\n### Synopsis\n\n" +
+			"See [the docs](https://kubectl.docs.kubernetes.io) for **more**.\n" +
+			"Resources are _never_ pruned, see `**/*_test.yaml`.\n\n" +
+			"```\nkustomize build [dir](x)\n```\n",
+	})
+	dest := filepath.Join(t.TempDir(), "main")
+	if err := run([]string{"mdtogo", source, dest, "--dual-long"}); err != nil {
+		t.Fatal(err)
+	}
+	out := runGenerated(t, dest, `package main
+
+import "fmt"
+
+func main() {
+	fmt.Printf("%s\n---\n%s\n", BuildLong, BuildLongMarkdown)
+}
+`)
+	expected := "\nSee the docs for more.\n" +
+		"Resources are never pruned, see `**/*_test.yaml`.\n\n" +
+		"\tkustomize build [dir](x)\n" +
+		"---\n" +
+		"\nSee [the docs](https://kubectl.docs.kubernetes.io) for **more**.\n" +
+		"Resources are _never_ pruned, see `**/*_test.yaml`.\n\n" +
+		"```\nkustomize build [dir](x)\n```\n"
+	if out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+// captureOutput redirects reports into a buffer for the duration of a test.
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	old := stdout
+	stdout = &buf
+	t.Cleanup(func() { stdout = old })
+	return &buf
+}
+
+func TestRunVerify(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{"build.md": buildDoc})
+	if err := run([]string{"mdtogo", source, dest, "--license=none"}); err != nil {
+		t.Fatal(err)
+	}
+	output := captureOutput(t)
+	if err := run([]string{"mdtogo", source, dest, "--license=none", "--verify"}); err != nil {
+		t.Fatalf("expected up to date file, got %v", err)
+	}
+	if output.Len() != 0 {
+		t.Errorf("expected no output, got %q", output.String())
+	}
+
+	// same content but different flags is stale
+	err := run([]string{"mdtogo", source, dest, "--verify"})
+	if err == nil || !strings.Contains(err.Error(), "is out of date") {
+		t.Errorf("expected stale file error, got %v", err)
+	}
+	if !strings.Contains(output.String(), "+// Copyright 2019 The Kubernetes Authors.\n") {
+		t.Errorf("expected diff of the license, got %q", output.String())
+	}
+
+	output.Reset()
+	stale := filepath.Join(source, "build.md")
+	if err := os.WriteFile(stale, []byte(strings.Replace(buildDoc, "Build a thing.", "Build it.", 1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = run([]string{"mdtogo", source, dest, "--license=none", "--verify"})
+	if err == nil || !strings.Contains(err.Error(), "is out of date") {
+		t.Errorf("expected stale file error, got %v", err)
+	}
+	expected := "@@ -3,7 +3,7 @@\n // Code generated by \"mdtogo\"; DO NOT EDIT.\n package generateddocs\n \n" +
+		"-var BuildShort=`Build a thing.`\n+var BuildShort=`Build it.`\n var BuildLong=`\n"
+	if !strings.Contains(output.String(), expected) {
+		t.Errorf("expected diff containing:\n%s\ngot:\n%s", expected, output.String())
+	}
+	after, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Errorf("--verify must not write the generated file")
+	}
+
+	output.Reset()
+	if err := os.Remove(filepath.Join(dest, "docs.go")); err != nil {
+		t.Fatal(err)
+	}
+	err = run([]string{"mdtogo", source, dest, "--license=none", "--verify"})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing file error, got %v", err)
+	}
+	if !strings.Contains(output.String(), "\n@@ -0,0 +1,") {
+		t.Errorf("expected diff adding the whole file, got:\n%s", output.String())
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc\nd\ne", "a\nc\nd\nx\ne\nf")
+	expected := []string{"-b", "+x", "+f"}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := diffLines("same\n", "same\n"); len(got) != 0 {
+		t.Errorf("expected no differences, got %v", got)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	b := "1\nx\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n15\ny\n"
+	expected := []string{
+		"@@ -1,5 +1,5 @@", " 1", "-2", "+x", " 3", " 4", " 5",
+		"@@ -11,5 +11,5 @@", " 11", " 12", " 13", "-14", " 15", "+y",
+	}
+	if got := unifiedDiff(a, b); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	// changes close together share a hunk
+	got := unifiedDiff("a\nb\nc\nd\ne\n", "a\nx\nc\nd\ny\n")
+	expected = []string{"@@ -1,5 +1,5 @@", " a", "-b", "+x", " c", " d", "-e", "+y"}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if got := unifiedDiff("same\n", "same\n"); len(got) != 0 {
+		t.Errorf("expected no differences, got %v", got)
+	}
+}
+
+func TestParseShortHeadingGuard(t *testing.T) {
+	warnings := captureWarnings(t)
+	d, err := testOptions(t).parse("build.md", "## build\n\n### Synopsis\n\nLong text.\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Short != "" {
+		t.Errorf("expected empty Short, got %q", d.Short)
+	}
+	if d.Long != "\nLong text." {
+		t.Errorf("expected the heading to still start the Synopsis, got Long %q", d.Long)
+	}
+	expected := "warning: build.md:3: expected a Short description below the command heading, found heading \"### Synopsis\"\n"
+	if warnings.String() != expected {
+		t.Errorf("expected warning %q, got %q", expected, warnings.String())
+	}
+}
+
+func TestRunManOut(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": "## build\n\nBuild a kustomization target.\n\n### Synopsis\n\n" +
+			"Prints configuration to stdout.\n\n    DIR: a directory\n\n.hidden is not a request\n\n" +
+			"### Examples\n\n    kustomize build --load-restrictor=none\n",
+	})
+	man := filepath.Join(t.TempDir(), "man")
+	if err := run([]string{"mdtogo", source, dest, "--man-out=" + man}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(man, "build.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `.TH "BUILD" "1"
+.SH NAME
+build \- Build a kustomization target.
+.SH DESCRIPTION
+.PP
+Prints configuration to stdout.
+.PP
+.nf
+    DIR: a directory
+
+.fi
+.PP
+\&.hidden is not a request
+.SH EXAMPLES
+.nf
+    kustomize build \-\-load\-restrictor=none
+.fi
+`
+	if string(b) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b)
+	}
+}
+
+func TestParseMultilineShort(t *testing.T) {
+	const md = "## build\n\nBuild a kustomization target\n  from a directory\n\tor a remote URL.\n\n" +
+		"### Synopsis\n\nLong text.\n"
+	for enabled, expected := range map[bool]string{
+		false: "Build a kustomization target",
+		true:  "Build a kustomization target from a directory or a remote URL.",
+	} {
+		d, err := testOptions(t, fmt.Sprintf("--multiline-short=%v", enabled)).parse("build.md", md)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Short != expected {
+			t.Errorf("%v: expected Short %q, got %q", enabled, expected, d.Short)
+		}
+		if d.Long != "\nLong text." {
+			t.Errorf("%v: unexpected Long %q", enabled, d.Long)
+		}
+	}
+
+	d, err := testOptions(t, "--multiline-short").parse("build.md", "## build\n\nBuild.\n### Synopsis\n    indented Long\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Short != "Build." || d.Long != "    indented Long" {
+		t.Errorf("expected the heading to end Short, got Short %q and Long %q", d.Short, d.Long)
+	}
+}
+
+func TestRunSplitLicenseFirstOnly(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md":   buildDoc,
+		"edit.md":    "## edit\n\nEdit a kustomization file.\n",
+		"run-fns.md": "## run-fns\n\nRun functions.\n",
+	})
+	const license = "// Copyright 2019 The Kubernetes Authors.\n"
+	for _, licenseFirstOnly := range []bool{false, true} {
+		args := []string{"mdtogo", source, dest, "--split"}
+		if licenseFirstOnly {
+			args = append(args, "--license-first-only")
+		}
+		if err := run(args); err != nil {
+			t.Fatal(err)
+		}
+		for i, name := range []string{"docs_build.go", "docs_edit.go", "docs_run_fns.go"} {
+			b, err := os.ReadFile(filepath.Join(dest, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectLicense := i == 0 || !licenseFirstOnly
+			if strings.HasPrefix(string(b), license) != expectLicense {
+				t.Errorf("%v: %s: expected license %v, got:\n%s", licenseFirstOnly, name, expectLicense, b)
+			}
+			if !strings.Contains(string(b), "// Code generated by \"mdtogo\"; DO NOT EDIT.\npackage generateddocs\n") {
+				t.Errorf("%v: %s: expected generated file header, got:\n%s", licenseFirstOnly, name, b)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "docs.go")); !os.IsNotExist(err) {
+		t.Errorf("expected no docs.go in split mode, got %v", err)
+	}
+}
+
+func TestRunEmitBothExamples(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{
+		"build.md": "## build\n\nBuild.\n\n### Examples\n\n```sh\n# build `dir`\nkustomize build dir\n```\n",
+	})
+	dest := filepath.Join(t.TempDir(), "main")
+	err := run([]string{"mdtogo", source, dest, "--emit-both-examples", "--examples-banner=Note:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := runGenerated(t, dest, `package main
+
+import "fmt"
+
+func main() {
+	fmt.Printf("%q\n%q\n", BuildExamples, BuildExamplesRaw)
+}
+`)
+	expected := `"Note:\n\n\t# build ` + "`dir`" + `\n\tkustomize build dir"
+"\n` + "```" + `sh\n# build ` + "`dir`" + `\nkustomize build dir\n` + "```" + `"
+`
+	if out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestRunWarnNameMismatch(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md":   "## compile\n\nBuild.\n",
+		"edit.md":    "## kustomize edit\n\nEdit.\n",
+		"run-fns.md": "## Run-Fns\n\nRun functions.\n",
+	})
+	warnings := captureWarnings(t)
+	if err := run([]string{"mdtogo", source, dest}); err != nil {
+		t.Fatal(err)
+	}
+	if warnings.Len() != 0 {
+		t.Errorf("expected no warnings without the flag, got %q", warnings.String())
+	}
+	if err := run([]string{"mdtogo", source, dest, "--warn-name-mismatch"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "warning: build.md: command heading \"## compile\" does not match the file name\n"
+	if warnings.String() != expected {
+		t.Errorf("expected %q, got %q", expected, warnings.String())
+	}
+}
+
+func TestRunConst(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md":   buildDoc,
+		"run-fns.md": "## run-fns\n\nRun functions.\n\n### Examples\n\n    kustomize fn run `dir`\n",
+	})
+	if err := run([]string{"mdtogo", source, dest, "--const"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatted, err := format.Source(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, formatted) {
+		t.Errorf("expected gofmt'd output, got:\n%s", b)
+	}
+	if !strings.Contains(string(b), "\tRunFnsShort    = `Run functions.`\n") {
+		t.Errorf("expected aligned constants, got:\n%s", b)
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "docs.go", b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks int
+	var names []string
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			t.Errorf("unexpected declaration %T", decl)
+			continue
+		}
+		blocks++
+		for _, spec := range gen.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				names = append(names, name.Name)
+			}
+		}
+	}
+	if blocks != 1 {
+		t.Errorf("expected a single const block, got %d", blocks)
+	}
+	expected := "BuildShort BuildLong BuildExamples RunFnsShort RunFnsExamples"
+	if got := strings.Join(names, " "); got != expected {
+		t.Errorf("expected constants %q, got %q", expected, got)
+	}
+}
+
+func TestRunCobraRenderTests(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md":   buildDoc,
+		"run-fns.md": "## run-fns\n\nRun functions.\n",
+	})
+	if err := run([]string{"mdtogo", source, dest, "--cobra-render-tests", "--license=none"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "docs_test.go", b, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("%v:\n%s", err, b)
+	}
+	if f.Name.Name != "generateddocs" {
+		t.Errorf("unexpected package %s", f.Name.Name)
+	}
+	var importsCobra bool
+	for _, imp := range f.Imports {
+		importsCobra = importsCobra || imp.Path.Value == `"github.com/spf13/cobra"`
+	}
+	if !importsCobra {
+		t.Errorf("expected cobra import, got:\n%s", b)
+	}
+	for _, expected := range []string{
+		`use:      "build",
+			short:    BuildShort,
+			long:     BuildLong,
+			example:  BuildExamples,
+			expected: []string{"Build a thing from a directory.", "kustomize build"},`,
+		`use:      "run-fns",
+			short:    RunFnsShort,
+			long:     "",
+			example:  "",
+			expected: []string{"Run functions."},`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("expected test case:\n%s\ngot:\n%s", expected, b)
+		}
+	}
+	// without a license the file starts with a blank line, like docs.go
+	b = bytes.TrimPrefix(b, []byte("\n"))
+	formatted, err := format.Source(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, formatted) {
+		t.Errorf("expected gofmt'd output, got:\n%s", b)
+	}
+}
+
+func TestRunDocsMarkdownFile(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": buildDoc,
+		"docs.md":  "## docs\n\nShow the docs.\n",
+	})
+	warnings := captureWarnings(t)
+	if err := run([]string{"mdtogo", source, dest}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "warning: docs.md: shares its name with the generated docs.go; its docs are generated as Docs variables\n"
+	if warnings.String() != expected {
+		t.Errorf("expected %q, got %q", expected, warnings.String())
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"var BuildShort=`Build a thing.`", "var DocsShort=`Show the docs.`"} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("expected %s, got:\n%s", v, b)
+		}
+	}
+
+	warnings.Reset()
+	if err := run([]string{"mdtogo", source, dest, "--gen-suffix=_gen.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if warnings.Len() != 0 {
+		t.Errorf("expected no warning for docs_gen.go, got %q", warnings.String())
+	}
+}
+
+func TestRunEmitEmpty(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"edit.md": "## edit\n\nEdit a kustomization file.\n\n### Synopsis\n\nEdit it.\n",
+	})
+	for _, emit := range []bool{false, true} {
+		args := []string{"mdtogo", source, dest, "--license=none"}
+		if emit {
+			args = append(args, "--emit-empty")
+		}
+		if err := run(args); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "var EditShort=`Edit a kustomization file.`\nvar EditLong=`\nEdit it.`\n"
+		if emit {
+			expected += "var EditExamples=``\n"
+		}
+		if !strings.HasSuffix(string(b), "package generateddocs\n\n"+expected) {
+			t.Errorf("%v: expected variables:\n%s\ngot:\n%s", emit, expected, b)
+		}
+	}
+}
+
+func TestRunSpellcheck(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": "## build\n\nBuild a Kustomization target.\n\n### Synopsis\n\n" +
+			"Prints the configration, see [the docs](https://kustomize.io/docs).\n" +
+			"Runs `kustomizze` and\n\n```\nconfgi unchecked\n```\n\n" +
+			"### Examples\n\n    kustomize buidl\n",
+	})
+	dir := t.TempDir()
+	words := filepath.Join(dir, "words")
+	if err := os.WriteFile(words, []byte("a\nand\nbuild\nconfiguration\ndocs\nprint\nrun\nsee\nthe\ntarget\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	allowed := filepath.Join(dir, "allowed")
+	if err := os.WriteFile(allowed, []byte("# product names\nkustomization\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := captureWarnings(t)
+	err := run([]string{"mdtogo", source, dest, "--spellcheck",
+		"--spellcheck-words=" + words, "--dictionary=" + allowed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "warning: build.md: Long: unrecognized word \"configration\"\n"
+	if warnings.String() != expected {
+		t.Errorf("expected %q, got %q", expected, warnings.String())
+	}
+
+	err = run([]string{"mdtogo", source, dest, "--spellcheck", "--spellcheck-words=" + filepath.Join(dir, "missing")})
+	if err == nil || !strings.Contains(err.Error(), "loading spellcheck dictionary") {
+		t.Errorf("expected dictionary error, got %v", err)
+	}
+}
+
+func TestRunCombinedOut(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": "---\nweight: 1\n---\n" + buildDoc,
+		"edit.md":  "## edit\n\nEdit a kustomization file.\n\n### Synopsis\n\n```\nkustomize edit\n```\n",
+		"apply.md": "---\nweight: 2\n---\n## apply\n\nApply.\n",
+	})
+	combined := filepath.Join(t.TempDir(), "reference.md")
+	if err := run([]string{"mdtogo", source, dest, "--combined-out=" + combined}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# Commands\n\n" +
+		"- [edit](#edit)\n" +
+		"- [build](#build)\n" +
+		"- [apply](#apply)\n" +
+		"\n## edit\n\nEdit a kustomization file.\n\n### Synopsis\n\n```\nkustomize edit\n```\n" +
+		"\n## build\n\nBuild a thing.\n\n### Synopsis\n\nBuild a thing from a directory.\n" +
+		"\n### Examples\n\n    kustomize build\n" +
+		"\n## apply\n\nApply.\n"
+	if string(b) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b)
+	}
+}
+
+func TestRunMissingTrailingNewline(t *testing.T) {
+	for name, md := range map[string]string{
+		"examples": buildDoc,
+		"synopsis": "## build\n\nBuild.\n\n### Synopsis\n\nLast line of Long.\n",
+		"fence":    "## build\n\nBuild.\n\n### Examples\n\n```\nkustomize build\n```\n",
+		"short":    "## build\n\nBuild a thing\n  over two lines.\n",
+		"front":    "---\nshort: Build.\n---\n",
+	} {
+		for _, flags := range [][]string{nil, {"--full=true"}, {"--multiline-short", "--emit-both-examples"}} {
+			var outputs []string
+			for _, content := range []string{md, strings.TrimSuffix(md, "\n")} {
+				source, dest := writeDocs(t, map[string]string{"build.md": content})
+				if err := run(append([]string{"mdtogo", source, dest}, flags...)); err != nil {
+					t.Fatal(err)
+				}
+				b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				outputs = append(outputs, string(b))
+			}
+			if outputs[0] != outputs[1] {
+				t.Errorf("%s %v: expected identical output, got:\n%s\nand without the trailing newline:\n%s",
+					name, flags, outputs[0], outputs[1])
+			}
+		}
+	}
+}
+
+func TestRunFullStripHeading(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{
+		"config-io.md": "# Configuration IO API Semantics\n\nResource Configuration may be read.\n\n" +
+			"### Index\n\nThe index.\n",
+	})
+	for _, strip := range []bool{false, true} {
+		args := []string{"mdtogo", source, filepath.Join(t.TempDir(), "main"), "--full=true"}
+		if strip {
+			args = append(args, "--full-strip-heading")
+		}
+		if err := run(args); err != nil {
+			t.Fatal(err)
+		}
+		out := runGenerated(t, args[2], "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Print(ConfigIoLong) }\n")
+		expected := "Resource Configuration may be read.\n\n### Index\n\nThe index."
+		if !strip {
+			expected = "# Configuration IO API Semantics\n\n" + expected
+		}
+		if out != expected {
+			t.Errorf("%v: expected Long:\n%s\ngot:\n%s", strip, expected, out)
+		}
+	}
+}
+
+func TestRunEmbedBuildTag(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{
+		"build.md":   buildDoc,
+		"run-fns.md": "## run-fns\n\nRun `functions`.\n",
+	})
+	dest := filepath.Join(t.TempDir(), "main")
+	err := run([]string{"mdtogo", source, dest, "--embed", "--build-tag=fulldocs", "--cobra-render-tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "// Copyright 2019 The Kubernetes Authors.\n"+
+		"// SPDX-License-Identifier: Apache-2.0\n\n//go:build fulldocs\n\n") {
+		t.Errorf("expected build constraint below the license, got:\n%s", b)
+	}
+	test, err := os.ReadFile(filepath.Join(dest, "docs_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(test), "\n//go:build fulldocs\n\n") {
+		t.Errorf("expected the generated test to carry the build constraint, got:\n%s", test)
+	}
+
+	// every directive refers to a generated file holding the section
+	expected := map[string]string{
+		"docs/build.short.txt":    "Build a thing.",
+		"docs/build.long.txt":     "\nBuild a thing from a directory.\n",
+		"docs/build.examples.txt": "\n    kustomize build",
+		"docs/run-fns.short.txt":  "Run `functions`.",
+	}
+	var directives int
+	for _, line := range strings.Split(string(b), "\n") {
+		if !strings.HasPrefix(line, "//go:embed ") {
+			continue
+		}
+		directives++
+		p := strings.TrimPrefix(line, "//go:embed ")
+		content, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(p)))
+		if err != nil {
+			t.Errorf("%s: %v", p, err)
+			continue
+		}
+		if string(content) != expected[p] {
+			t.Errorf("%s: expected %q, got %q", p, expected[p], content)
+		}
+	}
+	if directives != len(expected) {
+		t.Errorf("expected %d //go:embed directives, got %d:\n%s", len(expected), directives, b)
+	}
+
+	program := `//go:build fulldocs
+
+package main
+
+import "fmt"
+
+func main() { fmt.Printf("%q %q", BuildShort, RunFnsShort) }
+`
+	if out := runGenerated(t, dest, program, "-tags=fulldocs"); out != `"Build a thing." "Run `+"`functions`"+`."` {
+		t.Errorf("unexpected embedded docs %s", out)
+	}
+}
+
+func TestParseHeadingDecoration(t *testing.T) {
+	d, err := testOptions(t).parse("build.md", "## 🚀 build\n\n✨ Build a `kustomization` target.\n\n"+
+		"### 📚 Synopsis\n\nLong text.\n\n"+
+		"### 👩‍💻 Examples\n\n    kustomize build\n\n"+
+		"### ⚙️ Flags\n\n    --foo\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Heading != "build" {
+		t.Errorf("unexpected heading %q", d.Heading)
+	}
+	if d.Short != "Build a `kustomization` target." {
+		t.Errorf("unexpected Short %q", d.Short)
+	}
+	if d.Long != "\nLong text.\n" {
+		t.Errorf("unexpected Long %q", d.Long)
+	}
+	if d.Examples != "\n    kustomize build\n" {
+		t.Errorf("unexpected Examples %q", d.Examples)
+	}
+	if strings.Join(d.Sections, ",") != "Synopsis,Examples,Flags" {
+		t.Errorf("unexpected sections %q", d.Sections)
+	}
+
+	d, err = testOptions(t).parse("build.md", "## build\n\n[Alpha] `kustomize` builds.\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Short != "[Alpha] `kustomize` builds." {
+		t.Errorf("expected punctuation to be kept, got Short %q", d.Short)
+	}
+}
+
+func TestRunGroupByParent(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"edit.md":      "## edit\n\nEdit a kustomization.\n",
+		"edit-add.md":  "---\nparent: edit\n---\n## edit add\n\nAdd an item.\n",
+		"edit-set.md":  "---\nparent: edit\n---\n## edit set\n\nSet a value.\n",
+		"set-image.md": "---\nparent: edit-set\n---\n## edit set image\n\nSet an image.\n",
+		"build.md":     buildDoc,
+	})
+	if err := run([]string{"mdtogo", source, dest, "--group-by-parent"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `var Children = map[string][]string{
+	"": {"build", "edit"},
+	"edit": {"edit-add", "edit-set"},
+	"edit-set": {"set-image"},
+}
+`
+	if !strings.HasSuffix(string(b), expected) {
+		t.Errorf("expected output to end with:\n%s\ngot:\n%s", expected, b)
+	}
+
+	if err := run([]string{"mdtogo", source, dest}); err != nil {
+		t.Fatal(err)
+	}
+	b, err = os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "Children") {
+		t.Errorf("expected no Children without --group-by-parent, got:\n%s", b)
+	}
+}
+
+// validateJSON checks value against the subset of JSON Schema emitted
+// by --json-schema: type, properties, required, additionalProperties
+// and items.
+func validateJSON(schema map[string]interface{}, value interface{}, path string) []string {
+	var problems []string
+	switch schema["type"] {
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{path + ": expected an array"}
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		for i, item := range items {
+			problems = append(problems, validateJSON(itemSchema, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{path + ": expected an object"}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		required, _ := schema["required"].([]interface{})
+		for _, r := range required {
+			if _, ok := object[r.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required %q", path, r))
+			}
+		}
+		for k, v := range object {
+			p, ok := properties[k].(map[string]interface{})
+			if !ok {
+				if schema["additionalProperties"] == false {
+					problems = append(problems, fmt.Sprintf("%s: unexpected property %q", path, k))
+				}
+				continue
+			}
+			problems = append(problems, validateJSON(p, v, path+"."+k)...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			problems = append(problems, path+": expected a string")
+		}
+	}
+	return problems
+}
+
+func TestRunJSONSchema(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md":   buildDoc,
+		"run-fns.md": "## run-fns\n\nRun functions.\n",
+	})
+	dir := t.TempDir()
+	docsJSON := filepath.Join(dir, "docs.json")
+	schemaJSON := filepath.Join(dir, "docs.schema.json")
+
+	read := func(path string) interface{} {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v interface{}
+		if err := json.Unmarshal(b, &v); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		return v
+	}
+
+	for _, tc := range []struct {
+		flags    []string
+		fields   []string
+		required int
+	}{
+		{nil, []string{"command", "name", "short", "long", "examples"}, 2},
+		{[]string{"--dual-long", "--emit-both-examples"},
+			[]string{"command", "name", "short", "long", "longMarkdown", "examples", "examplesRaw"}, 2},
+		{[]string{"--emit-empty", "--group-by-parent"},
+			[]string{"command", "name", "short", "long", "examples", "parent"}, 5},
+	} {
+		args := append([]string{"mdtogo", source, dest,
+			"--json-out=" + docsJSON, "--json-schema=" + schemaJSON}, tc.flags...)
+		if err := run(args); err != nil {
+			t.Fatal(err)
+		}
+		schema := read(schemaJSON).(map[string]interface{})
+		items := schema["items"].(map[string]interface{})
+		properties := items["properties"].(map[string]interface{})
+		if len(properties) != len(tc.fields) {
+			t.Errorf("%v: expected properties %v, got %v", tc.flags, tc.fields, properties)
+		}
+		for _, f := range tc.fields {
+			if _, ok := properties[f]; !ok {
+				t.Errorf("%v: expected property %q, got %v", tc.flags, f, properties)
+			}
+		}
+		if got := len(items["required"].([]interface{})); got != tc.required {
+			t.Errorf("%v: expected %d required fields, got %v", tc.flags, tc.required, items["required"])
+		}
+		if problems := validateJSON(schema, read(docsJSON), "docs"); len(problems) > 0 {
+			t.Errorf("%v: docs.json does not match its schema:\n%s", tc.flags, strings.Join(problems, "\n"))
+		}
+	}
+
+	// the validator must reject what the schema doesn't allow
+	if err := run([]string{"mdtogo", source, dest, "--json-schema=" + schemaJSON}); err != nil {
+		t.Fatal(err)
+	}
+	schema := read(schemaJSON).(map[string]interface{})
+	bad := []interface{}{map[string]interface{}{"command": "build", "name": 1, "longMarkdown": "x"}}
+	if problems := validateJSON(schema, bad, "docs"); len(problems) != 2 {
+		t.Errorf("expected a wrongly typed and an unexpected property, got %v", problems)
+	}
+
+	docs := read(docsJSON).([]interface{})
+	build := docs[0].(map[string]interface{})
+	if build["short"] != "Build a thing." || build["command"] != "build" || build["name"] != "Build" {
+		t.Errorf("unexpected build object %v", build)
+	}
+}
+
+func TestRunFencedBackticks(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{
+		"fmt.md": "## fmt\n\nFormat markdown.\n\n### Synopsis\n\n" +
+			"Fences may nest `code` spans:\n\n" +
+			"````markdown\n" +
+			"Run `fmt` on a file:\n" +
+			"```go\n" +
+			"s := `raw ``string```\n" +
+			"```\n" +
+			"````\n\n" +
+			"### Examples\n\n" +
+			"```\n" +
+			"echo '``' | kustomize fmt\n" +
+			"```\n",
+	})
+	dest := filepath.Join(t.TempDir(), "main")
+	if err := run([]string{"mdtogo", source, dest, "--emit-both-examples"}); err != nil {
+		t.Fatal(err)
+	}
+	out := runGenerated(t, dest, `package main
+
+import "fmt"
+
+func main() { fmt.Print(FmtLong + "\x00" + FmtExamples + "\x00" + FmtExamplesRaw) }
+`)
+	expected := []string{
+		"\nFences may nest `code` spans:\n\n" +
+			"\tRun `fmt` on a file:\n" +
+			"\t```go\n" +
+			"\ts := `raw ``string```\n" +
+			"\t```\n",
+		"\n\techo '``' | kustomize fmt",
+		"\n```\necho '``' | kustomize fmt\n```",
+	}
+	got := strings.Split(out, "\x00")
+	if len(got) != len(expected) {
+		t.Fatalf("unexpected output %q", out)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected:\n%q\ngot:\n%q", expected[i], got[i])
+		}
+	}
+}
+
+func TestRunLineEnding(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{"build.md": buildDoc})
+	dest := filepath.Join(t.TempDir(), "main")
+	license := filepath.Join(t.TempDir(), "license.txt")
+	if err := os.WriteFile(license, []byte("// Copyright Windows.\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run([]string{"mdtogo", source, dest, "--line-ending=crlf", "--license=" + license}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lf, crlf := strings.Count(string(b), "\n"), strings.Count(string(b), "\r\n"); lf != crlf || crlf == 0 {
+		t.Errorf("expected only crlf line endings, got %d of %d lines:\n%q", crlf, lf, b)
+	}
+	if strings.Contains(string(b), "\r\r") {
+		t.Errorf("expected crlf license lines to be kept as they are, got:\n%q", b)
+	}
+	if err := run([]string{"mdtogo", source, dest, "--line-ending=crlf", "--license=" + license, "--verify"}); err != nil {
+		t.Errorf("expected --verify to accept the crlf file: %v", err)
+	}
+	out := runGenerated(t, dest, "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Printf(\"%q\", BuildLong) }\n")
+	if out != `"\nBuild a thing from a directory.\n"` {
+		t.Errorf("expected the docs to read the same with crlf, got %s", out)
+	}
+
+	if err := run([]string{"mdtogo", source, dest}); err != nil {
+		t.Fatal(err)
+	}
+	b, err = os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "\r") {
+		t.Errorf("expected lf line endings by default, got:\n%q", b)
+	}
+
+	if err := run([]string{"mdtogo", source, dest, "--line-ending=cr"}); err == nil {
+		t.Error("expected an error for an unknown line ending")
+	}
+}
+
+func TestRunDeriveShort(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": "## build\n\n### Synopsis\n\n" +
+			"Build a kustomization target, e.g. a directory,\ninto YAML.  Writes to stdout.\n\n" +
+			"    kustomize build\n",
+		"edit.md": "## edit\n\nEdit a kustomization file.\n\n### Synopsis\n\nFirst sentence.  Second.\n",
+	})
+	warnings := captureWarnings(t)
+	if err := run([]string{"mdtogo", source, dest, "--derive-short"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"var BuildShort=`Build a kustomization target, e.g. a directory, into YAML.`\n",
+		"var EditShort=`Edit a kustomization file.`\n",
+	} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("expected %s, got:\n%s", v, b)
+		}
+	}
+	expected := "warning: build.md:3: expected a Short description below the command heading, found heading \"### Synopsis\"\n" +
+		"build.md: derived Short \"Build a kustomization target, e.g. a directory, into YAML.\" from Long\n"
+	if warnings.String() != expected {
+		t.Errorf("expected log:\n%s\ngot:\n%s", expected, warnings)
+	}
+
+	if err := run([]string{"mdtogo", source, dest}); err != nil {
+		t.Fatal(err)
+	}
+	b, err = os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "BuildShort") {
+		t.Errorf("expected no Short without --derive-short, got:\n%s", b)
+	}
+}
+
+// loadProperties parses a .properties file the way java.util.Properties
+// loads one.
+func loadProperties(t *testing.T, s string) map[string]string {
+	t.Helper()
+	unescape := func(s string) string {
+		var units []uint16
+		var b strings.Builder
+		flush := func() {
+			b.WriteString(string(utf16.Decode(units)))
+			units = nil
+		}
+		for i := 0; i < len(s); i++ {
+			if s[i] != '\\' || i+1 == len(s) {
+				flush()
+				b.WriteByte(s[i])
+				continue
+			}
+			i++
+			switch s[i] {
+			case 'u':
+				u, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+				if err != nil {
+					t.Fatal(err)
+				}
+				units = append(units, uint16(u))
+				i += 4
+				continue
+			case 't':
+				flush()
+				b.WriteByte('\t')
+			case 'n':
+				flush()
+				b.WriteByte('\n')
+			case 'r':
+				flush()
+				b.WriteByte('\r')
+			case 'f':
+				flush()
+				b.WriteByte('\f')
+			default:
+				flush()
+				b.WriteByte(s[i])
+			}
+		}
+		flush()
+		return b.String()
+	}
+
+	properties := map[string]string{}
+	lines := strings.Split(s, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// an odd number of trailing backslashes continues the line
+		for strings.HasSuffix(line, `\`) && (len(line)-len(strings.TrimRight(line, `\`)))%2 == 1 && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		end := len(line)
+		for j := 0; j < len(line); j++ {
+			if line[j] == '\\' {
+				j++
+				continue
+			}
+			if strings.IndexByte("=: \t\f", line[j]) >= 0 {
+				end = j
+				break
+			}
+		}
+		value := strings.TrimLeft(line[end:], " \t\f")
+		if value != "" && (value[0] == '=' || value[0] == ':') {
+			value = strings.TrimLeft(value[1:], " \t\f")
+		}
+		properties[unescape(line[:end])] = unescape(value)
+	}
+	return properties
+}
+
+func TestRunPropertiesOut(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": buildDoc,
+		"edit.md": "## edit\n\n  Édit a `kustomization` file: key=value # 🚀\n\n" +
+			"### Synopsis\n\n```\n\tindented\\path\n  spaced\n```\n\n" +
+			"### Examples\n\n    kustomize edit\n",
+	})
+	out := filepath.Join(t.TempDir(), "docs.properties")
+	if err := run([]string{"mdtogo", source, dest, "--properties-out=" + out}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"build.short=Build a thing.\n",
+		"build.long=\\n\\\nBuild a thing from a directory.\\n\\\n\n",
+		"build.examples=\\n\\\n\\ \\ \\ \\ kustomize build\n",
+	} {
+		if !strings.Contains(string(b), line) {
+			t.Errorf("expected %q, got:\n%s", line, b)
+		}
+	}
+	for _, c := range string(b) {
+		if c > '~' {
+			t.Errorf("expected only ASCII, got %q in:\n%s", c, b)
+			break
+		}
+	}
+
+	got := loadProperties(t, string(b))
+	expected := map[string]string{
+		"build.short":    "Build a thing.",
+		"build.long":     "\nBuild a thing from a directory.\n",
+		"build.examples": "\n    kustomize build",
+		"edit.short":     "Édit a `kustomization` file: key=value # 🚀",
+		"edit.long":      "\n\t\tindented\\path\n\t  spaced\n",
+		"edit.examples":  "\n    kustomize edit",
+	}
+	if len(got) != len(expected) {
+		t.Errorf("expected %d properties, got %v", len(expected), got)
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got[k])
+		}
+	}
+}
+
+func TestRunPackageName(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{"build.md": buildDoc})
+	root := t.TempDir()
+	for _, tc := range []struct{ dir, message string }{
+		{"func", `package name "func", from the DEST_GO_DIR/ name, is a reserved Go keyword; choose another with --package=NAME`},
+		{"package", `package name "package", from the DEST_GO_DIR/ name, is a reserved Go keyword; choose another with --package=NAME`},
+		{"generated-docs", `package name "generated-docs", from the DEST_GO_DIR/ name, is not a valid Go identifier; choose another with --package=NAME`},
+		{"_", `package name "_", from the DEST_GO_DIR/ name, is the blank identifier; choose another with --package=NAME`},
+	} {
+		dest := filepath.Join(root, tc.dir)
+		err := run([]string{"mdtogo", source, dest})
+		if err == nil || err.Error() != tc.message {
+			t.Errorf("%s: expected error %q, got %v", tc.dir, tc.message, err)
+		}
+		if _, err := os.Stat(filepath.Join(dest, "docs.go")); err == nil {
+			t.Errorf("%s: expected no docs.go to be written", tc.dir)
+		}
+	}
+
+	dest := filepath.Join(root, "func")
+	if err := run([]string{"mdtogo", source, dest, "--package=funcdocs"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "\npackage funcdocs\n") {
+		t.Errorf("expected package funcdocs, got:\n%s", b)
+	}
+
+	err = run([]string{"mdtogo", source, dest, "--package=type"})
+	if err == nil || err.Error() != `--package "type" is a reserved Go keyword` {
+		t.Errorf("expected a keyword error for --package, got %v", err)
+	}
+}
+
+func TestRunReverse(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": buildDoc,
+		"run-fns.md": "## run-fns\n\nRun `functions`.\n\n### Synopsis\n\n" +
+			"Run the functions in a [directory](https://kustomize.io).\n\n" +
+			"````markdown\n```\nkustomize fn run\n```\n````\n" +
+			"### Examples\n\n```shell\nkustomize fn run dir/\n```\n\n    # indented\n",
+		"version.md": "## version\n\nPrint the version.\n",
+	})
+	captureWarnings(t)
+	for _, flags := range [][]string{nil, {"--const"}, {"--dual-long", "--emit-both-examples"}} {
+		if err := run(append([]string{"mdtogo", source, dest}, flags...)); err != nil {
+			t.Fatal(err)
+		}
+		forward, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		markdown := t.TempDir()
+		if err := run(append([]string{"mdtogo", dest, markdown, "--reverse"}, flags...)); err != nil {
+			t.Fatal(err)
+		}
+		roundTrip := filepath.Join(t.TempDir(), filepath.Base(dest))
+		if err := run(append([]string{"mdtogo", markdown, roundTrip}, flags...)); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(filepath.Join(roundTrip, "docs.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != string(forward) {
+			t.Errorf("%v: expected the reconstructed markdown to generate:\n%s\ngot:\n%s", flags, forward, b)
+		}
+
+		entries, err := os.ReadDir(markdown)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		if got := strings.Join(names, " "); got != "build.md run-fns.md version.md" {
+			t.Errorf("%v: unexpected markdown files %s", flags, got)
+		}
+	}
+
+	markdown := t.TempDir()
+	if err := run([]string{"mdtogo", filepath.Join(dest, "docs.go"), markdown, "--reverse"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(markdown, "build.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != buildDoc {
+		t.Errorf("expected build.md to be reconstructed as:\n%s\ngot:\n%s", buildDoc, b)
+	}
+}
+
+func TestRunReverseFormats(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		docs  map[string]string
+		flags []string
+		files []string
+	}{
+		{
+			name: "struct",
+			docs: map[string]string{
+				"build.md": buildDoc,
+				"edit/add.md": "---\ndeprecated: use set instead\naliases:\n  - a\n  - plus\n---\n" +
+					"## edit add\n\nAdd a thing.\n\n### Synopsis\n\nAdd a thing to the kustomization.\n",
+			},
+			flags: []string{"--format=struct", "--recursive"},
+			files: []string{"build.md", "edit/add.md"},
+		},
+		{
+			name: "group-by-parent",
+			docs: map[string]string{
+				"edit.md":     "## edit\n\nEdit a kustomization.\n",
+				"edit-add.md": "---\nparent: edit\n---\n## edit-add\n\nAdd a thing.\n",
+			},
+			flags: []string{"--group-by-parent"},
+			files: []string{"edit-add.md", "edit.md"},
+		},
+		{
+			name:  "typed",
+			docs:  map[string]string{"build.md": buildDoc, "version.md": "## version\n\nPrint the version.\n"},
+			flags: []string{"--typed"},
+			files: []string{"build.md", "version.md"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			source, dest := writeDocs(t, tc.docs)
+			captureWarnings(t)
+			if err := run(append([]string{"mdtogo", source, dest}, tc.flags...)); err != nil {
+				t.Fatal(err)
+			}
+			forward, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			markdown := t.TempDir()
+			if err := run([]string{"mdtogo", dest, markdown, "--reverse"}); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tc.files {
+				b, err := os.ReadFile(filepath.Join(markdown, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if expected := tc.docs[name]; string(b) != expected {
+					t.Errorf("expected %s to be reconstructed as:\n%s\ngot:\n%s", name, expected, b)
+				}
+			}
+
+			roundTrip := filepath.Join(t.TempDir(), filepath.Base(dest))
+			if err := run(append([]string{"mdtogo", markdown, roundTrip}, tc.flags...)); err != nil {
+				t.Fatal(err)
+			}
+			b, err := os.ReadFile(filepath.Join(roundTrip, "docs.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != string(forward) {
+				t.Errorf("expected the reconstructed markdown to generate:\n%s\ngot:\n%s", forward, b)
+			}
+		})
+	}
+}
+
+func TestFileStem(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Build":            "build",
+		"RunFns":           "run-fns",
+		"EditAddConfigmap": "edit-add-configmap",
+		"ServeHTTPProxy":   "serve-http-proxy",
+		"Cfg2Yaml":         "cfg2-yaml",
+	} {
+		if got := fileStem(name); got != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, got)
+		}
+	}
+}
+
+func TestRunExamplesAliases(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": "## build\n\nBuild a thing.\n\n### Usage Examples\n\n    kustomize build\n",
+		"edit.md":  "## edit\n\nEdit a thing.\n\n### Examples\n\n    kustomize edit\n",
+		"fmt.md":   "## fmt\n\nFormat a thing.\n\n### sample\n\n    kustomize fmt\n",
+	})
+	read := func() string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	if err := run([]string{"mdtogo", source, dest, "--examples-aliases=Examples,Usage Examples,Sample"}); err != nil {
+		t.Fatal(err)
+	}
+	b := read()
+	for _, v := range []string{
+		"var BuildExamples=`\n    kustomize build`\n",
+		"var EditExamples=`\n    kustomize edit`\n",
+		"var FmtExamples=`\n    kustomize fmt`\n",
+	} {
+		if !strings.Contains(b, v) {
+			t.Errorf("expected %s, got:\n%s", v, b)
+		}
+	}
+
+	if err := run([]string{"mdtogo", source, dest}); err != nil {
+		t.Fatal(err)
+	}
+	b = read()
+	if !strings.Contains(b, "EditExamples") || strings.Contains(b, "BuildExamples") || strings.Contains(b, "FmtExamples") {
+		t.Errorf("expected only ### Examples by default, got:\n%s", b)
+	}
+
+	if err := run([]string{"mdtogo", source, dest, "--examples-aliases=,"}); err == nil {
+		t.Error("expected an error for empty --examples-aliases")
+	}
+}
+
+func TestRunGzip(t *testing.T) {
+	long := strings.Repeat("Build a thing from a directory of `kustomization` files.\n", 50)
+	source, _ := writeDocs(t, map[string]string{
+		"build.md": "## build\n\nBuild a thing.\n\n### Synopsis\n\n" + long + "\n### Examples\n\n    kustomize build\n",
+		"edit.md":  "## edit\n\nEdit a thing.\n",
+	})
+	dest := filepath.Join(t.TempDir(), "main")
+	if err := run([]string{"mdtogo", source, dest, "--gzip", "--registry=register"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "docs.go", b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored []byte
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || spec.Names[0].Name != "buildLongGzip" {
+			return true
+		}
+		lit := spec.Values[0].(*ast.UnaryExpr).X.(*ast.CompositeLit).Elts[0].(*ast.KeyValueExpr).Value.(*ast.CallExpr).Args[0].(*ast.BasicLit)
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored = []byte(s)
+		return false
+	})
+	if !bytes.HasPrefix(stored, []byte{0x1f, 0x8b}) {
+		t.Fatalf("expected gzip data for BuildLong, got %q", stored)
+	}
+	if len(stored) >= len(long)/4 {
+		t.Errorf("expected BuildLong to be compressed, got %d bytes for %d", len(stored), len(long))
+	}
+	if strings.Contains(string(b), "kustomization") {
+		t.Errorf("expected no uncompressed text, got:\n%s", b)
+	}
+
+	out := runGenerated(t, dest, `package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+func register(command, short, long, examples string) {
+	fmt.Printf("%s %q %d %q\n", command, short, len(long), examples)
+}
+
+func main() {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() { defer wg.Done(); _ = BuildLong() }()
+	}
+	wg.Wait()
+	fmt.Printf("%q\n", BuildShort())
+	fmt.Print(BuildLong())
+}
+`)
+	expected := "build \"Build a thing.\" " + strconv.Itoa(len(long)+1) + " \"\\n    kustomize build\"\n" +
+		"edit \"Edit a thing.\" 0 \"\"\n" +
+		"\"Build a thing.\"\n\n" + long
+	if out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	if err := run([]string{"mdtogo", source, dest, "--gzip", "--typed"}); err == nil {
+		t.Error("expected --gzip and --typed to be rejected")
+	}
+}
+
+func TestRunCommandsFrom(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md":    buildDoc,
+		"edit.md":     "## edit\n\nEdit a thing.\n\n### Synopsis\n\nEdit.\n\n### Examples\n\n    kustomize edit\n",
+		"cfg-tree.md": "## cfg tree\n\nShow a tree.\n\n### Synopsis\n\nShow.\n\n### Examples\n\n    kustomize cfg tree\n",
+	})
+	list := filepath.Join(t.TempDir(), "commands.txt")
+	if err := os.WriteFile(list, []byte("# commands\nbuild\n\n  edit  \ncreate\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	warnings := captureWarnings(t)
+	if err := run([]string{"mdtogo", source, dest, "--commands-from=" + list}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "warning: cfg-tree.md: docs for unknown command \"cfg-tree\"\n" +
+		"warning: command \"create\" has no docs\n"
+	if warnings.String() != expected {
+		t.Errorf("expected warnings:\n%s\ngot:\n%s", expected, warnings)
+	}
+
+	err := run([]string{"mdtogo", source, dest, "--commands-from=" + list, "--strict"})
+	if err == nil || !strings.Contains(err.Error(), "2 command(s) out of sync") {
+		t.Errorf("expected --strict to fail, got %v", err)
+	}
+
+	if err := os.WriteFile(list, []byte("build\nedit\ncfg-tree\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	warnings.Reset()
+	if err := run([]string{"mdtogo", source, dest, "--commands-from=" + list, "--strict"}); err != nil {
+		t.Fatal(err)
+	}
+	if warnings.Len() > 0 {
+		t.Errorf("expected no warnings, got:\n%s", warnings)
+	}
+}
+
+func TestParseRenderers(t *testing.T) {
+	parseWith := func(r, value string) doc {
+		t.Helper()
+		d, err := testOptions(t, "--renderer="+r).parse("build.md", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	captureWarnings(t)
+
+	// documents both renderers read the same way
+	for _, value := range []string{
+		buildDoc,
+		"## build   \n\nBuild a thing.\n\n### Synopsis  \n\nBuild.\n\n### Flags\n\n    --foo\n",
+		"## build\n\nBuild a thing.\n\n### Synopsis\n\n````markdown\n```go\ns := `x`\n```\n````\n\n" +
+			"### Examples\n\n```shell\n# build\nkustomize build\n```\n",
+		"---\nshort: Build.\n---\n## build\n\n### Synopsis\n\nText with a #hash.\n",
+	} {
+		legacy, commonmark := parseWith(legacyRenderer, value), parseWith(commonmarkRenderer, value)
+		if legacy.Short != commonmark.Short || legacy.Long != commonmark.Long ||
+			legacy.Examples != commonmark.Examples || legacy.LongMarkdown != commonmark.LongMarkdown ||
+			legacy.Heading != commonmark.Heading ||
+			strings.Join(legacy.Sections, ",") != strings.Join(commonmark.Sections, ",") {
+			t.Errorf("expected the renderers to agree on:\n%s\nlegacy: %+v\ncommonmark: %+v", value, legacy, commonmark)
+		}
+	}
+
+	// documents commonmark reads more correctly
+	for _, tc := range []struct {
+		name, value        string
+		legacy, commonmark doc
+	}{
+		{
+			name:       "tilde fences hide headings",
+			value:      "## build\n\nBuild.\n\n### Synopsis\n\n~~~\n### Examples\n~~~\n",
+			legacy:     doc{Heading: "build", Short: "Build.", Long: "\n~~~", Examples: "~~~"},
+			commonmark: doc{Heading: "build", Short: "Build.", Long: "\n\t### Examples"},
+		},
+		{
+			name:       "setext headings",
+			value:      "build\n-----\n\nBuild.\n\nSynopsis\n--------\n",
+			legacy:     doc{},
+			commonmark: doc{Heading: "build", Short: "Build."},
+		},
+		{
+			name:       "html blocks hide headings",
+			value:      "## build\n\nBuild.\n\n### Synopsis\n\n<details>\n### Examples\n</details>\n",
+			legacy:     doc{Heading: "build", Short: "Build.", Long: "\n<details>", Examples: "</details>"},
+			commonmark: doc{Heading: "build", Short: "Build.", Long: "\n<details>\n### Examples\n</details>"},
+		},
+		{
+			name:       "indented fences",
+			value:      "## build\n\nBuild.\n\n### Synopsis\n\n  ```\n  ## not a heading\n  ```\n",
+			legacy:     doc{Heading: "build", Short: "Build.", Long: "\n  ```\n  ## not a heading\n  ```"},
+			commonmark: doc{Heading: "build", Short: "Build.", Long: "\n\t  ## not a heading"},
+		},
+	} {
+		for _, r := range []struct {
+			renderer string
+			expected doc
+		}{{legacyRenderer, tc.legacy}, {commonmarkRenderer, tc.commonmark}} {
+			d := parseWith(r.renderer, tc.value)
+			if d.Heading != r.expected.Heading || d.Short != r.expected.Short ||
+				d.Long != r.expected.Long || d.Examples != r.expected.Examples {
+				t.Errorf("%s: %s: expected %q %q %q %q, got %q %q %q %q", tc.name, r.renderer,
+					r.expected.Heading, r.expected.Short, r.expected.Long, r.expected.Examples,
+					d.Heading, d.Short, d.Long, d.Examples)
+			}
+		}
+	}
+
+	source, dest := writeDocs(t, map[string]string{"build.md": buildDoc})
+	if err := run([]string{"mdtogo", source, dest, "--renderer=markdown-it"}); err == nil {
+		t.Error("expected an error for an unknown renderer")
+	}
+}
+
+func TestRunRecursive(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md":               buildDoc,
+		"edit.md":                "## edit\n\nEdit a kustomization file.\n",
+		"edit/add/configmap.md":  "## edit add configmap\n\nAdd a ConfigMap.\n",
+		"edit/remove/label.md":   "## edit remove label\n\nRemove a label.\n",
+		"edit/set.md":            "## edit set\n\nSet a value.\n",
+		".git/notes.md":          "## notes\n\nNot a command.\n",
+		"edit/remove/README.txt": "not markdown",
+	})
+	warnings := captureWarnings(t)
+	if err := run([]string{"mdtogo", source, dest, "--recursive", "--warn-name-mismatch"}); err != nil {
+		t.Fatal(err)
+	}
+	if warnings.Len() > 0 {
+		t.Errorf("expected no warnings, got:\n%s", warnings)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "docs.go", b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shorts []string
+	for _, decl := range f.Decls {
+		for _, spec := range decl.(*ast.GenDecl).Specs {
+			if name := spec.(*ast.ValueSpec).Names[0].Name; strings.HasSuffix(name, "Short") {
+				shorts = append(shorts, name)
+			}
+		}
+	}
+	expected := "BuildShort EditShort EditAddConfigmapShort EditRemoveLabelShort EditSetShort"
+	if got := strings.Join(shorts, " "); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	if err := run([]string{"mdtogo", source, dest}); err != nil {
+		t.Fatal(err)
+	}
+	b, err = os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "EditSet") {
+		t.Errorf("expected sub-directories to be skipped without --recursive, got:\n%s", b)
+	}
+
+	source, dest = writeDocs(t, map[string]string{
+		"edit-set.md": "## edit-set\n\nSet.\n",
+		"edit/set.md": "## edit set\n\nSet.\n",
+	})
+	err = run([]string{"mdtogo", source, dest, "--recursive"})
+	if err == nil || err.Error() != "edit/set.md and edit-set.md both generate EditSet variables" {
+		t.Errorf("expected a name collision error, got %v", err)
+	}
+}
+
+func TestRunFormatStruct(t *testing.T) {
+	source, _ := writeDocs(t, map[string]string{
+		"build.md": buildDoc,
+		"edit.md": "---\naliases: [e, ed]\ndeprecated: use `kustomize cfg` instead\n---\n" +
+			"## edit\n\nEdit a kustomization file.\n",
+		"edit/set.md": "## edit set\n\nSet a value.\n",
+	})
+	dest := filepath.Join(t.TempDir(), "main")
+	if err := run([]string{"mdtogo", source, dest, "--format=struct", "--recursive", "--registry=register"}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "docs.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatted, err := format.Source(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, formatted) {
+		t.Errorf("expected gofmt'd output, got:\n%s", b)
+	}
+	if strings.Contains(string(b), "BuildShort") {
+		t.Errorf("expected no loose variables, got:\n%s", b)
+	}
+
+	out := runGenerated(t, dest, `package main
+
+import "fmt"
+
+func register(command, short, long, example string) {
+	fmt.Printf("register %s %q\n", command, short)
+}
+
+func main() {
+	for _, c := range []string{"build", "edit", "edit set", "missing"} {
+		d, ok := CmdDocs[c]
+		fmt.Printf("%s %v %q %q %q %q %q\n", c, ok, d.Short, d.Long, d.Example, d.Deprecated, d.Aliases)
+	}
+}
+`)
+	expected := `register build "Build a thing."
+register edit "Edit a kustomization file."
+register edit set "Set a value."
+build true "Build a thing." "\nBuild a thing from a directory.\n" "\n    kustomize build" "" []
+edit true "Edit a kustomization file." "" "" "use ` + "`kustomize cfg`" + ` instead" ["e" "ed"]
+edit set true "Set a value." "" "" "" []
+missing false "" "" "" "" []
+`
+	if out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	if err := run([]string{"mdtogo", source, dest, "--format=struct", "--split"}); err == nil {
+		t.Error("expected --format=struct and --split to be rejected")
+	}
+	if err := run([]string{"mdtogo", source, dest, "--format=yaml"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestRunFlags(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{"build.md": buildDoc})
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(dest, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	// flags may be interspersed with the directories, and take separate values
+	if err := run([]string{"mdtogo", "--full=true", source, "--license", "none", dest, "--out-file=help.go"}); err != nil {
+		t.Fatal(err)
+	}
+	b := read("help.go")
+	if !strings.HasPrefix(b, "\n\n// Code generated by \"mdtogo\"") || !strings.Contains(b, "var BuildLong=`\n### Synopsis") {
+		t.Errorf("expected the flags to apply, got:\n%s", b)
+	}
+
+	if err := run([]string{"mdtogo", source, dest, "--package=helpdocs", "--full"}); err != nil {
+		t.Fatal(err)
+	}
+	if b := read("docs.go"); !strings.Contains(b, "\npackage helpdocs\n") || !strings.Contains(b, "var BuildLong=`\n### Synopsis") {
+		t.Errorf("expected package helpdocs, got:\n%s", b)
+	}
+
+	warnings := captureWarnings(t)
+	if err := run([]string{"mdtogo", "--help"}); err != nil {
+		t.Errorf("expected --help to succeed, got %v", err)
+	}
+	for _, s := range []string{"Usage: mdtogo SOURCE_MD_DIR/ DEST_GO_DIR/ [flags]", "  --out-file\n", `(default "lf")`} {
+		if !strings.Contains(warnings.String(), s) {
+			t.Errorf("expected the usage to contain %q, got:\n%s", s, warnings)
+		}
+	}
+
+	for _, tc := range []struct {
+		args    []string
+		message string
+	}{
+		{[]string{source}, "Usage: mdtogo SOURCE_MD_DIR/ DEST_GO_DIR/"},
+		{[]string{source, dest, "extra"}, "Usage: mdtogo SOURCE_MD_DIR/ DEST_GO_DIR/"},
+		{[]string{source, dest, "--no-such-flag"}, "flag provided but not defined: -no-such-flag"},
+		{[]string{source, dest, "--out-file=docs.txt"}, `--out-file "docs.txt" must be a .go file name, without a directory`},
+		{[]string{source, dest, "--out-file=help.go", "--split"}, "--out-file cannot be combined with --gen-suffix or --split"},
+	} {
+		err := run(append([]string{"mdtogo"}, tc.args...))
+		if err == nil || err.Error() != tc.message {
+			t.Errorf("%v: expected error %q, got %v", tc.args, tc.message, err)
+		}
+	}
+}
+
+func TestRunStrictStructure(t *testing.T) {
+	source, dest := writeDocs(t, map[string]string{
+		"build.md": buildDoc,
+		"apply.md": "Apply resources.\n",
+		"edit.md": "---\nweight: 1\n---\n## edit\n\n### Synopsis\n\nEdit it.\n\n" +
+			"### Creating a setter\n\n```shell\nkustomize edit set\n",
+		"fmt.md": "## fmt\n\nFormat.\n\n### Synopsis\n\n````\n```\n````\n\n### Examples\n\n    kustomize fmt\n",
+	})
+	captureWarnings(t)
+	if err := run([]string{"mdtogo", source, dest}); err != nil {
+		t.Fatalf("expected malformed docs to be accepted without --strict, got %v", err)
+	}
+
+	err := run([]string{"mdtogo", source, dest, "--strict"})
+	expected := `apply.md:1: missing the "## apply" command heading
+edit.md:4: empty Short below the command heading
+edit.md:4: missing the "### Examples" section
+edit.md:10: unknown heading "### Creating a setter"
+edit.md:12: code fence "` + "```shell" + `" is never closed`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%v", expected, err)
+	}
+
+	for _, f := range []string{"apply.md", "edit.md"} {
+		if err := os.Remove(filepath.Join(source, f)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := run([]string{"mdtogo", source, dest, "--strict"}); err != nil {
+		t.Errorf("expected well formed docs to pass --strict, got %v", err)
+	}
+	if err := run([]string{"mdtogo", source, dest, "--strict", "--renderer=commonmark"}); err != nil {
+		t.Errorf("expected well formed docs to pass --strict with commonmark, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeManPages writes a man page for each of the docs into dir.
+func writeManPages(dir string, docs []doc) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	for i := range docs {
+		path := filepath.Join(dir, manPageName(docs[i])+".1")
+		if err := os.WriteFile(path, []byte(docs[i].manPage()), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// manPageName returns the name of the man page for d, e.g. "edit-set-image".
+func manPageName(d doc) string {
+	return strings.ReplaceAll(d.Command, " ", "-")
+}
+
+// manPage returns a troff man page for d, with the NAME taken from
+// Short, the DESCRIPTION from Long and the EXAMPLES from Examples.
+func (d doc) manPage() string {
+	name := manPageName(d)
+	var b strings.Builder
+	b.WriteString(".TH \"" + strings.ToUpper(name) + "\" \"1\"\n")
+	b.WriteString(".SH NAME\n")
+	b.WriteString(manEscape(name))
+	if d.Short != "" {
+		b.WriteString(" \\- " + manEscape(d.Short))
+	}
+	b.WriteString("\n")
+	if strings.TrimSpace(d.Long) != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		writeManText(&b, d.Long)
+	}
+	if strings.TrimSpace(d.Examples) != "" {
+		b.WriteString(".SH EXAMPLES\n.nf\n")
+		for _, line := range strings.Split(strings.Trim(d.Examples, "\n"), "\n") {
+			b.WriteString(manLine(line) + "\n")
+		}
+		b.WriteString(".fi\n")
+	}
+	return b.String()
+}
+
+// writeManText writes markdown prose as man page paragraphs, keeping
+// indented blocks such as code verbatim.
+func writeManText(b *strings.Builder, text string) {
+	var verbatim, paragraph bool
+	for _, line := range strings.Split(strings.Trim(text, "\n"), "\n") {
+		indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		switch {
+		case strings.TrimSpace(line) == "":
+			if verbatim {
+				b.WriteString("\n")
+				continue
+			}
+			paragraph = false
+			continue
+		case indented && !verbatim:
+			b.WriteString(".PP\n.nf\n")
+			verbatim = true
+		case !indented && verbatim:
+			b.WriteString(".fi\n")
+			verbatim = false
+			paragraph = false
+		}
+		if !verbatim && !paragraph {
+			b.WriteString(".PP\n")
+			paragraph = true
+		}
+		b.WriteString(manLine(line) + "\n")
+	}
+	if verbatim {
+		b.WriteString(".fi\n")
+	}
+}
+
+// manLine escapes a line of text so that troff doesn't interpret it
+// as a request.
+func manLine(line string) string {
+	line = manEscape(line)
+	if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+		line = `\&` + line
+	}
+	return line
+}
+
+func manEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	return strings.ReplaceAll(s, "-", `\-`)
+}
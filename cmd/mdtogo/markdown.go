@@ -0,0 +1,37 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	linkPattern       = regexp.MustCompile(`!?\[([^\]]*)\](?:\([^)]*\)|\[[^\]]*\])`)
+	strongPattern     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	emphasisPattern   = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	underscorePattern = regexp.MustCompile(`(^|[^\w])_([^_\s][^_]*)_($|[^\w])`)
+)
+
+// stripMarkdown removes links and emphasis from a line of markdown
+// prose so that it reads naturally in a terminal, e.g.
+// "see [the docs](https://example.com) for **more**" becomes
+// "see the docs for more".  Indented code and inline code spans are
+// left untouched.
+func stripMarkdown(line string) string {
+	if strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+		return line
+	}
+	// odd numbered parts are inline code spans
+	parts := strings.Split(line, "`")
+	for i := 0; i < len(parts); i += 2 {
+		p := linkPattern.ReplaceAllString(parts[i], "$1")
+		p = strongPattern.ReplaceAllString(p, "$1$2")
+		p = emphasisPattern.ReplaceAllString(p, "$1")
+		p = underscorePattern.ReplaceAllString(p, "$1$2$3")
+		parts[i] = p
+	}
+	return strings.Join(parts, "`")
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+// docsProperties returns the docs in the .properties format read by
+// java.util.Properties, with a key per section, e.g. build.short.
+// Multi-line values are continued over several lines.
+func (o *options) docsProperties(docs []doc) string {
+	var b strings.Builder
+	b.WriteString("# Code generated by \"mdtogo\"; DO NOT EDIT.\n")
+	for i := range docs {
+		for _, v := range o.variables(docs[i]) {
+			key := docs[i].Command + "." + jsonField(docs[i], v)
+			b.WriteString(propertiesEscape(key, true) + "=")
+			lines := strings.Split(v.value, "\n")
+			for j, line := range lines {
+				if j > 0 {
+					b.WriteString("\\n\\\n")
+				}
+				b.WriteString(propertiesEscape(line, false))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// propertiesEscape escapes s for a .properties file.  Leading spaces
+// are escaped since they would otherwise be dropped, and keys have
+// their separators and spaces escaped too.
+func propertiesEscape(s string, isKey bool) string {
+	var b strings.Builder
+	leading := true
+	for _, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && (leading || isKey):
+			b.WriteString(`\ `)
+		case isKey && strings.ContainsRune("=:#!", r):
+			b.WriteString(`\` + string(r))
+		case r < ' ' || r > '~':
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&b, `\u%04x`, u)
+			}
+		default:
+			b.WriteRune(r)
+		}
+		if r != ' ' {
+			leading = false
+		}
+	}
+	return b.String()
+}
+
+func (o *options) writeProperties(path string, docs []doc) error {
+	return os.WriteFile(path, []byte(o.docsProperties(docs)), 0600)
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const defaultRegistryTemplate = `{{.Registry}}({{printf "%q" .Command}}, {{.Short}}, {{.Long}}, {{.Examples}})`
+
+// registration is the data available to the --registry-template.
+// Short, Long and Examples are the expressions referring to the
+// command's docs, e.g. BuildShort, BuildDoc.Short with --typed or
+// BuildShort() with --gzip, or CmdDocs["build"].Short with --format=struct.
+type registration struct {
+	Registry string
+	Command  string
+	Name     string
+	Short    string
+	Long     string
+	Examples string
+}
+
+// registryInit returns a func init() that registers each of the docs
+// with the --registry function.
+func (o *options) registryInit(docs []doc) (string, error) {
+	tmpl, err := template.New("registry").Parse(o.registryTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid --registry-template: %w", err)
+	}
+	var b strings.Builder
+	b.WriteString("func init() {\n")
+	for i := range docs {
+		r := registration{
+			Registry: o.registry,
+			Command:  docs[i].Command,
+			Name:     docs[i].Name,
+			Short:    docs[i].Name + "Short",
+			Long:     docs[i].Name + "Long",
+			Examples: docs[i].Name + "Examples",
+		}
+		if o.typed {
+			r.Short = docs[i].Name + "Doc.Short"
+			r.Long = docs[i].Name + "Doc.Long"
+			r.Examples = docs[i].Name + "Doc.Examples"
+		}
+		if o.outputFormat == structFormat {
+			r.Short = cmdDocRef(docs[i], "Short")
+			r.Long = cmdDocRef(docs[i], "Long")
+			r.Examples = cmdDocRef(docs[i], "Example")
+		}
+		if o.gzipped {
+			r.Short += "()"
+			r.Long += "()"
+			r.Examples += "()"
+		}
+		b.WriteString("\t")
+		err := tmpl.Execute(&b, r)
+		if err != nil {
+			return "", fmt.Errorf("executing --registry-template for %s: %w", docs[i].File, err)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
@@ -0,0 +1,315 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// reverseSuffixes lists the suffixes of the generated variables read by
+// --reverse, longest first so that e.g. LongMarkdown isn't taken as Long.
+var reverseSuffixes = []string{"LongMarkdown", "ExamplesRaw", "Examples", "Short", "Long"}
+
+// reversedDoc is the documentation of a command read by --reverse.
+type reversedDoc struct {
+	// command is the command name, e.g. "edit add".
+	command string
+	// sections holds the sections by their variable name suffix, e.g. Long.
+	sections map[string]string
+	// frontMatter holds the fields that aren't part of the markdown body.
+	frontMatter frontMatter
+}
+
+// reverseDocs reads the docs declared by the Go files in the goDir/
+// package, or in a single Go file, and writes a markdown file per command
+// into mdDir/.  The docs may be variables per section, CommandDoc
+// variables (--typed) or a CmdDocs map (--format=struct), and a Children
+// map (--group-by-parent) sets the parent of each command.
+func reverseDocs(goPath, mdDir string) error {
+	paths := []string{goPath}
+	if info, err := os.Stat(goPath); err != nil {
+		return err
+	} else if info.IsDir() {
+		if paths, err = filepath.Glob(filepath.Join(goPath, "*.go")); err != nil {
+			return err
+		}
+	}
+
+	var docs []*reversedDoc
+	byName := map[string]*reversedDoc{}
+	docOf := func(name, command string) *reversedDoc {
+		if byName[name] == nil {
+			byName[name] = &reversedDoc{command: command, sections: map[string]string{}}
+			docs = append(docs, byName[name])
+		}
+		return byName[name]
+	}
+	children := map[string][]string{}
+
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || (gen.Tok != token.VAR && gen.Tok != token.CONST) {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if len(vs.Names) != 1 || len(vs.Values) != 1 {
+					continue
+				}
+				v, value := vs.Names[0].Name, vs.Values[0]
+				switch lit, _ := value.(*ast.CompositeLit); {
+				case v == "CmdDocs" && lit != nil:
+					for _, e := range keyValues(lit) {
+						command, ok := stringValue(e.Key)
+						fields, isLit := e.Value.(*ast.CompositeLit)
+						if ok && isLit {
+							readFields(docOf(command, command), fields)
+						}
+					}
+				case v == "Children" && lit != nil:
+					for _, e := range keyValues(lit) {
+						parent, ok := stringValue(e.Key)
+						list, isLit := e.Value.(*ast.CompositeLit)
+						if !ok || !isLit {
+							continue
+						}
+						for _, c := range list.Elts {
+							if child, ok := stringValue(c); ok {
+								children[parent] = append(children[parent], child)
+							}
+						}
+					}
+				case strings.HasSuffix(v, "Doc") && lit != nil:
+					name := strings.TrimSuffix(v, "Doc")
+					readFields(docOf(name, fileStem(name)), lit)
+				default:
+					name, suffix := splitVariableName(v)
+					if name == "" {
+						continue
+					}
+					if s, ok := stringValue(value); ok {
+						docOf(name, fileStem(name)).sections[suffix] = s
+					}
+				}
+			}
+		}
+	}
+	if len(docs) == 0 {
+		return fmt.Errorf("no docs variables found in %s", goPath)
+	}
+
+	for parent, commands := range children {
+		for _, c := range commands {
+			for _, d := range docs {
+				if d.command == c {
+					d.frontMatter.Parent = parent
+				}
+			}
+		}
+	}
+
+	for _, d := range docs {
+		md, err := reverseMarkdown(d)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(mdDir, filepath.FromSlash(strings.ReplaceAll(d.command, " ", "/"))+".md")
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(md), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyValues returns the keyed elements of a composite literal.
+func keyValues(lit *ast.CompositeLit) []*ast.KeyValueExpr {
+	var elts []*ast.KeyValueExpr
+	for _, e := range lit.Elts {
+		if kv, ok := e.(*ast.KeyValueExpr); ok {
+			elts = append(elts, kv)
+		}
+	}
+	return elts
+}
+
+// readFields reads the fields of a CommandDoc or CmdDoc literal into d.
+func readFields(d *reversedDoc, lit *ast.CompositeLit) {
+	for _, e := range keyValues(lit) {
+		field, ok := e.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if field.Name == "Aliases" {
+			if list, ok := e.Value.(*ast.CompositeLit); ok {
+				for _, a := range list.Elts {
+					if alias, ok := stringValue(a); ok {
+						d.frontMatter.Aliases = append(d.frontMatter.Aliases, alias)
+					}
+				}
+			}
+			continue
+		}
+		s, ok := stringValue(e.Value)
+		if !ok {
+			continue
+		}
+		switch field.Name {
+		case "Short", "Long", "Examples":
+			d.sections[field.Name] = s
+		case "Example":
+			d.sections["Examples"] = s
+		case "Deprecated":
+			d.frontMatter.Deprecated = s
+		}
+	}
+}
+
+// splitVariableName splits a docs variable name into the command's name
+// and the section suffix, e.g. RunFnsLong into RunFns and Long.  It
+// returns "" for other variables.
+func splitVariableName(v string) (string, string) {
+	for _, suffix := range reverseSuffixes {
+		if name := strings.TrimSuffix(v, suffix); name != v && name != "" {
+			return name, suffix
+		}
+	}
+	return "", ""
+}
+
+// stringValue returns the value of a string literal, or of a
+// concatenation of string literals like the ones goString produces.
+func stringValue(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringValue(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringValue(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return stringValue(e.X)
+	}
+	return "", false
+}
+
+// fileStem returns the markdown file name, without extension, that
+// deriveName turns into name, e.g. RunFns for run-fns.md.
+func fileStem(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				b.WriteRune('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// reverseMarkdown returns the markdown document that parses into the
+// docs of d.  Sections keep their blank lines as they are, so a section
+// may directly follow the one before it.  The unprocessed LongMarkdown
+// and ExamplesRaw are used when available; otherwise code blocks are
+// recovered from the tab indented lines of Long and Examples.
+func reverseMarkdown(d *reversedDoc) (string, error) {
+	var b strings.Builder
+	fm := d.frontMatter
+	if fm.Parent != "" || fm.Deprecated != "" || len(fm.Aliases) > 0 {
+		var y bytes.Buffer
+		e := yaml.NewEncoder(&y)
+		e.SetIndent(2)
+		if err := e.Encode(fm); err != nil {
+			return "", fmt.Errorf("%s: %w", d.command, err)
+		}
+		b.WriteString(frontMatterDelimiter + "\n" + y.String() + frontMatterDelimiter + "\n")
+	}
+	b.WriteString("## " + d.command + "\n")
+	if short := d.sections["Short"]; short != "" {
+		b.WriteString("\n" + short + "\n")
+	}
+	long, ok := d.sections["LongMarkdown"]
+	if !ok {
+		long = unindentCode(d.sections["Long"])
+	}
+	if long != "" {
+		b.WriteString("\n### Synopsis\n" + long + "\n")
+	}
+	examples, ok := d.sections["ExamplesRaw"]
+	if !ok {
+		examples = unindentCode(d.sections["Examples"])
+	}
+	if examples != "" {
+		if long == "" {
+			b.WriteString("\n")
+		}
+		b.WriteString("### Examples\n" + examples + "\n")
+	}
+	return b.String(), nil
+}
+
+// unindentCode turns each run of tab indented lines, which were read
+// from a code block, back into a fenced code block.
+func unindentCode(s string) string {
+	lines := strings.Split(s, "\n")
+	var out []string
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "\t") {
+			out = append(out, lines[i])
+			continue
+		}
+		var code []string
+		for ; i < len(lines) && strings.HasPrefix(lines[i], "\t"); i++ {
+			code = append(code, strings.TrimPrefix(lines[i], "\t"))
+		}
+		i--
+		// the fence must be longer than any fence in the code
+		fence := "```"
+		for _, line := range code {
+			if marker := fenceMarker(line); len(marker) >= len(fence) {
+				fence = marker + "`"
+			}
+		}
+		out = append(out, fence)
+		out = append(out, code...)
+		out = append(out, fence)
+	}
+	return strings.Join(out, "\n")
+}
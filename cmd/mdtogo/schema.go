@@ -0,0 +1,67 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schema holds documentation rules that every markdown file is
+// validated against.
+type schema struct {
+	// RequiredSections are "### " headings each file must contain.
+	RequiredSections []string `yaml:"requiredSections,omitempty"`
+	// MaxShortLength limits the length of Short, if non-zero.
+	MaxShortLength int `yaml:"maxShortLength,omitempty"`
+	// RequiredFrontMatter are front matter fields each file must set.
+	RequiredFrontMatter []string `yaml:"requiredFrontMatter,omitempty"`
+}
+
+func loadSchema(path string) (*schema, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &schema{}
+	if err := yaml.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// validate returns a description of every rule d violates.
+func (s *schema) validate(d doc) []string {
+	var violations []string
+	for _, want := range s.RequiredSections {
+		if !hasSection(d, want) {
+			violations = append(violations,
+				fmt.Sprintf("%s: missing required section %q", d.File, want))
+		}
+	}
+	if s.MaxShortLength > 0 && len(d.Short) > s.MaxShortLength {
+		violations = append(violations,
+			fmt.Sprintf("%s: Short is %d characters long, exceeding the maximum of %d",
+				d.File, len(d.Short), s.MaxShortLength))
+	}
+	for _, field := range s.RequiredFrontMatter {
+		if !d.FrontMatter.has(field) {
+			violations = append(violations,
+				fmt.Sprintf("%s: missing required front matter field %q", d.File, field))
+		}
+	}
+	return violations
+}
+
+func hasSection(d doc, name string) bool {
+	for _, s := range d.Sections {
+		if strings.EqualFold(s, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const defaultSpellcheckWords = "/usr/share/dict/words"
+
+// linkTargetPattern matches the target of a markdown link, e.g. "(https://...)".
+var linkTargetPattern = regexp.MustCompile(`\]\([^)]*\)`)
+
+// dictionary is a set of known words.
+type dictionary map[string]bool
+
+// loadDictionary reads words, one per line, from each of the files
+// into a single dictionary.
+func loadDictionary(paths ...string) (dictionary, error) {
+	dict := dictionary{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if word := strings.TrimSpace(scanner.Text()); word != "" && !strings.HasPrefix(word, "#") {
+				dict[word] = true
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
+
+// knows reports whether word, or its lower case or singular form, is
+// in the dictionary.
+func (dict dictionary) knows(word string) bool {
+	word = strings.TrimSuffix(word, "'s")
+	for _, w := range []string{word, strings.ToLower(word)} {
+		if dict[w] || dict[strings.TrimSuffix(w, "s")] {
+			return true
+		}
+	}
+	return false
+}
+
+// spellcheck warns about each word of d's prose that isn't in the
+// dictionary.  Code blocks, inline code and link targets are skipped.
+func spellcheck(d doc, dict dictionary) {
+	seen := map[string]bool{}
+	for _, section := range []struct{ name, text string }{
+		{"Short", d.Short},
+		{"Long", d.Long},
+		{"Examples", d.Examples},
+	} {
+		for _, word := range proseWords(section.text) {
+			if seen[word] || dict.knows(word) {
+				continue
+			}
+			seen[word] = true
+			warnf("%s: %s: unrecognized word %q", d.File, section.name, word)
+		}
+	}
+}
+
+// proseWords returns the words of the markdown prose in text.
+func proseWords(text string) []string {
+	var words []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ") {
+			continue
+		}
+		// odd numbered parts are inline code spans
+		parts := strings.Split(line, "`")
+		for i := 0; i < len(parts); i += 2 {
+			p := linkTargetPattern.ReplaceAllString(parts[i], "]")
+			for _, field := range strings.Fields(p) {
+				if strings.Contains(field, "://") {
+					continue
+				}
+				for _, word := range strings.FieldsFunc(field, func(r rune) bool {
+					return !unicode.IsLetter(r) && r != '\''
+				}) {
+					word = strings.Trim(word, "'")
+					if len([]rune(word)) > 1 {
+						words = append(words, word)
+					}
+				}
+			}
+		}
+	}
+	return words
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	varsFormat   = "vars"
+	structFormat = "struct"
+)
+
+// cmdDocsTemplate generates the CmdDoc type and the CmdDocs map of
+// --format=struct.  Empty fields are left out.
+var cmdDocsTemplate = template.Must(template.New("cmddocs").Funcs(template.FuncMap{
+	"goString": goString,
+	"quote":    strconv.Quote,
+}).Parse(`// CmdDoc holds the documentation of a single command.
+type CmdDoc struct {
+	Short      string
+	Long       string
+	Example    string
+	Deprecated string
+	Aliases    []string
+}
+
+// CmdDocs holds the documentation of each command, by command name.
+var CmdDocs = map[string]CmdDoc{
+{{- range .}}
+	{{quote .Command}}: {
+{{- if .Short}}
+		Short: {{goString .Short}},
+{{- end}}
+{{- if .Long}}
+		Long: {{goString .Long}},
+{{- end}}
+{{- if .Examples}}
+		Example: {{goString .Examples}},
+{{- end}}
+{{- with .FrontMatter.Deprecated}}
+		Deprecated: {{goString .}},
+{{- end}}
+{{- with .FrontMatter.Aliases}}
+		Aliases: []string{ {{- range $i, $a := .}}{{if $i}}, {{end}}{{quote $a}}{{end -}} },
+{{- end}}
+	},
+{{- end}}
+}
+`))
+
+// cmdDocs returns the CmdDoc type and a CmdDocs map holding the docs.
+func cmdDocs(docs []doc) (string, error) {
+	var b strings.Builder
+	err := cmdDocsTemplate.Execute(&b, docs)
+	return b.String(), err
+}
+
+// cmdDocRef returns the expression referring to a field of the CmdDoc
+// of d, e.g. CmdDocs["build"].Short.
+func cmdDocRef(d doc, field string) string {
+	return "CmdDocs[" + strconv.Quote(d.Command) + "]." + field
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// childrenMap returns a Children variable mapping each parent command,
+// named by the front matter "parent" field, to its child commands in
+// the order of the docs.  Commands without a parent map under "".
+func childrenMap(docs []doc) string {
+	children := map[string][]string{}
+	for i := range docs {
+		parent := docs[i].FrontMatter.Parent
+		children[parent] = append(children[parent], docs[i].Command)
+	}
+	var parents []string
+	for p := range children {
+		parents = append(parents, p)
+	}
+	sort.Strings(parents)
+
+	var b strings.Builder
+	b.WriteString("var Children = map[string][]string{\n")
+	for _, p := range parents {
+		quoted := make([]string, len(children[p]))
+		for i, c := range children[p] {
+			quoted[i] = fmt.Sprintf("%q", c)
+		}
+		fmt.Fprintf(&b, "\t%q: {%s},\n", p, strings.Join(quoted, ", "))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,30 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import "fmt"
+
+// commandDocType is emitted once by --typed, ahead of the per-command docs.
+const commandDocType = `// CommandDoc holds the documentation of a single command.
+type CommandDoc struct {
+	Short    string
+	Long     string
+	Examples string
+}
+
+// GetShort returns the one line description of the command.
+func (d CommandDoc) GetShort() string { return d.Short }
+
+// GetLong returns the full description of the command.
+func (d CommandDoc) GetLong() string { return d.Long }
+
+// GetExamples returns the usage examples of the command.
+func (d CommandDoc) GetExamples() string { return d.Examples }
+`
+
+// typedString returns the typed CommandDoc variable for d.
+func (d doc) typedString() string {
+	return fmt.Sprintf("var %sDoc = CommandDoc{\n\tShort: %s,\n\tLong: %s,\n\tExamples: %s,\n}\n",
+		d.Name, goString(d.Short), goString(d.Long), goString(d.Examples))
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"time"
 
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
@@ -23,16 +24,62 @@ var theArgs struct {
 var theFlags struct {
 	outputPath string
 	enable     struct {
-		plugins        bool
-		managedByLabel bool
-		helm           bool
+		plugins         bool
+		managedByLabel  bool
+		helm            bool
+		sops            bool
+		secretProviders bool
+		clusterCrds     bool
+		buildProvenance bool
 	}
 	helmCommand     string
 	helmApiVersions []string
 	helmKubeVersion string
 	loadRestrictor  string
 	reorderOutput   string
-	fnOptions       types.FnPluginLoadingOptions
+	outputFormat    string
+	outputNaming    string
+	emitGraph       string
+	crdSchemaDir    string
+	kubeVersion     string
+	kubeVersionSum  string
+	validate        string
+	fnResults       struct {
+		path   string
+		format string
+	}
+	trace struct {
+		enabled bool
+		path    string
+		format  string
+	}
+	set          []string
+	envAllow     []string
+	parallel     int
+	serverDryRun struct {
+		enabled        bool
+		kubectlCommand string
+		kubeconfig     string
+	}
+	verifyImages struct {
+		enabled       bool
+		key           string
+		identity      string
+		issuer        string
+		lockFile      string
+		cosignCommand string
+	}
+	cache struct {
+		ttl     time.Duration
+		offline bool
+	}
+	applySet struct {
+		parent      string
+		namespace   string
+		tooling     string
+		pruneLabels []string
+	}
+	fnOptions types.FnPluginLoadingOptions
 }
 
 type Help struct {
@@ -78,31 +125,102 @@ func NewCmdBuild(
 			if err := Validate(args); err != nil {
 				return err
 			}
-			k := krusty.MakeKustomizer(
-				HonorKustomizeFlags(krusty.MakeDefaultOptions(), cmd.Flags()),
-			)
+			kOpts := HonorKustomizeFlags(krusty.MakeDefaultOptions(), cmd.Flags())
+			cache, err := getFlagRemoteCache()
+			if err != nil {
+				return err
+			}
+			kOpts.RemoteCache = cache
+			if kOpts.Reorder == krusty.ReorderOptionCustom {
+				kOpts.CustomSortOptions, err = getFlagCustomSortOptions(fSys)
+				if err != nil {
+					return err
+				}
+			}
+			if theFlags.emitGraph != "" {
+				g, err := buildGraph(fSys, theArgs.kustomizationPath, kOpts)
+				if err != nil {
+					return err
+				}
+				out, err := formatGraph(g)
+				if err != nil {
+					return err
+				}
+				if theFlags.outputPath != "" {
+					return fSys.WriteFile(theFlags.outputPath, out)
+				}
+				_, err = writer.Write(out)
+				return err
+			}
+			theFnResults.reset()
+			k := krusty.MakeKustomizer(kOpts)
 			m, err := k.Run(fSys, theArgs.kustomizationPath)
+			if rErr := theFnResults.report(fSys, cmd.ErrOrStderr()); rErr != nil && err == nil {
+				err = rErr
+			}
+			// the trace helps debug failing builds too
+			if tErr := writeTrace(kOpts.Trace, fSys, cmd.ErrOrStderr()); tErr != nil && err == nil {
+				err = tErr
+			}
 			if err != nil {
 				return err
 			}
+			if n := theFnResults.errorCount(); n > 0 {
+				return fmt.Errorf("functions reported %d error(s)", n)
+			}
+			if err = validateResources(m, cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+			if theFlags.serverDryRun.enabled {
+				if err = serverDryRun(m, cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+			}
+			if theFlags.outputNaming != "" {
+				// validated by Validate
+				naming, _ := getFlagOutputNaming()
+				return MakeWriter(fSys).WriteNamedFiles(
+					theFlags.outputPath, naming, m)
+			}
 			if theFlags.outputPath != "" && fSys.IsDir(theFlags.outputPath) {
+				if theFlags.outputFormat != outputFormatYaml {
+					return fmt.Errorf(
+						"--%s %s cannot be used with a directory output",
+						flagOutputFormatName, theFlags.outputFormat)
+				}
 				// Ignore writer; write to o.outputPath directly.
 				return MakeWriter(fSys).WriteIndividualFiles(
 					theFlags.outputPath, m)
 			}
-			yml, err := m.AsYaml()
+			out, err := formatOutput(m)
 			if err != nil {
 				return err
 			}
 			if theFlags.outputPath != "" {
 				// Ignore writer; write to o.outputPath directly.
-				return fSys.WriteFile(theFlags.outputPath, yml)
+				return fSys.WriteFile(theFlags.outputPath, out)
 			}
-			_, err = writer.Write(yml)
+			_, err = writer.Write(out)
 			return err
 		},
 	}
 	AddFlagOutputPath(cmd.Flags())
+	AddFlagOutputFormat(cmd.Flags())
+	AddFlagOutputNaming(cmd.Flags())
+	AddFlagEmitGraph(cmd.Flags())
+	AddFlagFnResults(cmd.Flags())
+	AddFlagTrace(cmd.Flags())
+	AddFlagSet(cmd.Flags())
+	AddFlagEnvAllow(cmd.Flags())
+	AddFlagRemoteCache(cmd.Flags())
+	AddFlagParallel(cmd.Flags())
+	AddFlagServerDryRun(cmd.Flags())
+	AddFlagVerifyImages(cmd.Flags())
+	AddFlagCrdSchemaDir(cmd.Flags())
+	AddFlagKubeVersion(cmd.Flags())
+	AddFlagValidate(cmd.Flags())
+	AddFlagApplySet(cmd.Flags())
+	AddFlagEnableBuildProvenance(cmd.Flags())
 	AddFunctionBasicsFlags(cmd.Flags())
 	AddFlagLoadRestrictor(cmd.Flags())
 	AddFlagEnablePlugins(cmd.Flags())
@@ -121,6 +239,9 @@ func NewCmdBuild(
 	}
 
 	AddFlagEnableHelm(cmd.Flags())
+	AddFlagEnableSops(cmd.Flags())
+	AddFlagEnableSecretProviders(cmd.Flags())
+	AddFlagEnableClusterCrds(cmd.Flags())
 	return cmd
 }
 
@@ -139,6 +260,39 @@ func Validate(args []string) error {
 	if err := validateFlagLoadRestrictor(); err != nil {
 		return err
 	}
+	if err := validateFlagOutputFormat(); err != nil {
+		return err
+	}
+	if err := validateFlagOutputNaming(); err != nil {
+		return err
+	}
+	if err := validateFlagEmitGraph(); err != nil {
+		return err
+	}
+	if err := validateFlagFnResults(); err != nil {
+		return err
+	}
+	if err := validateFlagTrace(); err != nil {
+		return err
+	}
+	if err := validateFlagValidate(); err != nil {
+		return err
+	}
+	if err := validateFlagApplySet(); err != nil {
+		return err
+	}
+	if err := validateFlagSet(); err != nil {
+		return err
+	}
+	if err := validateFlagRemoteCache(); err != nil {
+		return err
+	}
+	if err := validateFlagVerifyImages(); err != nil {
+		return err
+	}
+	if err := validateFlagParallel(); err != nil {
+		return err
+	}
 	return validateFlagReorderOutput()
 }
 
@@ -150,6 +304,8 @@ func HonorKustomizeFlags(kOpts *krusty.Options, flags *flag.FlagSet) *krusty.Opt
 	if theFlags.enable.plugins {
 		c := types.EnabledPluginConfig(types.BploUseStaticallyLinked)
 		c.FnpLoadingOptions = theFlags.fnOptions
+		c.FnpLoadingOptions.ResultsSink = theFnResults.add
+		c.FnpLoadingOptions.VerifyImage = getFlagVerifyImage()
 		kOpts.PluginConfig = c
 	} else {
 		kOpts.PluginConfig.HelmConfig.Enabled = theFlags.enable.helm
@@ -157,6 +313,21 @@ func HonorKustomizeFlags(kOpts *krusty.Options, flags *flag.FlagSet) *krusty.Opt
 	kOpts.PluginConfig.HelmConfig.Command = theFlags.helmCommand
 	kOpts.PluginConfig.HelmConfig.ApiVersions = theFlags.helmApiVersions
 	kOpts.PluginConfig.HelmConfig.KubeVersion = theFlags.helmKubeVersion
+	kOpts.PluginConfig.SopsConfig.Enabled = theFlags.enable.sops
+	kOpts.PluginConfig.SecretProvidersConfig.Enabled = theFlags.enable.secretProviders
+	kOpts.EnableClusterCrds = theFlags.enable.clusterCrds
 	kOpts.AddManagedbyLabel = isManagedByLabelEnabled()
+	kOpts.AddBuildProvenance = theFlags.enable.buildProvenance
+	kOpts.Overrides = getFlagSetValues()
+	kOpts.AllowedEnv = theFlags.envAllow
+	kOpts.Parallel = theFlags.parallel
+	kOpts.CrdSchemaDir = theFlags.crdSchemaDir
+	kOpts.KubeVersion = theFlags.kubeVersion
+	kOpts.KubeVersionSHA256 = theFlags.kubeVersionSum
+	kOpts.KubectlCommand = theFlags.serverDryRun.kubectlCommand
+	// validated by Validate
+	kOpts.ApplySet, _ = getFlagApplySet()
+	kOpts.PruneLabels = getFlagPruneLabels()
+	kOpts.Trace = getFlagTrace()
 	return kOpts
 }
@@ -6,6 +6,8 @@ package build_test
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -197,6 +199,143 @@ type: Opaque
 	}
 }
 
+func TestBuildWithOutputFormat(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  namespace: ns1
+data:
+  a: "1"
+`))
+	for format, expected := range map[string]string{
+		"json": `{
+  "apiVersion": "v1",
+  "items": [
+    {
+      "apiVersion": "v1",
+      "kind": "Namespace",
+      "metadata": {
+        "name": "ns1"
+      }
+    },
+    {
+      "apiVersion": "v1",
+      "data": {
+        "a": "1"
+      },
+      "kind": "ConfigMap",
+      "metadata": {
+        "name": "cm",
+        "namespace": "ns1"
+      }
+    }
+  ],
+  "kind": "List"
+}
+`,
+		"jsonl": `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"ns1"}}
+{"apiVersion":"v1","data":{"a":"1"},"kind":"ConfigMap","metadata":{"name":"cm","namespace":"ns1"}}
+`,
+	} {
+		buffy := new(bytes.Buffer)
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+		cmd.Flags().Set("output-format", format)
+		if err := cmd.RunE(cmd, []string{}); err != nil {
+			t.Fatal(err)
+		}
+		if buffy.String() != expected {
+			t.Errorf("%s: expected output:\n%s\nbut got output:\n%s", format, expected, buffy)
+		}
+	}
+
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output-format", "xml")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "illegal flag value --output-format xml") {
+		t.Errorf("expected illegal flag value error, got %v", err)
+	}
+
+	fSys.Mkdir("someDir")
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output-format", "json")
+	cmd.Flags().Set("output", "someDir")
+	err = cmd.RunE(cmd, []string{})
+	if err == nil || err.Error() != "--output-format json cannot be used with a directory output" {
+		t.Errorf("expected directory output error, got %v", err)
+	}
+}
+
+func TestBuildWithSet(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- deployment.yaml
+substitutions:
+- name: tag
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - spec.template.spec.containers.[name=app].image
+    options:
+      delimiter: ':'
+      index: 1
+`))
+	fSys.WriteFile("deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:latest
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("set", "tag=v1.2")
+	cmd.Flags().Set("set", "namespace=prod")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - image: app:v1.2
+        name: app
+`
+	if buffy.String() != expected {
+		t.Errorf("expected output:\n%s\nbut got output:\n%s", expected, buffy)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("set", "tag")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || err.Error() != "illegal flag value --set tag; expected key=value" {
+		t.Errorf("expected illegal flag value error, got %v", err)
+	}
+}
+
 func TestHelp(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	buffy := new(bytes.Buffer)
@@ -249,3 +388,721 @@ func TestValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildOfflineFailsForUncachedRemoteFiles(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- https://example.com/deployment.yaml
+`))
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("offline", "true")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "unable to fetch https://example.com/deployment.yaml offline") {
+		t.Fatalf("expected offline error, got %v", err)
+	}
+}
+
+func TestBuildWithNegativeCacheTTL(t *testing.T) {
+	cmd := NewCmdBuild(filesys.MakeFsInMemory(), MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("cache-ttl", "-1h")
+	err := cmd.RunE(cmd, []string{})
+	expected := "illegal flag value --cache-ttl -1h0m0s; must not be negative"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestBuildWithParallel(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	serial := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), serial)
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	parallel := new(bytes.Buffer)
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), parallel)
+	cmd.Flags().Set("parallel", "4")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if serial.String() != parallel.String() {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s\n", serial, parallel)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("parallel", "0")
+	err := cmd.RunE(cmd, []string{})
+	expected := "illegal flag value --parallel 0; must be at least 1"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
+
+// fakeKubectl applies the stream of resources in one go, printing
+// their names, but rejects those named rejected and, as if their
+// Namespace didn't exist, those named pending.
+const fakeKubectl = `#!/bin/sh
+[ "$*" = "apply --dry-run=server --output name -f -" ] || exit 2
+awk '
+/^kind: / { kind = tolower($2) }
+/^  name: / {
+  if ($2 == "rejected") {
+    print "Error from server (Invalid): error when creating \"STDIN\": Deployment.apps \"rejected\" is invalid: spec.replicas: Invalid value: -1" > "/dev/stderr"
+    failed = 1
+  } else if ($2 == "pending") {
+    print "Error from server (NotFound): error when creating \"STDIN\": namespaces \"new\" not found" > "/dev/stderr"
+    failed = 1
+  } else if (kind == "deployment") {
+    print "deployment.apps/" $2
+  } else {
+    print kind "/" $2
+  }
+}
+END { exit failed }'
+`
+
+func TestBuildWithServerDryRun(t *testing.T) {
+	kubectl := filepath.Join(t.TempDir(), "kubectl")
+	if err := os.WriteFile(kubectl, []byte(fakeKubectl), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: accepted
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: rejected
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("server-dry-run", "true")
+	cmd.Flags().Set("kubectl-command", kubectl)
+	err := cmd.RunE(cmd, []string{})
+	expected := `server-side dry-run rejected 1 of 2 resources:
+Deployment.v1.apps/rejected.[noNs]: Error from server (Invalid): error when creating "STDIN": Deployment.apps "rejected" is invalid: spec.replicas: Invalid value: -1`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected error:\n%s\nBut got:\n%v", expected, err)
+	}
+	if buffy.Len() != 0 {
+		t.Fatalf("Expected no output, but got:\n%s", buffy)
+	}
+}
+
+func TestBuildWithServerDryRunNamespaceOfBuild(t *testing.T) {
+	kubectl := filepath.Join(t.TempDir(), "kubectl")
+	if err := os.WriteFile(kubectl, []byte(fakeKubectl), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: new
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pending
+  namespace: new
+`))
+	buffy := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.SetErr(stderr)
+	cmd.Flags().Set("server-dry-run", "true")
+	cmd.Flags().Set("kubectl-command", kubectl)
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `server-side dry-run couldn't validate 1 resources depending on Namespaces or CRDs of the build:
+Deployment.v1.apps/pending.new
+`
+	if stderr.String() != expected {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s", expected, stderr)
+	}
+	if !strings.Contains(buffy.String(), "name: pending") {
+		t.Fatalf("Expected the build output, but got:\n%s", buffy)
+	}
+}
+
+func TestBuildWithEmitGraph(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("base/kustomization.yaml", []byte(`
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - a=1
+`))
+	fSys.WriteFile("base/deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`))
+	fSys.WriteFile("monitoring/kustomization.yaml", []byte(`
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+commonLabels:
+  monitored: "true"
+`))
+	fSys.WriteFile("overlay/kustomization.yaml", []byte(`
+resources:
+- ../base
+components:
+- ../monitoring
+namePrefix: prod-
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("emit-graph", "dot")
+	if err := cmd.RunE(cmd, []string{"overlay"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph kustomize {
+  "overlay" [label="overlay\n2 resources", shape=box];
+  "base" [label="base\n2 resources", shape=box];
+  "base/deployment.yaml" [label="base/deployment.yaml\n1 resources", shape=note];
+  "base#configMapGenerator/config" [label="base#configMapGenerator/config", shape=ellipse];
+  "monitoring" [label="monitoring", shape=box];
+  "monitoring#commonLabels" [label="monitoring#commonLabels", shape=ellipse];
+  "overlay#namePrefix" [label="overlay#namePrefix", shape=ellipse];
+  "base" -> "base/deployment.yaml" [label="resources"];
+  "base" -> "base#configMapGenerator/config" [label="generator"];
+  "overlay" -> "base" [label="resources"];
+  "monitoring" -> "monitoring#commonLabels" [label="transformer"];
+  "overlay" -> "monitoring" [label="components"];
+  "overlay" -> "overlay#namePrefix" [label="transformer"];
+}
+`
+	if buffy.String() != expected {
+		t.Errorf("expected output:\n%s\nbut got output:\n%s", expected, buffy)
+	}
+
+	buffy = new(bytes.Buffer)
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("emit-graph", "json")
+	if err := cmd.RunE(cmd, []string{"base"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`"id": "base/deployment.yaml"`,
+		`"Deployment.v1.apps/web.[noNs]"`,
+		`"kind": "Generator"`,
+	} {
+		if !strings.Contains(buffy.String(), s) {
+			t.Errorf("expected output to contain %s, got:\n%s", s, buffy)
+		}
+	}
+}
+
+// fakeLinter passes its input through and reports an error.
+const fakeLinter = `#!/bin/sh
+cat
+cat <<EOF
+results:
+- message: replicas must be set
+  severity: error
+  resourceRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  field:
+    path: spec.replicas
+  file:
+    path: deployment.yaml
+EOF
+`
+
+func TestBuildWithFnResults(t *testing.T) {
+	linter := filepath.Join(t.TempDir(), "linter")
+	if err := os.WriteFile(linter, []byte(fakeLinter), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("app/"+konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- deployment.yaml
+transformers:
+- linter.yaml
+`))
+	fSys.WriteFile("app/deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`))
+	fSys.WriteFile("app/linter.yaml", []byte(fmt.Sprintf(`
+apiVersion: example.com/v1
+kind: Linter
+metadata:
+  name: lint
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: %s
+`, linter)))
+
+	for format, expected := range map[string]string{
+		"table": `SEVERITY  FUNCTION     RESOURCE        FIELD          FILE             MESSAGE
+error     Linter/lint  Deployment/web  spec.replicas  deployment.yaml  replicas must be set
+`,
+		"sarif": `"ruleId": "Linter/lint"`,
+	} {
+		t.Run(format, func(t *testing.T) {
+			buffy, errBuffy := new(bytes.Buffer), new(bytes.Buffer)
+			cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+			AddFunctionAlphaEnablementFlags(cmd.Flags())
+			cmd.SetErr(errBuffy)
+			cmd.Flags().Set("enable-alpha-plugins", "true")
+			cmd.Flags().Set("enable-exec", "true")
+			cmd.Flags().Set("fn-results-format", format)
+			err := cmd.RunE(cmd, []string{"app"})
+			if err == nil || err.Error() != "functions reported 1 error(s)" {
+				t.Fatalf("expected functions to report an error, got %v", err)
+			}
+			if !strings.Contains(errBuffy.String(), expected) {
+				t.Errorf("expected results to contain:\n%s\nbut got:\n%s", expected, errBuffy)
+			}
+			if buffy.Len() != 0 {
+				t.Errorf("expected no output, but got:\n%s", buffy)
+			}
+		})
+	}
+}
+
+func TestBuildWithVerifyFunctionImages(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("app/"+konfig.DefaultKustomizationFileName(), []byte(`
+transformers:
+- fn.yaml
+`))
+	fSys.WriteFile("app/fn.yaml", []byte(`
+apiVersion: example.com/v1
+kind: Fn
+metadata:
+  name: fn
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: example.com/fn:v1
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("enable-alpha-plugins", "true")
+	cmd.Flags().Set("verify-function-images", "true")
+	err := cmd.RunE(cmd, []string{"app"})
+	expected := "--verify-function-images requires --function-image-key"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected error containing %q, got %v", expected, err)
+	}
+
+	cmd.Flags().Set("function-image-key", "cosign.pub")
+	err = cmd.RunE(cmd, []string{"app"})
+	expected = "function image example.com/fn:v1 must be referenced by digest"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected error containing %q, got %v", expected, err)
+	}
+}
+
+func TestBuildWithValidate(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: valid
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: web
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: invalid
+spec:
+  replicas: one
+  strategy:
+    kind: Recreate
+  selector:
+    matchLabels:
+      app: web
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+---
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: unchecked
+spec:
+  anything: goes
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("validate", "strict")
+	err := cmd.RunE(cmd, []string{})
+	expected := `validation failed for 1 of 3 resources:
+Deployment.v1.apps/invalid.[noNs]: spec.replicas: expected integer, got string "one"
+Deployment.v1.apps/invalid.[noNs]: spec.strategy.kind: unknown field`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected error:\n%s\nBut got:\n%v", expected, err)
+	}
+	if buffy.Len() != 0 {
+		t.Fatalf("Expected no output, but got:\n%s", buffy)
+	}
+
+	stderr := new(bytes.Buffer)
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.SetErr(stderr)
+	cmd.Flags().Set("validate", "warn")
+	if err = cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected = `Warning: Deployment.v1.apps/invalid.[noNs]: spec.replicas: expected integer, got string "one"
+Warning: Deployment.v1.apps/invalid.[noNs]: spec.strategy.kind: unknown field
+`
+	if stderr.String() != expected {
+		t.Fatalf("Expected warnings:\n%s\nBut got:\n%s", expected, stderr)
+	}
+	if !strings.Contains(buffy.String(), "name: invalid") {
+		t.Fatalf("Expected output, but got:\n%s", buffy)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("validate", "lax")
+	err = cmd.RunE(cmd, []string{})
+	expected = "illegal flag value --validate lax; legal values: [strict warn ignore]"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestBuildWithApplySet(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+namespace: test
+resources:
+- service.yaml
+`))
+	fSys.WriteFile("service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("applyset", "configmaps/web")
+	cmd.Flags().Set("prune-label", "team=a")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    applyset.kubernetes.io/part-of: applyset-AYBFmvyL42CzETHwBgGxfdzTkTj-LGi8tiQsI9Li01I-v1
+    team: a
+  name: web
+  namespace: test
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  annotations:
+    applyset.kubernetes.io/contains-group-kinds: Service
+    applyset.kubernetes.io/tooling: kubectl/v1.27.0
+  labels:
+    applyset.kubernetes.io/id: applyset-AYBFmvyL42CzETHwBgGxfdzTkTj-LGi8tiQsI9Li01I-v1
+  name: web
+  namespace: test
+`
+	if buffy.String() != expected {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s", expected, buffy)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("applyset", "deployments/web")
+	err := cmd.RunE(cmd, []string{})
+	expected = "illegal flag value --applyset deployments/web; the parent must be a secret or a configmap"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestBuildWithCustomReorder(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  labels:
+    tier: z
+`))
+	fSys.WriteFile("order.yaml", []byte(`
+orderLast:
+- group: apiextensions.k8s.io
+sortBy:
+- label:tier
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("reorder", "custom:order.yaml")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    tier: z
+  name: a
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+`
+	if buffy.String() != expected {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s", expected, buffy)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("reorder", "custom:")
+	err := cmd.RunE(cmd, []string{})
+	expected = "illegal flag value --reorder custom:; legal values: [legacy none custom:<file>]"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("reorder", "custom:missing.yaml")
+	err = cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "unable to read --reorder file") {
+		t.Fatalf("expected error reading the --reorder file, got %v", err)
+	}
+}
+
+func TestBuildWithOutputNaming(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: test
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`))
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output", "out")
+	cmd.Flags().Set("output-naming", "{{.Namespace}}/{{.Kind | lower}}-{{.Name}}.yaml")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	for path, expected := range map[string]string{
+		"out/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- clusterrole-reader.yaml
+- test/deployment-web.yaml
+`,
+		"out/clusterrole-reader.yaml": `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`,
+		"out/test/deployment-web.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: test
+`,
+	} {
+		actual, err := fSys.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != expected {
+			t.Fatalf("Expected %s:\n%s\nBut got:\n%s", path, expected, actual)
+		}
+	}
+
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: test
+`))
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output", "out")
+	cmd.Flags().Set("output-naming", "{{.Namespace}}/{{.Kind | lower}}-{{.Name}}.yaml")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if fSys.Exists("out/clusterrole-reader.yaml") {
+		t.Fatal("expected the file of the removed resource to be removed")
+	}
+	if !fSys.Exists("out/test/deployment-web.yaml") {
+		t.Fatal("expected the file of the resource to be kept")
+	}
+
+	for naming, expected := range map[string]string{
+		"{{.Namespace}}.yaml": "file name test.yaml of Deployment.v1.apps/web.test is that of another resource",
+		"{{.Kind":             "illegal flag value --output-naming {{.Kind; template: output-naming:1: unclosed action",
+	} {
+		fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: test
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: test
+`))
+		cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.Flags().Set("output", "out")
+		cmd.Flags().Set("output-naming", naming)
+		err := cmd.RunE(cmd, []string{})
+		if err == nil || err.Error() != expected {
+			t.Fatalf("expected %q, got %v", expected, err)
+		}
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output-naming", "{{.Name}}.yaml")
+	err := cmd.RunE(cmd, []string{})
+	expected := "--output-naming requires --output"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestBuildWithTrace(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+namePrefix: foo-
+resources:
+- service.yaml
+`))
+	fSys.WriteFile("service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`))
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("trace", "true")
+	cmd.Flags().Set("trace-file", "trace.json")
+	cmd.Flags().Set("trace-format", "json")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := fSys.ReadFile("trace.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "steps": [
+    {
+      "kustomization": "/",
+      "type": "transformer",
+      "plugin": "PrefixTransformer",
+      "configuredIn": "kustomization.yaml",
+      "modified": [
+        {
+          "resource": "Service.v1.[noGrp]/foo-web.[noNs]",
+          "fields": [
+            {
+              "path": "metadata.name",
+              "before": "web",
+              "after": "foo-web"
+            }
+          ]
+        }
+      ]
+    },
+    {
+      "kustomization": "/",
+      "type": "transformer",
+      "plugin": "builtins.HashTransformerPlugin"
+    }
+  ]
+}
+`
+	if string(actual) != expected {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s", expected, actual)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("trace-format", "yaml")
+	err = cmd.RunE(cmd, []string{})
+	expected = "illegal flag value --trace-format yaml; legal values: [text json]"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagApplySetName   = "applyset"
+	flagPruneLabelName = "prune-label"
+)
+
+func AddFlagApplySet(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.applySet.parent, flagApplySetName, "",
+		"Parent of the ApplySet whose members are the resources of the build, as [KIND/]NAME,"+
+			" where KIND is secret (the default) or configmap. The resources are labeled as"+
+			" its members and the parent is added to them, for kubectl apply --prune --applyset.")
+	set.StringVar(
+		&theFlags.applySet.namespace, "applyset-namespace", "",
+		"Namespace of the --"+flagApplySetName+" parent; that of the namespaced resources if empty")
+	set.StringVar(
+		&theFlags.applySet.tooling, "applyset-tooling", types.DefaultApplySetTooling,
+		"Tooling annotation of the --"+flagApplySetName+" parent")
+	set.StringArrayVar(
+		&theFlags.applySet.pruneLabels, flagPruneLabelName, []string{},
+		"Label, as key=value, added to the resources of the build, for kubectl apply --prune --selector;"+
+			" may be repeated.")
+}
+
+func validateFlagApplySet() error {
+	if _, err := getFlagApplySet(); err != nil {
+		return err
+	}
+	for _, kv := range theFlags.applySet.pruneLabels {
+		if k, _, ok := strings.Cut(kv, "="); !ok || k == "" {
+			return fmt.Errorf(
+				"illegal flag value --%s %s; expected key=value", flagPruneLabelName, kv)
+		}
+	}
+	return nil
+}
+
+// getFlagApplySet returns the ApplySet parent of --applyset, nil
+// if it isn't set.
+func getFlagApplySet() (*types.ApplySet, error) {
+	parent := theFlags.applySet.parent
+	if parent == "" {
+		return nil, nil
+	}
+	s := &types.ApplySet{
+		Kind:      "Secret",
+		Name:      parent,
+		Namespace: theFlags.applySet.namespace,
+		Tooling:   theFlags.applySet.tooling,
+	}
+	if kind, name, ok := strings.Cut(parent, "/"); ok {
+		switch strings.ToLower(kind) {
+		case "secret", "secrets":
+		case "configmap", "configmaps":
+			s.Kind = "ConfigMap"
+		default:
+			return nil, fmt.Errorf(
+				"illegal flag value --%s %s; the parent must be a secret or a configmap",
+				flagApplySetName, parent)
+		}
+		s.Name = name
+	}
+	if s.Name == "" {
+		return nil, fmt.Errorf(
+			"illegal flag value --%s %s; expected [KIND/]NAME", flagApplySetName, parent)
+	}
+	return s, nil
+}
+
+func getFlagPruneLabels() map[string]string {
+	if len(theFlags.applySet.pruneLabels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(theFlags.applySet.pruneLabels))
+	for _, kv := range theFlags.applySet.pruneLabels {
+		k, v, _ := strings.Cut(kv, "=")
+		labels[k] = v
+	}
+	return labels
+}
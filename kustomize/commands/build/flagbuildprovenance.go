@@ -0,0 +1,17 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func AddFlagEnableBuildProvenance(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.buildProvenance, "enable-build-provenance", false,
+		"Annotate every resource with the kustomization built, its git commit and the kustomize version,"+
+			" and with its origin and the transformers that touched it, as the '"+
+			types.BuildProvenanceOption+"' option of the 'buildMetadata' field does.")
+}
@@ -0,0 +1,15 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagCrdSchemaDir(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.crdSchemaDir, "crd-schema-dir", "",
+		"Directory of CRDs whose schemas strategic merge patches of their custom resources honor,"+
+			" e.g. to merge lists by their keys rather than replace them.")
+}
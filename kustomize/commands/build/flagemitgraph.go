@@ -0,0 +1,37 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagEmitGraphName = "emit-graph"
+
+	graphFormatDot  = "dot"
+	graphFormatJson = "json"
+)
+
+func AddFlagEmitGraph(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.emitGraph, flagEmitGraphName, "",
+		"Instead of the resources, print the graph of the kustomizations, components,"+
+			" resource files, generators and transformers of the build, in '"+graphFormatDot+
+			"' or '"+graphFormatJson+"' format.")
+}
+
+func validateFlagEmitGraph() error {
+	switch theFlags.emitGraph {
+	case "", graphFormatDot, graphFormatJson:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagEmitGraphName, theFlags.emitGraph,
+			[]string{graphFormatDot, graphFormatJson})
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagEnableClusterCrds adds the --enable-cluster-crds flag.
+// kubectl reads the CRDs with the credentials of its current context.
+func AddFlagEnableClusterCrds(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.clusterCrds,
+		"enable-cluster-crds",
+		false,
+		"Enable reading the CRDs of the cluster with kubectl, as named by \"openapi: {crds: cluster}\".")
+}
@@ -0,0 +1,19 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagEnableSecretProviders adds the --enable-secret-providers flag.
+// Providers authenticate with the credentials of the vault, aws
+// and gcloud command lines found in the environment.
+func AddFlagEnableSecretProviders(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.secretProviders,
+		"enable-secret-providers",
+		false,
+		"Enable pulling secretGenerator values from Vault, AWS Secrets Manager and GCP Secret Manager.")
+}
@@ -0,0 +1,19 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagEnableSops adds the --enable-sops flag.
+// Decryption reads age identities from the environment,
+// as sops does, without running the sops binary.
+func AddFlagEnableSops(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.sops,
+		"enable-sops",
+		false,
+		"Enable decryption of SOPS-encrypted secretGenerator files and envs.")
+}
@@ -0,0 +1,15 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagEnvAllow(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&theFlags.envAllow, "env-allow", []string{},
+		"Name of an environment variable that the build may read, in substitutions, conditions,"+
+			" the namespace or templated literals; may be repeated. The build can't read any other.")
+}
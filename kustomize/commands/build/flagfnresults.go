@@ -0,0 +1,212 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	flagFnResultsName       = "fn-results"
+	flagFnResultsFormatName = "fn-results-format"
+
+	fnResultsFormatTable = "table"
+	fnResultsFormatSarif = "sarif"
+)
+
+func AddFlagFnResults(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.fnResults.path, flagFnResultsName, "",
+		"File to write the results reported by functions to; stderr if empty.")
+	set.StringVar(
+		&theFlags.fnResults.format, flagFnResultsFormatName, fnResultsFormatTable,
+		"Format of the results reported by functions, '"+fnResultsFormatTable+
+			"' or '"+fnResultsFormatSarif+"'.")
+}
+
+func validateFlagFnResults() error {
+	switch theFlags.fnResults.format {
+	case fnResultsFormatTable, fnResultsFormatSarif:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagFnResultsFormatName, theFlags.fnResults.format,
+			[]string{fnResultsFormatTable, fnResultsFormatSarif})
+	}
+}
+
+// theFnResults holds the results reported by the functions of
+// the current build.
+var theFnResults = &fnResultsCollector{}
+
+// fnResult is a result reported by a function.
+type fnResult struct {
+	function string
+	*framework.Result
+}
+
+// fnResultsCollector gathers the results reported by the
+// functions of a build.
+type fnResultsCollector struct {
+	mu      sync.Mutex
+	results []fnResult
+	errs    []error
+}
+
+func (c *fnResultsCollector) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = nil
+	c.errs = nil
+}
+
+// add is the results sink of the functions.
+func (c *fnResultsCollector) add(function string, b []byte) {
+	var results framework.Results
+	err := yaml.Unmarshal(b, &results)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.errs = append(c.errs, fmt.Errorf("invalid results of function %s: %w", function, err))
+		return
+	}
+	for _, r := range results {
+		c.results = append(c.results, fnResult{function: function, Result: r})
+	}
+}
+
+// errorCount returns the number of results of error severity.
+func (c *fnResultsCollector) errorCount() int {
+	n := 0
+	for _, r := range c.results {
+		if r.Severity == framework.Error {
+			n++
+		}
+	}
+	return n
+}
+
+// report writes the results, if any, to the file given by
+// --fn-results or else to w.
+func (c *fnResultsCollector) report(fSys filesys.FileSystem, w io.Writer) error {
+	if len(c.errs) > 0 {
+		return c.errs[0]
+	}
+	if len(c.results) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	var err error
+	if theFlags.fnResults.format == fnResultsFormatSarif {
+		err = c.writeSarif(&buf)
+	} else {
+		err = c.writeTable(&buf)
+	}
+	if err != nil {
+		return err
+	}
+	if theFlags.fnResults.path != "" {
+		return fSys.WriteFile(theFlags.fnResults.path, buf.Bytes())
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func (r fnResult) resource() string {
+	if r.ResourceRef == nil {
+		return ""
+	}
+	return r.ResourceRef.Kind + "/" + r.ResourceRef.Name
+}
+
+func (r fnResult) field() string {
+	if r.Field == nil {
+		return ""
+	}
+	return r.Field.Path
+}
+
+func (r fnResult) file() string {
+	if r.File == nil {
+		return ""
+	}
+	return r.File.Path
+}
+
+func (c *fnResultsCollector) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEVERITY\tFUNCTION\tRESOURCE\tFIELD\tFILE\tMESSAGE")
+	for _, r := range c.results {
+		severity := string(r.Severity)
+		if severity == "" {
+			severity = string(framework.Info)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", severity, r.function,
+			r.resource(), r.field(), r.file(), strings.ReplaceAll(r.Message, "\n", " "))
+	}
+	return tw.Flush()
+}
+
+// writeSarif writes the results as a SARIF 2.1.0 log, with a rule
+// per function.
+func (c *fnResultsCollector) writeSarif(w io.Writer) error {
+	type location map[string]interface{}
+	results := []map[string]interface{}{}
+	for _, r := range c.results {
+		level := "note"
+		switch r.Severity {
+		case framework.Error:
+			level = "error"
+		case framework.Warning:
+			level = "warning"
+		}
+		result := map[string]interface{}{
+			"ruleId":  r.function,
+			"level":   level,
+			"message": map[string]string{"text": r.Message},
+		}
+		loc := location{}
+		if f := r.file(); f != "" {
+			loc["physicalLocation"] = map[string]interface{}{
+				"artifactLocation": map[string]string{"uri": f},
+			}
+		}
+		if res := r.resource(); res != "" {
+			name := res
+			if f := r.field(); f != "" {
+				name += ":" + f
+			}
+			loc["logicalLocations"] = []map[string]string{{"fullyQualifiedName": name}}
+		}
+		if len(loc) > 0 {
+			result["locations"] = []location{loc}
+		}
+		results = append(results, result)
+	}
+	b, err := json.MarshalIndent(map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{map[string]interface{}{
+			"tool":    map[string]interface{}{"driver": map[string]string{"name": "kustomize"}},
+			"results": results,
+		}},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,20 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagKubeVersion(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.kubeVersion, "kube-version", "",
+		"Kubernetes version, e.g. 1.29, whose OpenAPI schema patches are merged by,"+
+			" in place of the 'openapi' field of the kustomization;"+
+			" the schema of a release that isn't built in, e.g. v1.29.3, is downloaded"+
+			" if --kube-version-sha256 is set.")
+	set.StringVar(
+		&theFlags.kubeVersionSum, "kube-version-sha256", "",
+		"Hex sha256 checksum the downloaded OpenAPI schema of --kube-version must have.")
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+const (
+	flagOutputFormatName = "output-format"
+
+	outputFormatYaml      = "yaml"
+	outputFormatJson      = "json"
+	outputFormatJsonLines = "jsonl"
+)
+
+func AddFlagOutputFormat(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.outputFormat, flagOutputFormatName,
+		outputFormatYaml,
+		"Format of the output. Use '"+outputFormatYaml+"' for a multi-document YAML stream,"+
+			" '"+outputFormatJson+"' for a single v1 List object, or"+
+			" '"+outputFormatJsonLines+"' for one JSON object per line.")
+}
+
+func validateFlagOutputFormat() error {
+	switch theFlags.outputFormat {
+	case outputFormatYaml, outputFormatJson, outputFormatJsonLines:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagOutputFormatName, theFlags.outputFormat,
+			[]string{outputFormatYaml, outputFormatJson, outputFormatJsonLines})
+	}
+}
+
+// formatOutput serializes the resources in the format given by
+// --output-format.
+func formatOutput(m resmap.ResMap) ([]byte, error) {
+	switch theFlags.outputFormat {
+	case outputFormatJson:
+		items := []json.RawMessage{}
+		for _, r := range m.Resources() {
+			b, err := r.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, b)
+		}
+		b, err := json.MarshalIndent(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+		}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	case outputFormatJsonLines:
+		var buf bytes.Buffer
+		for _, r := range m.Resources() {
+			b, err := r.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(b)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), nil
+	default:
+		return m.AsYaml()
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/pflag"
+)
+
+const flagOutputNamingName = "output-naming"
+
+func AddFlagOutputNaming(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.outputNaming, flagOutputNamingName, "",
+		"If specified, write each resource to its own file in the --output directory,"+
+			" at the path given by this Go template of the resource's .Group, .Version,"+
+			" .Kind, .Name and .Namespace, e.g. '{{.Namespace}}/{{.Kind}}-{{.Name}}.yaml',"+
+			" and a kustomization file listing the files in order.")
+}
+
+func validateFlagOutputNaming() error {
+	if theFlags.outputNaming == "" {
+		return nil
+	}
+	if theFlags.outputPath == "" {
+		return fmt.Errorf("--%s requires --output", flagOutputNamingName)
+	}
+	if theFlags.outputFormat != outputFormatYaml {
+		return fmt.Errorf(
+			"--%s cannot be used with --%s %s",
+			flagOutputNamingName, flagOutputFormatName, theFlags.outputFormat)
+	}
+	if _, err := getFlagOutputNaming(); err != nil {
+		return fmt.Errorf(
+			"illegal flag value --%s %s; %w",
+			flagOutputNamingName, theFlags.outputNaming, err)
+	}
+	return nil
+}
+
+// getFlagOutputNaming returns the template naming the file of each
+// resource, which also has the function lower.
+func getFlagOutputNaming() (*template.Template, error) {
+	return template.New(flagOutputNamingName).
+		Funcs(template.FuncMap{"lower": strings.ToLower}).
+		Option("missingkey=error").
+		Parse(theFlags.outputNaming)
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const flagParallelName = "parallel"
+
+func AddFlagParallel(set *pflag.FlagSet) {
+	set.IntVar(
+		&theFlags.parallel, flagParallelName, 1,
+		"Number of resource files and bases, e.g. remote bases, to load at once."+
+			" The output doesn't depend on it.")
+}
+
+func validateFlagParallel() error {
+	if theFlags.parallel < 1 {
+		return fmt.Errorf(
+			"illegal flag value --%s %d; must be at least 1",
+			flagParallelName, theFlags.parallel)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/remotecache"
+)
+
+const (
+	flagCacheTTLName = "cache-ttl"
+	flagOfflineName  = "offline"
+)
+
+func AddFlagRemoteCache(set *pflag.FlagSet) {
+	set.DurationVar(
+		&theFlags.cache.ttl, flagCacheTTLName, 0,
+		"Keep remote bases and files in the cache, reusing them for the given duration, e.g. 1h."+
+			" Zero disables the cache.")
+	set.BoolVar(
+		&theFlags.cache.offline, flagOfflineName, false,
+		"Build remote bases and files from the cache regardless of their age,"+
+			" failing if they aren't cached.")
+}
+
+func validateFlagRemoteCache() error {
+	if theFlags.cache.ttl < 0 {
+		return fmt.Errorf(
+			"illegal flag value --%s %s; must not be negative",
+			flagCacheTTLName, theFlags.cache.ttl)
+	}
+	return nil
+}
+
+// getFlagRemoteCache returns nil unless a flag enables the cache.
+func getFlagRemoteCache() (*remotecache.Cache, error) {
+	if theFlags.cache.ttl == 0 && !theFlags.cache.offline {
+		return nil, nil
+	}
+	return remotecache.New(theFlags.cache.ttl, theFlags.cache.offline)
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+const flagServerDryRunName = "server-dry-run"
+
+func AddFlagServerDryRun(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.serverDryRun.enabled, flagServerDryRunName, false,
+		"Validate the build output with a server-side dry-run apply before printing it,"+
+			" reporting the resources the cluster rejects.")
+	set.StringVar(
+		&theFlags.serverDryRun.kubectlCommand, "kubectl-command", "kubectl",
+		"kubectl command (path to executable) used by --"+flagServerDryRunName+
+			" and to read the CRDs of the cluster for openapi: {crds: cluster}")
+	set.StringVar(
+		&theFlags.serverDryRun.kubeconfig, "kubeconfig", "",
+		"kubeconfig of the cluster used by --"+flagServerDryRunName+"; kubectl's default if empty")
+}
+
+// serverDryRun applies the resources with kubectl's server-side
+// dry-run, all in one stream, and reports those the cluster rejects
+// with the errors kubectl printed about them.
+//
+// A dry-run persists nothing, so the cluster rejects the resources in
+// a Namespace, or of a kind defined by a CRD, that the build itself
+// creates.  Those are reported to w as not validated instead.
+func serverDryRun(m resmap.ResMap, w io.Writer) error {
+	y, err := m.AsYaml()
+	if err != nil {
+		return err
+	}
+	args := []string{"apply", "--dry-run=server", "--output", "name", "-f", "-"}
+	if theFlags.serverDryRun.kubeconfig != "" {
+		args = append(args, "--kubeconfig", theFlags.serverDryRun.kubeconfig)
+	}
+	cmd := exec.Command(theFlags.serverDryRun.kubectlCommand, args...)
+	cmd.Stdin = bytes.NewReader(y)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*exec.ExitError); !ok { //nolint:errorlint
+		return fmt.Errorf("unable to run %s: %w", theFlags.serverDryRun.kubectlCommand, err)
+	}
+	// kubectl prints the name of each resource it applies, and the
+	// errors of the others
+	applied := map[string]int{}
+	for _, name := range strings.Fields(stdout.String()) {
+		applied[name]++
+	}
+	errs := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+	namespaces, kinds := createdByBuild(m)
+	var failures, unvalidated []string
+	for _, r := range m.Resources() {
+		name := dryRunName(r)
+		if applied[name] > 0 {
+			applied[name]--
+			continue
+		}
+		gvk := r.GetGvk()
+		if namespaces[r.GetNamespace()] || kinds[gvk.Group+"/"+gvk.Kind] {
+			unvalidated = append(unvalidated, r.CurId().String())
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("%s: %s", r.CurId(), dryRunErrors(errs, r.GetName())))
+	}
+	if len(unvalidated) > 0 {
+		fmt.Fprintf(w, "server-side dry-run couldn't validate %d resources depending on Namespaces or CRDs of the build:\n%s\n",
+			len(unvalidated), strings.Join(unvalidated, "\n"))
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("server-side dry-run rejected %d of %d resources:\n%s",
+			len(failures), m.Size(), strings.Join(failures, "\n"))
+	}
+	if len(unvalidated) == 0 {
+		return fmt.Errorf("server-side dry-run failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// dryRunName returns the name kubectl prints for r, e.g.
+// deployment.apps/web.
+func dryRunName(r *resource.Resource) string {
+	gvk := r.GetGvk()
+	kind := strings.ToLower(gvk.Kind)
+	if gvk.Group != "" {
+		kind += "." + gvk.Group
+	}
+	return kind + "/" + r.GetName()
+}
+
+// dryRunErrors returns the errors kubectl printed about the resource
+// with the given name.
+func dryRunErrors(errs []string, name string) string {
+	var matched []string
+	for _, e := range errs {
+		if strings.Contains(e, fmt.Sprintf("%q", name)) {
+			matched = append(matched, strings.TrimSpace(e))
+		}
+	}
+	if len(matched) == 0 {
+		return "rejected by the server"
+	}
+	return strings.Join(matched, "; ")
+}
+
+// createdByBuild returns the Namespaces and the kinds, by group/kind,
+// of the CRDs that the build creates.
+func createdByBuild(m resmap.ResMap) (namespaces, kinds map[string]bool) {
+	namespaces, kinds = map[string]bool{}, map[string]bool{}
+	for _, r := range m.Resources() {
+		switch r.GetKind() {
+		case "Namespace":
+			namespaces[r.GetName()] = true
+		case "CustomResourceDefinition":
+			group, _ := r.GetString("spec.group")
+			kind, _ := r.GetString("spec.names.kind")
+			kinds[group+"/"+kind] = true
+		}
+	}
+	return namespaces, kinds
+}
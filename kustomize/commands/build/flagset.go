@@ -0,0 +1,42 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const flagSetName = "set"
+
+func AddFlagSet(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&theFlags.set, flagSetName, []string{},
+		"Override a value of the kustomization as key=value; may be repeated."+
+			" The key is 'namespace', 'commonLabels.<label>' or the name of a substitution.")
+}
+
+func validateFlagSet() error {
+	for _, kv := range theFlags.set {
+		if k, _, ok := strings.Cut(kv, "="); !ok || k == "" {
+			return fmt.Errorf(
+				"illegal flag value --%s %s; expected key=value", flagSetName, kv)
+		}
+	}
+	return nil
+}
+
+func getFlagSetValues() map[string]string {
+	if len(theFlags.set) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(theFlags.set))
+	for _, kv := range theFlags.set {
+		k, v, _ := strings.Cut(kv, "=")
+		values[k] = v
+	}
+	return values
+}
@@ -23,6 +23,10 @@ func AddFunctionBasicsFlags(set *pflag.FlagSet) {
 	set.BoolVar(
 		&theFlags.fnOptions.AsCurrentUser, "as-current-user", false,
 		"use the uid and gid of the command executor to run the function in the container")
+	set.StringVar(
+		&theFlags.fnOptions.ContainerRuntime, "container-runtime", "",
+		"the container runtime executable that runs container functions, "+
+			"the first of docker, podman and nerdctl on the PATH by default")
 }
 
 func AddFunctionAlphaEnablementFlags(set *pflag.FlagSet) {
@@ -33,4 +37,7 @@ func AddFunctionAlphaEnablementFlags(set *pflag.FlagSet) {
 	set.BoolVar(
 		&theFlags.fnOptions.EnableStar, "enable-star", false,
 		"enable support for starlark functions. (Alpha)")
+	set.BoolVar(
+		&theFlags.fnOptions.EnableWasm, "enable-wasm", false,
+		"enable support for WebAssembly functions, run sandboxed in-process. (Alpha)")
 }
@@ -0,0 +1,79 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/trace"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	flagTraceName       = "trace"
+	flagTraceFileName   = "trace-file"
+	flagTraceFormatName = "trace-format"
+
+	traceFormatJson = "json"
+	traceFormatText = "text"
+)
+
+func AddFlagTrace(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.trace.enabled, flagTraceName, false,
+		"Trace the resources each generator and transformer adds, removes and"+
+			" modifies, with the changes to their fields.")
+	set.StringVar(
+		&theFlags.trace.path, flagTraceFileName, "",
+		"File to write the trace to; stderr if empty.")
+	set.StringVar(
+		&theFlags.trace.format, flagTraceFormatName, traceFormatText,
+		"Format of the trace, '"+traceFormatText+"' for a log or '"+traceFormatJson+"'.")
+}
+
+func validateFlagTrace() error {
+	switch theFlags.trace.format {
+	case traceFormatJson, traceFormatText:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagTraceFormatName, theFlags.trace.format,
+			[]string{traceFormatText, traceFormatJson})
+	}
+}
+
+// getFlagTrace returns the trace to record the build in, if enabled.
+func getFlagTrace() *trace.Trace {
+	if !theFlags.trace.enabled {
+		return nil
+	}
+	return &trace.Trace{}
+}
+
+// writeTrace writes t, if not nil, to the file given by --trace-file
+// or else to w.
+func writeTrace(t *trace.Trace, fSys filesys.FileSystem, w io.Writer) error {
+	if t == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	var err error
+	if theFlags.trace.format == traceFormatJson {
+		err = t.WriteJSON(&buf)
+	} else {
+		err = t.WriteText(&buf)
+	}
+	if err != nil {
+		return err
+	}
+	if theFlags.trace.path != "" {
+		return fSys.WriteFile(theFlags.trace.path, buf.Bytes())
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+const (
+	flagValidateName = "validate"
+
+	validateStrict = "strict"
+	validateWarn   = "warn"
+	validateIgnore = "ignore"
+)
+
+func AddFlagValidate(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.validate, flagValidateName, validateIgnore,
+		"Validate each resource of the build output against the OpenAPI schema of its type,"+
+			" builtin or from the 'openapi' field and CRDs, reporting unknown fields,"+
+			" fields of the wrong type and missing required fields. Use '"+validateStrict+
+			"' to fail the build, '"+validateWarn+"' to print them to stderr, or '"+
+			validateIgnore+"'.")
+}
+
+func validateFlagValidate() error {
+	switch theFlags.validate {
+	case validateStrict, validateWarn, validateIgnore:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagValidateName, theFlags.validate,
+			[]string{validateStrict, validateWarn, validateIgnore})
+	}
+}
+
+// validateResources checks the resources of m against the schema of
+// the build, failing with their errors if --validate is strict, and
+// writing them to w if it's warn.  Resources of types without a schema
+// aren't checked.
+func validateResources(m resmap.ResMap, w io.Writer) error {
+	if theFlags.validate == validateIgnore {
+		return nil
+	}
+	var failures []string
+	invalid := 0
+	for _, r := range m.Resources() {
+		errs, _ := openapi.Validate(&r.RNode)
+		if len(errs) > 0 {
+			invalid++
+		}
+		for _, e := range errs {
+			failures = append(failures, fmt.Sprintf("%s: %s", r.CurId(), e.Error()))
+		}
+	}
+	if invalid == 0 {
+		return nil
+	}
+	if theFlags.validate == validateStrict {
+		return fmt.Errorf("validation failed for %d of %d resources:\n%s",
+			invalid, m.Size(), strings.Join(failures, "\n"))
+	}
+	for _, f := range failures {
+		if _, err := fmt.Fprintf(w, "Warning: %s\n", f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
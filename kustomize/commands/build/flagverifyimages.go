@@ -0,0 +1,67 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+)
+
+const flagVerifyImagesName = "verify-function-images"
+
+func AddFlagVerifyImages(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.verifyImages.enabled, flagVerifyImagesName, false,
+		"Run container functions only if their images are referenced by digest,"+
+			" or recorded in --function-image-lock, and signed as verified by cosign.")
+	set.StringVar(
+		&theFlags.verifyImages.key, "function-image-key", "",
+		"Public key, or cosign key reference, the images of container functions are signed with;"+
+			" keyless signatures are verified if empty")
+	set.StringVar(
+		&theFlags.verifyImages.identity, "function-image-identity", "",
+		"Signer keyless signatures of the images of container functions must have")
+	set.StringVar(
+		&theFlags.verifyImages.issuer, "function-image-oidc-issuer", "",
+		"OIDC issuer keyless signatures of the images of container functions must have")
+	set.StringVar(
+		&theFlags.verifyImages.lockFile, "function-image-lock", "",
+		"File recording the digests of container function images referenced by tag;"+
+			" missing digests are resolved and added")
+	set.StringVar(
+		&theFlags.verifyImages.cosignCommand, "cosign-command", container.DefaultCosignCommand,
+		"cosign command (path to executable) used by --"+flagVerifyImagesName)
+}
+
+func validateFlagVerifyImages() error {
+	v := &theFlags.verifyImages
+	if !v.enabled || v.key != "" {
+		return nil
+	}
+	if v.identity == "" || v.issuer == "" {
+		return fmt.Errorf(
+			"--%s requires --function-image-key, or --function-image-identity and "+
+				"--function-image-oidc-issuer for keyless signatures", flagVerifyImagesName)
+	}
+	return nil
+}
+
+// getFlagVerifyImage returns the function verifying the images of
+// container functions, nil if --verify-function-images isn't set.
+func getFlagVerifyImage() func(string) (string, error) {
+	v := &theFlags.verifyImages
+	if !v.enabled {
+		return nil
+	}
+	verifier := &container.ImageVerifier{
+		Command:               v.cosignCommand,
+		Key:                   v.key,
+		CertificateIdentity:   v.identity,
+		CertificateOIDCIssuer: v.issuer,
+		LockFile:              v.lockFile,
+	}
+	return verifier.Verify
+}
@@ -0,0 +1,255 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Kinds of graph nodes.
+const (
+	nodeKustomization = "Kustomization"
+	nodeComponent     = "Component"
+	nodeFile          = "File"
+	nodeRemote        = "Remote"
+	nodeGenerator     = "Generator"
+	nodeTransformer   = "Transformer"
+)
+
+// graphNode is a kustomization, file, generator or transformer
+// taking part in a build, with the ids of the resources it
+// contributes to the output.
+type graphNode struct {
+	ID        string   `json:"id"`
+	Kind      string   `json:"kind"`
+	Resources []string `json:"resources,omitempty"`
+}
+
+// graphEdge tells that the kustomization From uses To, as
+// given by the field of the kustomization named by Kind.
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+type graph struct {
+	Nodes []*graphNode `json:"nodes"`
+	Edges []graphEdge  `json:"edges"`
+
+	seen map[string]bool
+}
+
+// buildGraph walks the kustomization at path and those it uses,
+// building each to list the resources it contributes.
+func buildGraph(fSys filesys.FileSystem, path string, kOpts *krusty.Options) (*graph, error) {
+	if !fSys.IsDir(path) {
+		return nil, fmt.Errorf("--%s requires a local kustomization directory", flagEmitGraphName)
+	}
+	g := &graph{seen: map[string]bool{}}
+	if err := g.addKustomization(fSys, filepath.Clean(path), kOpts); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *graph) add(n *graphNode) bool {
+	if g.seen[n.ID] {
+		return false
+	}
+	g.seen[n.ID] = true
+	g.Nodes = append(g.Nodes, n)
+	return true
+}
+
+func (g *graph) addKustomization(fSys filesys.FileSystem, dir string, kOpts *krusty.Options) error {
+	k, err := readKustomization(fSys, dir)
+	if err != nil {
+		return err
+	}
+	n := &graphNode{ID: dir, Kind: nodeKustomization}
+	if k.Kind == types.ComponentKind {
+		n.Kind = nodeComponent
+	} else {
+		// Components only build as part of a kustomization.
+		m, err := krusty.MakeKustomizer(kOpts).Run(fSys, dir)
+		if err != nil {
+			return fmt.Errorf("building %s: %w", dir, err)
+		}
+		for _, r := range m.Resources() {
+			n.Resources = append(n.Resources, r.CurId().String())
+		}
+	}
+	if !g.add(n) {
+		return nil
+	}
+
+	type use struct {
+		field string
+		paths []string
+	}
+	uses := []use{{"resources", k.Resources}, {"components", k.Components}}
+	for _, c := range k.ConditionalResources {
+		uses = append(uses, use{"conditionalResources", []string{c.Path}})
+	}
+	for _, c := range k.ConditionalComponents {
+		uses = append(uses, use{"conditionalComponents", []string{c.Path}})
+	}
+	for _, u := range uses {
+		for _, p := range u.paths {
+			to, err := g.addPath(fSys, dir, p, kOpts)
+			if err != nil {
+				return err
+			}
+			g.Edges = append(g.Edges, graphEdge{From: dir, To: to, Kind: u.field})
+		}
+	}
+
+	var generators, transformers []string
+	for _, c := range k.ConfigMapGenerator {
+		generators = append(generators, "configMapGenerator/"+c.Name)
+	}
+	for _, s := range k.SecretGenerator {
+		generators = append(generators, "secretGenerator/"+s.Name)
+	}
+	for _, h := range k.HelmCharts {
+		generators = append(generators, "helmCharts/"+h.Name)
+	}
+	for _, p := range k.Generators {
+		generators = append(generators, "generators/"+p)
+	}
+	for field, set := range map[string]bool{
+		"namePrefix":        k.NamePrefix != "",
+		"nameSuffix":        k.NameSuffix != "",
+		"namePrefixes":      len(k.NamePrefixes) > 0,
+		"nameSuffixes":      len(k.NameSuffixes) > 0,
+		"namespace":         k.Namespace != "",
+		"commonLabels":      len(k.CommonLabels) > 0,
+		"labels":            len(k.Labels) > 0,
+		"commonAnnotations": len(k.CommonAnnotations) > 0,
+		"annotations":       len(k.Annotations) > 0,
+		"patches":           len(k.Patches) > 0,
+		"images":            len(k.Images) > 0,
+		"replicas":          len(k.Replicas) > 0,
+		"replacements":      len(k.Replacements) > 0,
+		"substitutions":     len(k.Substitutions) > 0,
+	} {
+		if set {
+			transformers = append(transformers, field)
+		}
+	}
+	sort.Strings(transformers)
+	for _, p := range k.Transformers {
+		transformers = append(transformers, "transformers/"+p)
+	}
+	for _, gen := range generators {
+		id := dir + "#" + gen
+		g.add(&graphNode{ID: id, Kind: nodeGenerator})
+		g.Edges = append(g.Edges, graphEdge{From: dir, To: id, Kind: "generator"})
+	}
+	for _, t := range transformers {
+		id := dir + "#" + t
+		g.add(&graphNode{ID: id, Kind: nodeTransformer})
+		g.Edges = append(g.Edges, graphEdge{From: dir, To: id, Kind: "transformer"})
+	}
+	return nil
+}
+
+// addPath adds the node of an entry of resources or components
+// of the kustomization in dir and returns its id.
+func (g *graph) addPath(fSys filesys.FileSystem, dir, p string, kOpts *krusty.Options) (string, error) {
+	path := p
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, p)
+	}
+	switch {
+	case fSys.IsDir(path):
+		return path, g.addKustomization(fSys, path, kOpts)
+	case fSys.Exists(path):
+		n := &graphNode{ID: path, Kind: nodeFile}
+		if !g.add(n) {
+			return path, nil
+		}
+		b, err := fSys.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		rs, err := provider.NewDefaultDepProvider().GetResourceFactory().SliceFromBytes(b)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", path, err)
+		}
+		for _, r := range rs {
+			n.Resources = append(n.Resources, r.CurId().String())
+		}
+		return path, nil
+	default:
+		// Not a local path, hence a remote kustomization or file;
+		// these aren't fetched to draw the graph.
+		g.add(&graphNode{ID: p, Kind: nodeRemote})
+		return p, nil
+	}
+}
+
+func readKustomization(fSys filesys.FileSystem, dir string) (*types.Kustomization, error) {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		path := filepath.Join(dir, name)
+		if !fSys.Exists(path) {
+			continue
+		}
+		b, err := fSys.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var k types.Kustomization
+		if err = k.Unmarshal(b); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		k.FixKustomization()
+		return &k, nil
+	}
+	return nil, fmt.Errorf("no kustomization file in %s", dir)
+}
+
+// formatGraph serializes the graph in the format given by
+// --emit-graph.
+func formatGraph(g *graph) ([]byte, error) {
+	if theFlags.emitGraph == graphFormatJson {
+		b, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString("digraph kustomize {\n")
+	for _, n := range g.Nodes {
+		label := n.ID
+		if n.Resources != nil {
+			label = fmt.Sprintf("%s\n%d resources", n.ID, len(n.Resources))
+		}
+		shape := "box"
+		switch n.Kind {
+		case nodeFile, nodeRemote:
+			shape = "note"
+		case nodeGenerator, nodeTransformer:
+			shape = "ellipse"
+		}
+		fmt.Fprintf(&buf, "  %q [label=%q, shape=%s];\n", n.ID, label, shape)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "  %q -> %q [label=%q];\n", e.From, e.To, e.Kind)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
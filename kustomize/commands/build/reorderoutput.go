@@ -5,21 +5,31 @@ package build
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/pflag"
 	flag "github.com/spf13/pflag"
 	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
 )
 
 const flagReorderOutputName = "reorder"
 
+// reorderCustomPrefix prefixes the file of custom sort options
+// in the value of the reorder flag.
+const reorderCustomPrefix = string(krusty.ReorderOptionCustom) + ":"
+
 func AddFlagReorderOutput(set *pflag.FlagSet) {
 	set.StringVar(
 		&theFlags.reorderOutput, flagReorderOutputName,
 		string(krusty.ReorderOptionLegacy),
 		"Reorder the resources just before output. Use '"+string(krusty.ReorderOptionLegacy)+"' to"+
 			" apply a legacy reordering (Namespaces first, Webhooks last, etc)."+
-			" Use '"+string(krusty.ReorderOptionNone)+"' to suppress a final reordering.")
+			" Use '"+string(krusty.ReorderOptionNone)+"' to suppress a final reordering."+
+			" Use '"+reorderCustomPrefix+"<file>' to refine the legacy reordering"+
+			" per the file, holding the fields of 'sortOptions.customOrder'.")
 }
 
 func validateFlagReorderOutput() error {
@@ -27,10 +37,15 @@ func validateFlagReorderOutput() error {
 	case string(krusty.ReorderOptionNone), string(krusty.ReorderOptionLegacy):
 		return nil
 	default:
+		if strings.HasPrefix(theFlags.reorderOutput, reorderCustomPrefix) &&
+			theFlags.reorderOutput != reorderCustomPrefix {
+			return nil
+		}
 		return fmt.Errorf(
 			"illegal flag value --%s %s; legal values: %v",
 			flagReorderOutputName, theFlags.reorderOutput,
-			[]string{string(krusty.ReorderOptionLegacy), string(krusty.ReorderOptionNone)})
+			[]string{string(krusty.ReorderOptionLegacy), string(krusty.ReorderOptionNone),
+				reorderCustomPrefix + "<file>"})
 	}
 }
 
@@ -45,6 +60,27 @@ func getFlagReorderOutput(flags *flag.FlagSet) krusty.ReorderOption {
 	case string(krusty.ReorderOptionLegacy):
 		return krusty.ReorderOptionLegacy
 	default:
+		if strings.HasPrefix(theFlags.reorderOutput, reorderCustomPrefix) {
+			return krusty.ReorderOptionCustom
+		}
 		return krusty.ReorderOptionUnspecified
 	}
 }
+
+// getFlagCustomSortOptions reads the custom sort options from the file
+// of the reorder flag, if any.
+func getFlagCustomSortOptions(fSys filesys.FileSystem) (*types.CustomSortOptions, error) {
+	path, found := strings.CutPrefix(theFlags.reorderOutput, reorderCustomPrefix)
+	if !found {
+		return nil, nil
+	}
+	b, err := fSys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read --%s file: %w", flagReorderOutputName, err)
+	}
+	var options types.CustomSortOptions
+	if err = yaml.UnmarshalStrict(b, &options); err != nil {
+		return nil, fmt.Errorf("invalid --%s file %s: %w", flagReorderOutputName, path, err)
+	}
+	return &options, nil
+}
@@ -4,11 +4,16 @@
 package build
 
 import (
+	"bytes"
+	"fmt"
 	"path/filepath"
 	"strings"
+	"text/template"
 
+	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 	"sigs.k8s.io/yaml"
 )
@@ -45,6 +50,100 @@ func (w Writer) WriteIndividualFiles(dirPath string, m resmap.ResMap) error {
 	return nil
 }
 
+// resourceNaming holds the fields of a resource that the naming
+// templates of WriteNamedFiles name its file by.
+type resourceNaming struct {
+	Group, Version, Kind, Name, Namespace string
+}
+
+// WriteNamedFiles writes each resource of m to the file in dirPath that
+// naming names, and a kustomization in dirPath listing the files in
+// order. The files listed by a previous kustomization that no resource
+// is written to anymore are removed, so that the directory always holds
+// exactly the resources of the build.
+func (w Writer) WriteNamedFiles(dirPath string, naming *template.Template, m resmap.ResMap) error {
+	index := konfig.DefaultKustomizationFileName()
+	fNames := make([]string, m.Size())
+	written := map[string]bool{}
+	for i, res := range m.Resources() {
+		gvk := res.GetGvk()
+		var buf bytes.Buffer
+		err := naming.Execute(&buf, resourceNaming{
+			Group:     gvk.Group,
+			Version:   gvk.Version,
+			Kind:      gvk.Kind,
+			Name:      res.GetName(),
+			Namespace: res.GetNamespace(),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to name file of %s: %w", res.CurId(), err)
+		}
+		// empty fields, e.g. the namespace of cluster scoped resources,
+		// collapse, and the path can't escape dirPath
+		fName := strings.TrimPrefix(filepath.Clean("/"+buf.String()), "/")
+		switch {
+		case fName == "":
+			return fmt.Errorf("empty file name for %s", res.CurId())
+		case fName == index:
+			return fmt.Errorf("file name of %s is that of the index %s", res.CurId(), index)
+		case written[fName]:
+			return fmt.Errorf("file name %s of %s is that of another resource", fName, res.CurId())
+		}
+		written[fName] = true
+		fNames[i] = filepath.ToSlash(fName)
+		if err = w.fSys.MkdirAll(filepath.Join(dirPath, filepath.Dir(fName))); err != nil {
+			return err
+		}
+		if err = w.write(dirPath, fName, res); err != nil {
+			return err
+		}
+	}
+	if err := w.removeUnlisted(dirPath, index, written); err != nil {
+		return err
+	}
+	yml, err := yaml.Marshal(&types.Kustomization{
+		TypeMeta: types.TypeMeta{
+			APIVersion: types.KustomizationVersion,
+			Kind:       types.KustomizationKind,
+		},
+		Resources: fNames,
+	})
+	if err != nil {
+		return err
+	}
+	return w.fSys.WriteFile(filepath.Join(dirPath, index), yml)
+}
+
+// removeUnlisted removes the files listed by the index in dirPath, if
+// any, that aren't written.
+func (w Writer) removeUnlisted(dirPath, index string, written map[string]bool) error {
+	path := filepath.Join(dirPath, index)
+	if !w.fSys.Exists(path) {
+		return nil
+	}
+	b, err := w.fSys.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var k types.Kustomization
+	if err = yaml.Unmarshal(b, &k); err != nil {
+		return fmt.Errorf("invalid index %s: %w", path, err)
+	}
+	for _, r := range k.Resources {
+		fName := filepath.FromSlash(r)
+		if written[fName] || !filepath.IsLocal(fName) {
+			continue
+		}
+		if !w.fSys.Exists(filepath.Join(dirPath, fName)) {
+			continue
+		}
+		if err = w.fSys.RemoveAll(filepath.Join(dirPath, fName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (w Writer) write(path, fName string, res *resource.Resource) error {
 	m, err := res.Map()
 	if err != nil {
@@ -0,0 +1,73 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/remotecache"
+)
+
+// NewCmdCache makes a new cache command.
+func NewCmdCache(w io.Writer) *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Commands for managing the cache of remote bases and files",
+		Long: `Commands for managing the cache of remote bases and files.
+The cache is filled by builds run with --cache-ttl, and
+read by builds run with --cache-ttl or --offline.
+`,
+		Example: `kustomize cache list`,
+	}
+	cacheCmd.AddCommand(newCmdList(w))
+	cacheCmd.AddCommand(newCmdClean())
+	return cacheCmd
+}
+
+func newCmdList(w io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "Lists the cached remote bases and files",
+		Example:      `kustomize cache list`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := remotecache.New(0, false)
+			if err != nil {
+				return err
+			}
+			entries, err := c.List()
+			if err != nil {
+				return err
+			}
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "URL\tREF\tFETCHED")
+			for _, e := range entries {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", e.URL, e.Ref, e.Fetched.Format(time.RFC3339))
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func newCmdClean() *cobra.Command {
+	return &cobra.Command{
+		Use:          "clean",
+		Short:        "Removes all cached remote bases and files",
+		Example:      `kustomize cache clean`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := remotecache.New(0, false)
+			if err != nil {
+				return err
+			}
+			return c.Clean()
+		},
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/remotecache"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/cache"
+)
+
+func TestCacheListAndClean(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	c, err := remotecache.New(0, false)
+	require.NoError(t, err)
+	_, err = c.StoreFile("https://example.com/deployment.yaml", []byte("kind: Deployment\n"))
+	require.NoError(t, err)
+
+	buffy := new(bytes.Buffer)
+	cmd := cache.NewCmdCache(buffy)
+	cmd.SetArgs([]string{"list"})
+	require.NoError(t, cmd.Execute())
+	lines := strings.Split(strings.TrimSpace(buffy.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, []string{"URL", "REF", "FETCHED"}, strings.Fields(lines[0]))
+	require.Equal(t, "https://example.com/deployment.yaml", strings.Fields(lines[1])[0])
+
+	cmd.SetArgs([]string{"clean"})
+	require.NoError(t, cmd.Execute())
+	entries, err := c.List()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
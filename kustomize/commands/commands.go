@@ -15,7 +15,10 @@ import (
 	"sigs.k8s.io/kustomize/cmd/config/completion"
 	"sigs.k8s.io/kustomize/cmd/config/configcobra"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/build"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/cache"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/config"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/create"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/diff"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/edit"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/localize"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/openapi"
@@ -55,6 +58,9 @@ See https://sigs.k8s.io/kustomize
 		version.NewCmdVersion(stdOut),
 		openapi.NewCmdOpenAPI(stdOut),
 		localize.NewCmdLocalize(fSys),
+		cache.NewCmdCache(stdOut),
+		config.NewCmdConfig(stdOut),
+		diff.NewCmdDiff(fSys, stdOut),
 	)
 	configcobra.AddCommands(c, konfig.ProgramName)
 
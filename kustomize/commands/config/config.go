@@ -0,0 +1,52 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+)
+
+// NewCmdConfig makes a new config command.
+func NewCmdConfig(w io.Writer) *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Commands for the transformer configurations of kustomizations",
+		Long: `Commands for the transformer configurations of kustomizations.
+The files listed in the configurations field of a kustomization add
+field specs to the builtin configuration; the fields they list under
+override replace the builtin field specs instead.
+`,
+		Example: `kustomize config print-default namereference`,
+	}
+	configCmd.AddCommand(newCmdPrintDefault(w))
+	return configCmd
+}
+
+func newCmdPrintDefault(w io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "print-default [NAME]...",
+		Short: "Prints the builtin configuration of the named transformers, or of all of them",
+		Long: fmt.Sprintf(`Prints the builtin configuration of the named transformers, or of all
+of them, in the format of the files of the configurations field.
+Legal names: %s
+`, strings.Join(konfig.DefaultFieldSpecNames(), ", ")),
+		Example: `# Print the field specs of the name references, e.g. to extend
+# them to custom resources
+  kustomize config print-default namereference`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := konfig.DefaultFieldSpecs(args...)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(w, strings.TrimSpace(out))
+			return err
+		},
+	}
+}
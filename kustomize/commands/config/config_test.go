@@ -0,0 +1,36 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package config_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/config"
+)
+
+func TestPrintDefault(t *testing.T) {
+	buffy := new(bytes.Buffer)
+	cmd := config.NewCmdConfig(buffy)
+	cmd.SetArgs([]string{"print-default", "namereference", "commonLabels"})
+	require.NoError(t, cmd.Execute())
+	require.True(t, strings.HasPrefix(buffy.String(), "nameReference:\n- kind: Deployment\n"))
+	require.Contains(t, buffy.String(), "\ncommonLabels:\n- path: spec/selector\n")
+	require.NotContains(t, buffy.String(), "namePrefix:")
+
+	buffy.Reset()
+	cmd.SetArgs([]string{"print-default"})
+	require.NoError(t, cmd.Execute())
+	for _, field := range []string{"namePrefix:", "nameReference:", "commonLabels:", "scheduling:"} {
+		require.Contains(t, buffy.String(), field)
+	}
+
+	cmd.SetArgs([]string{"print-default", "labels"})
+	require.EqualError(t, cmd.Execute(),
+		`no builtin transformer configuration named "labels"; legal names: `+
+			`[commonannotations commonlabels images nameprefix namereference namespace `+
+			`namesuffix replicas resourceoverrides scheduling templatelabels varreference]`)
+}
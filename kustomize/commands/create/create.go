@@ -13,6 +13,7 @@ import (
 	"sigs.k8s.io/kustomize/api/konfig"
 	ldrhelper "sigs.k8s.io/kustomize/api/pkg/loader"
 	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/util"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -28,6 +29,16 @@ type createFlags struct {
 	detectResources bool
 	detectRecursive bool
 	path            string
+	fromCluster     bool
+	kinds           string
+	kubeconfig      string
+	kubectlCommand  string
+	helmChart       string
+	helmRepo        string
+	helmVersion     string
+	helmValues      string
+	helmReleaseName string
+	helmCommand     string
 }
 
 // NewCmdCreate returns an instance of 'create' subcommand.
@@ -47,8 +58,17 @@ func NewCmdCreate(fSys filesys.FileSystem, rf *resource.Factory) *cobra.Command
 
 	# Create a new kustomization with multiple resources and fields set.
 	kustomize create --resources deployment.yaml,service.yaml,../base --namespace staging --nameprefix acme-
+
+	# Create a new kustomization from the deployments and services of the namespace 'foo' in the cluster.
+	kustomize create --from-cluster --namespace foo --kinds deployments,services
+
+	# Eject the chart 'minecraft' rendered with values.yaml into a base and an overlay of it.
+	kustomize create --from-helm-chart minecraft --helm-repo https://itzg.github.io/minecraft-server-charts --helm-values values.yaml
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.helmChart != "" {
+				return createFromHelm(opts, fSys)
+			}
 			return runCreate(opts, fSys, rf)
 		},
 	}
@@ -92,6 +112,58 @@ func NewCmdCreate(fSys filesys.FileSystem, rf *resource.Factory) *cobra.Command
 		"recursive",
 		false,
 		"Enable recursive directory searching for resource auto-detection.")
+	c.Flags().BoolVar(
+		&opts.fromCluster,
+		"from-cluster",
+		false,
+		"Write the live objects of --kinds in --namespace, without their server-managed fields, "+
+			"to resource files added to the kustomization file.")
+	c.Flags().StringVar(
+		&opts.kinds,
+		"kinds",
+		"",
+		"Comma-separated kinds of the objects read with --from-cluster, e.g. deployments,services.")
+	c.Flags().StringVar(
+		&opts.kubeconfig,
+		"kubeconfig",
+		"",
+		"kubeconfig of the cluster read with --from-cluster; kubectl's default if empty.")
+	c.Flags().StringVar(
+		&opts.kubectlCommand,
+		"kubectl-command",
+		"kubectl",
+		"kubectl command (path to executable) used by --from-cluster.")
+	c.Flags().StringVar(
+		&opts.helmChart,
+		"from-helm-chart",
+		"",
+		"Render this helm chart into a base with a file per resource, in a directory per kind, "+
+			"and an overlay of it in overlays/<release name> on which the other flags are set.")
+	c.Flags().StringVar(
+		&opts.helmRepo,
+		"helm-repo",
+		"",
+		"Repository of the chart rendered with --from-helm-chart.")
+	c.Flags().StringVar(
+		&opts.helmVersion,
+		"helm-version",
+		"",
+		"Version of the chart rendered with --from-helm-chart.")
+	c.Flags().StringVar(
+		&opts.helmValues,
+		"helm-values",
+		"",
+		"Comma-separated values files of the chart rendered with --from-helm-chart.")
+	c.Flags().StringVar(
+		&opts.helmReleaseName,
+		"helm-release-name",
+		"",
+		"Release name of the chart rendered with --from-helm-chart; the chart name if empty.")
+	c.Flags().StringVar(
+		&opts.helmCommand,
+		"helm-command",
+		"helm",
+		"helm command (path to executable) used by --from-helm-chart.")
 	return c
 }
 
@@ -107,6 +179,13 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, rf *resource.Factory)
 	if _, err = kustfile.NewKustomizationFile(fSys); err == nil {
 		return fmt.Errorf("kustomization file already exists")
 	}
+	if opts.fromCluster {
+		created, err := createFromCluster(opts, fSys)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, created...)
+	}
 	if opts.detectResources {
 		detected, err := detectResources(fSys, rf, opts.path, opts.detectRecursive)
 		if err != nil {
@@ -133,6 +212,14 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, rf *resource.Factory)
 		return err
 	}
 	m.Resources = resources
+	if err = setFields(m, opts); err != nil {
+		return err
+	}
+	return mf.Write(m)
+}
+
+// setFields sets the fields of m given by the flags.
+func setFields(m *types.Kustomization, opts createFlags) error {
 	m.Namespace = opts.namespace
 	m.NamePrefix = opts.prefix
 	m.NameSuffix = opts.suffix
@@ -146,6 +233,31 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, rf *resource.Factory)
 		return err
 	}
 	m.CommonLabels = labels
+	return nil
+}
+
+// writeKustomization creates a kustomization file in dir with the
+// fields set by fn.
+func writeKustomization(fSys filesys.FileSystem, dir string, fn func(*types.Kustomization) error) error {
+	if err := fSys.MkdirAll(dir); err != nil {
+		return err
+	}
+	f, err := fSys.Create(filepath.Join(dir, konfig.DefaultKustomizationFileName()))
+	if err != nil {
+		return err
+	}
+	f.Close()
+	mf, err := kustfile.NewKustomizationFileInDir(fSys, dir)
+	if err != nil {
+		return err
+	}
+	m, err := mf.Read()
+	if err != nil {
+		return err
+	}
+	if err = fn(m); err != nil {
+		return err
+	}
 	return mf.Write(m)
 }
 
@@ -4,10 +4,13 @@
 package create
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/api/provider"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
@@ -203,3 +206,182 @@ func TestCreateWithDetectRecursive(t *testing.T) {
 		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
 	}
 }
+
+// fakeKubectl prints a list of live objects.
+const fakeKubectl = `#!/bin/sh
+cat <<EOF
+apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    annotations:
+      deployment.kubernetes.io/revision: "3"
+    creationTimestamp: "2023-01-01T00:00:00Z"
+    generation: 3
+    labels:
+      app: web
+    managedFields:
+    - manager: kubectl
+    name: web
+    namespace: foo
+    resourceVersion: "1234"
+    uid: 0d9b6a4e
+  spec:
+    replicas: 2
+  status:
+    replicas: 2
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+    namespace: foo
+  spec:
+    clusterIP: 10.0.0.1
+    clusterIPs:
+    - 10.0.0.1
+    ports:
+    - port: 80
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: web-abc
+    namespace: foo
+    ownerReferences:
+    - kind: ReplicaSet
+      name: web
+EOF
+`
+
+func TestCreateFromCluster(t *testing.T) {
+	kubectl := filepath.Join(t.TempDir(), "kubectl")
+	require.NoError(t, os.WriteFile(kubectl, []byte(fakeKubectl), 0o700))
+	fSys := filesys.MakeEmptyDirInMemory()
+	opts := createFlags{
+		fromCluster:    true,
+		namespace:      "foo",
+		kinds:          "deployments,services,pods",
+		kubectlCommand: kubectl,
+	}
+	require.NoError(t, runCreate(opts, fSys, factory))
+	m := readKustomizationFS(t, fSys)
+	assert.Equal(t, []string{"deployment-web.yaml", "service-web.yaml"}, m.Resources)
+	assert.Equal(t, "foo", m.Namespace)
+	content, err := fSys.ReadFile("deployment-web.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: web
+  name: web
+spec:
+  replicas: 2
+`, string(content))
+	content, err = fSys.ReadFile("service-web.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`, string(content))
+}
+
+func TestCreateFromClusterNoKinds(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	err := runCreate(createFlags{fromCluster: true}, fSys, factory)
+	assert.EqualError(t, err, "--from-cluster requires --kinds")
+}
+
+// fakeHelm prints a rendered chart.
+const fakeHelm = `#!/bin/sh
+cat <<EOF
+---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    helm.sh/chart: web-1.0.0
+    app.kubernetes.io/managed-by: Helm
+spec:
+  ports:
+  - port: 80
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/name: web
+    helm.sh/chart: web-1.0.0
+spec:
+  template:
+    metadata:
+      labels:
+        helm.sh/chart: web-1.0.0
+EOF
+`
+
+func TestCreateFromHelm(t *testing.T) {
+	helm := filepath.Join(t.TempDir(), "helm")
+	require.NoError(t, os.WriteFile(helm, []byte(fakeHelm), 0o700))
+	fSys := filesys.MakeEmptyDirInMemory()
+	cmd := NewCmdCreate(fSys, factory)
+	require.NoError(t, cmd.Flags().Set("from-helm-chart", "charts/web"))
+	require.NoError(t, cmd.Flags().Set("helm-command", helm))
+	require.NoError(t, cmd.Flags().Set("namespace", "prod"))
+	require.NoError(t, cmd.RunE(cmd, []string{}))
+
+	for path, expected := range map[string]string{
+		"base/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment/web.yaml
+- service/web.yaml
+`,
+		"base/deployment/web.yaml": `# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/name: web
+spec:
+  template:
+    metadata: {}
+`,
+		"base/service/web.yaml": `# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`,
+		"overlays/web/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../../base
+namespace: prod
+`,
+	} {
+		content, err := fSys.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content), path)
+	}
+}
+
+func TestCreateFromHelmExistingBase(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	require.NoError(t, fSys.MkdirAll("base"))
+	err := createFromHelm(createFlags{helmChart: "web"}, fSys)
+	assert.EqualError(t, err, "base already exists")
+}
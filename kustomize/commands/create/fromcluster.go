@@ -0,0 +1,106 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package create
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// serverFields are the fields the API server sets on live objects.
+var serverFields = [][]string{
+	{"status"},
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "uid"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "generation"},
+	{"metadata", "selfLink"},
+	{"metadata", "namespace"},
+	{"spec", "clusterIP"},
+	{"spec", "clusterIPs"},
+}
+
+// serverAnnotations are the annotations clients and controllers set
+// on live objects.
+var serverAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+}
+
+// createFromCluster reads the objects of the given kinds from the
+// cluster with kubectl, writes each without its server-managed fields
+// to a file named after its kind and name, and returns the files.
+// Objects owned by other objects are skipped, their owners creating
+// them.
+func createFromCluster(opts createFlags, fSys filesys.FileSystem) ([]string, error) {
+	if opts.kinds == "" {
+		return nil, fmt.Errorf("--from-cluster requires --kinds")
+	}
+	args := []string{"get", opts.kinds, "--output", "yaml"}
+	if opts.namespace != "" {
+		args = append(args, "--namespace", opts.namespace)
+	}
+	if opts.kubeconfig != "" {
+		args = append(args, "--kubeconfig", opts.kubeconfig)
+	}
+	cmd := exec.Command(opts.kubectlCommand, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read objects from the cluster: %w\n%s",
+			err, strings.TrimSpace(stderr.String()))
+	}
+	nodes, err := (&kio.ByteReader{
+		Reader:                bytes.NewReader(out),
+		OmitReaderAnnotations: true,
+	}).Read()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, rn := range nodes {
+		if owners, _ := rn.Pipe(yaml.Lookup("metadata", "ownerReferences")); owners != nil {
+			continue
+		}
+		if err = stripServerFields(rn); err != nil {
+			return nil, err
+		}
+		path := fmt.Sprintf("%s-%s.yaml", strings.ToLower(rn.GetKind()), rn.GetName())
+		if fSys.Exists(path) {
+			return nil, fmt.Errorf("file %s already exists", path)
+		}
+		s, err := rn.String()
+		if err != nil {
+			return nil, err
+		}
+		if err = fSys.WriteFile(path, []byte(s)); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func stripServerFields(rn *yaml.RNode) error {
+	for _, path := range serverFields {
+		last := len(path) - 1
+		if _, err := rn.Pipe(yaml.Lookup(path[:last]...), yaml.Clear(path[last])); err != nil {
+			return err
+		}
+	}
+	for _, a := range serverAnnotations {
+		if err := rn.PipeE(yaml.ClearAnnotation(a)); err != nil {
+			return err
+		}
+	}
+	return yaml.ClearEmptyAnnotations(rn)
+}
@@ -0,0 +1,148 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package create
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	helmBaseDir     = "base"
+	helmOverlaysDir = "overlays"
+)
+
+// helmLabels are the labels helm charts conventionally set to track
+// the releases managing their resources.
+var helmLabels = []string{
+	"helm.sh/chart",
+	"app.kubernetes.io/managed-by",
+}
+
+// createFromHelm renders the chart with helm and writes a base holding
+// one file per resource, in a directory per kind, and an overlay of it
+// named after the release, on which the other flags are set.
+func createFromHelm(opts createFlags, fSys filesys.FileSystem) error {
+	release := opts.helmReleaseName
+	if release == "" {
+		release = path.Base(opts.helmChart)
+	}
+	overlay := filepath.Join(helmOverlaysDir, release)
+	for _, dir := range []string{helmBaseDir, overlay} {
+		if fSys.Exists(dir) {
+			return fmt.Errorf("%s already exists", dir)
+		}
+	}
+	nodes, err := renderChart(opts, release)
+	if err != nil {
+		return err
+	}
+	var resources []string
+	for _, rn := range nodes {
+		if rn.GetKind() == "" {
+			continue
+		}
+		if err = stripHelmLabels(rn); err != nil {
+			return err
+		}
+		file := filepath.Join(strings.ToLower(rn.GetKind()), rn.GetName()+".yaml")
+		if kustfile.StringInSlice(file, resources) {
+			return fmt.Errorf("the chart renders more than one %s named %s", rn.GetKind(), rn.GetName())
+		}
+		s, err := rn.String()
+		if err != nil {
+			return err
+		}
+		if err = fSys.MkdirAll(filepath.Join(helmBaseDir, filepath.Dir(file))); err != nil {
+			return err
+		}
+		if err = fSys.WriteFile(filepath.Join(helmBaseDir, file), []byte(s)); err != nil {
+			return err
+		}
+		resources = append(resources, file)
+	}
+	sort.Strings(resources)
+	if err = writeKustomization(fSys, helmBaseDir, func(m *types.Kustomization) error {
+		m.Resources = resources
+		return nil
+	}); err != nil {
+		return err
+	}
+	return writeKustomization(fSys, overlay, func(m *types.Kustomization) error {
+		m.Resources = []string{filepath.Join("..", "..", helmBaseDir)}
+		return setFields(m, opts)
+	})
+}
+
+// renderChart runs helm template on the chart and returns the
+// rendered resources.
+func renderChart(opts createFlags, release string) ([]*yaml.RNode, error) {
+	args := []string{"template", release, opts.helmChart}
+	if opts.helmRepo != "" {
+		args = append(args, "--repo", opts.helmRepo)
+	}
+	if opts.helmVersion != "" {
+		args = append(args, "--version", opts.helmVersion)
+	}
+	if opts.namespace != "" {
+		args = append(args, "--namespace", opts.namespace)
+	}
+	if opts.helmValues != "" {
+		for _, v := range strings.Split(opts.helmValues, ",") {
+			args = append(args, "--values", v)
+		}
+	}
+	cmd := exec.Command(opts.helmCommand, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to render chart %s: %w\n%s",
+			opts.helmChart, err, strings.TrimSpace(stderr.String()))
+	}
+	return (&kio.ByteReader{
+		Reader:                bytes.NewReader(out),
+		OmitReaderAnnotations: true,
+	}).Read()
+}
+
+// stripHelmLabels removes the helm labels from the resource and
+// from its pod template, if any.
+func stripHelmLabels(rn *yaml.RNode) error {
+	for _, labelsPath := range [][]string{
+		{"metadata", "labels"},
+		{"spec", "template", "metadata", "labels"},
+	} {
+		labels, err := rn.Pipe(yaml.Lookup(labelsPath...))
+		if err != nil {
+			return err
+		}
+		if labels == nil {
+			continue
+		}
+		for _, l := range helmLabels {
+			if _, err = labels.Pipe(yaml.Clear(l)); err != nil {
+				return err
+			}
+		}
+		if len(labels.Content()) == 0 {
+			last := len(labelsPath) - 1
+			if _, err = rn.Pipe(yaml.Lookup(labelsPath[:last]...), yaml.Clear(labelsPath[last])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
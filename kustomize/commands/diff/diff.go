@@ -0,0 +1,126 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+type flags struct {
+	against string
+}
+
+// NewCmdDiff returns a new diff command.
+func NewCmdDiff(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f flags
+	cmd := &cobra.Command{
+		Use:   "diff OVERLAY_A [OVERLAY_B]",
+		Short: "Builds two kustomizations and prints the differences between their resources",
+		Long: `Builds two kustomizations and prints, per resource, the resources
+added and removed, and the fields added, removed and changed.
+
+Resources are matched by apiVersion, kind, namespace and name.
+With --against, the kustomization is compared with itself at
+the given git ref.
+`,
+		Example: `
+# Compare the staging and production overlays
+kustomize diff overlays/staging overlays/production
+
+# Compare the production overlay with its state on main
+kustomize diff overlays/production --against main
+`,
+		SilenceUsage: true,
+		Args:         cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if f.against == "" && len(args) != 2 {
+				return errors.Errorf("specify two kustomizations to compare, or one and --against")
+			}
+			if f.against != "" && len(args) != 1 {
+				return errors.Errorf("specify one kustomization to compare with --against")
+			}
+			var before, after resmap.ResMap
+			var err error
+			if f.against != "" {
+				before, err = buildAtRef(args[0], f.against)
+			} else {
+				before, err = build(fSys, args[0])
+			}
+			if err != nil {
+				return err
+			}
+			after, err = build(fSys, args[len(args)-1])
+			if err != nil {
+				return err
+			}
+			return Write(w, Diff(before, after))
+		},
+	}
+	cmd.Flags().StringVar(&f.against, "against", "",
+		"Compare the kustomization with itself at this git ref, e.g. main.")
+	return cmd
+}
+
+func build(fSys filesys.FileSystem, path string) (resmap.ResMap, error) {
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, path)
+	return m, errors.WrapPrefixf(err, "unable to build %s", path)
+}
+
+// buildAtRef builds the kustomization at path as of the git
+// ref, from a clone sharing the objects of path's repository.
+func buildAtRef(path, ref string) (resmap.ResMap, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	top, err := git(abs, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	// Resolve symlinks on both sides, e.g. macOS' /var.
+	if abs, err = filepath.EvalSymlinks(abs); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if top, err = filepath.EvalSymlinks(top); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	rel, err := filepath.Rel(top, abs)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	dir, err := os.MkdirTemp("", "kustomize-diff-")
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err = git(top, "clone", "--quiet", "--shared", "--no-checkout", top, dir); err != nil {
+		return nil, err
+	}
+	if _, err = git(dir, "checkout", "--quiet", "--detach", ref); err != nil {
+		return nil, err
+	}
+	m, err := build(filesys.MakeFsOnDisk(), filepath.Join(dir, rel))
+	return m, errors.WrapPrefixf(err, "at %s", ref)
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "git %s: %s",
+			strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/diff"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const base = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+      - name: sidecar
+        image: sidecar:v1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`
+
+func writeOverlays(t *testing.T, fSys filesys.FileSystem) {
+	t.Helper()
+	require.NoError(t, fSys.WriteFile("base/kustomization.yaml", []byte("resources:\n- resources.yaml\n")))
+	require.NoError(t, fSys.WriteFile("base/resources.yaml", []byte(base)))
+	require.NoError(t, fSys.WriteFile("staging/kustomization.yaml", []byte(`
+namespace: staging
+resources:
+- ../base
+`)))
+	require.NoError(t, fSys.WriteFile("production/kustomization.yaml", []byte(`
+namespace: staging
+resources:
+- ../base
+- configmap.yaml
+patches:
+- patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: app
+    spec:
+      replicas: 3
+      template:
+        metadata:
+          labels:
+            tier: web
+        spec:
+          containers:
+          - name: app
+            image: app:v2
+          - name: sidecar
+            $patch: delete
+- patch: |-
+    $patch: delete
+    apiVersion: v1
+    kind: Service
+    metadata:
+      name: app
+`)))
+	require.NoError(t, fSys.WriteFile("production/configmap.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`)))
+}
+
+func TestDiff(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeOverlays(t, fSys)
+	buffy := new(bytes.Buffer)
+	cmd := diff.NewCmdDiff(fSys, buffy)
+	require.NoError(t, cmd.RunE(cmd, []string{"staging", "production"}))
+	require.Equal(t, `~ apps/v1 Deployment staging/app
+    ~ spec.replicas: 1 -> 3
+    + spec.template.metadata: {"labels":{"tier":"web"}}
+    ~ spec.template.spec.containers[name=app].image: "app:v1" -> "app:v2"
+    - spec.template.spec.containers[name=sidecar]: {"image":"sidecar:v1","name":"sidecar"}
++ v1 ConfigMap staging/settings
+- v1 Service staging/app
+`, buffy.String())
+}
+
+func TestDiffNoDifferences(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeOverlays(t, fSys)
+	buffy := new(bytes.Buffer)
+	cmd := diff.NewCmdDiff(fSys, buffy)
+	require.NoError(t, cmd.RunE(cmd, []string{"staging", "staging"}))
+	require.Equal(t, "no differences\n", buffy.String())
+}
+
+func TestDiffAgainstGitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("skipping: " + err.Error())
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	fSys := filesys.MakeFsOnDisk()
+	run("init", "--quiet")
+	require.NoError(t, fSys.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(`
+resources:
+- configmap.yaml
+`)))
+	require.NoError(t, fSys.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  level: info
+`)))
+	run("add", ".")
+	run("commit", "--quiet", "-m", "initial")
+	run("tag", "v1")
+	require.NoError(t, fSys.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  level: debug
+`)))
+
+	buffy := new(bytes.Buffer)
+	cmd := diff.NewCmdDiff(fSys, buffy)
+	require.NoError(t, cmd.Flags().Set("against", "v1"))
+	require.NoError(t, cmd.RunE(cmd, []string{dir}))
+	require.Equal(t, `~ v1 ConfigMap settings
+    ~ data.level: "info" -> "debug"
+`, buffy.String())
+}
+
+func TestDiffArgs(t *testing.T) {
+	cmd := diff.NewCmdDiff(filesys.MakeFsInMemory(), new(bytes.Buffer))
+	err := cmd.RunE(cmd, []string{"staging"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "specify two kustomizations to compare")
+}
@@ -0,0 +1,246 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// Change kinds, as prefixed to the printed resources and fields.
+const (
+	Added   = "+"
+	Removed = "-"
+	Changed = "~"
+)
+
+// FieldChange is a field added, removed or changed in a resource.
+type FieldChange struct {
+	Kind   string
+	Path   string
+	Before interface{}
+	After  interface{}
+}
+
+// ResourceDiff is a resource added, removed or changed between
+// two builds, with the changed fields of changed resources.
+type ResourceDiff struct {
+	Kind   string
+	ID     string
+	Fields []FieldChange
+}
+
+// Diff compares the resources of two builds, in the order of
+// the resources of after, followed by those removed from before.
+func Diff(before, after resmap.ResMap) []ResourceDiff {
+	old := map[string]*resource.Resource{}
+	for _, r := range before.Resources() {
+		old[resourceID(r)] = r
+	}
+	var diffs []ResourceDiff
+	seen := map[string]bool{}
+	for _, r := range after.Resources() {
+		id := resourceID(r)
+		seen[id] = true
+		o, found := old[id]
+		if !found {
+			diffs = append(diffs, ResourceDiff{Kind: Added, ID: id})
+			continue
+		}
+		var fields []FieldChange
+		diffValues("", mapOf(o), mapOf(r), &fields)
+		if len(fields) > 0 {
+			diffs = append(diffs, ResourceDiff{Kind: Changed, ID: id, Fields: fields})
+		}
+	}
+	for _, r := range before.Resources() {
+		if id := resourceID(r); !seen[id] {
+			diffs = append(diffs, ResourceDiff{Kind: Removed, ID: id})
+		}
+	}
+	return diffs
+}
+
+// Write prints the diffs, one resource per line, followed by
+// the indented field changes of changed resources.
+func Write(w io.Writer, diffs []ResourceDiff) error {
+	if len(diffs) == 0 {
+		_, err := fmt.Fprintln(w, "no differences")
+		return err
+	}
+	for _, d := range diffs {
+		if _, err := fmt.Fprintf(w, "%s %s\n", d.Kind, d.ID); err != nil {
+			return err
+		}
+		for _, f := range d.Fields {
+			var err error
+			switch f.Kind {
+			case Added:
+				_, err = fmt.Fprintf(w, "    + %s: %s\n", f.Path, format(f.After))
+			case Removed:
+				_, err = fmt.Fprintf(w, "    - %s: %s\n", f.Path, format(f.Before))
+			default:
+				_, err = fmt.Fprintf(w, "    ~ %s: %s -> %s\n", f.Path, format(f.Before), format(f.After))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resourceID identifies a resource across builds, e.g.
+// apps/v1 Deployment prod/app
+func resourceID(r *resource.Resource) string {
+	name := r.GetName()
+	if ns := r.GetNamespace(); ns != "" {
+		name = ns + "/" + name
+	}
+	return r.GetApiVersion() + " " + r.GetKind() + " " + name
+}
+
+func mapOf(r *resource.Resource) map[string]interface{} {
+	m, err := r.Map()
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+func diffValues(path string, before, after interface{}, out *[]FieldChange) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			diffMaps(path, b, a, out)
+			return
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok {
+			diffLists(path, b, a, out)
+			return
+		}
+	}
+	*out = append(*out, FieldChange{Kind: Changed, Path: path, Before: before, After: after})
+}
+
+func diffMaps(path string, before, after map[string]interface{}, out *[]FieldChange) {
+	keys := map[string]bool{}
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		p := k
+		if path != "" {
+			p = path + "." + k
+		}
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+		switch {
+		case !inBefore:
+			*out = append(*out, FieldChange{Kind: Added, Path: p, After: a})
+		case !inAfter:
+			*out = append(*out, FieldChange{Kind: Removed, Path: p, Before: b})
+		default:
+			diffValues(p, b, a, out)
+		}
+	}
+}
+
+// diffLists matches the elements of lists of named maps, like
+// containers, by name, and the elements of other lists by index.
+func diffLists(path string, before, after []interface{}, out *[]FieldChange) {
+	bNames, bOk := namedElements(before)
+	aNames, aOk := namedElements(after)
+	if !bOk || !aOk {
+		for i := 0; i < len(before) || i < len(after); i++ {
+			p := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(before):
+				*out = append(*out, FieldChange{Kind: Added, Path: p, After: after[i]})
+			case i >= len(after):
+				*out = append(*out, FieldChange{Kind: Removed, Path: p, Before: before[i]})
+			default:
+				diffValues(p, before[i], after[i], out)
+			}
+		}
+		return
+	}
+	for _, e := range before {
+		name := nameOf(e)
+		p := path + "[name=" + name + "]"
+		if a, found := aNames[name]; found {
+			diffValues(p, e, a, out)
+		} else {
+			*out = append(*out, FieldChange{Kind: Removed, Path: p, Before: e})
+		}
+	}
+	for _, e := range after {
+		name := nameOf(e)
+		if _, found := bNames[name]; !found {
+			p := path + "[name=" + name + "]"
+			*out = append(*out, FieldChange{Kind: Added, Path: p, After: e})
+		}
+	}
+}
+
+// namedElements indexes a list whose elements are maps with
+// distinct string names, returning false for any other list.
+func namedElements(list []interface{}) (map[string]interface{}, bool) {
+	if len(list) == 0 {
+		return map[string]interface{}{}, true
+	}
+	named := map[string]interface{}{}
+	for _, e := range list {
+		name := nameOf(e)
+		if name == "" {
+			return nil, false
+		}
+		if _, dup := named[name]; dup {
+			return nil, false
+		}
+		named[name] = e
+	}
+	return named, true
+}
+
+func nameOf(e interface{}) string {
+	m, ok := e.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := m["name"].(string)
+	return name
+}
+
+func format(v interface{}) string {
+	switch x := v.(type) {
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(x)
+		if err == nil {
+			return string(b)
+		}
+	case string:
+		return strconv.Quote(x)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -5,9 +5,16 @@ package add
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"os/exec"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -15,6 +22,27 @@ import (
 
 type addPatchOptions struct {
 	Patch types.Patch
+
+	// fromStdin reads the content of the patch from stdin.
+	fromStdin bool
+
+	// edit opens an editor on a patch skeleton for the target.
+	edit bool
+}
+
+// editFile opens the user's editor on the file at path and
+// waits for it to exit.
+var editFile = func(path string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
 }
 
 // newCmdAddPatch adds the name of a file containing a patch to the kustomization file.
@@ -31,19 +59,47 @@ Each item may:
  - be either a strategic merge patch, or a JSON patch
  - be either a file, or an inline string
  - target a single resource or multiple resources
+
+With --from-stdin, the content of the patch is read from stdin.
+With --edit, the kustomization is built to find the one resource
+selected by the target flags, and $EDITOR is opened on a strategic
+merge patch skeleton for it. The edited patch is written to
+patch-<kind>-<name>.yaml and added by path.
 `,
 		Example: `
-		add patch --path {filepath} --group {target group name} --version {target version}`,
+		add patch --path {filepath} --group {target group name} --version {target version}
+
+		cat patch.yaml | add patch --from-stdin --kind Deployment
+
+		add patch --edit --kind Deployment --name {target name}`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate()
 			if err != nil {
 				return err
 			}
+			if o.fromStdin {
+				b, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				o.Patch.Patch = string(b)
+				if strings.TrimSpace(o.Patch.Patch) == "" {
+					return errors.New("no patch read from stdin")
+				}
+			}
+			if o.edit {
+				if err = o.editPatch(fSys); err != nil {
+					return err
+				}
+			}
 			return o.RunAddPatch(fSys)
 		},
 	}
 	cmd.Flags().StringVar(&o.Patch.Path, "path", "", "Path to the patch file. Cannot be used with --patch at the same time.")
 	cmd.Flags().StringVar(&o.Patch.Patch, "patch", "", "Literal string of patch content. Cannot be used with --path at the same time.")
+	cmd.Flags().BoolVar(&o.fromStdin, "from-stdin", false, "Read the patch content from stdin.")
+	cmd.Flags().BoolVar(&o.edit, "edit", false,
+		"Edit a patch skeleton for the resource selected by the target flags in $EDITOR.")
 	cmd.Flags().StringVar(&o.Patch.Target.Group, "group", "", "API group in patch target")
 	cmd.Flags().StringVar(&o.Patch.Target.Version, "version", "", "API version in patch target")
 	cmd.Flags().StringVar(&o.Patch.Target.Kind, "kind", "", "Resource kind in patch target")
@@ -57,15 +113,88 @@ Each item may:
 
 // Validate validates addPatch command.
 func (o *addPatchOptions) Validate() error {
-	if o.Patch.Patch != "" && o.Patch.Path != "" {
-		return errors.New("patch and path can't be set at the same time")
+	n := 0
+	for _, set := range []bool{o.Patch.Patch != "", o.Patch.Path != "", o.fromStdin, o.edit} {
+		if set {
+			n++
+		}
+	}
+	if n > 1 {
+		return errors.New("only one of patch, path, from-stdin and edit can be set")
 	}
-	if o.Patch.Patch == "" && o.Patch.Path == "" {
+	if n == 0 {
 		return errors.New("must provide either patch or path")
 	}
 	return nil
 }
 
+// editPatch builds the kustomization, lets the user edit a patch
+// skeleton for the resource selected by the target and writes it
+// to a file named after the resource.
+func (o *addPatchOptions) editPatch(fSys filesys.FileSystem) error {
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, ".")
+	if err != nil {
+		return fmt.Errorf("unable to build the kustomization: %w", err)
+	}
+	selected, err := m.Select(*o.Patch.Target)
+	if err != nil {
+		return err
+	}
+	if len(selected) != 1 {
+		return fmt.Errorf("the target selects %d resources, it must select exactly one", len(selected))
+	}
+	r := selected[0]
+	path := fmt.Sprintf("patch-%s-%s.yaml", strings.ToLower(r.GetKind()), r.GetName())
+	if fSys.Exists(path) {
+		return fmt.Errorf("patch file %s already exists", path)
+	}
+	content, err := editSkeleton(r)
+	if err != nil {
+		return err
+	}
+	if err = fSys.WriteFile(path, content); err != nil {
+		return err
+	}
+	// The patch names its resource, so it needs no target.
+	o.Patch.Path = path
+	o.Patch.Target = nil
+	return nil
+}
+
+// editSkeleton opens the editor on a strategic merge patch
+// skeleton for r and returns the edited patch.
+func editSkeleton(r *resource.Resource) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "apiVersion: %s\nkind: %s\nmetadata:\n  name: %s\n",
+		r.GetApiVersion(), r.GetKind(), r.GetName())
+	if ns := r.GetNamespace(); ns != "" {
+		fmt.Fprintf(&b, "  namespace: %s\n", ns)
+	}
+	f, err := os.CreateTemp("", "kustomize-patch-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(b.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = editFile(f.Name()); err != nil {
+		return nil, fmt.Errorf("editing the patch: %w", err)
+	}
+	content, err := os.ReadFile(f.Name())
+	if err != nil {
+		return nil, err
+	}
+	if string(content) == b.String() || strings.TrimSpace(string(content)) == "" {
+		return nil, errors.New("the patch was not edited; not adding it")
+	}
+	return content, nil
+}
+
 // RunAddPatch runs addPatch command (do real work).
 func (o *addPatchOptions) RunAddPatch(fSys filesys.FileSystem) error {
 	mf, err := kustfile.NewKustomizationFile(fSys)
@@ -4,6 +4,7 @@
 package add
 
 import (
+	"os"
 	"strings"
 	"testing"
 
@@ -111,3 +112,82 @@ func TestAddPatchNoArgs(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "must provide either patch or path", err.Error())
 }
+
+func TestAddPatchFromStdin(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+
+	cmd := newCmdAddPatch(fSys)
+	cmd.SetIn(strings.NewReader(patchFileContent))
+	cmd.SetArgs([]string{"--from-stdin", "--kind", kind})
+	assert.NoError(t, cmd.Execute())
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), strings.TrimSpace(patchFileContent))
+	assert.Contains(t, string(content), kind)
+}
+
+func TestAddPatchEdit(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`resources:
+- deployment.yaml
+`))
+	require.NoError(t, fSys.WriteFile("deployment.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+`)))
+	defer func(f func(string) error) { editFile = f }(editFile)
+	var skeleton []byte
+	editFile = func(path string) error {
+		var err error
+		if skeleton, err = os.ReadFile(path); err != nil {
+			return err
+		}
+		return os.WriteFile(path, append(skeleton, "spec:\n  replicas: 3\n"...), 0o600)
+	}
+
+	cmd := newCmdAddPatch(fSys)
+	cmd.SetArgs([]string{"--edit", "--kind", "Deployment", "--name", "web"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+`, string(skeleton))
+	patch, err := fSys.ReadFile("patch-deployment-web.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(patch), "replicas: 3")
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "path: patch-deployment-web.yaml")
+	assert.NotContains(t, string(content), "target:")
+}
+
+func TestAddPatchEditErrors(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`resources:
+- deployment.yaml
+`))
+	require.NoError(t, fSys.WriteFile("deployment.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)))
+	defer func(f func(string) error) { editFile = f }(editFile)
+	editFile = func(string) error { return nil }
+
+	cmd := newCmdAddPatch(fSys)
+	cmd.SetArgs([]string{"--edit", "--kind", "Service"})
+	assert.EqualError(t, cmd.Execute(), "the target selects 0 resources, it must select exactly one")
+
+	cmd = newCmdAddPatch(fSys)
+	cmd.SetArgs([]string{"--edit", "--kind", "Deployment"})
+	assert.EqualError(t, cmd.Execute(), "the patch was not edited; not adding it")
+
+	cmd = newCmdAddPatch(fSys)
+	cmd.SetArgs([]string{"--edit", "--path", patchFileName})
+	assert.EqualError(t, cmd.Execute(), "only one of patch, path, from-stdin and edit can be set")
+}
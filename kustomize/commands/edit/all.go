@@ -36,10 +36,22 @@ func NewCmdEdit(
 
 	# Sets the namesuffix field
 	kustomize edit set namesuffix <suffix-value>
+
+	# Applies the edit operations listed in ops.yaml, or none if one fails
+	kustomize edit batch -f ops.yaml
 `,
 		Args: cobra.MinimumNArgs(1),
 	}
 
+	addEditCommands(c, fSys, v, rf, w)
+	c.AddCommand(newCmdBatch(fSys, v, rf, w))
+	return c
+}
+
+// addEditCommands adds the commands editing the kustomization file to c.
+func addEditCommands(
+	c *cobra.Command, fSys filesys.FileSystem, v ifc.Validator,
+	rf *resource.Factory, w io.Writer) {
 	c.AddCommand(
 		add.NewCmdAdd(
 			fSys,
@@ -54,5 +66,4 @@ func NewCmdEdit(
 		remove.NewCmdRemove(fSys, v),
 		listbuiltin.NewCmdListBuiltinPlugin(),
 	)
-	return c
 }
@@ -0,0 +1,127 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package edit
+
+import (
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// newCmdBatch returns the 'batch' subcommand, which applies a
+// list of edit operations to the kustomization file as a whole.
+func newCmdBatch(
+	fSys filesys.FileSystem, v ifc.Validator, rf *resource.Factory,
+	w io.Writer) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Short: "Applies a list of edit operations to the kustomization file atomically",
+		Long: `Applies a list of edit operations, read from a file or stdin, to the
+kustomization file, then builds the kustomization. If an operation or
+the build fails, the kustomization file is restored as it was.
+
+Each operation is the list of arguments of a 'kustomize edit' command.
+`,
+		Example: `
+	# ops.yaml
+	- [add, resource, deployment.yaml]
+	- [set, nameprefix, prod-]
+	- [add, label, env:prod]
+
+	kustomize edit batch -f ops.yaml
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var b []byte
+			var err error
+			if file == "" || file == "-" {
+				b, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				b, err = fSys.ReadFile(file)
+			}
+			if err != nil {
+				return errors.Wrap(err)
+			}
+			var ops [][]string
+			if err = yaml.Unmarshal(b, &ops); err != nil {
+				return errors.WrapPrefixf(err, "invalid operations")
+			}
+			return runBatch(fSys, ops, func() *cobra.Command {
+				return newCmdOperation(fSys, v, rf, w)
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&file, "filename", "f", "",
+		"File holding the operations; stdin if empty or '-'.")
+	return cmd
+}
+
+// newCmdOperation returns a command running the edit operations
+// that batch may apply.
+func newCmdOperation(
+	fSys filesys.FileSystem, v ifc.Validator, rf *resource.Factory,
+	w io.Writer) *cobra.Command {
+	c := &cobra.Command{
+		Use:           "edit",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	addEditCommands(c, fSys, v, rf, w)
+	return c
+}
+
+// runBatch runs each operation with a fresh command from newCmd, as
+// cobra keeps flag values between runs, and builds the result. It
+// restores the kustomization file if anything fails.
+func runBatch(
+	fSys filesys.FileSystem, ops [][]string,
+	newCmd func() *cobra.Command) error {
+	if len(ops) == 0 {
+		return errors.Errorf("no operations to apply")
+	}
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+	path := mf.GetPath()
+	original, err := fSys.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if err = applyBatch(fSys, ops, newCmd); err != nil {
+		if rerr := fSys.WriteFile(path, original); rerr != nil {
+			return errors.WrapPrefixf(rerr, "restoring %s after: %v", path, err)
+		}
+		return errors.WrapPrefixf(err, "%s left unchanged", path)
+	}
+	return nil
+}
+
+func applyBatch(
+	fSys filesys.FileSystem, ops [][]string,
+	newCmd func() *cobra.Command) error {
+	for i, op := range ops {
+		if len(op) == 0 {
+			return errors.Errorf("operation %d is empty", i+1)
+		}
+		cmd := newCmd()
+		cmd.SetArgs(op)
+		if err := cmd.Execute(); err != nil {
+			return errors.WrapPrefixf(err, "operation %d (%s)", i+1, strings.Join(op, " "))
+		}
+	}
+	if _, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, "."); err != nil {
+		return errors.WrapPrefixf(err, "building the edited kustomization")
+	}
+	return nil
+}
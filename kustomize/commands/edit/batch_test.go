@@ -0,0 +1,101 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package edit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/provider"
+	testutils_test "sigs.k8s.io/kustomize/kustomize/v5/commands/internal/testutils"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const batchKustomization = `resources:
+- deployment.yaml
+`
+
+func makeBatchFs(t *testing.T) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(batchKustomization))
+	require.NoError(t, fSys.WriteFile("deployment.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)))
+	require.NoError(t, fSys.WriteFile("service.yaml", []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)))
+	return fSys
+}
+
+func runBatchCmd(fSys filesys.FileSystem, ops string, args ...string) error {
+	pvd := provider.NewDefaultDepProvider()
+	cmd := NewCmdEdit(fSys, pvd.GetFieldValidator(), pvd.GetResourceFactory(), &bytes.Buffer{})
+	cmd.SetIn(strings.NewReader(ops))
+	cmd.SetArgs(append([]string{"batch"}, args...))
+	return cmd.Execute()
+}
+
+func TestBatch(t *testing.T) {
+	fSys := makeBatchFs(t)
+	require.NoError(t, fSys.WriteFile("ops.yaml", []byte(`
+- [add, resource, service.yaml]
+- [set, nameprefix, prod-]
+- [add, label, "env:prod"]
+`)))
+	require.NoError(t, runBatchCmd(fSys, "", "-f", "ops.yaml"))
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "- service.yaml")
+	assert.Contains(t, string(content), "namePrefix: prod-")
+	assert.Contains(t, string(content), "env: prod")
+}
+
+func TestBatchRollback(t *testing.T) {
+	testCases := map[string]struct {
+		ops         string
+		expectedErr string
+	}{
+		"failing operation": {
+			ops: `
+- [set, nameprefix, prod-]
+- [add, resource, missing.yaml]
+`,
+			expectedErr: "operation 2 (add resource missing.yaml)",
+		},
+		"unknown operation": {
+			ops: `
+- [set, nameprefix, prod-]
+- [frobnicate]
+`,
+			expectedErr: "operation 2 (frobnicate)",
+		},
+		"failing build": {
+			ops: `
+- [set, nameprefix, prod-]
+- [add, patch, --patch, "not a patch"]
+`,
+			expectedErr: "building the edited kustomization",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fSys := makeBatchFs(t)
+			err := runBatchCmd(fSys, tc.ops)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+			assert.Contains(t, err.Error(), "left unchanged")
+			content, err := testutils_test.ReadTestKustomization(fSys)
+			require.NoError(t, err)
+			assert.Equal(t, batchKustomization, string(content))
+		})
+	}
+}
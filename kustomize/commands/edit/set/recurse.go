@@ -0,0 +1,189 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package set
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// recurseOptions holds the flags propagating an edit into the
+// overlays of the current kustomization.
+type recurseOptions struct {
+	recurse   bool
+	workspace string
+}
+
+func (r *recurseOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&r.recurse, "recurse", false,
+		"Also edit the overlays that use this kustomization and already set the same "+
+			"names, as found under the parent directory or listed in --workspace")
+	cmd.Flags().StringVar(&r.workspace, "workspace", "",
+		"File listing the directories of the overlays to consider with --recurse")
+}
+
+// overlays returns the directories of the kustomizations that list
+// the current directory, directly or through one another, in their
+// resources or components.
+func (r *recurseOptions) overlays(fSys filesys.FileSystem) ([]string, error) {
+	cur, _, err := fSys.CleanedAbs(".")
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := r.candidates(fSys, cur.String())
+	if err != nil {
+		return nil, err
+	}
+	refs := map[string][]string{}
+	for _, dir := range candidates {
+		mf, err := kustfile.NewKustomizationFileInDir(fSys, dir)
+		if err != nil {
+			return nil, err
+		}
+		m, err := mf.Read()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", mf.GetPath(), err)
+		}
+		for _, ref := range append(m.Resources, m.Components...) {
+			if !filepath.IsAbs(ref) {
+				ref = filepath.Join(dir, ref)
+			}
+			refs[dir] = append(refs[dir], filepath.Clean(ref))
+		}
+	}
+	reached := map[string]bool{cur.String(): true}
+	var overlays []string
+	for grew := true; grew; {
+		grew = false
+		for _, dir := range candidates {
+			if reached[dir] {
+				continue
+			}
+			for _, ref := range refs[dir] {
+				if reached[ref] {
+					reached[dir] = true
+					overlays = append(overlays, dir)
+					grew = true
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(overlays)
+	return overlays, nil
+}
+
+// candidates returns the absolute directories holding a kustomization
+// that are listed in the workspace file or, without one, found under
+// the parent of cur.
+func (r *recurseOptions) candidates(fSys filesys.FileSystem, cur string) ([]string, error) {
+	hasKustomization := func(dir string) bool {
+		for _, n := range konfig.RecognizedKustomizationFileNames() {
+			if fSys.Exists(filepath.Join(dir, n)) {
+				return true
+			}
+		}
+		return false
+	}
+	var dirs []string
+	if r.workspace != "" {
+		b, err := fSys.ReadFile(r.workspace)
+		if err != nil {
+			return nil, err
+		}
+		var listed []string
+		if err = yaml.Unmarshal(b, &listed); err != nil {
+			return nil, fmt.Errorf("invalid workspace %s: %w", r.workspace, err)
+		}
+		root, _, err := fSys.CleanedAbs(filepath.Dir(r.workspace))
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range listed {
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(root.String(), dir)
+			}
+			dir = filepath.Clean(dir)
+			if !hasKustomization(dir) {
+				return nil, fmt.Errorf("no kustomization in %s, listed in %s", dir, r.workspace)
+			}
+			dirs = append(dirs, dir)
+		}
+		return dirs, nil
+	}
+	err := fSys.Walk(filepath.Dir(cur), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && path != cur && hasKustomization(path) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// run applies edit to the current kustomization and, with --recurse,
+// to its overlays, writing the kustomizations edit reports changed
+// and printing their paths to w.
+func (r *recurseOptions) run(
+	fSys filesys.FileSystem, w io.Writer,
+	edit func(m *types.Kustomization, overlay bool) bool) error {
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+	m, err := mf.Read()
+	if err != nil {
+		return err
+	}
+	edit(m, false)
+	if err = mf.Write(m); err != nil {
+		return err
+	}
+	if !r.recurse {
+		return nil
+	}
+	fmt.Fprintf(w, "updated %s\n", mf.GetPath())
+	overlays, err := r.overlays(fSys)
+	if err != nil {
+		return err
+	}
+	cur, _, err := fSys.CleanedAbs(".")
+	if err != nil {
+		return err
+	}
+	for _, dir := range overlays {
+		mf, err := kustfile.NewKustomizationFileInDir(fSys, dir)
+		if err != nil {
+			return err
+		}
+		m, err := mf.Read()
+		if err != nil {
+			return err
+		}
+		if !edit(m, true) {
+			continue
+		}
+		if err = mf.Write(m); err != nil {
+			return err
+		}
+		path := mf.GetPath()
+		if rel, err := filepath.Rel(cur.String(), path); err == nil {
+			path = rel
+		}
+		fmt.Fprintf(w, "updated %s\n", path)
+	}
+	return nil
+}
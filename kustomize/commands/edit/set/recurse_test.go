@@ -0,0 +1,127 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package set
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func makeOverlaysFs(t *testing.T) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	for path, content := range map[string]string{
+		"kustomization.yaml": `images:
+- name: app
+  newTag: "1.0"
+`,
+		"overlays/dev/kustomization.yaml": `resources:
+- ../..
+`,
+		"overlays/prod/kustomization.yaml": `resources:
+- ../..
+images:
+- name: app
+  newTag: "0.9"
+- name: sidecar
+  newTag: "2.0"
+replicas:
+- count: 5
+  name: web
+`,
+		"overlays/prod-eu/kustomization.yaml": `resources:
+- ../prod
+images:
+- name: app
+  newTag: "0.8"
+`,
+		"unrelated/kustomization.yaml": `images:
+- name: app
+  newTag: "0.1"
+`,
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+	return fSys
+}
+
+func TestSetImageRecurse(t *testing.T) {
+	fSys := makeOverlaysFs(t)
+	var out bytes.Buffer
+	cmd := newCmdSetImage(fSys)
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--recurse", "app:1.1"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, `updated kustomization.yaml
+updated overlays/prod/kustomization.yaml
+updated overlays/prod-eu/kustomization.yaml
+`, out.String())
+
+	for path, expected := range map[string]string{
+		"kustomization.yaml": `images:
+- name: app
+  newTag: "1.1"
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`,
+		"overlays/dev/kustomization.yaml": `resources:
+- ../..
+`,
+		"overlays/prod/kustomization.yaml": `resources:
+- ../..
+images:
+- name: app
+  newTag: "1.1"
+- name: sidecar
+  newTag: "2.0"
+replicas:
+- count: 5
+  name: web
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`,
+		"overlays/prod-eu/kustomization.yaml": `resources:
+- ../prod
+images:
+- name: app
+  newTag: "1.1"
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`,
+		"unrelated/kustomization.yaml": `images:
+- name: app
+  newTag: "0.1"
+`,
+	} {
+		content, err := fSys.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content), path)
+	}
+}
+
+func TestSetReplicasRecurseWorkspace(t *testing.T) {
+	fSys := makeOverlaysFs(t)
+	require.NoError(t, fSys.WriteFile("workspace.yaml", []byte(`
+- overlays/dev
+- overlays/prod
+`)))
+	var out bytes.Buffer
+	cmd := newCmdSetReplicas(fSys)
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--recurse", "--workspace", "workspace.yaml", "web=3"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, `updated kustomization.yaml
+updated overlays/prod/kustomization.yaml
+`, out.String())
+	content, err := fSys.ReadFile("overlays/prod/kustomization.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `replicas:
+- count: 3
+  name: web
+`)
+}
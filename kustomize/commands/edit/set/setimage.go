@@ -5,6 +5,7 @@ package set
 
 import (
 	"errors"
+	"io"
 	"regexp"
 	"sort"
 	"strings"
@@ -12,12 +13,17 @@ import (
 	"sigs.k8s.io/kustomize/api/types"
 
 	"github.com/spf13/cobra"
-	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 type setImageOptions struct {
 	imageMap map[string]types.Image
+
+	// resolve, if changed, sets resolveDigests on the images.
+	resolve        bool
+	resolveChanged bool
+
+	recurseOptions
 }
 
 var pattern = regexp.MustCompile(`^(.*):([a-zA-Z0-9._-]*|\*)$`)
@@ -27,8 +33,9 @@ var preserveSeparator = "*"
 // errors
 
 var (
-	errImageNoArgs      = errors.New("no image specified")
-	errImageInvalidArgs = errors.New(`invalid format of image, use one of the following options:
+	errImageNoArgs        = errors.New("no image specified")
+	errImageResolveDigest = errors.New("--resolve cannot be used with a digest")
+	errImageInvalidArgs   = errors.New(`invalid format of image, use one of the following options:
 - <image>=<newimage>:<newtag>
 - <image>=<newimage>@<digest>
 - <image>=<newimage>
@@ -78,15 +85,39 @@ and overwrite the previous ones if the image name exists.
 
 The image tag can only contain alphanumeric, '.', '_' and '-'. Passing * (asterisk) either as the new name, 
 the new tag, or the digest will preserve the appropriate values from the kustomization file.
+
+The command
+  set image --resolve nginx=registry.example.com/nginx:1.25.1
+will add
+
+images:
+- name: nginx
+  newName: registry.example.com/nginx
+  newTag: 1.25.1
+  resolveDigests: true
+
+so that builds replace the tag with the digest it has in the registry.
+
+The command
+  set image --recurse my-app=my-registry/my-app:1.2.0
+will also set the image in every overlay using this kustomization,
+directly or through other overlays, that already sets an image named
+my-app, and print the path of every kustomization file it updates.
+Overlays are looked for under the parent directory, or in the
+directories listed in the YAML file given with --workspace.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			o.resolveChanged = cmd.Flags().Changed("resolve")
 			err := o.Validate(args)
 			if err != nil {
 				return err
 			}
-			return o.RunSetImage(fSys)
+			return o.RunSetImage(fSys, cmd.OutOrStdout())
 		},
 	}
+	cmd.Flags().BoolVar(&o.resolve, "resolve", false,
+		"Resolve the tags of the images to their digests in the registry at build time")
+	o.recurseOptions.addFlags(cmd)
 	return cmd
 }
 
@@ -109,25 +140,34 @@ func (o *setImageOptions) Validate(args []string) error {
 		if err != nil {
 			return err
 		}
+		if o.resolveChanged {
+			if o.resolve && img.Digest != "" && img.Digest != preserveSeparator {
+				return errImageResolveDigest
+			}
+			img.ResolveDigests = o.resolve
+		}
 		o.imageMap[img.Name] = img
 	}
 	return nil
 }
 
 // RunSetImage runs setImage command.
-func (o *setImageOptions) RunSetImage(fSys filesys.FileSystem) error {
-	mf, err := kustfile.NewKustomizationFile(fSys)
-	if err != nil {
-		return err
-	}
-	m, err := mf.Read()
-	if err != nil {
-		return err
+func (o *setImageOptions) RunSetImage(fSys filesys.FileSystem, w io.Writer) error {
+	return o.recurseOptions.run(fSys, w, o.setImages)
+}
+
+// setImages sets the images of m, or with overlay only those m
+// already has, and tells if it changed any.
+func (o *setImageOptions) setImages(m *types.Kustomization, overlay bool) bool {
+	imageMap := make(map[string]types.Image, len(o.imageMap))
+	for name, im := range o.imageMap {
+		imageMap[name] = im
 	}
+	changed := false
 
 	// append only new images from kustomize file
 	for _, im := range m.Images {
-		if argIm, ok := o.imageMap[im.Name]; ok {
+		if argIm, ok := imageMap[im.Name]; ok {
 			// Reuse the existing new name when asterisk new name is passed
 			if argIm.NewName == preserveSeparator {
 				argIm = replaceNewName(argIm, im.NewName)
@@ -143,16 +183,29 @@ func (o *setImageOptions) RunSetImage(fSys filesys.FileSystem) error {
 				argIm = replaceDigest(argIm, im.Digest)
 			}
 
-			o.imageMap[im.Name] = argIm
+			// Keep resolving digests unless --resolve is passed
+			if !o.resolveChanged {
+				argIm.ResolveDigests = im.ResolveDigests
+			}
+
+			imageMap[im.Name] = argIm
+			changed = true
 
 			continue
 		}
 
-		o.imageMap[im.Name] = im
+		imageMap[im.Name] = im
+	}
+	if overlay && !changed {
+		return false
 	}
 
 	var images []types.Image
-	for _, v := range o.imageMap {
+	for name, v := range imageMap {
+		if overlay && !hasImage(m.Images, name) {
+			continue
+		}
+
 		if v.NewName == preserveSeparator {
 			v = replaceNewName(v, "")
 		}
@@ -173,33 +226,45 @@ func (o *setImageOptions) RunSetImage(fSys filesys.FileSystem) error {
 	})
 
 	m.Images = images
-	return mf.Write(m)
+	return true
+}
+
+func hasImage(images []types.Image, name string) bool {
+	for _, im := range images {
+		if im.Name == name {
+			return true
+		}
+	}
+	return false
 }
 
 func replaceNewName(image types.Image, newName string) types.Image {
 	return types.Image{
-		Name:    image.Name,
-		NewName: newName,
-		NewTag:  image.NewTag,
-		Digest:  image.Digest,
+		Name:           image.Name,
+		NewName:        newName,
+		NewTag:         image.NewTag,
+		Digest:         image.Digest,
+		ResolveDigests: image.ResolveDigests,
 	}
 }
 
 func replaceNewTag(image types.Image, newTag string) types.Image {
 	return types.Image{
-		Name:    image.Name,
-		NewName: image.NewName,
-		NewTag:  newTag,
-		Digest:  image.Digest,
+		Name:           image.Name,
+		NewName:        image.NewName,
+		NewTag:         newTag,
+		Digest:         image.Digest,
+		ResolveDigests: image.ResolveDigests,
 	}
 }
 
 func replaceDigest(image types.Image, digest string) types.Image {
 	return types.Image{
-		Name:    image.Name,
-		NewName: image.NewName,
-		NewTag:  image.NewTag,
-		Digest:  digest,
+		Name:           image.Name,
+		NewName:        image.NewName,
+		NewTag:         image.NewTag,
+		Digest:         digest,
+		ResolveDigests: image.ResolveDigests,
 	}
 }
 
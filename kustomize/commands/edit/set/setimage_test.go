@@ -443,3 +443,50 @@ func TestSetImage(t *testing.T) {
 		})
 	}
 }
+
+func TestSetImageResolve(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(strings.Join([]string{
+		"images:",
+		"- name: busybox",
+		"  newTag: \"1\"",
+		"  resolveDigests: true",
+	}, "\n")))
+	cmd := newCmdSetImage(fSys)
+	if err := cmd.Flags().Set("resolve", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.RunE(cmd, []string{"nginx=registry.example.com/nginx:1.25.1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Without --resolve, images keep resolving digests.
+	cmd = newCmdSetImage(fSys)
+	if err := cmd.RunE(cmd, []string{"busybox:1.36"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"images:",
+		"- name: busybox",
+		"  newTag: \"1.36\"",
+		"  resolveDigests: true",
+		"- name: nginx",
+		"  newName: registry.example.com/nginx",
+		"  newTag: 1.25.1",
+		"  resolveDigests: true",
+	}, "\n")
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("unexpected images in kustomization file. \nActual:\n%s\nExpected:\n%s", content, expected)
+	}
+
+	cmd = newCmdSetImage(fSys)
+	if err := cmd.Flags().Set("resolve", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.RunE(cmd, []string{"nginx@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3"}); err != errImageResolveDigest {
+		t.Errorf("expected %v, got %v", errImageResolveDigest, err)
+	}
+}
@@ -5,18 +5,20 @@ package set
 
 import (
 	"errors"
+	"io"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/types"
-	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 type setReplicasOptions struct {
 	replicasMap map[string]types.Replica
+
+	recurseOptions
 }
 
 // errors
@@ -48,15 +50,19 @@ replicas:
 
 to the kustomization file if it doesn't exist,
 and overwrite the previous ones if the replicas name exists.
+
+With --recurse, the counts are also set in every overlay using this
+kustomization that already sets replicas for the same names.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
 				return err
 			}
-			return o.RunSetReplicas(fSys)
+			return o.RunSetReplicas(fSys, cmd.OutOrStdout())
 		},
 	}
+	o.recurseOptions.addFlags(cmd)
 	return cmd
 }
 
@@ -79,27 +85,37 @@ func (o *setReplicasOptions) Validate(args []string) error {
 }
 
 // RunSetReplicas runs setReplicas command.
-func (o *setReplicasOptions) RunSetReplicas(fSys filesys.FileSystem) error {
-	mf, err := kustfile.NewKustomizationFile(fSys)
-	if err != nil {
-		return err
-	}
-	m, err := mf.Read()
-	if err != nil {
-		return err
+func (o *setReplicasOptions) RunSetReplicas(fSys filesys.FileSystem, w io.Writer) error {
+	return o.recurseOptions.run(fSys, w, o.setReplicas)
+}
+
+// setReplicas sets the replicas of m, or with overlay only those m
+// already has, and tells if it changed any.
+func (o *setReplicasOptions) setReplicas(m *types.Kustomization, overlay bool) bool {
+	replicasMap := make(map[string]types.Replica, len(o.replicasMap))
+	if !overlay {
+		for name, rep := range o.replicasMap {
+			replicasMap[name] = rep
+		}
 	}
+	changed := false
 
 	// append only new replicas from kustomize file
 	for _, rep := range m.Replicas {
-		if _, ok := o.replicasMap[rep.Name]; ok {
+		if argRep, ok := o.replicasMap[rep.Name]; ok {
+			replicasMap[rep.Name] = argRep
+			changed = true
 			continue
 		}
 
-		o.replicasMap[rep.Name] = rep
+		replicasMap[rep.Name] = rep
+	}
+	if overlay && !changed {
+		return false
 	}
 
 	var replicas []types.Replica
-	for _, v := range o.replicasMap {
+	for _, v := range replicasMap {
 		replicas = append(replicas, v)
 	}
 
@@ -108,7 +124,7 @@ func (o *setReplicasOptions) RunSetReplicas(fSys filesys.FileSystem) error {
 	})
 
 	m.Replicas = replicas
-	return mf.Write(m)
+	return true
 }
 
 func parseReplicasArg(arg string) (types.Replica, error) {
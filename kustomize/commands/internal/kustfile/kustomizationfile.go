@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"path/filepath"
 	"reflect"
 	"regexp"
 
@@ -41,6 +42,7 @@ func determineFieldOrder() []string {
 	ordered := []string{
 		"MetaData",
 		"Resources",
+		"ConditionalResources",
 		"Bases",
 		"NamePrefix",
 		"NameSuffix",
@@ -54,6 +56,7 @@ func determineFieldOrder() []string {
 		"Patches",
 		"ConfigMapGenerator",
 		"SecretGenerator",
+		"NetworkPolicyGenerator",
 		"HelmCharts",
 		"HelmChartInflationGenerator",
 		"HelmGlobals",
@@ -61,11 +64,18 @@ func determineFieldOrder() []string {
 		"Vars",
 		"Images",
 		"Replacements",
+		"Substitutions",
 		"Replicas",
+		"ResourceOverrides",
+		"Scheduling",
 		"Configurations",
 		"Generators",
 		"Transformers",
+		"Pipeline",
 		"Components",
+		"ConditionalComponents",
+		"ComponentInputs",
+		"Inputs",
 		"OpenAPI",
 		"BuildMetadata",
 	}
@@ -110,6 +120,7 @@ func squash(x [][]byte) []byte {
 }
 
 type kustomizationFile struct {
+	dir            string
 	path           string
 	fSys           filesys.FileSystem
 	originalFields []*commentedField
@@ -125,6 +136,17 @@ func NewKustomizationFile(fSys filesys.FileSystem) (*kustomizationFile, error) {
 	return mf, nil
 }
 
+// NewKustomizationFileInDir returns a new instance for the
+// kustomization file in dir.
+func NewKustomizationFileInDir(fSys filesys.FileSystem, dir string) (*kustomizationFile, error) {
+	mf := &kustomizationFile{fSys: fSys, dir: dir}
+	err := mf.validate()
+	if err != nil {
+		return nil, err
+	}
+	return mf, nil
+}
+
 func (mf *kustomizationFile) GetPath() string {
 	if mf == nil {
 		return ""
@@ -136,6 +158,7 @@ func (mf *kustomizationFile) validate() error {
 	match := 0
 	var path []string
 	for _, kfilename := range konfig.RecognizedKustomizationFileNames() {
+		kfilename = filepath.Join(mf.dir, kfilename)
 		if mf.fSys.Exists(kfilename) {
 			match += 1
 			path = append(path, kfilename)
@@ -22,6 +22,7 @@ func TestFieldOrder(t *testing.T) {
 		"Kind",
 		"MetaData",
 		"Resources",
+		"ConditionalResources",
 		"Bases",
 		"NamePrefix",
 		"NameSuffix",
@@ -35,6 +36,7 @@ func TestFieldOrder(t *testing.T) {
 		"Patches",
 		"ConfigMapGenerator",
 		"SecretGenerator",
+		"NetworkPolicyGenerator",
 		"HelmCharts",
 		"HelmChartInflationGenerator",
 		"HelmGlobals",
@@ -42,11 +44,18 @@ func TestFieldOrder(t *testing.T) {
 		"Vars",
 		"Images",
 		"Replacements",
+		"Substitutions",
 		"Replicas",
+		"ResourceOverrides",
+		"Scheduling",
 		"Configurations",
 		"Generators",
 		"Transformers",
+		"Pipeline",
 		"Components",
+		"ConditionalComponents",
+		"ComponentInputs",
+		"Inputs",
 		"OpenAPI",
 		"BuildMetadata",
 	}
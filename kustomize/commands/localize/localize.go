@@ -10,6 +10,7 @@ import (
 	lclzr "sigs.k8s.io/kustomize/api/krusty/localizer"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
 )
 
 const numArgs = 2
@@ -20,7 +21,12 @@ type arguments struct {
 }
 
 type flags struct {
-	scope string
+	scope             string
+	vendorHelmCharts  bool
+	helmCommand       string
+	functionImagesDir string
+	skopeoCommand     string
+	update            bool
 }
 
 // NewCmdLocalize returns a new localize command.
@@ -40,9 +46,21 @@ working directory.
 
 For details, see: https://kubectl.docs.kubernetes.io/references/kustomize/cmd/
 
+With --vendor-helm-charts, the charts of helmCharts entries with a repo are 
+pulled into the localized chart home. With --function-images-dir, the images 
+of container functions are exported to OCI layouts in that directory of 
+destination, and their references rewritten to them, so that the localized 
+copy builds offline.
+
+Destination records the remote content vendored into it, with its source, 
+ref, commit and hash, in localize-provenance.yaml. With --update, the existing 
+destination is updated instead: local content is copied anew, while remote 
+roots whose refs still point to the recorded commits, and exported function 
+images, are reused rather than fetched again.
+
 Disclaimer:
-This command does not yet localize helm or KRM plugin fields. This command also
-alphabetizes kustomization fields in the localized copy.
+This command does not yet localize the other fields of KRM plugins. This 
+command also alphabetizes kustomization fields in the localized copy.
 `,
 		Example: `
 # Localize the current working directory, with default scope and destination
@@ -51,6 +69,12 @@ kustomize localize
 # Localize some local directory, with scope and default destination
 kustomize localize /home/path/scope/target --scope /home/path/scope
 
+# Localize the current working directory with its helm charts and function images
+kustomize localize . path/non-existing-dir --vendor-helm-charts --function-images-dir images
+
+# Update a previously localized copy, fetching only changed remote roots
+kustomize localize . path/existing-dir --update
+
 # Localize remote at set destination relative to working directory
 kustomize localize https://github.com/kubernetes-sigs/kustomize//api/krusty/testdata/localize/simple?ref=v4.5.7 path/non-existing-dir
 `,
@@ -58,10 +82,22 @@ kustomize localize https://github.com/kubernetes-sigs/kustomize//api/krusty/test
 		Args:         cobra.MaximumNArgs(numArgs),
 		RunE: func(cmd *cobra.Command, rawArgs []string) error {
 			args := matchArgs(rawArgs)
-			dst, err := lclzr.Run(fs, args.target, f.scope, args.dest)
+			opts := lclzr.Options{
+				FunctionImagesDir: f.functionImagesDir,
+				SkopeoCommand:     f.skopeoCommand,
+				Update:            f.update,
+			}
+			if f.vendorHelmCharts {
+				opts.HelmCommand = f.helmCommand
+			}
+			dst, err := lclzr.RunWithOptions(fs, args.target, f.scope, args.dest, opts)
 			if err != nil {
 				return errors.Wrap(err)
 			}
+			if f.update {
+				log.Printf("SUCCESS: updated localized %q in directory %s\n", args.target, dst)
+				return nil
+			}
 			log.Printf("SUCCESS: localized %q to directory %s\n", args.target, dst)
 			return nil
 		},
@@ -73,6 +109,30 @@ kustomize localize https://github.com/kubernetes-sigs/kustomize//api/krusty/test
 		`Path to directory inside of which localize is limited to running.
 Cannot specify for remote targets, as scope is by default the containing repo.
 If not specified for local target, scope defaults to target.
+`)
+	cmd.Flags().BoolVar(&f.vendorHelmCharts,
+		"vendor-helm-charts",
+		false,
+		"Pull the charts of helmCharts entries with a repo into the localized chart home.")
+	cmd.Flags().StringVar(&f.helmCommand,
+		"helm-command",
+		"helm",
+		"helm command (path to executable) used by --vendor-helm-charts")
+	cmd.Flags().StringVar(&f.functionImagesDir,
+		"function-images-dir",
+		"",
+		`Directory, relative to destination, to which to export the images of 
+container functions as OCI layouts, rewriting their references.
+`)
+	cmd.Flags().StringVar(&f.skopeoCommand,
+		"skopeo-command",
+		container.DefaultSkopeoCommand,
+		"skopeo command (path to executable) used by --function-images-dir")
+	cmd.Flags().BoolVar(&f.update,
+		"update",
+		false,
+		`Update the existing destination of a previous localize, re-fetching only the 
+remote roots whose refs point to other commits than recorded in its provenance.
 `)
 	return cmd
 }
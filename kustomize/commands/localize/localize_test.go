@@ -156,3 +156,52 @@ func TestTooManyArgs(t *testing.T) {
 	})
 	require.EqualError(t, err, "accepts at most 2 arg(s), received 3")
 }
+
+func TestVendorHelmChartsFlag(t *testing.T) {
+	kustomization := map[string]string{
+		"kustomization.yaml": `helmCharts:
+- name: minecraft
+  repo: https://itzg.github.io/minecraft-server-charts
+  version: 3.1.3
+`,
+	}
+	expected, actual, testDir := loctest.PrepareFs(t, nil, kustomization)
+	helm := filepath.Join(t.TempDir(), "helm")
+	require.NoError(t, os.WriteFile(helm, []byte(`#!/bin/sh
+mkdir -p "$4/$7"
+echo "name: $7" > "$4/$7/Chart.yaml"
+`), 0o700))
+
+	cmd := localize.NewCmdLocalize(actual)
+	require.NoError(t, cmd.Flags().Set("vendor-helm-charts", "true"))
+	require.NoError(t, cmd.Flags().Set("helm-command", helm))
+	err := cmd.RunE(cmd, []string{
+		testDir.String(),
+		testDir.Join("dst"),
+	})
+	require.NoError(t, err)
+	provenance := filepath.Join(testDir.Join("dst"), "localize-provenance.yaml")
+	require.FileExists(t, provenance)
+	require.NoError(t, os.Remove(provenance))
+
+	loctest.SetupDir(t, expected, testDir.Join("dst"), map[string]string{
+		"kustomization.yaml": kustomization["kustomization.yaml"],
+		filepath.Join("charts", "minecraft-3.1.3", "minecraft", "Chart.yaml"): "name: minecraft\n",
+	})
+	loctest.CheckFs(t, testDir.String(), expected, actual)
+}
+
+func TestUpdateFlag(t *testing.T) {
+	_, actual, testDir := loctest.PrepareFs(t, []string{"dst"}, map[string]string{
+		"kustomization.yaml": `namePrefix: test-`,
+	})
+
+	cmd := localize.NewCmdLocalize(actual)
+	require.NoError(t, cmd.Flags().Set("update", "true"))
+	err := cmd.RunE(cmd, []string{
+		testDir.String(),
+		testDir.Join("dst"),
+	})
+	require.ErrorContains(t, err, fmt.Sprintf(`localize destination "%s" to update has no localize-provenance.yaml`,
+		testDir.Join("dst")))
+}
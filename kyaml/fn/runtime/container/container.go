@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
@@ -128,6 +129,11 @@ type Filter struct {
 	Exec runtimeexec.Filter
 
 	UIDGID string
+
+	// Runtime is the container runtime executable that runs the
+	// container, one of docker, podman and nerdctl or a compatible
+	// one.  It is detected by DetectRuntime if empty.
+	Runtime string
 }
 
 func (c Filter) String() string {
@@ -161,31 +167,49 @@ func (c *Filter) setupExec() error {
 		c.Exec.WorkingDir = wd
 	}
 
+	if strings.HasPrefix(c.Image, OCILayoutPrefix) {
+		image, err := loadOCILayout(c.runtime(), c.Image, c.Exec.WorkingDir)
+		if err != nil {
+			return err
+		}
+		c.Image = image
+	}
+
 	path, args := c.getCommand()
 	c.Exec.Path = path
 	c.Exec.Args = args
 	return nil
 }
 
+// runtime returns the container runtime running the function.
+func (c *Filter) runtime() string {
+	if c.Runtime == "" {
+		return DetectRuntime()
+	}
+	return c.Runtime
+}
+
 // getArgs returns the command + args to run to spawn the container
 func (c *Filter) getCommand() (string, []string) {
 	network := runtimeutil.NetworkNameNone
 	if c.ContainerSpec.Network {
 		network = runtimeutil.NetworkNameHost
 	}
-	// run the container using docker.  this is simpler than using the docker
-	// libraries, and ensures things like auth work the same as if the container
-	// was run from the cli.
-	args := []string{"run",
-		"--rm",                                              // delete the container afterward
-		"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR", // attach stdin, stdout, stderr
-		"--network", string(network),
-
-		// added security options
-		"--user", c.UIDGID,
+	runtime := c.runtime()
+	flavor := runtimeFlavor(runtime)
+	// run the container using the runtime cli.  this is simpler than using the
+	// runtime libraries, and ensures things like auth work the same as if the
+	// container was run from the cli.
+	args := []string{"run", "--rm"} // delete the container afterward
+	args = append(args, attachFlags(flavor)...)
+	args = append(args, "--network", string(network))
+
+	// added security options
+	args = append(args, userFlags(flavor, c.UIDGID)...)
+	args = append(args,
 		"--security-opt=no-new-privileges", // don't allow the user to escalate privileges
 		// note: don't make fs readonly because things like heredoc rely on writing tmp files
-	}
+	)
 
 	for _, storageMount := range c.StorageMounts {
 		// convert declarative relative paths to absolute (otherwise docker will throw an error)
@@ -197,7 +221,7 @@ func (c *Filter) getCommand() (string, []string) {
 
 	args = append(args, runtimeutil.NewContainerEnvFromStringSlice(c.Env).GetDockerFlags()...)
 	a := append(args, c.Image) //nolint:gocritic
-	return "docker", a
+	return runtime, a
 }
 
 // NewContainer returns a new container filter
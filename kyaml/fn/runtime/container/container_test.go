@@ -254,3 +254,108 @@ func getAbsFilePath(args ...string) string {
 	path, _ := filepath.Abs(filepath.Join(args...))
 	return path
 }
+
+func TestFilter_setupExecRuntime(t *testing.T) {
+	var tests = []struct {
+		name           string
+		runtime        string
+		installed      []string
+		rootless       bool
+		UIDGID         string
+		expectedPath   string
+		expectedPrefix []string
+	}{
+		{
+			name:         "detect docker first",
+			installed:    []string{"nerdctl", "podman", "docker"},
+			UIDGID:       "nobody",
+			expectedPath: "docker",
+			expectedPrefix: []string{"run", "--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "nobody"},
+		},
+		{
+			name:         "detect podman",
+			installed:    []string{"nerdctl", "podman"},
+			UIDGID:       "nobody",
+			expectedPath: "podman",
+			expectedPrefix: []string{"run", "--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "nobody"},
+		},
+		{
+			name:         "detect nerdctl",
+			installed:    []string{"nerdctl"},
+			UIDGID:       "nobody",
+			expectedPath: "nerdctl",
+			expectedPrefix: []string{"run", "--rm", "-i",
+				"--network", "none", "--user", "nobody"},
+		},
+		{
+			name:         "none installed",
+			UIDGID:       "nobody",
+			expectedPath: "docker",
+			expectedPrefix: []string{"run", "--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "nobody"},
+		},
+		{
+			name:         "rootless podman as current user",
+			runtime:      "/usr/bin/podman-remote",
+			rootless:     true,
+			UIDGID:       "1000:1000",
+			expectedPath: "/usr/bin/podman-remote",
+			expectedPrefix: []string{"run", "--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "1000:1000", "--userns=keep-id"},
+		},
+		{
+			name:         "rootful podman as current user",
+			runtime:      "podman",
+			UIDGID:       "1000:1000",
+			expectedPath: "podman",
+			expectedPrefix: []string{"run", "--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "1000:1000"},
+		},
+		{
+			name:         "other runtime",
+			runtime:      "/opt/bin/my-docker",
+			installed:    []string{"podman"},
+			UIDGID:       "nobody",
+			expectedPath: "/opt/bin/my-docker",
+			expectedPrefix: []string{"run", "--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "nobody"},
+		},
+	}
+
+	defer func(l func(string) (string, error), r func() bool) {
+		lookPath, isRootless = l, r
+	}(lookPath, isRootless)
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			lookPath = func(file string) (string, error) {
+				for _, r := range tt.installed {
+					if r == file {
+						return "/usr/bin/" + file, nil
+					}
+				}
+				return "", fmt.Errorf("%s not found", file)
+			}
+			isRootless = func() bool { return tt.rootless }
+
+			instance := NewContainer(runtimeutil.ContainerSpec{Image: "example.com:version"}, tt.UIDGID)
+			instance.Runtime = tt.runtime
+			require.NoError(t, instance.setupExec())
+			assert.Equal(t, tt.expectedPath, instance.Exec.Path)
+			expectedArgs := append(tt.expectedPrefix, //nolint:gocritic
+				"--security-opt=no-new-privileges")
+			expectedArgs = append(expectedArgs,
+				runtimeutil.NewContainerEnvFromStringSlice(instance.Env).GetDockerFlags()...)
+			expectedArgs = append(expectedArgs, instance.Image)
+			assert.Equal(t, expectedArgs, instance.Exec.Args)
+		})
+	}
+}
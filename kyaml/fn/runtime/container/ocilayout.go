@@ -0,0 +1,114 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+const (
+	// OCILayoutPrefix prefixes the images of container functions that
+	// are OCI layout directories, e.g. oci:images/my-fn, rather than
+	// references to a registry.  Relative directories are relative to
+	// the working directory of the function.
+	OCILayoutPrefix = "oci:"
+
+	// DefaultSkopeoCommand is the skopeo executable used when none is set.
+	DefaultSkopeoCommand = "skopeo"
+)
+
+// ExportImage copies image from its registry to a new OCI layout
+// directory dir with skopeo, so that it can run offline as
+// OCILayoutPrefix + dir.
+func ExportImage(skopeo, image, dir string) error {
+	if skopeo == "" {
+		skopeo = DefaultSkopeoCommand
+	}
+	cmd := exec.Command(skopeo, "copy", "docker://"+image, OCILayoutPrefix+dir)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Errorf("exporting function image %s with %s: %v: %s",
+			image, skopeo, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// loadOCILayout loads the OCI layout directory of image, relative to
+// wd, into runtime and returns the reference to run it by.
+func loadOCILayout(runtime, image, wd string) (string, error) {
+	dir := strings.TrimPrefix(image, OCILayoutPrefix)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(wd, dir)
+	}
+	archive, err := tarDir(dir)
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "reading function image %s", image)
+	}
+	cmd := exec.Command(runtime, "load")
+	cmd.Stdin = archive
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return "", errors.Errorf("loading function image %s with %s: %v: %s",
+			image, runtime, err, strings.TrimSpace(stderr.String()))
+	}
+	// docker prints "Loaded image: name" or "Loaded image ID: id",
+	// podman "Loaded image: name" or "Loaded image(s): name"
+	var ref string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if strings.HasPrefix(line, "Loaded image") {
+			if i := strings.Index(line, ": "); i >= 0 {
+				ref = strings.TrimSpace(line[i+2:])
+			}
+		}
+	}
+	if ref == "" {
+		return "", errors.Errorf("loading function image %s with %s: no image loaded: %s",
+			image, runtime, strings.TrimSpace(stdout.String()))
+	}
+	return ref, nil
+}
+
+// tarDir returns a tar archive of the files of dir.
+func tarDir(dir string) (io.Reader, error) {
+	var b bytes.Buffer
+	w := tar.NewWriter(&b)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err = w.WriteHeader(&tar.Header{
+			Name: filepath.ToSlash(name),
+			Mode: 0o644,
+			Size: int64(len(content)),
+		}); err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return &b, errors.Wrap(w.Close())
+}
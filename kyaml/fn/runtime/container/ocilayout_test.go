@@ -0,0 +1,78 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+// fakeLoader loads the archive on stdin, saving it to $0.tar.
+const fakeLoader = `#!/bin/sh
+cat > "$0.tar"
+echo "Loaded image ID: sha256:abc"
+`
+
+// fakeSkopeo logs its arguments to $0.log and creates the layout.
+const fakeSkopeo = `#!/bin/sh
+echo "$@" >> "$0.log"
+case "$2" in
+docker://example.com/missing*)
+  echo "manifest unknown" >&2
+  exit 1
+  ;;
+esac
+mkdir -p "${3#oci:}"
+echo '{"imageLayoutVersion":"1.0.0"}' > "${3#oci:}/oci-layout"
+`
+
+func writeFake(t *testing.T, name, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o700))
+	return path
+}
+
+func TestExportImage(t *testing.T) {
+	skopeo := writeFake(t, "skopeo", fakeSkopeo)
+	dir := filepath.Join(t.TempDir(), "my-fn")
+
+	require.NoError(t, ExportImage(skopeo, "example.com/my-fn:v1", dir))
+	assert.FileExists(t, filepath.Join(dir, "oci-layout"))
+	log, err := os.ReadFile(skopeo + ".log")
+	require.NoError(t, err)
+	assert.Equal(t, "copy docker://example.com/my-fn:v1 oci:"+dir+"\n", string(log))
+
+	err = ExportImage(skopeo, "example.com/missing:v1", dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exporting function image example.com/missing:v1")
+	assert.Contains(t, err.Error(), "manifest unknown")
+}
+
+func TestFilter_setupExecOCILayout(t *testing.T) {
+	docker := writeFake(t, "docker", fakeLoader)
+	wd := t.TempDir()
+	layout := filepath.Join(wd, "images", "my-fn")
+	require.NoError(t, os.MkdirAll(filepath.Join(layout, "blobs"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(layout, "oci-layout"), []byte("{}"), 0o600))
+
+	c := NewContainer(runtimeutil.ContainerSpec{Image: "oci:images/my-fn"}, "nobody")
+	c.Runtime = docker
+	c.Exec.WorkingDir = wd
+	require.NoError(t, c.setupExec())
+	assert.Equal(t, "sha256:abc", c.Exec.Args[len(c.Exec.Args)-1])
+	assert.FileExists(t, docker+".tar")
+
+	c = NewContainer(runtimeutil.ContainerSpec{Image: "oci:images/missing"}, "nobody")
+	c.Runtime = docker
+	c.Exec.WorkingDir = wd
+	err := c.setupExec()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading function image oci:images/missing")
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Container runtimes whose CLIs can run container functions.
+const (
+	Docker  = "docker"
+	Podman  = "podman"
+	Nerdctl = "nerdctl"
+)
+
+// lookPath and isRootless are variables so tests can fake the
+// installed runtimes and the user running them.
+var (
+	lookPath   = exec.LookPath
+	isRootless = func() bool { return os.Geteuid() > 0 }
+)
+
+// DetectRuntime returns the first of docker, podman and nerdctl
+// found on the PATH, or docker if none is.
+func DetectRuntime() string {
+	for _, r := range []string{Docker, Podman, Nerdctl} {
+		if _, err := lookPath(r); err == nil {
+			return r
+		}
+	}
+	return Docker
+}
+
+// runtimeFlavor tells which of the known runtimes the executable
+// runtime is by its name, e.g. podman for /usr/bin/podman-remote.
+// Other executables are taken to be compatible with docker.
+func runtimeFlavor(runtime string) string {
+	base := filepath.Base(runtime)
+	for _, f := range []string{Podman, Nerdctl} {
+		if strings.HasPrefix(base, f) {
+			return f
+		}
+	}
+	return Docker
+}
+
+// attachFlags returns the flags attaching the standard streams of
+// the container.
+func attachFlags(flavor string) []string {
+	if flavor == Nerdctl {
+		// nerdctl attaches stdout and stderr unless detached, and
+		// stdin with -i; it doesn't accept -a on older releases.
+		return []string{"-i"}
+	}
+	return []string{"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR"}
+}
+
+// userFlags returns the flags running the container as uidgid.
+func userFlags(flavor, uidgid string) []string {
+	flags := []string{"--user", uidgid}
+	if flavor == Podman && uidgid != "nobody" && isRootless() {
+		// Rootless podman maps the uid of the user to root in the
+		// container, so the user's uid would be a subordinate one
+		// that can't read the files the user mounts; keep-id maps
+		// it to itself instead.
+		flags = append(flags, "--userns=keep-id")
+	}
+	return flags
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DefaultCosignCommand is the cosign executable used when none is set.
+const DefaultCosignCommand = "cosign"
+
+// ImageVerifier pins container function images to digests and
+// verifies their cosign signatures before they run.
+type ImageVerifier struct {
+	// Command is the cosign executable, DefaultCosignCommand if empty.
+	Command string
+
+	// Key is the public key, or a KMS or other reference cosign
+	// accepts for one, that signed the images.  If empty the
+	// signatures are verified keyless.
+	Key string
+
+	// CertificateIdentity and CertificateOIDCIssuer are the signer
+	// and the issuer of its certificate that keyless signatures
+	// must have.
+	CertificateIdentity   string
+	CertificateOIDCIssuer string
+
+	// LockFile, if set, is a file recording the digests of images
+	// referenced by tag, which are resolved and added to it when
+	// missing.  Without it, images must be referenced by digest.
+	LockFile string
+
+	mu       sync.Mutex
+	lock     *imageLock
+	verified map[string]bool
+}
+
+// imageLock is the content of a lock file.
+type imageLock struct {
+	// Images maps images referenced by tag to their digests.
+	Images map[string]string `yaml:"images"`
+}
+
+// cosignVerification is the part of a verified signature payload,
+// as printed by cosign verify, identifying the image.
+type cosignVerification struct {
+	Critical struct {
+		Image struct {
+			Digest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// Verify returns image pinned to its digest once its signature is
+// verified.
+func (v *ImageVerifier) Verify(image string) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	ref := image
+	if !strings.Contains(image, "@") {
+		if v.LockFile == "" {
+			return "", errors.Errorf(
+				"function image %s must be referenced by digest, or its digest recorded in a lock file", image)
+		}
+		if err := v.loadLock(); err != nil {
+			return "", err
+		}
+		if d, ok := v.lock.Images[image]; ok {
+			ref = image + "@" + d
+		}
+	}
+	if v.verified[ref] {
+		return ref, nil
+	}
+	digest, err := v.cosignVerify(ref)
+	if err != nil {
+		return "", err
+	}
+	if i := strings.Index(ref, "@"); i >= 0 {
+		if ref[i+1:] != digest {
+			return "", errors.Errorf(
+				"the signature of function image %s is for digest %s", ref, digest)
+		}
+	} else {
+		// resolved by tag, record the digest that was verified
+		ref = image + "@" + digest
+		v.lock.Images[image] = digest
+		if err := v.saveLock(); err != nil {
+			return "", err
+		}
+	}
+	if v.verified == nil {
+		v.verified = map[string]bool{}
+	}
+	v.verified[ref] = true
+	return ref, nil
+}
+
+// cosignVerify verifies the signature of ref and returns the digest
+// of the image it is for.
+func (v *ImageVerifier) cosignVerify(ref string) (string, error) {
+	command := v.Command
+	if command == "" {
+		command = DefaultCosignCommand
+	}
+	args := []string{"verify", "--output", "json"}
+	if v.Key != "" {
+		args = append(args, "--key", v.Key)
+	} else {
+		if v.CertificateIdentity == "" || v.CertificateOIDCIssuer == "" {
+			return "", errors.Errorf(
+				"keyless verification of function images requires a certificate identity and OIDC issuer")
+		}
+		args = append(args,
+			"--certificate-identity", v.CertificateIdentity,
+			"--certificate-oidc-issuer", v.CertificateOIDCIssuer)
+	}
+	args = append(args, ref)
+	cmd := exec.Command(command, args...) //nolint:gosec
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Errorf("verifying the signature of function image %s: %v: %s",
+			ref, err, strings.TrimSpace(stderr.String()))
+	}
+	var verifications []cosignVerification
+	if err := json.Unmarshal(stdout.Bytes(), &verifications); err != nil {
+		return "", errors.WrapPrefixf(err, "reading the output of %s", command)
+	}
+	if len(verifications) == 0 || verifications[0].Critical.Image.Digest == "" {
+		return "", errors.Errorf("no verified signature for function image %s", ref)
+	}
+	return verifications[0].Critical.Image.Digest, nil
+}
+
+func (v *ImageVerifier) loadLock() error {
+	if v.lock != nil {
+		return nil
+	}
+	v.lock = &imageLock{Images: map[string]string{}}
+	b, err := os.ReadFile(v.LockFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if err = yaml.Unmarshal(b, v.lock); err != nil {
+		return errors.WrapPrefixf(err, "reading lock file %s", v.LockFile)
+	}
+	if v.lock.Images == nil {
+		v.lock.Images = map[string]string{}
+	}
+	return nil
+}
+
+func (v *ImageVerifier) saveLock() error {
+	b, err := yaml.Marshal(v.lock)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if err = os.WriteFile(v.LockFile, b, 0o600); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
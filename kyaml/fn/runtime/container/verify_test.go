@@ -0,0 +1,110 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCosign verifies the images of example.com/signed, which all
+// have digest sha256:abc, logging its arguments to $0.log.
+const fakeCosign = `#!/bin/sh
+echo "$@" >> "$0.log"
+for ref; do :; done
+case "$ref" in
+example.com/signed*)
+  echo '[{"critical":{"image":{"docker-manifest-digest":"sha256:abc"}}}]'
+  ;;
+*)
+  echo "Error: no matching signatures" >&2
+  exit 1
+  ;;
+esac
+`
+
+func writeFakeCosign(t *testing.T) string {
+	t.Helper()
+	cosign := filepath.Join(t.TempDir(), "cosign")
+	require.NoError(t, os.WriteFile(cosign, []byte(fakeCosign), 0o700))
+	return cosign
+}
+
+func TestImageVerifier_Verify(t *testing.T) {
+	cosign := writeFakeCosign(t)
+	v := &ImageVerifier{Command: cosign, Key: "cosign.pub"}
+
+	ref, err := v.Verify("example.com/signed@sha256:abc")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/signed@sha256:abc", ref)
+	// verified once only
+	ref, err = v.Verify("example.com/signed@sha256:abc")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/signed@sha256:abc", ref)
+	log, err := os.ReadFile(cosign + ".log")
+	require.NoError(t, err)
+	assert.Equal(t,
+		"verify --output json --key cosign.pub example.com/signed@sha256:abc\n", string(log))
+
+	_, err = v.Verify("example.com/signed@sha256:def")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is for digest sha256:abc")
+
+	_, err = v.Verify("example.com/unsigned@sha256:abc")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no matching signatures")
+
+	_, err = v.Verify("example.com/signed:v1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be referenced by digest")
+}
+
+func TestImageVerifier_VerifyKeyless(t *testing.T) {
+	cosign := writeFakeCosign(t)
+	v := &ImageVerifier{Command: cosign}
+	_, err := v.Verify("example.com/signed@sha256:abc")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a certificate identity and OIDC issuer")
+
+	v.CertificateIdentity = "ci@example.com"
+	v.CertificateOIDCIssuer = "https://issuer.example.com"
+	_, err = v.Verify("example.com/signed@sha256:abc")
+	require.NoError(t, err)
+	log, err := os.ReadFile(cosign + ".log")
+	require.NoError(t, err)
+	assert.Equal(t, "verify --output json --certificate-identity ci@example.com "+
+		"--certificate-oidc-issuer https://issuer.example.com example.com/signed@sha256:abc\n", string(log))
+}
+
+func TestImageVerifier_VerifyLockFile(t *testing.T) {
+	cosign := writeFakeCosign(t)
+	lockFile := filepath.Join(t.TempDir(), "functions.lock.yaml")
+	require.NoError(t, os.WriteFile(lockFile, []byte(`images:
+  example.com/signed:v0: sha256:def
+`), 0o600))
+	v := &ImageVerifier{Command: cosign, Key: "cosign.pub", LockFile: lockFile}
+
+	ref, err := v.Verify("example.com/signed:v1")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/signed:v1@sha256:abc", ref)
+	lock, err := os.ReadFile(lockFile)
+	require.NoError(t, err)
+	assert.Equal(t, `images:
+  example.com/signed:v0: sha256:def
+  example.com/signed:v1: sha256:abc
+`, string(lock))
+
+	// the recorded digest is verified rather than the tag
+	_, err = v.Verify("example.com/signed:v0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "example.com/signed:v0@sha256:def is for digest sha256:abc")
+	log, err := os.ReadFile(cosign + ".log")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(log), " example.com/signed:v0@sha256:def\n"))
+}
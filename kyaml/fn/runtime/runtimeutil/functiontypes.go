@@ -137,6 +137,9 @@ type FunctionSpec struct {
 
 	// ExecSpec is the spec for running a function as an executable
 	Exec ExecSpec `json:"exec,omitempty" yaml:"exec,omitempty"`
+
+	// Wasm is the spec for running a function as a WebAssembly module
+	Wasm WasmSpec `json:"wasm,omitempty" yaml:"wasm,omitempty"`
 }
 
 type ExecSpec struct {
@@ -145,7 +148,8 @@ type ExecSpec struct {
 
 // ContainerSpec defines a spec for running a function as a container
 type ContainerSpec struct {
-	// Image is the container image to run
+	// Image is the container image to run, or an OCI layout directory
+	// of it prefixed with oci:, e.g. oci:images/my-fn
 	Image string `json:"image,omitempty" yaml:"image,omitempty"`
 
 	// Network defines network specific configuration
@@ -169,6 +173,16 @@ type StarlarkSpec struct {
 	URL string `json:"url,omitempty" yaml:"url,omitempty"`
 }
 
+// WasmSpec defines how to run a function as a WebAssembly module
+type WasmSpec struct {
+	// Path specifies a path to a module compiled for WASI
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Image is an OCI artifact holding the module, as
+	// oci://registry/repo[:tag][@digest][//path]
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+}
+
 // StorageMount represents a container's mounted storage option(s)
 type StorageMount struct {
 	// Type of mount e.g. bind mount, local volume, etc.
@@ -0,0 +1,10 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wasm contains the WebAssembly function implementation.
+//
+// A function is a WebAssembly module compiled for WASI which reads a
+// ResourceList from stdin and writes the result to stdout.  The module
+// is run in-process by the wazero runtime, which gives it no access to
+// the filesystem, the network or the environment.
+package wasm
@@ -0,0 +1,67 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type Filter struct {
+	// Path is the path to the WebAssembly module to run
+	Path string `yaml:"path,omitempty"`
+
+	runtimeutil.FunctionFilter
+}
+
+func (f *Filter) String() string {
+	return fmt.Sprintf("wasm: path: %v", f.Path)
+}
+
+func (f *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	f.FunctionFilter.Run = f.Run
+	return f.FunctionFilter.Filter(nodes)
+}
+
+func (f *Filter) Run(reader io.Reader, writer io.Writer) error {
+	if f.Path == "" {
+		return errors.Errorf("no module path set for wasm function")
+	}
+	module, err := os.ReadFile(f.Path)
+	if err != nil {
+		return errors.WrapPrefixf(err, "wasm function module")
+	}
+
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+
+	// no filesystem, environment or arguments, so the module sees
+	// nothing of the host but its standard streams
+	config := wazero.NewModuleConfig().
+		WithStdin(reader).
+		WithStdout(writer).
+		WithStderr(os.Stderr)
+	m, err := r.InstantiateWithConfig(ctx, module, config)
+	if m != nil {
+		defer m.Close(ctx)
+	}
+	if exitErr, ok := err.(*sys.ExitError); ok && exitErr.ExitCode() == 0 { //nolint:errorlint
+		return nil
+	}
+	if err != nil {
+		return errors.WrapPrefixf(err, "running wasm function %s", f.Path)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// echoModule is a WASI module which copies stdin to stdout.
+var echoModule = []byte("\x00asm\x01\x00\x00\x00" +
+	// types: (i32, i32, i32, i32) -> i32 and () -> ()
+	"\x01\x0c\x02\x60\x04\x7f\x7f\x7f\x7f\x01\x7f\x60\x00\x00" +
+	// imports: fd_read and fd_write
+	"\x02\x44\x02" +
+	"\x16wasi_snapshot_preview1\x07fd_read\x00\x00" +
+	"\x16wasi_snapshot_preview1\x08fd_write\x00\x00" +
+	// functions: _start
+	"\x03\x02\x01\x01" +
+	// memory: one page
+	"\x05\x03\x01\x00\x01" +
+	// exports: memory and _start
+	"\x07\x13\x02\x06memory\x02\x00\x06_start\x00\x02" +
+	// code of _start: read up to 4096 bytes at 64 and write them
+	// until the end of stdin
+	"\x0a\x47\x01\x45\x01\x01\x7f" +
+	"\x02\x40\x03\x40" +
+	"\x41\x00\x41\xc0\x00\x36\x02\x00" +
+	"\x41\x04\x41\x80\x20\x36\x02\x00" +
+	"\x41\x00\x41\x00\x41\x01\x41\x08\x10\x00\x1a" +
+	"\x41\x08\x28\x02\x00\x21\x00" +
+	"\x20\x00\x45\x0d\x01" +
+	"\x41\x04\x20\x00\x36\x02\x00" +
+	"\x41\x01\x41\x00\x41\x01\x41\x08\x10\x01\x1a" +
+	"\x0c\x00\x0b\x0b\x0b")
+
+func TestFilter(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Join(dir, "fn.wasm")
+	require.NoError(t, os.WriteFile(module, echoModule, 0600))
+
+	input, err := kio.FromBytes([]byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`))
+	require.NoError(t, err)
+	f := &wasm.Filter{Path: module}
+	output, err := f.Filter(input)
+	require.NoError(t, err)
+	if assert.Len(t, output, 1) {
+		assert.Equal(t, "Deployment", output[0].GetKind())
+		assert.Equal(t, "foo", output[0].GetName())
+	}
+	assert.Equal(t, "wasm: path: "+module, f.String())
+}
+
+func TestFilter_Errors(t *testing.T) {
+	dir := t.TempDir()
+	input, err := kio.FromBytes([]byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`))
+	require.NoError(t, err)
+
+	_, err = (&wasm.Filter{}).Filter(input)
+	assert.EqualError(t, err, "no module path set for wasm function")
+
+	_, err = (&wasm.Filter{Path: filepath.Join(dir, "missing.wasm")}).Filter(input)
+	assert.ErrorContains(t, err, "wasm function module")
+
+	invalid := filepath.Join(dir, "invalid.wasm")
+	require.NoError(t, os.WriteFile(invalid, []byte("\x00asm"), 0600))
+	_, err = (&wasm.Filter{Path: invalid}).Filter(input)
+	assert.ErrorContains(t, err, "running wasm function "+invalid)
+}
@@ -11,6 +11,7 @@ require (
 	github.com/sergi/go-diff v1.1.0
 	github.com/spf13/cobra v1.7.0
 	github.com/stretchr/testify v1.8.1
+	github.com/tetratelabs/wazero v1.5.0
 	github.com/xlab/treeprint v1.2.0
 	go.starlark.net v0.0.0-20200306205701-8dd3e2ee1dd5
 	golang.org/x/sys v0.13.0
@@ -50,6 +50,9 @@ type ByteReadWriter struct {
 	// note that this wrapping is different and not related to ResourceList wrapping
 	WrapBareSeqNode bool
 
+	// LazyParse if true reads Resources as lazy RNodes. See ByteReader.
+	LazyParse bool
+
 	FunctionConfig *yaml.RNode
 
 	Results *yaml.RNode
@@ -65,6 +68,7 @@ func (rw *ByteReadWriter) Read() ([]*yaml.RNode, error) {
 		OmitReaderAnnotations: rw.OmitReaderAnnotations,
 		PreserveSeqIndent:     rw.PreserveSeqIndent,
 		WrapBareSeqNode:       rw.WrapBareSeqNode,
+		LazyParse:             rw.LazyParse,
 	}
 	val, err := b.Read()
 	rw.Results = b.Results
@@ -160,6 +164,12 @@ type ByteReader struct {
 	// AnchorsAweigh set to true attempts to replace all YAML anchor aliases
 	// with their definitions (anchor values) immediately after the read.
 	AnchorsAweigh bool
+
+	// LazyParse if true reads Resources as lazy RNodes, parsing only their
+	// apiVersion, kind and metadata until a Filter needs their other fields.
+	// ByteWriter writes the Resources no Filter parsed as they were read.
+	// See yaml.NewLazyRNode.
+	LazyParse bool
 }
 
 var _ Reader = &ByteReader{}
@@ -288,32 +298,39 @@ func (r *ByteReader) Read() ([]*yaml.RNode, error) {
 }
 
 func (r *ByteReader) decode(originalYAML string, index int, decoder *yaml.Decoder) (*yaml.RNode, error) {
-	node := &yaml.Node{}
-	err := decoder.Decode(node)
-	if err == io.EOF {
-		return nil, io.EOF
-	}
-	if err != nil {
-		return nil, errors.WrapPrefixf(err, "MalformedYAMLError")
+	var n *yaml.RNode
+	if r.LazyParse {
+		n = yaml.NewLazyRNode(originalYAML)
 	}
+	if n == nil {
+		node := &yaml.Node{}
+		err := decoder.Decode(node)
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "MalformedYAMLError")
+		}
+
+		if yaml.IsYNodeEmptyDoc(node) {
+			return nil, nil
+		}
 
-	if yaml.IsYNodeEmptyDoc(node) {
-		return nil, nil
+		n = yaml.NewRNode(node)
+		// check if it is a bare sequence node and wrap it with a yaml.BareSeqNodeWrappingKey
+		if r.WrapBareSeqNode && node.Kind == yaml.DocumentNode && len(node.Content) > 0 &&
+			node.Content[0] != nil && node.Content[0].Kind == yaml.SequenceNode {
+			wrappedNode := yaml.NewRNode(&yaml.Node{
+				Kind: yaml.MappingNode,
+			})
+			wrappedNode.PipeE(yaml.SetField(yaml.BareSeqNodeWrappingKey, n))
+			n = wrappedNode
+		}
 	}
 
 	// set annotations on the read Resources
 	// sort the annotations by key so the output Resources is consistent (otherwise the
 	// annotations will be in a random order)
-	n := yaml.NewRNode(node)
-	// check if it is a bare sequence node and wrap it with a yaml.BareSeqNodeWrappingKey
-	if r.WrapBareSeqNode && node.Kind == yaml.DocumentNode && len(node.Content) > 0 &&
-		node.Content[0] != nil && node.Content[0].Kind == yaml.SequenceNode {
-		wrappedNode := yaml.NewRNode(&yaml.Node{
-			Kind: yaml.MappingNode,
-		})
-		wrappedNode.PipeE(yaml.SetField(yaml.BareSeqNodeWrappingKey, n))
-		n = wrappedNode
-	}
 
 	if r.SetAnnotations == nil {
 		r.SetAnnotations = map[string]string{}
@@ -340,7 +357,7 @@ func (r *ByteReader) decode(originalYAML string, index int, decoder *yaml.Decode
 	}
 	sort.Strings(keys)
 	for _, k := range keys {
-		_, err = n.Pipe(yaml.SetAnnotation(k, r.SetAnnotations[k]))
+		_, err := n.Pipe(yaml.SetAnnotation(k, r.SetAnnotations[k]))
 		if err != nil {
 			return nil, errors.Wrap(err)
 		}
@@ -5,10 +5,13 @@ package kio_test
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -866,3 +869,229 @@ results:
 		})
 	}
 }
+
+func TestByteReadWriter_LazyParse(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          string
+		filter         kio.FilterFunc
+		expectedOutput string
+		expectedLazy   []bool
+	}{
+		{
+			name: "pass through",
+			input: `# a comment
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+    name: foos.example.com   # the name
+spec:
+    group: example.com
+    versions:
+        -   name: v1
+            schema:
+                openAPIV3Schema: {type: object}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data: {"a": 'b'}
+`,
+			expectedOutput: `# a comment
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+    name: foos.example.com   # the name
+spec:
+    group: example.com
+    versions:
+        -   name: v1
+            schema:
+                openAPIV3Schema: {type: object}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data: {"a": 'b'}
+`,
+			expectedLazy: []bool{true, true},
+		},
+		{
+			name: "metadata changed",
+			input: `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+    name: foos.example.com
+spec:
+    group: example.com
+`,
+			filter: func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+				return nodes, nodes[0].SetLabels(map[string]string{"app": "foo"})
+			},
+			expectedOutput: `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+  labels:
+    app: foo
+spec:
+    group: example.com
+`,
+			expectedLazy: []bool{true},
+		},
+		{
+			name: "spec changed",
+			input: `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+    name: foos.example.com
+spec:
+    group: example.com
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: cm
+`,
+			filter: func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+				return nodes, nodes[0].PipeE(
+					yaml.Lookup("spec"), yaml.SetField("group", yaml.NewScalarRNode("example.org")))
+			},
+			expectedOutput: `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+spec:
+  group: example.org
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: cm
+`,
+			expectedLazy: []bool{false, true},
+		},
+		{
+			name: "not lazy",
+			input: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: b
+`,
+			expectedOutput: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: b
+`,
+			expectedLazy: []bool{false, true},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			rw := kio.ByteReadWriter{
+				Reader:    bytes.NewBufferString(tc.input),
+				Writer:    &out,
+				LazyParse: true,
+			}
+			nodes, err := rw.Read()
+			require.NoError(t, err)
+			if tc.filter != nil {
+				nodes, err = tc.filter(nodes)
+				require.NoError(t, err)
+			}
+			var lazy []bool
+			for _, n := range nodes {
+				lazy = append(lazy, n.IsLazy())
+			}
+			assert.Equal(t, tc.expectedLazy, lazy)
+			require.NoError(t, rw.Write(nodes))
+			assert.Equal(t, tc.expectedOutput, out.String())
+		})
+	}
+}
+
+func TestByteReadWriter_LazyParseMalformed(t *testing.T) {
+	for name, input := range map[string]string{
+		"body": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  k: [unclosed
+`,
+		"metadata": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: [cm
+`,
+	} {
+		rw := kio.ByteReadWriter{
+			Reader:    bytes.NewBufferString(input),
+			LazyParse: true,
+		}
+		_, err := rw.Read()
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), "MalformedYAMLError", name)
+		}
+	}
+}
+
+// BenchmarkByteReadWriter reads and writes CRDs with large schemas,
+// setting a label on each.
+func BenchmarkByteReadWriter(b *testing.B) {
+	var input strings.Builder
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&input, `---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos%d.example.com
+spec:
+  group: example.com
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+`, i)
+		for j := 0; j < 500; j++ {
+			fmt.Fprintf(&input, `          field%d:
+            type: string
+            description: the field %d of the foo
+`, j, j)
+		}
+	}
+	for _, lazy := range []bool{false, true} {
+		b.Run(fmt.Sprintf("lazy=%t", lazy), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rw := kio.ByteReadWriter{
+					Reader:    bytes.NewBufferString(input.String()),
+					Writer:    io.Discard,
+					LazyParse: lazy,
+				}
+				nodes, err := rw.Read()
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, n := range nodes {
+					if err = n.PipeE(yaml.SetLabel("app", "foo")); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err = rw.Write(nodes); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
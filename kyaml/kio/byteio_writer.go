@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"io"
 	"path/filepath"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
@@ -108,6 +109,10 @@ func (w ByteWriter) Write(inputNodes []*yaml.RNode) error {
 		return errors.Wrap(encoder.Encode(nodes[0]))
 	}
 
+	// write lazy nodes as they were read
+	if w.WrappingKind == "" && anyLazy(nodes) {
+		return w.writeDocuments(nodes, seqIndentsForNodes)
+	}
 	encoder := yaml.NewEncoder(w.Writer)
 	defer encoder.Close()
 	// don't wrap the elements
@@ -155,6 +160,53 @@ func (w ByteWriter) Write(inputNodes []*yaml.RNode) error {
 	return encoder.Encode(doc)
 }
 
+// writeDocuments writes nodes as documents, the lazy ones as they were
+// read, but for changes to their metadata.
+func (w ByteWriter) writeDocuments(nodes []*yaml.RNode, seqIndents []string) error {
+	for i := range nodes {
+		if i > 0 {
+			if _, err := io.WriteString(w.Writer, "---\n"); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+		if nodes[i].IsLazy() {
+			s, err := nodes[i].String()
+			if err != nil {
+				return errors.Wrap(err)
+			}
+			if s != "" && !strings.HasSuffix(s, "\n") {
+				s += "\n"
+			}
+			if _, err = io.WriteString(w.Writer, s); err != nil {
+				return errors.Wrap(err)
+			}
+			continue
+		}
+		encoder := yaml.NewEncoder(w.Writer)
+		if seqIndents[i] == string(yaml.WideSequenceStyle) {
+			encoder.DefaultSeqIndent()
+		} else {
+			encoder.CompactSeqIndent()
+		}
+		if err := encoder.Encode(upWrapBareSequenceNode(nodes[i].Document())); err != nil {
+			return errors.Wrap(err)
+		}
+		if err := encoder.Close(); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func anyLazy(nodes []*yaml.RNode) bool {
+	for i := range nodes {
+		if nodes[i].IsLazy() {
+			return true
+		}
+	}
+	return false
+}
+
 func copyRNodes(in []*yaml.RNode) []*yaml.RNode {
 	out := make([]*yaml.RNode, len(in))
 	for i := range in {
@@ -89,6 +89,9 @@ type LocalPackageReadWriter struct {
 	// sequence nodes into map node with key yaml.BareSeqNodeWrappingKey
 	// note that this wrapping is different and not related to ResourceList wrapping
 	WrapBareSeqNode bool
+
+	// LazyParse if true reads Resources as lazy RNodes. See ByteReader.
+	LazyParse bool
 }
 
 func (r *LocalPackageReadWriter) Read() ([]*yaml.RNode, error) {
@@ -103,6 +106,7 @@ func (r *LocalPackageReadWriter) Read() ([]*yaml.RNode, error) {
 		PreserveSeqIndent:   r.PreserveSeqIndent,
 		FileSystem:          r.FileSystem,
 		WrapBareSeqNode:     r.WrapBareSeqNode,
+		LazyParse:           r.LazyParse,
 	}.Read()
 	if err != nil {
 		return nil, errors.Wrap(err)
@@ -208,6 +212,9 @@ type LocalPackageReader struct {
 	// sequence nodes into map node with key yaml.BareSeqNodeWrappingKey
 	// note that this wrapping is different and not related to ResourceList wrapping
 	WrapBareSeqNode bool
+
+	// LazyParse if true reads Resources as lazy RNodes. See ByteReader.
+	LazyParse bool
 }
 
 var _ Reader = LocalPackageReader{}
@@ -303,6 +310,7 @@ func (r *LocalPackageReader) readFile(path string, _ os.FileInfo) ([]*yaml.RNode
 		SetAnnotations:        r.SetAnnotations,
 		PreserveSeqIndent:     r.PreserveSeqIndent,
 		WrapBareSeqNode:       r.WrapBareSeqNode,
+		LazyParse:             r.LazyParse,
 	}
 	return rr.Read()
 }
@@ -0,0 +1,172 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// kubernetesListTypeExtensionKey is the key to lookup the list type
+	// of structural schemas, e.g. of CRDs -- the extension is a string
+	kubernetesListTypeExtensionKey = "x-kubernetes-list-type"
+)
+
+// crdVersion is a version of a CustomResourceDefinition.
+type crdVersion struct {
+	Name   string `yaml:"name"`
+	Schema struct {
+		OpenAPIV3Schema interface{} `yaml:"openAPIV3Schema"`
+	} `yaml:"schema"`
+}
+
+// crd holds the fields of a CustomResourceDefinition needed to
+// index the schemas of its versions, or the items of a list of them.
+type crd struct {
+	Kind  string `yaml:"kind"`
+	Items []crd  `yaml:"items"`
+	Spec  struct {
+		Group string `yaml:"group"`
+		Scope string `yaml:"scope"`
+		Names struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"names"`
+		Versions []crdVersion `yaml:"versions"`
+		// v1beta1 CRDs may have a single schema for all versions
+		Validation struct {
+			OpenAPIV3Schema interface{} `yaml:"openAPIV3Schema"`
+		} `yaml:"validation"`
+	} `yaml:"spec"`
+}
+
+// AddCrdSchemas adds the schemas of the versions of the
+// CustomResourceDefinitions in b, a YAML stream of them or of lists
+// of them, as kubectl prints, in which other objects are ignored, to
+// the global schema.  Their list types and
+// map keys become patch strategies and merge keys, so that strategic
+// merge patches of the custom resources merge their lists rather
+// than replace them.
+func AddCrdSchemas(b []byte) error {
+	definitions := spec.Definitions{}
+	namespaced := map[yaml.TypeMeta]bool{}
+	var add func(c *crd) error
+	add = func(c *crd) error {
+		for i := range c.Items {
+			if err := add(&c.Items[i]); err != nil {
+				return err
+			}
+		}
+		if c.Kind != "CustomResourceDefinition" {
+			return nil
+		}
+		for _, v := range c.Spec.Versions {
+			s := v.Schema.OpenAPIV3Schema
+			if s == nil {
+				s = c.Spec.Validation.OpenAPIV3Schema
+			}
+			if s == nil {
+				continue
+			}
+			sc, err := crdSchema(s, c.Spec.Group, v.Name, c.Spec.Names.Kind)
+			if err != nil {
+				return errors.WrapPrefixf(err, "schema of %s %s/%s",
+					c.Spec.Names.Kind, c.Spec.Group, v.Name)
+			}
+			definitions[fmt.Sprintf("%s.%s.%s", c.Spec.Group, v.Name, c.Spec.Names.Kind)] = *sc
+			apiVersion := v.Name
+			if c.Spec.Group != "" {
+				apiVersion = c.Spec.Group + "/" + v.Name
+			}
+			namespaced[yaml.TypeMeta{APIVersion: apiVersion, Kind: c.Spec.Names.Kind}] =
+				c.Spec.Scope != "Cluster"
+		}
+		return nil
+	}
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var c crd
+		err := d.Decode(&c)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errors.WrapPrefixf(err, "reading CRDs")
+		}
+		if err = add(&c); err != nil {
+			return err
+		}
+	}
+
+	schemaLock.Lock()
+	defer schemaLock.Unlock()
+	AddDefinitions(definitions)
+	if globalSchema.namespaceabilityByResourceType == nil {
+		globalSchema.namespaceabilityByResourceType = map[yaml.TypeMeta]bool{}
+	}
+	for t, n := range namespaced {
+		globalSchema.namespaceabilityByResourceType[t] = n
+	}
+	return nil
+}
+
+// crdSchema converts the openAPIV3Schema of a version of a CRD to a
+// definition of the global schema.
+func crdSchema(openAPIV3Schema interface{}, group, version, kind string) (*spec.Schema, error) {
+	j, err := json.Marshal(openAPIV3Schema)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var sc spec.Schema
+	if err = sc.UnmarshalJSON(j); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	addPatchStrategies(&sc)
+	sc.AddExtension(kubernetesGVKExtensionKey, []interface{}{
+		map[string]interface{}{groupKey: group, versionKey: version, kindKey: kind},
+	})
+	return &sc, nil
+}
+
+// addPatchStrategies sets the patch strategy and merge key of the
+// lists of the schema, and of those of its fields and elements, whose
+// list type is map or set, unless already set.
+func addPatchStrategies(sc *spec.Schema) {
+	if sc == nil {
+		return
+	}
+	if _, found := sc.Extensions[kubernetesPatchStrategyExtensionKey]; !found {
+		switch listType, _ := sc.Extensions.GetString(kubernetesListTypeExtensionKey); listType {
+		case "map":
+			keys, _ := sc.Extensions[kubernetesMergeKeyMapList].([]interface{})
+			if len(keys) > 0 {
+				sc.AddExtension(kubernetesPatchStrategyExtensionKey, "merge")
+				if _, found := sc.Extensions[kubernetesMergeKeyExtensionKey]; !found {
+					sc.AddExtension(kubernetesMergeKeyExtensionKey, keys[0])
+				}
+			}
+		case "set":
+			sc.AddExtension(kubernetesPatchStrategyExtensionKey, "merge")
+		}
+	}
+	for k, p := range sc.Properties {
+		addPatchStrategies(&p)
+		sc.Properties[k] = p
+	}
+	if sc.Items != nil {
+		addPatchStrategies(sc.Items.Schema)
+		for i := range sc.Items.Schemas {
+			addPatchStrategies(&sc.Items.Schemas[i])
+		}
+	}
+	if sc.AdditionalProperties != nil {
+		addPatchStrategies(sc.AdditionalProperties.Schema)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const gatewayCrd = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gateways.example.com
+spec:
+  group: example.com
+  scope: Cluster
+  names:
+    kind: Gateway
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              listeners:
+                type: array
+                x-kubernetes-list-type: map
+                x-kubernetes-list-map-keys:
+                - name
+                - port
+                items:
+                  type: object
+                  properties:
+                    hostnames:
+                      type: array
+                      x-kubernetes-list-type: set
+                      items:
+                        type: string
+              addresses:
+                type: array
+                x-kubernetes-list-type: atomic
+                items:
+                  type: string
+`
+
+func TestAddCrdSchemas(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+	require.NoError(t, AddCrdSchemas([]byte(gatewayCrd)))
+
+	gateway := yaml.TypeMeta{APIVersion: "example.com/v1", Kind: "Gateway"}
+	s := SchemaForResourceType(gateway)
+	require.NotNil(t, s)
+
+	strategy, keys := s.Lookup("spec", "listeners").PatchStrategyAndKeyList()
+	assert.Equal(t, "merge", strategy)
+	assert.Equal(t, []string{"name", "port"}, keys)
+	strategy, key := s.Lookup("spec", "listeners").PatchStrategyAndKey()
+	assert.Equal(t, "merge", strategy)
+	assert.Equal(t, "name", key)
+
+	strategy, keys = s.Lookup("spec", "listeners", "[]", "hostnames").PatchStrategyAndKeyList()
+	assert.Equal(t, "merge", strategy)
+	assert.Empty(t, keys)
+
+	strategy, _ = s.Lookup("spec", "addresses").PatchStrategyAndKeyList()
+	assert.Empty(t, strategy)
+
+	namespaced, found := IsNamespaceScoped(gateway)
+	assert.True(t, found)
+	assert.False(t, namespaced)
+}
+
+func TestAddCrdSchemasFromList(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+	require.NoError(t, AddCrdSchemas([]byte(`
+apiVersion: v1
+kind: List
+items:
+- apiVersion: apiextensions.k8s.io/v1
+  kind: CustomResourceDefinition
+  metadata:
+    name: routes.example.com
+  spec:
+    group: example.com
+    scope: Namespaced
+    names:
+      kind: Route
+    versions:
+    - name: v1alpha1
+      schema:
+        openAPIV3Schema:
+          type: object
+`)))
+	route := yaml.TypeMeta{APIVersion: "example.com/v1alpha1", Kind: "Route"}
+	assert.NotNil(t, SchemaForResourceType(route))
+	namespaced, found := IsNamespaceScoped(route)
+	assert.True(t, found)
+	assert.True(t, namespaced)
+}
+
+func TestAddCrdSchemasInvalid(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+	err := AddCrdSchemas([]byte("kind: [CustomResourceDefinition"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading CRDs")
+}
@@ -633,13 +633,15 @@ func SetSchema(openAPIField map[string]string, schema []byte, reset bool) error
 	}
 
 	// use builtin version
-	kubernetesOpenAPIVersion = version
-	if kubernetesOpenAPIVersion == "" {
+	if version == "" {
+		kubernetesOpenAPIVersion = ""
 		return nil
 	}
-	if _, ok := kubernetesapi.OpenAPIMustAsset[kubernetesOpenAPIVersion]; !ok {
+	builtin, ok := BuiltinVersion(version)
+	if !ok {
 		return fmt.Errorf("the specified OpenAPI version is not built in")
 	}
+	kubernetesOpenAPIVersion = builtin
 
 	customSchema = nil
 	// if the schema is changed, initSchema should parse the new schema
@@ -647,6 +649,22 @@ func SetSchema(openAPIField map[string]string, schema []byte, reset bool) error
 	return nil
 }
 
+// BuiltinVersion returns the builtin Kubernetes OpenAPI version that
+// version names, either fully, e.g. v1.21.2, or by its minor version,
+// e.g. v1.21 or 1.21.
+func BuiltinVersion(version string) (string, bool) {
+	version = "v" + strings.TrimPrefix(version, "v")
+	if _, ok := kubernetesapi.OpenAPIMustAsset[version]; ok {
+		return version, true
+	}
+	for builtin := range kubernetesapi.OpenAPIMustAsset {
+		if strings.HasPrefix(builtin, version+".") {
+			return builtin, true
+		}
+	}
+	return "", false
+}
+
 // GetSchemaVersion returns what kubernetes OpenAPI version is being used
 func GetSchemaVersion() string {
 	schemaLock.RLock()
@@ -393,3 +393,29 @@ func TestCanSetAndResetSchemaConcurrently(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestBuiltinVersion(t *testing.T) {
+	for version, expected := range map[string]string{
+		"v1.21.2": "v1.21.2",
+		"1.21.2":  "v1.21.2",
+		"v1.21":   "v1.21.2",
+		"1.21":    "v1.21.2",
+		"v1.2":    "",
+		"v1.21.3": "",
+		"1.29":    "",
+	} {
+		builtin, ok := BuiltinVersion(version)
+		assert.Equal(t, expected, builtin, version)
+		assert.Equal(t, expected != "", ok, version)
+	}
+}
+
+func TestSetSchemaMinorVersion(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+	require.NoError(t, SetSchema(map[string]string{"version": "1.21"}, nil, true))
+	assert.Equal(t, "v1.21.2", GetSchemaVersion())
+	require.EqualError(t, SetSchema(map[string]string{"version": "v1.14"}, nil, true),
+		"the specified OpenAPI version is not built in")
+	assert.Equal(t, "v1.21.2", GetSchemaVersion())
+}
@@ -0,0 +1,233 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// kubernetesPreserveUnknownFieldsExtensionKey marks objects of
+	// structural schemas whose fields aren't all declared
+	kubernetesPreserveUnknownFieldsExtensionKey = "x-kubernetes-preserve-unknown-fields"
+
+	// kubernetesIntOrStringExtensionKey marks fields of structural
+	// schemas that are either integers or strings
+	kubernetesIntOrStringExtensionKey = "x-kubernetes-int-or-string"
+)
+
+// ValidationError is a field of a resource that doesn't match the
+// schema of its type.
+type ValidationError struct {
+	// Path is the path of the field, e.g.
+	// spec.template.spec.containers[0].image.
+	Path string
+
+	// Message tells how the field doesn't match the schema.
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Validate checks the resource in node against the schema of its
+// type, reporting unknown fields, fields of the wrong type and missing
+// required fields.  The second value is false if there's no schema
+// for its type, in which case it isn't checked.
+func Validate(node *yaml.RNode) ([]ValidationError, bool) {
+	meta, err := node.GetMeta()
+	if err != nil {
+		return nil, false
+	}
+	rs := SchemaForResourceType(meta.TypeMeta)
+	if rs == nil {
+		return nil, false
+	}
+	v := &validator{root: Schema()}
+	v.validate(node.YNode(), rs.Schema, "", false)
+	return v.errs, true
+}
+
+// typeMetaFields are the fields of every resource.
+var typeMetaFields = map[string]bool{
+	yaml.APIVersionField: true, yaml.KindField: true, yaml.MetadataField: true,
+}
+
+type validator struct {
+	root *spec.Schema
+	errs []ValidationError
+}
+
+func (v *validator) errorf(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "."
+	}
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// resolve follows the reference of s, if any; quantity tells whether
+// it's a Quantity, which may be given as a number.
+func (v *validator) resolve(s *spec.Schema) (resolved *spec.Schema, quantity bool) {
+	for s != nil && s.Ref.String() != "" {
+		quantity = quantity || strings.HasSuffix(s.Ref.String(), ".Quantity")
+		r, err := resolve(v.root, &s.Ref)
+		if err != nil {
+			return nil, false
+		}
+		s = r
+	}
+	return s, quantity
+}
+
+func (v *validator) validate(n *yaml.Node, s *spec.Schema, path string, quantity bool) {
+	s, q := v.resolve(s)
+	if s == nil {
+		return
+	}
+	quantity = quantity || q
+	for i := range s.AllOf {
+		v.validate(n, &s.AllOf[i], path, quantity)
+	}
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.ShortTag() == yaml.NodeTagNull {
+		return
+	}
+	if len(s.Type) == 0 {
+		if len(s.Properties) > 0 {
+			v.validateObject(n, s, path)
+		}
+		return
+	}
+	switch {
+	case s.Type.Contains("object"):
+		if n.Kind != yaml.MappingNode {
+			v.errorf(path, "expected object, got %s", describe(n))
+			return
+		}
+		v.validateObject(n, s, path)
+	case s.Type.Contains("array"):
+		if n.Kind != yaml.SequenceNode {
+			v.errorf(path, "expected array, got %s", describe(n))
+			return
+		}
+		if s.Items == nil || s.Items.Schema == nil {
+			return
+		}
+		for i, e := range n.Content {
+			v.validate(e, s.Items.Schema, fmt.Sprintf("%s[%d]", path, i), false)
+		}
+	default:
+		v.validateScalar(n, s, path, quantity)
+	}
+}
+
+func (v *validator) validateObject(n *yaml.Node, s *spec.Schema, path string) {
+	present := map[string]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i].Value, n.Content[i+1]
+		present[key] = true
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		if p, ok := s.Properties[key]; ok {
+			v.validate(value, &p, fieldPath, false)
+			continue
+		}
+		if path == "" && typeMetaFields[key] {
+			// CRD schemas may leave out the fields every resource has
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			if s.AdditionalProperties.Schema != nil {
+				v.validate(value, s.AdditionalProperties.Schema, fieldPath, false)
+				continue
+			}
+			if s.AdditionalProperties.Allows {
+				continue
+			}
+		} else if len(s.Properties) == 0 {
+			// a free-form object
+			continue
+		}
+		if preserve, _ := s.Extensions.GetBool(kubernetesPreserveUnknownFieldsExtensionKey); preserve {
+			continue
+		}
+		v.errorf(fieldPath, "unknown field")
+	}
+	required := append([]string{}, s.Required...)
+	sort.Strings(required)
+	for _, r := range required {
+		if !present[r] {
+			fieldPath := r
+			if path != "" {
+				fieldPath = path + "." + r
+			}
+			v.errorf(fieldPath, "missing required field")
+		}
+	}
+}
+
+func (v *validator) validateScalar(n *yaml.Node, s *spec.Schema, path string, quantity bool) {
+	if n.Kind != yaml.ScalarNode {
+		v.errorf(path, "expected %s, got %s", strings.Join(s.Type, " or "), describe(n))
+		return
+	}
+	tag := n.ShortTag()
+	intOrString, _ := s.Extensions.GetBool(kubernetesIntOrStringExtensionKey)
+	intOrString = intOrString || s.Format == "int-or-string"
+	for _, t := range s.Type {
+		switch t {
+		case "string":
+			if tag == yaml.NodeTagString ||
+				(tag == yaml.NodeTagInt && intOrString) ||
+				((tag == yaml.NodeTagInt || tag == yaml.NodeTagFloat) && quantity) {
+				return
+			}
+		case "integer":
+			if tag == yaml.NodeTagInt {
+				return
+			}
+		case "number":
+			if tag == yaml.NodeTagInt || tag == yaml.NodeTagFloat {
+				return
+			}
+		case "boolean":
+			if tag == yaml.NodeTagBool {
+				return
+			}
+		default:
+			return
+		}
+	}
+	v.errorf(path, "expected %s, got %s", strings.Join(s.Type, " or "), describe(n))
+}
+
+// describe returns the type of a node, as named by schemas.
+func describe(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch n.ShortTag() {
+	case yaml.NodeTagInt:
+		return fmt.Sprintf("integer %s", n.Value)
+	case yaml.NodeTagFloat:
+		return fmt.Sprintf("number %s", n.Value)
+	case yaml.NodeTagBool:
+		return fmt.Sprintf("boolean %s", n.Value)
+	default:
+		return fmt.Sprintf("string %q", n.Value)
+	}
+}
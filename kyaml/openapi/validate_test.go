@@ -0,0 +1,153 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestValidate(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+	testCases := map[string]struct {
+		resource string
+		expected []string
+	}{
+		"valid": {`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        ports:
+        - containerPort: 80
+        resources:
+          limits:
+            cpu: 1
+            memory: 1Gi
+        livenessProbe:
+          httpGet:
+            port: 8080
+`, nil},
+		"invalid": {`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels: [app]
+spec:
+  replicas: "3"
+  selector:
+    matchLabels:
+      app: web
+  template:
+    spec:
+      containers:
+      - image: nginx
+        imagePullPolicy: Always
+        cmd: [nginx]
+        ports:
+        - containerPort: 80
+          hostIP: true
+`, []string{
+			"metadata.labels: expected object, got array",
+			"spec.replicas: expected integer, got string \"3\"",
+			"spec.template.spec.containers[0].cmd: unknown field",
+			"spec.template.spec.containers[0].ports[0].hostIP: expected string, got boolean true",
+			"spec.template.spec.containers[0].name: missing required field",
+		}},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			errs, found := Validate(yaml.MustParse(tc.resource))
+			require.True(t, found)
+			var actual []string
+			for _, e := range errs {
+				actual = append(actual, e.Error())
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestValidateCrd(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+	require.NoError(t, AddCrdSchemas([]byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          metadata:
+            type: object
+          spec:
+            type: object
+            required: [size]
+            properties:
+              size:
+                x-kubernetes-int-or-string: true
+              extra:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+`)))
+	errs, found := Validate(yaml.MustParse(`
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+  annotations:
+    a: b
+spec:
+  size: 3
+  extra:
+    anything: goes
+  color: blue
+`))
+	require.True(t, found)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "spec.color: unknown field", errs[0].Error())
+
+	errs, _ = Validate(yaml.MustParse(`
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec: {}
+`))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "spec.size: missing required field", errs[0].Error())
+
+	_, found = Validate(yaml.MustParse(`
+apiVersion: example.com/v2
+kind: Widget
+metadata:
+  name: w
+`))
+	assert.False(t, found)
+}
@@ -20,6 +20,7 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -67,6 +68,23 @@ type RunFns struct {
 	// EnableExec will enable exec functions
 	EnableExec bool
 
+	// EnableWasm will enable functions run as WebAssembly modules
+	EnableWasm bool
+
+	// WasmImageLoader fetches the OCI artifact of a WebAssembly function
+	// image and returns the path of its module.  Functions from images
+	// fail if it is nil.
+	WasmImageLoader func(image string) (string, error)
+
+	// ContainerRuntime is the container runtime executable that runs
+	// container functions, detected by container.DetectRuntime if empty
+	ContainerRuntime string
+
+	// VerifyImage, if set, is called with the image of each container
+	// function before it runs, and returns the image to run, e.g. pinned
+	// to its digest, or an error if the image mustn't run
+	VerifyImage func(image string) (string, error)
+
 	// DisableContainers will disable functions run as containers
 	DisableContainers bool
 
@@ -480,9 +498,16 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 		storageMounts := spec.Container.StorageMounts
 		storageMounts = append(storageMounts, r.StorageMounts...)
 
+		image := spec.Container.Image
+		if r.VerifyImage != nil {
+			if image, err = r.VerifyImage(image); err != nil {
+				return nil, err
+			}
+		}
+
 		c := container.NewContainer(
 			runtimeutil.ContainerSpec{
-				Image:         spec.Container.Image,
+				Image:         image,
 				Network:       spec.Container.Network,
 				StorageMounts: storageMounts,
 				Env:           spec.Container.Env,
@@ -490,6 +515,7 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 			uidgid,
 		)
 		cf := &c
+		cf.Runtime = r.ContainerRuntime
 		cf.Exec.FunctionConfig = api
 		cf.Exec.GlobalScope = r.GlobalScope
 		cf.Exec.ResultsFile = resultsFile
@@ -505,22 +531,9 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 
 		var p string
 		if spec.Starlark.Path != "" {
-			pathAnno := m.Annotations[kioutil.PathAnnotation]
-			if pathAnno == "" {
-				pathAnno = m.Annotations[kioutil.LegacyPathAnnotation]
-			}
-			p = filepath.ToSlash(path.Clean(pathAnno))
-
-			spec.Starlark.Path = filepath.ToSlash(path.Clean(spec.Starlark.Path))
-			if filepath.IsAbs(spec.Starlark.Path) || path.IsAbs(spec.Starlark.Path) {
-				return nil, errors.Errorf(
-					"absolute function path %s not allowed", spec.Starlark.Path)
-			}
-			if strings.HasPrefix(spec.Starlark.Path, "..") {
-				return nil, errors.Errorf(
-					"function path %s not allowed to start with ../", spec.Starlark.Path)
+			if p, err = r.functionPath(m, spec.Starlark.Path); err != nil {
+				return nil, err
 			}
-			p = filepath.ToSlash(filepath.Join(r.Path, filepath.Dir(p), spec.Starlark.Path))
 		}
 
 		sf := &starlark.Filter{Name: spec.Starlark.Name, Path: p, URL: spec.Starlark.URL}
@@ -532,6 +545,37 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 		return sf, nil
 	}
 
+	if r.EnableWasm && (spec.Wasm.Path != "" || spec.Wasm.Image != "") {
+		var p string
+		if spec.Wasm.Image != "" {
+			if r.WasmImageLoader == nil {
+				return nil, errors.Errorf(
+					"wasm function image %s can't be loaded", spec.Wasm.Image)
+			}
+			var err error
+			if p, err = r.WasmImageLoader(spec.Wasm.Image); err != nil {
+				return nil, errors.WrapPrefixf(err, "wasm function image %s", spec.Wasm.Image)
+			}
+		} else {
+			// the module path is relative to the function config file
+			m, err := api.GetMeta()
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			if p, err = r.functionPath(m, spec.Wasm.Path); err != nil {
+				return nil, err
+			}
+		}
+
+		wf := &wasm.Filter{Path: p}
+
+		wf.FunctionConfig = api
+		wf.GlobalScope = r.GlobalScope
+		wf.ResultsFile = resultsFile
+		wf.DeferFailure = spec.DeferFailure
+		return wf, nil
+	}
+
 	if r.EnableExec && spec.Exec.Path != "" {
 		ef := &exec.Filter{
 			Path:       spec.Exec.Path,
@@ -547,3 +591,24 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 
 	return nil, nil
 }
+
+// functionPath resolves the path of a function script or module, which
+// must be relative to the function config file, to a path below r.Path.
+func (r *RunFns) functionPath(m yaml.ResourceMeta, fnPath string) (string, error) {
+	pathAnno := m.Annotations[kioutil.PathAnnotation]
+	if pathAnno == "" {
+		pathAnno = m.Annotations[kioutil.LegacyPathAnnotation]
+	}
+	p := filepath.ToSlash(path.Clean(pathAnno))
+
+	fnPath = filepath.ToSlash(path.Clean(fnPath))
+	if filepath.IsAbs(fnPath) || path.IsAbs(fnPath) {
+		return "", errors.Errorf(
+			"absolute function path %s not allowed", fnPath)
+	}
+	if strings.HasPrefix(fnPath, "..") {
+		return "", errors.Errorf(
+			"function path %s not allowed to start with ../", fnPath)
+	}
+	return filepath.ToSlash(filepath.Join(r.Path, filepath.Dir(p), fnPath)), nil
+}
@@ -14,6 +14,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/kyaml/copyutil"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -103,6 +104,38 @@ kind:
 	assert.Equal(t, cf, filter)
 }
 
+func TestRunFns_initVerifyImage(t *testing.T) {
+	instance := RunFns{
+		VerifyImage: func(image string) (string, error) {
+			if image != "example.com:version" {
+				return "", fmt.Errorf("unsigned image %s", image)
+			}
+			return image + "@sha256:abc", nil
+		},
+	}
+	instance.init()
+	api, err := yaml.Parse(`apiVersion: apps/v1
+kind: 
+`)
+	require.NoError(t, err)
+
+	spec := runtimeutil.FunctionSpec{
+		Container: runtimeutil.ContainerSpec{
+			Image: "example.com:version",
+		},
+	}
+	filter, err := instance.functionFilterProvider(spec, api, currentUser)
+	require.NoError(t, err)
+	c := container.NewContainer(runtimeutil.ContainerSpec{Image: "example.com:version@sha256:abc"}, "nobody")
+	cf := &c
+	cf.Exec.FunctionConfig = api
+	assert.Equal(t, cf, filter)
+
+	spec.Container.Image = "example.com:other"
+	_, err = instance.functionFilterProvider(spec, api, currentUser)
+	require.EqualError(t, err, "unsigned image example.com:other")
+}
+
 func TestRunFns_Execute__initGlobalScope(t *testing.T) {
 	instance := RunFns{GlobalScope: true}
 	instance.init()
@@ -256,6 +289,7 @@ func TestRunFns_getFilters(t *testing.T) {
 		noFunctionsFromInput *bool
 
 		enableStarlark bool
+		enableWasm     bool
 
 		disableContainers bool
 	}{
@@ -659,6 +693,88 @@ metadata:
 			error:          "function path ../a/b/c not allowed to start with ../",
 		},
 
+		// Test
+		//
+		//
+		{name: "wasm-function",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: fn.wasm
+`,
+				},
+			},
+			enableWasm: true,
+			outFn: func(path string) []string {
+				return []string{
+					fmt.Sprintf("wasm: path: %s/foo/fn.wasm", filepath.ToSlash(path))}
+			},
+		},
+
+		{name: "wasm-function-image",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        image: oci://registry.example.com/fn:v1
+`,
+				},
+			},
+			enableWasm: true,
+			outFn: func(path string) []string {
+				return []string{"wasm: path: /images/registry.example.com/fn:v1/fn.wasm"}
+			},
+		},
+
+		{name: "wasm-function-escape-parent",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: ../fn.wasm
+`,
+				},
+			},
+			enableWasm: true,
+			error:      "function path ../fn.wasm not allowed to start with ../",
+		},
+
+		{name: "wasm-function-disabled",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: fn.wasm
+`,
+				},
+			},
+		},
+
 		{name: "starlark-function-disabled",
 			in: []f{
 				{
@@ -723,11 +839,15 @@ metadata:
 			// init the instance
 			r := &RunFns{
 				EnableStarlark:       tt.enableStarlark,
+				EnableWasm:           tt.enableWasm,
 				DisableContainers:    tt.disableContainers,
 				FunctionPaths:        fnPaths,
 				Functions:            parsedFns,
 				Path:                 d,
 				NoFunctionsFromInput: tt.noFunctionsFromInput,
+				WasmImageLoader: func(image string) (string, error) {
+					return "/images/" + strings.TrimPrefix(image, "oci://") + "/fn.wasm", nil
+				},
 			}
 			r.init()
 
@@ -0,0 +1,383 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jsonpatch applies JSON patches (RFC 6902) to RNodes in place,
+// editing the yaml nodes rather than converting them through JSON, so
+// that comments, anchors, styles and the order of fields are preserved.
+package jsonpatch
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Operation is an operation of a JSON patch.
+type Operation struct {
+	// Op is one of add, remove, replace, move, copy or test.
+	Op string
+
+	// Path is a JSON pointer (RFC 6901) to the target location.
+	Path string
+
+	// From is a JSON pointer to the source location of move and copy.
+	From string
+
+	// Value is the value of add, replace and test.
+	Value *yaml.RNode
+}
+
+// DecodePatch parses a JSON patch, written either as JSON or as YAML.
+// Values written in YAML keep their comments.
+func DecodePatch(patch string) ([]Operation, error) {
+	node, err := yaml.Parse(patch)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "unable to parse JSON patch")
+	}
+	elements, err := node.Elements()
+	if err != nil {
+		return nil, errors.Errorf("JSON patch must be a list of operations")
+	}
+	ops := make([]Operation, 0, len(elements))
+	for i, e := range elements {
+		if e.YNode().Kind != yaml.MappingNode {
+			return nil, errors.Errorf("JSON patch operation %d must be a map", i)
+		}
+		op := Operation{
+			Op:   stringField(e, "op"),
+			Path: stringField(e, "path"),
+			From: stringField(e, "from"),
+		}
+		if v := e.Field("value"); v != nil {
+			op.Value = v.Value
+		}
+		if err := op.validate(); err != nil {
+			return nil, errors.WrapPrefixf(err, "JSON patch operation %d", i)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func stringField(rn *yaml.RNode, name string) string {
+	if f := rn.Field(name); f != nil {
+		return f.Value.YNode().Value
+	}
+	return ""
+}
+
+func (op Operation) validate() error {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return errors.Errorf("%s requires a value", op.Op)
+		}
+	case "move", "copy":
+		if _, err := parsePointer(op.From); err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return errors.Errorf("unknown op %q", op.Op)
+	}
+	_, err := parsePointer(op.Path)
+	return err
+}
+
+// Filter applies a JSON patch to an RNode.
+type Filter struct {
+	// Patch is the JSON patch, as JSON or YAML.
+	Patch string
+
+	// Operations, if set, are applied instead of Patch.
+	Operations []Operation
+}
+
+var _ yaml.Filter = Filter{}
+
+// Filter applies the patch to rn, returning rn.
+func (f Filter) Filter(rn *yaml.RNode) (*yaml.RNode, error) {
+	ops := f.Operations
+	if ops == nil {
+		var err error
+		if ops, err = DecodePatch(f.Patch); err != nil {
+			return nil, err
+		}
+	}
+	for _, op := range ops {
+		if err := apply(rn.YNode(), op); err != nil {
+			return nil, errors.WrapPrefixf(err, "%s operation on %q", op.Op, op.Path)
+		}
+	}
+	return rn, nil
+}
+
+func apply(root *yaml.Node, op Operation) error {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case "add":
+		return add(root, path, yaml.CopyYNode(op.Value.YNode()))
+	case "remove":
+		_, err = remove(root, path)
+		return err
+	case "replace":
+		return replace(root, path, yaml.CopyYNode(op.Value.YNode()))
+	case "move":
+		from, _ := parsePointer(op.From)
+		if isPrefix(from, path) && len(from) < len(path) {
+			return errors.Errorf("cannot move %q into itself", op.From)
+		}
+		value, err := remove(root, from)
+		if err != nil {
+			return err
+		}
+		return add(root, path, value)
+	case "copy":
+		from, _ := parsePointer(op.From)
+		value, err := get(root, from)
+		if err != nil {
+			return err
+		}
+		return add(root, path, yaml.CopyYNode(value))
+	case "test":
+		value, err := get(root, path)
+		if err != nil {
+			return err
+		}
+		equal, err := equalValues(value, op.Value.YNode())
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return errors.Errorf("value differs from the tested value")
+		}
+		return nil
+	}
+	return errors.Errorf("unknown op %q", op.Op)
+}
+
+// parsePointer splits a JSON pointer into its unescaped tokens.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, errors.Errorf("JSON pointer %q must start with /", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve follows aliases to the nodes holding the values.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.DocumentNode && len(n.Content) == 1 {
+		return resolve(n.Content[0])
+	}
+	return n
+}
+
+// get returns the node at the path.
+func get(root *yaml.Node, path []string) (*yaml.Node, error) {
+	n := resolve(root)
+	for i, token := range path {
+		switch n.Kind {
+		case yaml.MappingNode:
+			j := keyIndex(n, token)
+			if j < 0 {
+				return nil, errors.Errorf("%s does not exist", pointer(path[:i+1]))
+			}
+			n = resolve(n.Content[j+1])
+		case yaml.SequenceNode:
+			j, err := index(n, token, false)
+			if err != nil {
+				return nil, errors.WrapPrefixf(err, "%s", pointer(path[:i+1]))
+			}
+			n = resolve(n.Content[j])
+		default:
+			return nil, errors.Errorf("%s is not a map or list", pointer(path[:i]))
+		}
+	}
+	return n, nil
+}
+
+// parent returns the container of the last token of the path.
+func parent(root *yaml.Node, path []string) (*yaml.Node, string, error) {
+	if len(path) == 0 {
+		return nil, "", errors.Errorf("the whole document cannot be the target")
+	}
+	n, err := get(root, path[:len(path)-1])
+	if err != nil {
+		return nil, "", err
+	}
+	if n.Kind != yaml.MappingNode && n.Kind != yaml.SequenceNode {
+		return nil, "", errors.Errorf("%s is not a map or list", pointer(path[:len(path)-1]))
+	}
+	return n, path[len(path)-1], nil
+}
+
+func add(root *yaml.Node, path []string, value *yaml.Node) error {
+	// Adding the whole document replaces it.
+	if len(path) == 0 {
+		return replace(root, path, value)
+	}
+	n, token, err := parent(root, path)
+	if err != nil {
+		return err
+	}
+	if n.Kind == yaml.MappingNode {
+		if j := keyIndex(n, token); j >= 0 {
+			keepComments(n.Content[j+1], value)
+			n.Content[j+1] = value
+			return nil
+		}
+		n.Content = append(n.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: yaml.NodeTagString, Value: token}, value)
+		return nil
+	}
+	j, err := index(n, token, true)
+	if err != nil {
+		return errors.WrapPrefixf(err, "%s", pointer(path))
+	}
+	n.Content = append(n.Content[:j], append([]*yaml.Node{value}, n.Content[j:]...)...)
+	return nil
+}
+
+func remove(root *yaml.Node, path []string) (*yaml.Node, error) {
+	n, token, err := parent(root, path)
+	if err != nil {
+		return nil, err
+	}
+	if n.Kind == yaml.MappingNode {
+		j := keyIndex(n, token)
+		if j < 0 {
+			return nil, errors.Errorf("%s does not exist", pointer(path))
+		}
+		value := n.Content[j+1]
+		n.Content = append(n.Content[:j], n.Content[j+2:]...)
+		return value, nil
+	}
+	j, err := index(n, token, false)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "%s", pointer(path))
+	}
+	value := n.Content[j]
+	n.Content = append(n.Content[:j], n.Content[j+1:]...)
+	return value, nil
+}
+
+func replace(root *yaml.Node, path []string, value *yaml.Node) error {
+	if len(path) == 0 {
+		r := root
+		if r.Kind == yaml.DocumentNode && len(r.Content) == 1 {
+			r = r.Content[0]
+		}
+		keepComments(r, value)
+		*r = *value
+		return nil
+	}
+	n, token, err := parent(root, path)
+	if err != nil {
+		return err
+	}
+	if n.Kind == yaml.MappingNode {
+		j := keyIndex(n, token)
+		if j < 0 {
+			return errors.Errorf("%s does not exist", pointer(path))
+		}
+		keepComments(n.Content[j+1], value)
+		n.Content[j+1] = value
+		return nil
+	}
+	j, err := index(n, token, false)
+	if err != nil {
+		return errors.WrapPrefixf(err, "%s", pointer(path))
+	}
+	keepComments(n.Content[j], value)
+	n.Content[j] = value
+	return nil
+}
+
+// keepComments carries the comments of a replaced node over to
+// its replacement, unless the replacement has comments of its own.
+func keepComments(old, value *yaml.Node) {
+	if value.HeadComment == "" && value.LineComment == "" && value.FootComment == "" {
+		value.HeadComment = old.HeadComment
+		value.LineComment = old.LineComment
+		value.FootComment = old.FootComment
+	}
+}
+
+func keyIndex(n *yaml.Node, key string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+var indexRegexp = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+
+// index parses a list index; the end of the list, as an index
+// or as "-", is only legal when inserting.
+func index(n *yaml.Node, token string, inserting bool) (int, error) {
+	if token == "-" && inserting {
+		return len(n.Content), nil
+	}
+	if !indexRegexp.MatchString(token) {
+		return 0, errors.Errorf("invalid list index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, errors.Errorf("invalid list index %q", token)
+	}
+	if i > len(n.Content) || (i == len(n.Content) && !inserting) {
+		return 0, errors.Errorf("list index %d out of range", i)
+	}
+	return i, nil
+}
+
+func pointer(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString("/")
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+func equalValues(a, b *yaml.Node) (bool, error) {
+	var av, bv interface{}
+	if err := a.Decode(&av); err != nil {
+		return false, errors.Wrap(err)
+	}
+	if err := b.Decode(&bv); err != nil {
+		return false, errors.Wrap(err)
+	}
+	return reflect.DeepEqual(av, bv), nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonpatch_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/jsonpatch"
+)
+
+const deployment = `# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels: &labels
+    app: web # the selector
+spec:
+  replicas: 1 # scaled by the HPA
+  selector:
+    matchLabels: *labels
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+      # sidecars follow
+      - name: proxy
+        image: proxy:v1
+`
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		patch    string
+		expected string
+	}{
+		"replace keeps comments": {
+			patch: `[{"op": "replace", "path": "/spec/replicas", "value": 3}]`,
+			expected: `# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels: &labels
+    app: web # the selector
+spec:
+  replicas: 3 # scaled by the HPA
+  selector:
+    matchLabels: *labels
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+      # sidecars follow
+      - name: proxy
+        image: proxy:v1
+`,
+		},
+		"add appends fields and inserts elements": {
+			patch: `
+- op: add
+  path: /metadata/annotations
+  value:
+    owner: team-a # who to page
+- op: add
+  path: /spec/template/spec/containers/1
+  value:
+    name: init
+    image: init:v1
+`,
+			expected: `# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels: &labels
+    app: web # the selector
+  annotations:
+    owner: team-a # who to page
+spec:
+  replicas: 1 # scaled by the HPA
+  selector:
+    matchLabels: *labels
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+      - name: init
+        image: init:v1
+      # sidecars follow
+      - name: proxy
+        image: proxy:v1
+`,
+		},
+		"remove, move and copy": {
+			patch: `
+- op: test
+  path: /spec/template/spec/containers/0/name
+  value: app
+- op: remove
+  path: /spec/template/spec/containers/0
+- op: copy
+  from: /metadata/name
+  path: /spec/template/spec/containers/0/name
+- op: move
+  from: /spec/replicas
+  path: /spec/minReplicas
+`,
+			expected: `# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels: &labels
+    app: web # the selector
+spec:
+  selector:
+    matchLabels: *labels
+  template:
+    spec:
+      containers:
+      # sidecars follow
+      - name: app
+        image: proxy:v1
+  minReplicas: 1 # scaled by the HPA
+`,
+		},
+		"through an alias": {
+			patch:    `[{"op": "test", "path": "/spec/selector/matchLabels/app", "value": "web"}]`,
+			expected: deployment,
+		},
+		"escaped pointer and list end": {
+			patch: `
+- op: add
+  path: /metadata/labels/app.kubernetes.io~1part-of
+  value: shop
+- op: add
+  path: /spec/template/spec/containers/-
+  value: {name: last, image: last:v1}
+`,
+			expected: `# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels: &labels
+    app: web # the selector
+    app.kubernetes.io/part-of: shop
+spec:
+  replicas: 1 # scaled by the HPA
+  selector:
+    matchLabels: *labels
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+      # sidecars follow
+      - name: proxy
+        image: proxy:v1
+      - {name: last, image: 'last:v1'}
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rn, err := yaml.Parse(deployment)
+			require.NoError(t, err)
+			_, err = rn.Pipe(jsonpatch.Filter{Patch: tc.patch})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rn.MustString())
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	testCases := map[string]struct {
+		patch  string
+		errMsg string
+	}{
+		"missing field": {
+			patch:  `[{"op": "remove", "path": "/spec/paused"}]`,
+			errMsg: `remove operation on "/spec/paused": /spec/paused does not exist`,
+		},
+		"index out of range": {
+			patch:  `[{"op": "replace", "path": "/spec/template/spec/containers/2", "value": {}}]`,
+			errMsg: "list index 2 out of range",
+		},
+		"invalid index": {
+			patch:  `[{"op": "remove", "path": "/spec/template/spec/containers/01"}]`,
+			errMsg: `invalid list index "01"`,
+		},
+		"failed test": {
+			patch:  `[{"op": "test", "path": "/spec/replicas", "value": 2}]`,
+			errMsg: "value differs from the tested value",
+		},
+		"unknown op": {
+			patch:  `[{"op": "merge", "path": "/spec"}]`,
+			errMsg: `JSON patch operation 0: unknown op "merge"`,
+		},
+		"missing value": {
+			patch:  `[{"op": "add", "path": "/spec/paused"}]`,
+			errMsg: "add requires a value",
+		},
+		"move into itself": {
+			patch:  `[{"op": "move", "from": "/spec", "path": "/spec/template/spec"}]`,
+			errMsg: `cannot move "/spec" into itself`,
+		},
+		"not a list": {
+			patch:  `{"op": "remove", "path": "/spec"}`,
+			errMsg: "JSON patch must be a list of operations",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rn, err := yaml.Parse(deployment)
+			require.NoError(t, err)
+			_, err = rn.Pipe(jsonpatch.Filter{Patch: tc.patch})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
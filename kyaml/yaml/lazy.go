@@ -0,0 +1,403 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// lazyNode holds the document of a lazy RNode, unparsed but for its
+// apiVersion, kind and metadata fields.
+type lazyNode struct {
+	// head is the text before the first field, e.g. comments.
+	head string
+
+	// fields are the top-level fields of the document, in order.
+	fields []lazyField
+
+	// original holds the metadata fields as read.  Shared by copies,
+	// never changed.
+	original *Node
+
+	// meta holds the metadata fields, which RNode methods reading or
+	// writing no other fields work on.
+	meta *RNode
+
+	// doc is the document, once parsed.
+	doc *lazyDocument
+}
+
+// lazyDocument is the parsed document of a lazy RNode.  Methods only
+// reading the RNode may parse it concurrently, so mu guards it.
+type lazyDocument struct {
+	mu sync.Mutex
+
+	// node is the document, nil until parsed.
+	node *Node
+
+	// err is the error parsing the document, if any.
+	err error
+}
+
+// lazyField is a top-level field of a lazy document.
+type lazyField struct {
+	key string
+
+	// text is the text of the field, from its key up to the next
+	// field, comments and blank lines included.
+	text string
+}
+
+// lazyFieldKeyRegexp matches the line starting a top-level field.
+var lazyFieldKeyRegexp = regexp.MustCompile(`^([A-Za-z0-9_][A-Za-z0-9_./-]*)[ \t]*:([ \t]|$)`)
+
+// isLazyMetaField reports whether field is parsed by lazy RNodes.
+func isLazyMetaField(field string) bool {
+	return field == APIVersionField || field == KindField || field == MetadataField
+}
+
+// NewLazyRNode returns a lazy RNode of the document value, or nil if
+// value isn't a block mapping of plain keys at its top level.
+//
+// A lazy RNode parses the apiVersion, kind and metadata fields of the
+// document only.  It parses the document once something needs one of
+// its other fields, e.g. a Filter piped from the RNode, or its YNode.
+// Until then, String returns the document as is, with only its
+// metadata fields re-encoded, if changed.
+//
+// The other fields are checked for unclosed flow collections and
+// quoted scalars only, which yield nil.  Errors that check misses
+// surface once the document is parsed: methods returning errors, e.g.
+// Pipe or Map, return them, and the others see the metadata fields
+// alone.
+func NewLazyRNode(value string) *RNode {
+	l := &lazyNode{}
+	// start is the offset of the head or current field, end that of
+	// the line
+	start, end := 0, 0
+	for end < len(value) {
+		next := len(value)
+		if i := strings.IndexByte(value[end:], '\n'); i >= 0 {
+			next = end + i + 1
+		}
+		line := strings.TrimRight(value[end:next], "\r\n")
+		switch {
+		case line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' ||
+			(line[0] == '-' && len(l.fields) > 0 && (line == "-" || line[1] == ' ')):
+			// a continuation of the current field, e.g. an indented
+			// line or an item of a sequence at the top level
+			if len(l.fields) == 0 && strings.TrimSpace(line) != "" && line[0] != '#' {
+				return nil
+			}
+		case line == "---" || strings.HasPrefix(line, "--- "):
+			if len(l.fields) > 0 || strings.TrimSpace(strings.Trim(line, "-")) != "" ||
+				strings.TrimSpace(value[start:end]) != "" {
+				return nil
+			}
+			// drop the document start marker; writers add separators
+			start = next
+		default:
+			m := lazyFieldKeyRegexp.FindStringSubmatch(line)
+			if m == nil {
+				return nil
+			}
+			for _, f := range l.fields {
+				if f.key == m[1] {
+					return nil
+				}
+			}
+			l.setText(value[start:end])
+			l.fields = append(l.fields, lazyField{key: m[1]})
+			start = end
+		}
+		end = next
+	}
+	l.setText(value[start:end])
+	if len(l.fields) == 0 {
+		return nil
+	}
+	var meta strings.Builder
+	for _, f := range l.fields {
+		if isLazyMetaField(f.key) {
+			meta.WriteString(withNewline(f.text))
+		} else if !isClosed(f.text) {
+			return nil
+		}
+	}
+	l.original = &Node{Kind: MappingNode}
+	if meta.Len() > 0 {
+		doc := &Node{}
+		if err := Unmarshal([]byte(meta.String()), doc); err != nil ||
+			len(doc.Content) != 1 || doc.Content[0].Kind != MappingNode {
+			return nil
+		}
+		l.original = doc.Content[0]
+	}
+	l.meta = NewRNode(CopyYNode(l.original))
+	l.doc = &lazyDocument{}
+	return &RNode{lazy: l}
+}
+
+// isClosed reports whether the flow collections and quoted scalars of
+// text, that of a top-level field, are closed, skipping comments and
+// block scalars.  It errs on the side of false, e.g. for a bracket
+// opening a plain scalar, as the document is then parsed eagerly.
+func isClosed(text string) bool {
+	depth := 0
+	var quote byte
+	// blockIndent is the indentation of the line starting the block
+	// scalar the lines are in, if any, else -1
+	blockIndent := -1
+	for len(text) > 0 {
+		line := text
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			line, text = text[:i], text[i+1:]
+		} else {
+			text = ""
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockIndent >= 0 {
+			if quote == 0 && (indent == len(line) || indent > blockIndent) {
+				continue
+			}
+			blockIndent = -1
+		}
+		if quote == 0 && depth == 0 && strings.HasPrefix(line[indent:], "\t") {
+			// tabs can't indent
+			return false
+		}
+		// prev is the last character before i that isn't a space, and
+		// spaced whether one precedes i
+		var prev byte
+		spaced := true
+	scan:
+		for i := indent; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case quote == '\'':
+				if c == '\'' {
+					if i+1 < len(line) && line[i+1] == '\'' {
+						i++
+					} else {
+						quote = 0
+					}
+				}
+				continue
+			case quote == '"':
+				if c == '\\' {
+					i++
+				} else if c == '"' {
+					quote = 0
+				}
+				continue
+			case c == ' ' || c == '\t':
+				spaced = true
+				continue
+			case c == '#' && spaced:
+				break scan
+			case c == '\'' || c == '"' || c == '[' || c == '{' || c == '|' || c == '>':
+				start := prev == 0 || prev == ',' || prev == '[' || prev == '{' ||
+					(spaced && (prev == ':' || prev == '-' || prev == '?'))
+				switch {
+				case (c == '\'' || c == '"') && start:
+					quote = c
+				case (c == '[' || c == '{') && (start || depth > 0):
+					depth++
+				case (c == '|' || c == '>') && start && depth == 0:
+					// only indicators and a comment may follow
+					rest := strings.TrimLeft(line[i+1:], "+-0123456789")
+					if comment := strings.TrimLeft(rest, " "); comment != "" &&
+						(comment[0] != '#' || len(comment) == len(rest)) {
+						return false
+					}
+					blockIndent = indent
+					break scan
+				}
+			case (c == ']' || c == '}') && depth > 0:
+				depth--
+			}
+			prev, spaced = c, false
+		}
+	}
+	return depth == 0 && quote == 0
+}
+
+// setText sets the text of the current field, or the head.
+func (l *lazyNode) setText(text string) {
+	if len(l.fields) == 0 {
+		l.head = text
+	} else {
+		l.fields[len(l.fields)-1].text = text
+	}
+}
+
+// ParseLazy returns a lazy RNode of value if possible, see
+// NewLazyRNode, else parses it.
+func ParseLazy(value string) (*RNode, error) {
+	if rn := NewLazyRNode(value); rn != nil {
+		return rn, nil
+	}
+	return Parse(value)
+}
+
+// IsLazy reports whether rn is a lazy RNode whose document is yet to
+// be parsed, or failed to.
+func (rn *RNode) IsLazy() bool {
+	return rn != nil && rn.lazy != nil && rn.lazy.parsed() == nil
+}
+
+// copy returns a copy of l, sharing the text and original metadata,
+// yet to be parsed.
+func (l *lazyNode) copy() *lazyNode {
+	c := *l
+	c.meta = NewRNode(CopyYNode(l.meta.YNode()))
+	c.doc = &lazyDocument{}
+	return &c
+}
+
+// parsed returns the document of l if parsed, else nil.
+func (l *lazyNode) parsed() *Node {
+	l.doc.mu.Lock()
+	defer l.doc.mu.Unlock()
+	return l.doc.node
+}
+
+// parse returns the document of l, parsing it the first time.
+func (l *lazyNode) parse() (*Node, error) {
+	l.doc.mu.Lock()
+	defer l.doc.mu.Unlock()
+	if l.doc.node != nil || l.doc.err != nil {
+		return l.doc.node, l.doc.err
+	}
+	s, err := l.String()
+	if err == nil {
+		var parsed *RNode
+		if parsed, err = Parse(s); err == nil {
+			l.doc.node = parsed.value
+			return l.doc.node, nil
+		}
+	}
+	l.doc.err = errors.WrapPrefixf(err, "failed to parse lazy node")
+	return nil, l.doc.err
+}
+
+// String returns the document of l, with its metadata fields
+// re-encoded if changed, those added last.
+func (l *lazyNode) String() (string, error) {
+	changed := !reflect.DeepEqual(l.original, l.meta.YNode())
+	var b strings.Builder
+	b.WriteString(l.head)
+	written := map[string]bool{}
+	for _, f := range l.fields {
+		if !changed || !isLazyMetaField(f.key) {
+			b.WriteString(f.text)
+			continue
+		}
+		written[f.key] = true
+		if err := l.writeMetaField(&b, f.key); err != nil {
+			return "", err
+		}
+	}
+	if changed {
+		for _, key := range []string{APIVersionField, KindField, MetadataField} {
+			if written[key] {
+				continue
+			}
+			if err := l.writeMetaField(&b, key); err != nil {
+				return "", err
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// writeMetaField writes the metadata field key to b, if l has it.
+func (l *lazyNode) writeMetaField(b *strings.Builder, key string) error {
+	f := l.meta.Field(key)
+	if f == nil {
+		return nil
+	}
+	s, err := String(&Node{Kind: MappingNode, Content: []*Node{f.Key.YNode(), f.Value.YNode()}})
+	if err != nil {
+		return errors.WrapPrefixf(err, "failed to encode %s", key)
+	}
+	if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString(s)
+	return nil
+}
+
+// document returns the node of rn, parsing it first if lazy.  Parsing
+// leaves rn as is, so that methods only reading rn may call document
+// concurrently.
+func (rn *RNode) document() (*Node, error) {
+	if rn == nil {
+		return nil, nil
+	}
+	if rn.lazy != nil {
+		return rn.lazy.parse()
+	}
+	return rn.value, nil
+}
+
+// node returns the node of rn, see document, or, if rn is a lazy RNode
+// failing to parse, the node of its metadata fields alone.
+func (rn *RNode) node() *Node {
+	doc, err := rn.document()
+	if err != nil {
+		return rn.lazy.meta.value
+	}
+	return doc
+}
+
+// settle turns rn, if lazy, into an RNode like any other, ahead of a
+// change to its node.  It fails if the document fails to parse.
+func (rn *RNode) settle() error {
+	doc, err := rn.document()
+	if err != nil {
+		return err
+	}
+	if rn.lazy != nil {
+		rn.lazy, rn.value = nil, doc
+	}
+	return nil
+}
+
+// metadataOnly reports whether functions, piped from the top of a
+// document, read or write its apiVersion, kind and metadata only.
+func metadataOnly(functions []Filter) bool {
+	for _, f := range functions {
+		switch f := f.(type) {
+		case AnnotationSetter, AnnotationGetter, AnnotationClearer, LabelSetter, k8sMetaSetter:
+			return true
+		case PathGetter:
+			if len(f.Path) == 0 {
+				continue
+			}
+			return isLazyMetaField(f.Path[0])
+		case FieldClearer:
+			return isLazyMetaField(f.Name)
+		case FieldMatcher:
+			return isLazyMetaField(f.Name)
+		case FieldSetter:
+			return isLazyMetaField(f.Name)
+		}
+		return false
+	}
+	return false
+}
+
+// withNewline returns s, ending with a newline.
+func withNewline(s string) string {
+	if strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,203 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lazyDeployment = `# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+    name: app
+    annotations:
+        a: b
+spec:
+    replicas: 1
+    template:
+        spec:
+            containers:
+            -   name: app
+                image: nginx
+`
+
+func TestNewLazyRNode_NotLazy(t *testing.T) {
+	for name, value := range map[string]string{
+		"empty":           "",
+		"comments only":   "# a comment\n",
+		"flow mapping":    `{"apiVersion": "v1", "kind": "ConfigMap"}`,
+		"sequence":        "- a\n- b\n",
+		"scalar":          "I am not valid",
+		"quoted key":      "\"kind\": ConfigMap\n",
+		"duplicate key":   "kind: ConfigMap\nkind: Secret\n",
+		"indented head":   "  kind: ConfigMap\n",
+		"document end":    "kind: ConfigMap\n...\n",
+		"separator":       "kind: ConfigMap\n---\nkind: Secret\n",
+		"invalid meta":    "kind: [ConfigMap\n",
+		"invalid body":    "kind: ConfigMap\ndata: [a\n",
+		"invalid nested":  "kind: ConfigMap\ndata:\n  k: [unclosed\n",
+		"directive":       "%YAML 1.2\n---\nkind: ConfigMap\n",
+		"marker comments": "# a comment\n---\nkind: ConfigMap\n",
+	} {
+		assert.Nil(t, NewLazyRNode(value), name)
+	}
+}
+
+func TestNewLazyRNode(t *testing.T) {
+	rn := NewLazyRNode("---\n" + lazyDeployment)
+	require.NotNil(t, rn)
+	assert.Equal(t, "Deployment", rn.GetKind())
+	assert.Equal(t, "apps/v1", rn.GetApiVersion())
+	assert.Equal(t, "app", rn.GetName())
+	assert.Equal(t, map[string]string{"a": "b"}, rn.GetAnnotations())
+	meta, err := rn.GetMeta()
+	require.NoError(t, err)
+	assert.Equal(t, "app", meta.Name)
+	assert.False(t, rn.IsNilOrEmpty())
+	assert.True(t, rn.IsLazy())
+
+	// the document start marker is dropped
+	s, err := rn.String()
+	require.NoError(t, err)
+	assert.Equal(t, lazyDeployment, s)
+
+	require.NoError(t, rn.PipeE(SetAnnotation("c", "d")))
+	require.NoError(t, rn.PipeE(ClearAnnotation("c")))
+	s, err = rn.String()
+	require.NoError(t, err)
+	assert.Equal(t, lazyDeployment, s)
+
+	c := rn.Copy()
+	require.NoError(t, rn.SetName("renamed"))
+	require.NoError(t, rn.SetNamespace("ns"))
+	assert.True(t, rn.IsLazy())
+	assert.Equal(t, "app", c.GetName())
+	s, err = rn.String()
+	require.NoError(t, err)
+	assert.Equal(t, `# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: renamed
+  annotations:
+    a: b
+  namespace: ns
+spec:
+    replicas: 1
+    template:
+        spec:
+            containers:
+            -   name: app
+                image: nginx
+`, s)
+
+	b, err := rn.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"replicas":1`)
+	assert.True(t, rn.IsLazy())
+
+	replicas, err := rn.Pipe(Lookup("spec", "replicas"))
+	require.NoError(t, err)
+	assert.False(t, rn.IsLazy())
+	assert.Equal(t, "1", replicas.YNode().Value)
+	assert.Equal(t, "renamed", rn.GetName())
+	assert.Equal(t, "ns", rn.GetNamespace())
+	assert.True(t, c.IsLazy())
+}
+
+func TestParseLazy(t *testing.T) {
+	rn, err := ParseLazy(lazyDeployment)
+	require.NoError(t, err)
+	assert.True(t, rn.IsLazy())
+
+	rn, err = ParseLazy(`{"kind": "ConfigMap"}`)
+	require.NoError(t, err)
+	assert.False(t, rn.IsLazy())
+	assert.Equal(t, "ConfigMap", rn.GetKind())
+}
+
+func TestParseLazy_Invalid(t *testing.T) {
+	_, err := ParseLazy("kind: ConfigMap\ndata:\n  k: [unclosed\n")
+	assert.Error(t, err)
+}
+
+func TestIsClosed(t *testing.T) {
+	for text, closed := range map[string]bool{
+		"data: {\"a\": 'b'}\n":                   true,
+		"data:\n  k: [a, [b]]\n":                 true,
+		"data:\n  k: [a,\n    b]\n":              true,
+		"data:\n  k: 'it''s [open'\n":            true,
+		"data:\n  k: \"a \\\" [b\"\n":            true,
+		"data:\n  k: a[0] # [open\n":             true,
+		"data:\n  k: http://a#[b]\n":             true,
+		"data:\n  k: |\n    [open\n    'open\n":  true,
+		"data:\n  k: >- # folded\n    {open\n":   true,
+		"data:\n  k: |\n    [open\n  l: [open\n": false,
+		"data:\n  k: [unclosed\n":                false,
+		"data:\n  k: 'unclosed\n":                false,
+		"data:\n  k: \"unclosed\\\"\n":           false,
+		"data:\n\tk: v\n":                        false,
+		"data:\n  k: | not a block\n":            false,
+	} {
+		assert.Equal(t, closed, isClosed(text), text)
+	}
+}
+
+func TestLazyRNode_ParseError(t *testing.T) {
+	// the bad indentation of b escapes the check of NewLazyRNode
+	value := "kind: ConfigMap\ndata:\n  a: 1\n b: 2\n"
+	rn := NewLazyRNode(value)
+	require.NotNil(t, rn)
+	assert.Equal(t, "ConfigMap", rn.GetKind())
+
+	_, err := rn.Pipe(Lookup("data", "a"))
+	assert.ErrorContains(t, err, "failed to parse lazy node")
+	_, err = rn.Map()
+	assert.ErrorContains(t, err, "failed to parse lazy node")
+	_, err = rn.MarshalJSON()
+	assert.Error(t, err)
+
+	// the methods not returning errors see the metadata alone
+	assert.Nil(t, rn.Field("data"))
+	assert.Equal(t, MappingNode, rn.YNode().Kind)
+	require.NoError(t, rn.PipeE(SetLabel("app", "a")))
+	assert.Equal(t, map[string]string{"app": "a"}, rn.GetLabels())
+	assert.True(t, rn.IsLazy())
+	s, err := rn.String()
+	require.NoError(t, err)
+	assert.Equal(t, `kind: ConfigMap
+data:
+  a: 1
+ b: 2
+metadata:
+  labels:
+    app: 'a'
+`, s)
+}
+
+func TestLazyRNode_Concurrent(t *testing.T) {
+	rn := NewLazyRNode(lazyDeployment)
+	require.NotNil(t, rn)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "app", rn.GetName())
+			assert.NotNil(t, rn.Field("spec"))
+			_, err := rn.String()
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.False(t, rn.IsLazy())
+	replicas, err := rn.Pipe(Lookup("spec", "replicas"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", replicas.YNode().Value)
+}
@@ -27,6 +27,9 @@ func MakeNullNode() *RNode {
 // IsMissingOrNull is true if the RNode is nil or explicitly tagged null.
 // TODO: make this a method on RNode.
 func IsMissingOrNull(node *RNode) bool {
+	if node.IsLazy() {
+		return false
+	}
 	return node.IsNil() || node.YNode().Tag == NodeTagNull
 }
 
@@ -214,6 +217,10 @@ type RNode struct {
 	// object root: object root
 	value *yaml.Node
 
+	// lazy, if not nil, holds the document of a lazy RNode, which has
+	// no value until parsed.  See NewLazyRNode.
+	lazy *lazyNode
+
 	Match []string
 }
 
@@ -224,6 +231,13 @@ func (rn *RNode) Copy() *RNode {
 	}
 	result := *rn
 	result.value = CopyYNode(rn.value)
+	if rn.lazy != nil {
+		if doc := rn.lazy.parsed(); doc != nil {
+			result.lazy, result.value = nil, CopyYNode(doc)
+		} else {
+			result.lazy = rn.lazy.copy()
+		}
+	}
 	return &result
 }
 
@@ -231,27 +245,33 @@ var ErrMissingMetadata = fmt.Errorf("missing Resource metadata")
 
 // IsNil is true if the node is nil, or its underlying YNode is nil.
 func (rn *RNode) IsNil() bool {
-	return rn == nil || rn.YNode() == nil
+	return rn == nil || (rn.lazy == nil && rn.YNode() == nil)
 }
 
 // IsTaggedNull is true if a non-nil node is explicitly tagged Null.
 func (rn *RNode) IsTaggedNull() bool {
-	return !rn.IsNil() && IsYNodeTaggedNull(rn.YNode())
+	return !rn.IsNil() && !rn.IsLazy() && IsYNodeTaggedNull(rn.YNode())
 }
 
 // IsNilOrEmpty is true if the node is nil,
 // has no YNode, or has YNode that appears empty.
 func (rn *RNode) IsNilOrEmpty() bool {
+	if rn.IsLazy() {
+		return false
+	}
 	return rn.IsNil() || IsYNodeNilOrEmpty(rn.YNode())
 }
 
 // IsStringValue is true if the RNode is not nil and is scalar string node
 func (rn *RNode) IsStringValue() bool {
-	return !rn.IsNil() && IsYNodeString(rn.YNode())
+	return !rn.IsNil() && !rn.IsLazy() && IsYNodeString(rn.YNode())
 }
 
 // GetMeta returns the ResourceMeta for an RNode
 func (rn *RNode) GetMeta() (ResourceMeta, error) {
+	if rn.IsLazy() {
+		return rn.lazy.meta.GetMeta()
+	}
 	if IsMissingOrNull(rn) {
 		return ResourceMeta{}, nil
 	}
@@ -333,14 +353,19 @@ func (rn *RNode) Pipe(functions ...Filter) (*RNode, error) {
 	}
 
 	var v *RNode
-	var err error
-	if rn.value != nil && rn.value.Kind == yaml.DocumentNode {
+	if rn.IsLazy() && metadataOnly(functions) {
+		v = rn.lazy.meta
+	} else if doc, err := rn.document(); err != nil {
+		return nil, err
+	} else if doc != nil && doc.Kind == yaml.DocumentNode {
 		// the first node may be a DocumentNode containing a single MappingNode
-		v = &RNode{value: rn.value.Content[0]}
+		v = &RNode{value: doc.Content[0]}
 	} else {
 		v = rn
 	}
 
+	var err error
+
 	// return each fn in sequence until encountering an error or missing value
 	for _, c := range functions {
 		v, err = c.Filter(v)
@@ -360,23 +385,27 @@ func (rn *RNode) PipeE(functions ...Filter) error {
 
 // Document returns the Node for the value.
 func (rn *RNode) Document() *yaml.Node {
-	return rn.value
+	return rn.node()
 }
 
 // YNode returns the yaml.Node value.  If the yaml.Node value is a DocumentNode,
 // YNode will return the DocumentNode Content entry instead of the DocumentNode.
 func (rn *RNode) YNode() *yaml.Node {
-	if rn == nil || rn.value == nil {
+	n := rn.node()
+	if n == nil {
 		return nil
 	}
-	if rn.value.Kind == yaml.DocumentNode {
-		return rn.value.Content[0]
+	if n.Kind == yaml.DocumentNode {
+		return n.Content[0]
 	}
-	return rn.value
+	return n
 }
 
 // SetYNode sets the yaml.Node value on an RNode.
 func (rn *RNode) SetYNode(node *yaml.Node) {
+	// a lazy RNode failing to parse is replaced as a whole
+	_ = rn.settle()
+	rn.lazy = nil
 	if rn.value == nil || node == nil {
 		rn.value = node
 		return
@@ -416,6 +445,9 @@ func (rn *RNode) SetApiVersion(av string) {
 // given field, so this function cannot be used to make distinctions
 // between these cases.
 func (rn *RNode) getMapFieldValue(field string) *yaml.Node {
+	if rn.IsLazy() && isLazyMetaField(field) {
+		return rn.lazy.meta.getMapFieldValue(field)
+	}
 	var result *yaml.Node
 	visitMappingNodeFields(rn.Content(), func(key, value *yaml.Node) {
 		result = value
@@ -447,6 +479,9 @@ func (rn *RNode) getMetaStringField(fName string) string {
 // getMetaData returns the *yaml.Node of the metadata field.
 // Return nil if field not found (no error).
 func (rn *RNode) getMetaData() *yaml.Node {
+	if rn.IsLazy() {
+		return rn.lazy.meta.getMetaData()
+	}
 	if IsMissingOrNull(rn) {
 		return nil
 	}
@@ -687,7 +722,10 @@ func (rn *RNode) String() (string, error) {
 	if rn == nil {
 		return "", nil
 	}
-	return String(rn.value)
+	if rn.IsLazy() {
+		return rn.lazy.String()
+	}
+	return String(rn.node())
 }
 
 // MustString returns string representation of the RNode or panics if there is an error
@@ -948,7 +986,7 @@ func (rn *RNode) MarshalJSON() ([]byte, error) {
 		return nil, err
 	}
 
-	if rn.YNode().Kind == SequenceNode {
+	if !rn.IsLazy() && rn.YNode().Kind == SequenceNode {
 		var a []interface{}
 		if err := Unmarshal([]byte(s), &a); err != nil {
 			return nil, err
@@ -973,13 +1011,22 @@ func (rn *RNode) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	rn.value = r.value
+	rn.lazy, rn.value = nil, r.value
 	return nil
 }
 
 // DeAnchor inflates all YAML aliases with their anchor values.
 // All YAML anchor data is permanently removed (feel free to call Copy first).
 func (rn *RNode) DeAnchor() (err error) {
+	if rn.IsLazy() {
+		if s, _ := rn.lazy.String(); !strings.ContainsAny(s, "&*") {
+			// no anchors or aliases to inflate
+			return nil
+		}
+	}
+	if err = rn.settle(); err != nil {
+		return err
+	}
 	rn.value, err = deAnchor(rn.value)
 	return
 }
@@ -1174,7 +1221,7 @@ func (rn *RNode) MatchesLabelSelector(selector string) (bool, error) {
 // TODO(broken): This doesn't do what it claims to do.
 // (see TODO in unit test and pr 1513).
 func (rn *RNode) HasNilEntryInList() (bool, string) {
-	return hasNilEntryInList(rn.value)
+	return hasNilEntryInList(rn.node())
 }
 
 func hasNilEntryInList(in interface{}) (bool, string) {
@@ -1207,11 +1254,15 @@ func FromMap(m map[string]interface{}) (*RNode, error) {
 }
 
 func (rn *RNode) Map() (map[string]interface{}, error) {
-	if rn == nil || rn.value == nil {
+	doc, err := rn.document()
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
 		return make(map[string]interface{}), nil
 	}
 	var result map[string]interface{}
-	if err := rn.value.Decode(&result); err != nil {
+	if err := doc.Decode(&result); err != nil {
 		// Should not be able to create an RNode that cannot be decoded;
 		// this is an unrecoverable error.
 		str, _ := rn.String()
@@ -5,6 +5,8 @@
 package main
 
 import (
+	"path/filepath"
+
 	"sigs.k8s.io/kustomize/api/kv"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
@@ -15,12 +17,17 @@ type plugin struct {
 	h                *resmap.PluginHelpers
 	types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
 	types.ConfigMapArgs
+
+	// TemplateData, set by the kustomization, is the
+	// data templated literals are evaluated with.
+	TemplateData *types.LiteralTemplateData `json:"templateData,omitempty" yaml:"templateData,omitempty"`
 }
 
 var KustomizePlugin plugin //nolint:gochecknoglobals
 
 func (p *plugin) Config(h *resmap.PluginHelpers, config []byte) (err error) {
 	p.ConfigMapArgs = types.ConfigMapArgs{}
+	p.TemplateData = nil
 	err = yaml.Unmarshal(config, p)
 	if p.ConfigMapArgs.Name == "" {
 		p.ConfigMapArgs.Name = p.Name
@@ -33,6 +40,17 @@ func (p *plugin) Config(h *resmap.PluginHelpers, config []byte) (err error) {
 }
 
 func (p *plugin) Generate() (resmap.ResMap, error) {
-	return p.h.ResmapFactory().FromConfigMapArgs(
-		kv.NewLoader(p.h.Loader(), p.h.Validator()), p.ConfigMapArgs)
+	kvLdr := kv.NewLoader(p.h.Loader(), p.h.Validator())
+	if p.TemplateLiterals {
+		data := types.LiteralTemplateData{
+			Overlay:   filepath.Base(p.h.Loader().Root()),
+			Namespace: p.ConfigMapArgs.Namespace,
+			Name:      p.ConfigMapArgs.Name,
+		}
+		if p.TemplateData != nil {
+			data = *p.TemplateData
+		}
+		kvLdr = kv.NewTemplateLoader(p.h.Loader(), p.h.Validator(), data)
+	}
+	return p.h.ResmapFactory().FromConfigMapArgs(kvLdr, p.ConfigMapArgs)
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
 )
 
@@ -31,6 +32,14 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 			if err != nil {
 				return err
 			}
+			if res.NeedPruneAnnotation() {
+				annotations := res.GetAnnotations()
+				annotations[konfig.GeneratedNameAnnotation] = res.GetName()
+				annotations[konfig.GeneratedHashAnnotation] = h
+				if err = res.SetAnnotations(annotations); err != nil {
+					return err
+				}
+			}
 			res.StorePreviousId()
 			res.SetName(fmt.Sprintf("%s-%s", res.GetName(), h))
 		}
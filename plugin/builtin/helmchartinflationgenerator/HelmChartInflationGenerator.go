@@ -56,7 +56,8 @@ func (p *plugin) Config(
 	if !h.GeneralConfig().HelmConfig.Enabled {
 		return fmt.Errorf("must specify --enable-helm")
 	}
-	if h.GeneralConfig().HelmConfig.Command == "" {
+	if h.GeneralConfig().HelmConfig.Command == "" &&
+		h.GeneralConfig().HelmConfig.Renderer == nil {
 		return fmt.Errorf("must specify --helm-command")
 	}
 
@@ -121,6 +122,12 @@ func (p *plugin) validateArgs() (err error) {
 		return err
 	}
 
+	// CredentialsFile is not loaded by the plugin; it is
+	// handed to helm, and can be located anywhere.
+	if p.CredentialsFile != "" && !filepath.IsAbs(p.CredentialsFile) {
+		p.CredentialsFile = filepath.Join(p.h.Loader().Root(), p.CredentialsFile)
+	}
+
 	// ConfigHome is not loaded by the plugin, and can be located anywhere.
 	if p.ConfigHome == "" {
 		if err = p.establishTmpDir(); err != nil {
@@ -250,15 +257,18 @@ func (p *plugin) cleanup() {
 // Generate implements generator
 func (p *plugin) Generate() (rm resmap.ResMap, err error) {
 	defer p.cleanup()
-	if err = p.checkHelmVersion(); err != nil {
-		return nil, err
+	renderer := p.h.GeneralConfig().HelmConfig.Renderer
+	if renderer == nil {
+		if err = p.checkHelmVersion(); err != nil {
+			return nil, err
+		}
 	}
 	if path, exists := p.chartExistsLocally(); !exists {
 		if p.Repo == "" {
 			return nil, fmt.Errorf(
 				"no repo specified for pull, no chart found at '%s'", path)
 		}
-		if _, err := p.runHelmCommand(p.pullCommand()); err != nil {
+		if err := p.pull(renderer); err != nil {
 			return nil, err
 		}
 	}
@@ -271,7 +281,11 @@ func (p *plugin) Generate() (rm resmap.ResMap, err error) {
 		return nil, err
 	}
 	var stdout []byte
-	stdout, err = p.runHelmCommand(p.AsHelmArgs(p.absChartHome()))
+	if renderer != nil {
+		stdout, err = renderer.Template(p.HelmChart, p.absChartHome())
+	} else {
+		stdout, err = p.runHelmCommand(p.AsHelmArgs(p.absChartHome()))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -298,6 +312,16 @@ func (p *plugin) Generate() (rm resmap.ResMap, err error) {
 	return nil, fmt.Errorf("could not parse bytes into resource map: %w", resMapErr)
 }
 
+// pull fetches the chart with the configured renderer,
+// falling back to `helm pull` when there is none.
+func (p *plugin) pull(renderer types.HelmRenderer) error {
+	if renderer != nil {
+		return renderer.Pull(p.HelmChart, p.absChartHome())
+	}
+	_, err := p.runHelmCommand(p.pullCommand())
+	return err
+}
+
 func (p *plugin) pullCommand() []string {
 	args := []string{
 		"pull",
@@ -318,6 +342,9 @@ func (p *plugin) pullCommand() []string {
 	if p.Version != "" {
 		args = append(args, "--version", p.Version)
 	}
+	if p.CredentialsFile != "" {
+		args = append(args, "--registry-config", p.CredentialsFile)
+	}
 	return args
 }
 
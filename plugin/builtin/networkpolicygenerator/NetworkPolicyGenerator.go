@@ -0,0 +1,36 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"sigs.k8s.io/kustomize/api/filters/networkpolicygenerator"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Generate a NetworkPolicy from a compact list of allowed peers.
+type plugin struct {
+	types.NetworkPolicyArgs
+	SelectorLabels map[string]string `json:"selectorLabels,omitempty" yaml:"selectorLabels,omitempty"`
+}
+
+var KustomizePlugin plugin //nolint:gochecknoglobals
+
+func (p *plugin) Config(_ *resmap.PluginHelpers, config []byte) (err error) {
+	p.NetworkPolicyArgs = types.NetworkPolicyArgs{}
+	p.SelectorLabels = nil
+	err = yaml.Unmarshal(config, p)
+	return
+}
+
+func (p *plugin) Generate() (resmap.ResMap, error) {
+	r := resmap.New()
+	err := r.ApplyFilter(networkpolicygenerator.Filter{
+		NetworkPolicy:  p.NetworkPolicyArgs,
+		SelectorLabels: p.SelectorLabels,
+	})
+	return r, err
+}
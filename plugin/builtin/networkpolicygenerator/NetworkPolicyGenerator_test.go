@@ -0,0 +1,56 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestNetworkPolicyGenerator(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("NetworkPolicyGenerator")
+	defer th.Reset()
+
+	rm := th.LoadAndRunGenerator(`
+apiVersion: builtin
+kind: NetworkPolicyGenerator
+metadata:
+  name: notImportantHere
+app: api
+namespace: shop
+selectorLabels:
+  team: shop
+ingress:
+- apps:
+  - web
+  ports:
+  - "8080"
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: api
+  namespace: shop
+spec:
+  ingress:
+  - from:
+    - podSelector:
+        matchLabels:
+          app: web
+          team: shop
+    ports:
+    - port: 8080
+      protocol: TCP
+  podSelector:
+    matchLabels:
+      app: api
+      team: shop
+  policyTypes:
+  - Ingress
+`)
+}
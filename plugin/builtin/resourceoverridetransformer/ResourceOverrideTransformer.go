@@ -0,0 +1,58 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/filters/resourceoverride"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Set or scale the resource requests and limits of containers.
+// Eases the kustomization configuration of workload sizes.
+type plugin struct {
+	ResourceOverride types.ResourceOverride `json:"resourceOverride,omitempty" yaml:"resourceOverride,omitempty"`
+	FieldSpecs       []types.FieldSpec      `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+}
+
+var KustomizePlugin plugin //nolint:gochecknoglobals
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.ResourceOverride = types.ResourceOverride{}
+	p.FieldSpecs = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	o := p.ResourceOverride
+	if len(o.Requests) == 0 && len(o.Limits) == 0 && o.Scale == nil {
+		return fmt.Errorf("resource override must specify requests, limits or scale")
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	resources := m.Resources()
+	if p.ResourceOverride.Target != nil {
+		var err error
+		resources, err = m.Select(*p.ResourceOverride.Target)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range resources {
+		err := r.ApplyFilter(resourceoverride.Filter{
+			Override: p.ResourceOverride,
+			FsSlice:  p.FieldSpecs,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to override the resources of %s: %w", r.CurId(), err)
+		}
+	}
+	return nil
+}
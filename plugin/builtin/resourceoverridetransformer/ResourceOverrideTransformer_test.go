@@ -0,0 +1,127 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestResourceOverrideTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ResourceOverrideTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: ResourceOverrideTransformer
+metadata:
+  name: notImportantHere
+
+resourceOverride:
+  target:
+    labelSelector: tier=web
+  containers:
+  - app
+  limits:
+    memory: 1Gi
+  scale:
+    requests:
+      cpu: 2
+fieldSpecs:
+- path: spec/template/spec/containers
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: 250m
+      - name: proxy
+        image: proxy
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: db
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: db
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: app
+        name: app
+        resources:
+          limits:
+            memory: 1Gi
+          requests:
+            cpu: 500m
+      - image: proxy
+        name: proxy
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: db
+spec:
+  template:
+    spec:
+      containers:
+      - image: db
+        name: app
+`)
+}
+
+func TestResourceOverrideTransformerNoOverride(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ResourceOverrideTransformer")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: ResourceOverrideTransformer
+metadata:
+  name: notImportantHere
+
+resourceOverride:
+  containers:
+  - app
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "resource override must specify requests, limits or scale") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
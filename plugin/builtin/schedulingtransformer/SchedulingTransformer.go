@@ -0,0 +1,60 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/filters/scheduling"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Add tolerations, node selectors, topology spread constraints
+// and affinity to the pods of workloads.
+type plugin struct {
+	Scheduling types.Scheduling  `json:"scheduling,omitempty" yaml:"scheduling,omitempty"`
+	FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+}
+
+var KustomizePlugin plugin //nolint:gochecknoglobals
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Scheduling = types.Scheduling{}
+	p.FieldSpecs = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	s := p.Scheduling
+	if len(s.NodeSelector) == 0 && len(s.Tolerations) == 0 &&
+		len(s.TopologySpreadConstraints) == 0 && len(s.Affinity) == 0 {
+		return fmt.Errorf(
+			"scheduling must specify nodeSelector, tolerations, topologySpreadConstraints or affinity")
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	resources := m.Resources()
+	if p.Scheduling.Target != nil {
+		var err error
+		resources, err = m.Select(*p.Scheduling.Target)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range resources {
+		err := r.ApplyFilter(scheduling.Filter{
+			Scheduling: p.Scheduling,
+			FsSlice:    p.FieldSpecs,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to set the scheduling of %s: %w", r.CurId(), err)
+		}
+	}
+	return nil
+}
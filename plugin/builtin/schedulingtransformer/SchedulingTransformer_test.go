@@ -0,0 +1,122 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestSchedulingTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("SchedulingTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: SchedulingTransformer
+metadata:
+  name: notImportantHere
+
+scheduling:
+  target:
+    labelSelector: pool=spot
+  nodeSelector:
+    pool: spot
+  tolerations:
+  - key: spot
+    operator: Exists
+    effect: NoSchedule
+fieldSpecs:
+- path: spec/template/spec
+  kind: Deployment
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    pool: spot
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: db
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: db
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    pool: spot
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: app
+        name: app
+      nodeSelector:
+        pool: spot
+      tolerations:
+      - effect: NoSchedule
+        key: spot
+        operator: Exists
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: db
+spec:
+  template:
+    spec:
+      containers:
+      - image: db
+        name: app
+`)
+}
+
+func TestSchedulingTransformerNoConstraints(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("SchedulingTransformer")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: SchedulingTransformer
+metadata:
+  name: notImportantHere
+
+scheduling:
+  target:
+    kind: Deployment
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"scheduling must specify nodeSelector, tolerations, topologySpreadConstraints or affinity") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -34,5 +34,5 @@ func (p *plugin) Config(h *resmap.PluginHelpers, config []byte) (err error) {
 
 func (p *plugin) Generate() (resmap.ResMap, error) {
 	return p.h.ResmapFactory().FromSecretArgs(
-		kv.NewLoader(p.h.Loader(), p.h.Validator()), p.SecretArgs)
+		kv.NewSecretLoader(p.h.Loader(), p.h.Validator(), p.h.GeneralConfig()), p.SecretArgs)
 }
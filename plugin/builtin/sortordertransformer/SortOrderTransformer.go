@@ -40,9 +40,10 @@ func (p *plugin) applyDefaults() {
 		}
 	}
 
-	// If legacy sort is selected and no options are given, default to
-	// hardcoded order.
-	if p.SortOptions.Order == types.LegacySortOrder && p.SortOptions.LegacySortOptions == nil {
+	// If legacy sort, or custom sort which refines it, is selected and no
+	// options are given, default to hardcoded order.
+	if (p.SortOptions.Order == types.LegacySortOrder || p.SortOptions.Order == types.CustomSortOrder) &&
+		p.SortOptions.LegacySortOptions == nil {
 		p.SortOptions.LegacySortOptions = &types.LegacySortOptions{
 			OrderFirst: defaultOrderFirst,
 			OrderLast:  defaultOrderLast,
@@ -52,9 +53,10 @@ func (p *plugin) applyDefaults() {
 
 func (p *plugin) validate() error {
 	// Check valid values for SortOrder
-	if p.SortOptions.Order != types.FIFOSortOrder && p.SortOptions.Order != types.LegacySortOrder {
-		return errors.Errorf("the field 'sortOptions.order' must be one of [%s, %s]",
-			types.FIFOSortOrder, types.LegacySortOrder)
+	if p.SortOptions.Order != types.FIFOSortOrder && p.SortOptions.Order != types.LegacySortOrder &&
+		p.SortOptions.Order != types.CustomSortOrder {
+		return errors.Errorf("the field 'sortOptions.order' must be one of [%s, %s, %s]",
+			types.FIFOSortOrder, types.LegacySortOrder, types.CustomSortOrder)
 	}
 
 	// Validate that the only options set are the ones corresponding to the
@@ -65,6 +67,22 @@ func (p *plugin) validate() error {
 			" set but the selected sort order is '%v', not 'legacy'",
 			p.SortOptions.Order)
 	}
+	if p.SortOptions.Order != types.CustomSortOrder &&
+		p.SortOptions.CustomOrder != nil {
+		return errors.Errorf("the field 'sortOptions.customOrder' is"+
+			" set but the selected sort order is '%v', not 'custom'",
+			p.SortOptions.Order)
+	}
+	if p.SortOptions.CustomOrder != nil {
+		for _, key := range p.SortOptions.CustomOrder.SortBy {
+			if key != types.SortByNamespace && key != types.SortByName &&
+				(!strings.HasPrefix(key, types.SortByLabelPrefix) || key == types.SortByLabelPrefix) {
+				return errors.Errorf("invalid key %q in 'sortOptions.customOrder.sortBy';"+
+					" must be one of [%s, %s, %s<key>]",
+					key, types.SortByNamespace, types.SortByName, types.SortByLabelPrefix)
+			}
+		}
+	}
 	return nil
 }
 
@@ -84,6 +102,14 @@ func (p *plugin) Transform(m resmap.ResMap) (err error) {
 			return err
 		}
 	}
+	if p.SortOptions.Order == types.CustomSortOrder {
+		s := newCustomIDSorter(m.Resources(), p.SortOptions)
+		sort.Sort(s)
+		err = applyOrdering(m, s.resids)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -208,6 +234,91 @@ func legacyResIDSortString(id resid.ResId) string {
 		[]string{id.Gvk.String(), ns, nm}, legacySeparator)
 }
 
+// Code for custom sorting.
+// Custom sorting refines legacy sorting, ordering resources by type
+// selectors rather than kinds, and those of a type by the given keys.
+
+// customIDSorter sorts resources based on two priority lists of type
+// selectors, placing the types the legacy sorter sorts in between, and
+// the resources of a type by a list of keys.
+type customIDSorter struct {
+	resids     []resid.ResId
+	labels     []map[string]string
+	options    *types.CustomSortOptions
+	typeOrders map[string]int
+}
+
+func newCustomIDSorter(
+	resources []*resource.Resource,
+	options *types.SortOptions) *customIDSorter {
+	s := &customIDSorter{
+		resids:     make([]resid.ResId, len(resources)),
+		labels:     make([]map[string]string, len(resources)),
+		options:    options.CustomOrder,
+		typeOrders: newLegacyIDSorter(nil, options.LegacySortOptions).typeOrders,
+	}
+	if s.options == nil {
+		s.options = &types.CustomSortOptions{}
+	}
+	for i, r := range resources {
+		s.resids[i] = r.CurId()
+		s.labels[i] = r.GetLabels()
+	}
+	return s
+}
+
+var _ sort.Interface = customIDSorter{}
+
+func (a customIDSorter) Len() int { return len(a.resids) }
+func (a customIDSorter) Swap(i, j int) {
+	a.resids[i], a.resids[j] = a.resids[j], a.resids[i]
+	a.labels[i], a.labels[j] = a.labels[j], a.labels[i]
+}
+func (a customIDSorter) Less(i, j int) bool {
+	gvk1, gvk2 := a.resids[i].Gvk, a.resids[j].Gvk
+	if index1, index2 := a.typeIndex(gvk1), a.typeIndex(gvk2); index1 != index2 {
+		return index1 < index2
+	}
+	if !gvk1.Equals(gvk2) {
+		return gvkLessThan(gvk1, gvk2, a.typeOrders)
+	}
+	for _, key := range a.options.SortBy {
+		v1, v2 := a.sortValue(i, key), a.sortValue(j, key)
+		if v1 != v2 {
+			return v1 < v2
+		}
+	}
+	return legacyResIDSortString(a.resids[i]) < legacyResIDSortString(a.resids[j])
+}
+
+// typeIndex returns the rank of gvk per the first selector of the
+// priority lists selecting it, 0 if none.
+func (a customIDSorter) typeIndex(gvk resid.Gvk) int {
+	for i := range a.options.OrderFirst {
+		if gvk.IsSelected(&a.options.OrderFirst[i]) {
+			return -len(a.options.OrderFirst) + i
+		}
+	}
+	for i := range a.options.OrderLast {
+		if gvk.IsSelected(&a.options.OrderLast[i]) {
+			return 1 + i
+		}
+	}
+	return 0
+}
+
+// sortValue returns the value of the sort key of the i-th resource.
+func (a customIDSorter) sortValue(i int, key string) string {
+	switch key {
+	case types.SortByNamespace:
+		return a.resids[i].Namespace
+	case types.SortByName:
+		return a.resids[i].Name
+	default:
+		return a.labels[i][strings.TrimPrefix(key, types.SortByLabelPrefix)]
+	}
+}
+
 // DO NOT CHANGE!
 // Final legacy ordering provided as a default by kustomize.
 // Originally an attempt to apply resources in the correct order, an effort
@@ -32,7 +32,7 @@ metadata:
 		resources,
 		func(t *testing.T, err error) {
 			t.Helper()
-			require.EqualError(t, err, "the field 'sortOptions.order' must be one of [fifo, legacy]")
+			require.EqualError(t, err, "the field 'sortOptions.order' must be one of [fifo, legacy, custom]")
 		},
 	)
 }
@@ -232,6 +232,64 @@ apiVersion: v1
 kind: Deployment
 metadata:
   name: pear
+`,
+		},
+		{
+			name:      "custom order without options",
+			resources: resources,
+			transformer: `
+apiVersion: builtin
+kind: SortOrderTransformer
+metadata:
+  name: notImportantHere
+sortOptions:
+  order: custom
+`,
+			expectedOutput: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+---
+apiVersion: v1
+kind: Role
+metadata:
+  name: banana
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: apricot
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+---
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: peach
+---
+apiVersion: v1
+kind: Deployment
+metadata:
+  name: pear
+---
+apiVersion: v1
+kind: Ingress
+metadata:
+  name: durian
+---
+apiVersion: v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: pomegranate
 `,
 		},
 		{
@@ -325,3 +383,153 @@ metadata:
 		})
 	}
 }
+
+func TestCustomSortOrder(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).PrepBuiltin("SortOrderTransformer")
+	defer th.Reset()
+	th.AssertActualEqualsExpected(
+		th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: SortOrderTransformer
+metadata:
+  name: notImportantHere
+sortOptions:
+  order: custom
+  customOrder:
+    orderFirst:
+    - kind: CustomResourceDefinition
+    - group: admissionregistration.k8s.io
+    orderLast:
+    - group: example.com
+    sortBy:
+    - label:tier
+    - name
+`, `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w1
+  namespace: a
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: b
+  labels:
+    tier: "2"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: db
+  namespace: a
+  labels:
+    tier: "3"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: c
+  labels:
+    tier: "1"
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: hook
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: a
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+`), `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: hook
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: a
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: "1"
+  name: api
+  namespace: c
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: "2"
+  name: web
+  namespace: b
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: "3"
+  name: db
+  namespace: a
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w1
+  namespace: a
+`)
+}
+
+func TestInvalidCustomSortOrder(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).PrepBuiltin("SortOrderTransformer")
+	defer th.Reset()
+	for transformer, expected := range map[string]string{
+		`
+apiVersion: builtin
+kind: SortOrderTransformer
+metadata:
+  name: notImportantHere
+sortOptions:
+  order: custom
+  customOrder:
+    sortBy:
+    - label
+`: `invalid key "label" in 'sortOptions.customOrder.sortBy'; must be one of [namespace, name, label:<key>]`,
+		`
+apiVersion: builtin
+kind: SortOrderTransformer
+metadata:
+  name: notImportantHere
+sortOptions:
+  order: legacy
+  customOrder: {}
+`: "the field 'sortOptions.customOrder' is set but the selected sort order is 'legacy', not 'custom'",
+	} {
+		th.RunTransformerAndCheckError(transformer, `
+apiVersion: v1
+kind: lalakis
+metadata:
+  name: lalakis
+`, func(t *testing.T, err error) {
+			t.Helper()
+			require.EqualError(t, err, expected)
+		})
+	}
+}